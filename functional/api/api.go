@@ -15,5 +15,21 @@ func MustRegister(router *gin.Engine, apiContext *types.ApiContext) error {
 	// Register invocation endpoints
 	(&v1Invocations{apiContext}).RegisterRoutesTo(groupV1)
 
+	// Register function export and import endpoints
+	(&v1Bundles{apiContext}).RegisterRoutesTo(groupV1)
+
+	// Register dead-letter endpoints for async invocations that exhausted their retries
+	(&v1DeadLetters{apiContext}).RegisterRoutesTo(groupV1)
+
+	// Register rate limit, quota and usage endpoints
+	(&v1RateLimits{apiContext}).RegisterRoutesTo(groupV1)
+
+	// Register the aggregated OpenAPI document for public functions
+	(&v1OpenAPI{apiContext}).RegisterRoutesTo(groupV1)
+
+	// Register the internal call path functions use to invoke each other
+	groupInternal := router.Group("/internal/v1")
+	(&internalInvocations{apiContext}).RegisterRoutesTo(groupInternal)
+
 	return nil
 }
\ No newline at end of file
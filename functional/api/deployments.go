@@ -0,0 +1,312 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/pirogoeth/apps/functional/compute"
+	"github.com/pirogoeth/apps/functional/database"
+	"github.com/pirogoeth/apps/functional/types"
+)
+
+const (
+	defaultDrainTimeout  = 30 * time.Second
+	drainPollInterval    = 1 * time.Second
+	defaultDeployTimeout = 10 * time.Minute
+	// cleanupTimeout bounds recording a failed deployment, which happens after
+	// the deploy itself may have run out of time
+	cleanupTimeout = 10 * time.Second
+)
+
+// deploymentManager moves deployments through the status state machine and
+// records each transition as a deployment event.
+type deploymentManager struct {
+	*types.ApiContext
+}
+
+// deploymentHistoryEntry is a deployment along with the events recorded for it
+type deploymentHistoryEntry struct {
+	database.Deployment
+	Events []database.DeploymentEvent `json:"events"`
+}
+
+// deploy creates a new deployment for the function, builds it on the configured
+// compute provider and, once it is active, drains any deployments it supersedes.
+// It isn't cancelled along with ctx, so that a caller going away doesn't leave
+// the deployment half built, but is bounded by the deploy timeout instead.
+func (m *deploymentManager) deploy(ctx context.Context, function database.Function) (database.Deployment, error) {
+	deployTimeout := m.Config.Runtime.DeployTimeout.Duration
+	if deployTimeout == 0 {
+		deployTimeout = defaultDeployTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), deployTimeout)
+	defer cancel()
+
+	providerName := m.providerNameFor(function)
+	provider, err := m.Compute.Get(providerName)
+	if err != nil {
+		return database.Deployment{}, fmt.Errorf("compute provider not available: %w", err)
+	}
+
+	deployment, err := m.Querier.CreateDeployment(ctx, database.CreateDeploymentParams{
+		ID:         uuid.New().String(),
+		FunctionID: function.ID,
+		Provider:   providerName,
+		ResourceID: "",
+		Status:     string(types.DeploymentStatusPending),
+		Replicas:   1,
+	})
+	if err != nil {
+		return database.Deployment{}, fmt.Errorf("failed to create deployment record: %w", err)
+	}
+
+	if err := m.recordEvent(ctx, deployment.ID, "", types.DeploymentStatusPending, "deployment created"); err != nil {
+		return m.fail(ctx, deployment, err)
+	}
+
+	building, err := m.transition(ctx, deployment, types.DeploymentStatusBuilding, "deploying to "+providerName)
+	if err != nil {
+		return m.fail(ctx, deployment, err)
+	}
+	deployment = building
+
+	deployResult, err := provider.Deploy(ctx, dbFunctionToComputeFunction(function), "")
+	if err != nil {
+		return m.fail(ctx, deployment, fmt.Errorf("deployment failed: %w", err))
+	}
+
+	compResult, ok := deployResult.(*compute.DeployResult)
+	if !ok {
+		return m.fail(ctx, deployment, fmt.Errorf("invalid deploy result type"))
+	}
+
+	result := computeDeployResultToTypesDeployResult(compResult)
+	updated, err := m.Querier.UpdateDeploymentResource(ctx, database.UpdateDeploymentResourceParams{
+		ID:         deployment.ID,
+		ResourceID: result.ResourceID,
+		ImageTag:   sql.NullString{String: result.ImageTag, Valid: result.ImageTag != ""},
+	})
+	if err != nil {
+		deployment.ResourceID = result.ResourceID
+		m.removeFailed(ctx, provider, deployment)
+		return m.fail(ctx, deployment, fmt.Errorf("failed to update deployment resource: %w", err))
+	}
+	deployment = updated
+
+	active, err := m.transition(ctx, deployment, types.DeploymentStatusActive, "deployment is serving invocations")
+	if err != nil {
+		m.removeFailed(ctx, provider, deployment)
+		return m.fail(ctx, deployment, err)
+	}
+	deployment = active
+
+	superseded, err := m.supersede(ctx, function.ID, deployment.ID)
+	if err != nil {
+		logrus.WithError(err).WithField("function_id", function.ID).Error("failed to supersede previous deployments")
+	}
+
+	for _, old := range superseded {
+		go m.drainAndStop(old)
+	}
+
+	return deployment, nil
+}
+
+// removeFailed removes the resource of a deployment that failed after it was
+// built, as nothing will reference it once the deployment has failed
+func (m *deploymentManager) removeFailed(ctx context.Context, provider compute.ComputeProvider, deployment database.Deployment) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cleanupTimeout)
+	defer cancel()
+
+	if err := provider.Remove(ctx, dbDeploymentToComputeDeployment(deployment)); err != nil {
+		logrus.WithError(err).WithField("deployment_id", deployment.ID).Error("failed to remove resource of failed deployment")
+	}
+}
+
+// providerNameFor picks the compute provider for a function. Functions with the
+// wasm runtime run in the wasm provider, everything else in the configured one.
+func (m *deploymentManager) providerNameFor(function database.Function) string {
+	if function.Runtime == compute.WasmRuntime {
+		return compute.WasmRuntime
+	}
+
+	return m.Config.Compute.Provider
+}
+
+// transition moves a deployment to the given status if the state machine allows it
+func (m *deploymentManager) transition(ctx context.Context, deployment database.Deployment, to types.DeploymentStatus, message string) (database.Deployment, error) {
+	from := types.DeploymentStatus(deployment.Status)
+	if !from.CanTransitionTo(to) {
+		return deployment, fmt.Errorf("invalid deployment transition from %s to %s", from, to)
+	}
+
+	updated, err := m.Querier.UpdateDeploymentStatus(ctx, database.UpdateDeploymentStatusParams{
+		ID:     deployment.ID,
+		Status: string(to),
+	})
+	if err != nil {
+		return deployment, fmt.Errorf("failed to update deployment status: %w", err)
+	}
+
+	if err := m.recordEvent(ctx, deployment.ID, from, to, message); err != nil {
+		return updated, err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"deployment_id": deployment.ID,
+		"function_id":   deployment.FunctionID,
+		"from":          from,
+		"to":            to,
+	}).Info("deployment status changed")
+
+	return updated, nil
+}
+
+// fail marks the deployment as failed with the cause as the event message and
+// returns the cause. It's recorded even if ctx is done, e.g. because the deploy
+// timed out.
+func (m *deploymentManager) fail(ctx context.Context, deployment database.Deployment, cause error) (database.Deployment, error) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cleanupTimeout)
+	defer cancel()
+
+	updated, err := m.transition(ctx, deployment, types.DeploymentStatusFailed, cause.Error())
+	if err != nil {
+		logrus.WithError(err).WithField("deployment_id", deployment.ID).Error("failed to mark deployment as failed")
+	}
+
+	return updated, cause
+}
+
+func (m *deploymentManager) recordEvent(ctx context.Context, deploymentID string, from, to types.DeploymentStatus, message string) error {
+	_, err := m.Querier.CreateDeploymentEvent(ctx, database.CreateDeploymentEventParams{
+		DeploymentID: deploymentID,
+		FromStatus:   sql.NullString{String: string(from), Valid: from != ""},
+		ToStatus:     string(to),
+		Message:      sql.NullString{String: message, Valid: message != ""},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record deployment event: %w", err)
+	}
+
+	return nil
+}
+
+// supersede moves every other active deployment of the function to draining so
+// that no new invocations are routed to them
+func (m *deploymentManager) supersede(ctx context.Context, functionID, currentID string) ([]database.Deployment, error) {
+	active, err := m.Querier.ListDeploymentsByFunctionAndStatus(ctx, database.ListDeploymentsByFunctionAndStatusParams{
+		FunctionID: functionID,
+		Status:     string(types.DeploymentStatusActive),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list active deployments: %w", err)
+	}
+
+	draining := make([]database.Deployment, 0, len(active))
+	for _, deployment := range active {
+		if deployment.ID == currentID {
+			continue
+		}
+
+		deployment, err := m.transition(ctx, deployment, types.DeploymentStatusDraining, "superseded by deployment "+currentID)
+		if err != nil {
+			return draining, err
+		}
+		draining = append(draining, deployment)
+	}
+
+	return draining, nil
+}
+
+// drainAndStop waits for the deployment's in-flight invocations to finish, or for
+// the drain timeout to pass, then removes it from its compute provider.
+func (m *deploymentManager) drainAndStop(deployment database.Deployment) {
+	drainTimeout := m.Config.Runtime.DrainTimeout.Duration
+	if drainTimeout == 0 {
+		drainTimeout = defaultDrainTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), drainTimeout+time.Minute)
+	defer cancel()
+
+	logger := logrus.WithFields(logrus.Fields{
+		"deployment_id": deployment.ID,
+		"function_id":   deployment.FunctionID,
+	})
+
+	message := "drained"
+	deadline := time.Now().Add(drainTimeout)
+	for {
+		inFlight, err := m.Querier.CountInFlightInvocationsByDeployment(ctx, sql.NullString{String: deployment.ID, Valid: true})
+		if err != nil {
+			logger.WithError(err).Warn("could not count in-flight invocations, stopping deployment")
+			message = "stopped without draining: " + err.Error()
+			break
+		}
+
+		if inFlight == 0 {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			message = fmt.Sprintf("drain timed out with %d invocations in flight", inFlight)
+			break
+		}
+
+		time.Sleep(drainPollInterval)
+	}
+
+	provider, err := m.Compute.Get(deployment.Provider)
+	if err != nil {
+		m.fail(ctx, deployment, fmt.Errorf("compute provider not available: %w", err))
+		return
+	}
+
+	if err := provider.Remove(ctx, dbDeploymentToComputeDeployment(deployment)); err != nil {
+		m.fail(ctx, deployment, fmt.Errorf("failed to remove deployment: %w", err))
+		return
+	}
+
+	if _, err := m.transition(ctx, deployment, types.DeploymentStatusStopped, message); err != nil {
+		logger.WithError(err).Error("failed to mark deployment as stopped")
+	}
+}
+
+// history returns all deployments of the function, newest first, with their events
+func (m *deploymentManager) history(ctx context.Context, functionID string) ([]deploymentHistoryEntry, error) {
+	deployments, err := m.Querier.GetDeploymentsByFunction(ctx, functionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployments: %w", err)
+	}
+
+	events, err := m.Querier.ListDeploymentEventsByFunction(ctx, functionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployment events: %w", err)
+	}
+
+	eventsByDeployment := make(map[string][]database.DeploymentEvent)
+	for _, event := range events {
+		eventsByDeployment[event.DeploymentID] = append(eventsByDeployment[event.DeploymentID], event)
+	}
+
+	entries := make([]deploymentHistoryEntry, 0, len(deployments))
+	for _, deployment := range deployments {
+		deploymentEvents := eventsByDeployment[deployment.ID]
+		if deploymentEvents == nil {
+			deploymentEvents = []database.DeploymentEvent{}
+		}
+
+		entries = append(entries, deploymentHistoryEntry{
+			Deployment: deployment,
+			Events:     deploymentEvents,
+		})
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,142 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/pirogoeth/apps/functional/database"
+	"github.com/pirogoeth/apps/functional/types"
+)
+
+func deploymentStatus(t *testing.T, apiContext *types.ApiContext, id string) types.DeploymentStatus {
+	t.Helper()
+
+	deployment, err := apiContext.Querier.GetDeployment(context.Background(), id)
+	if err != nil {
+		t.Fatalf("Failed to get deployment %s: %v", id, err)
+	}
+
+	return types.DeploymentStatus(deployment.Status)
+}
+
+func TestDeploymentManager_SupersedeDrainsBeforeStopping(t *testing.T) {
+	_, apiContext := setupTestAPI(t)
+	apiContext.Config.Compute.Provider = "mock"
+	ctx := context.Background()
+
+	function := createTestDeployedFunction(t, apiContext, "drain-function")
+	oldID := "drain-function-deployment-id"
+
+	_, err := apiContext.Querier.CreateInvocation(ctx, database.CreateInvocationParams{
+		ID:           "in-flight-invocation",
+		FunctionID:   function.ID,
+		DeploymentID: sql.NullString{String: oldID, Valid: true},
+		Status:       string(types.InvocationStatusRunning),
+		Attempt:      1,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create invocation: %v", err)
+	}
+
+	manager := &deploymentManager{apiContext}
+	deployment, err := manager.deploy(ctx, function)
+	if err != nil {
+		t.Fatalf("Failed to deploy: %v", err)
+	}
+
+	if status := types.DeploymentStatus(deployment.Status); status != types.DeploymentStatusActive {
+		t.Errorf("Expected the new deployment to be active, got %s", status)
+	}
+	if status := deploymentStatus(t, apiContext, oldID); status != types.DeploymentStatusDraining {
+		t.Fatalf("Expected the old deployment to be draining, got %s", status)
+	}
+
+	// The old deployment keeps draining while its invocation is in flight
+	time.Sleep(drainPollInterval + drainPollInterval/2)
+	if status := deploymentStatus(t, apiContext, oldID); status != types.DeploymentStatusDraining {
+		t.Fatalf("Expected the old deployment to drain its invocation, got %s", status)
+	}
+
+	_, err = apiContext.Querier.UpdateInvocationComplete(ctx, database.UpdateInvocationCompleteParams{
+		ID:     "in-flight-invocation",
+		Status: string(types.InvocationStatusSuccess),
+	})
+	if err != nil {
+		t.Fatalf("Failed to complete invocation: %v", err)
+	}
+
+	// The status changes just before the event is recorded, so wait for both
+	oldTransitions := func() []string {
+		events, err := apiContext.Querier.ListDeploymentEventsByFunction(ctx, function.ID)
+		if err != nil {
+			t.Fatalf("Failed to list deployment events: %v", err)
+		}
+
+		var transitions []string
+		for _, event := range events {
+			if event.DeploymentID == oldID {
+				transitions = append(transitions, event.FromStatus.String+"->"+event.ToStatus)
+			}
+		}
+		return transitions
+	}
+
+	deadline := time.Now().Add(5 * drainPollInterval)
+	transitions := oldTransitions()
+	for len(transitions) < 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the old deployment to stop once drained, got %s", deploymentStatus(t, apiContext, oldID))
+		}
+		time.Sleep(drainPollInterval / 10)
+		transitions = oldTransitions()
+	}
+
+	if status := deploymentStatus(t, apiContext, oldID); status != types.DeploymentStatusStopped {
+		t.Errorf("Expected the old deployment to be stopped, got %s", status)
+	}
+	if len(transitions) != 2 || transitions[0] != "active->draining" || transitions[1] != "draining->stopped" {
+		t.Errorf("Expected the old deployment to go active, draining, stopped, got %v", transitions)
+	}
+}
+
+func TestDeploymentManager_DeployOutlivesCaller(t *testing.T) {
+	_, apiContext := setupTestAPI(t)
+	apiContext.Config.Compute.Provider = "mock"
+
+	function := createTestDeployedFunction(t, apiContext, "detached-function")
+
+	// The caller went away before the deploy started
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	manager := &deploymentManager{apiContext}
+	deployment, err := manager.deploy(ctx, function)
+	if err != nil {
+		t.Fatalf("Failed to deploy: %v", err)
+	}
+	if status := deploymentStatus(t, apiContext, deployment.ID); status != types.DeploymentStatusActive {
+		t.Errorf("Expected the deployment to be active, got %s", status)
+	}
+}
+
+func TestDeploymentManager_DeployTimeoutFailsDeployment(t *testing.T) {
+	_, apiContext := setupTestAPI(t)
+	apiContext.Config.Compute.Provider = "mock"
+	apiContext.Config.Runtime.DeployTimeout.Duration = 50 * time.Millisecond
+	mockProviderFrom(t, apiContext).deployBlocks = true
+
+	function := createTestDeployedFunction(t, apiContext, "slow-function")
+
+	manager := &deploymentManager{apiContext}
+	deployment, err := manager.deploy(context.Background(), function)
+	if err == nil {
+		t.Fatalf("Expected the deploy to time out")
+	}
+
+	// The failure is recorded even though the deploy ran out of time
+	if status := deploymentStatus(t, apiContext, deployment.ID); status != types.DeploymentStatusFailed {
+		t.Errorf("Expected the deployment to have failed, got %s", status)
+	}
+}
@@ -1,9 +1,19 @@
 package api
 
 import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/pirogoeth/apps/functional/types"
+	"github.com/pirogoeth/apps/functional/validation"
+	"github.com/pirogoeth/apps/pkg/apitools"
 )
 
 // GetQueryInt extracts an integer query parameter with a default value
@@ -14,4 +24,108 @@ func GetQueryInt(c *gin.Context, key string, defaultValue int) int {
 		return defaultValue
 	}
 	return intVal
-}
\ No newline at end of file
+}
+
+// schemaColumn checks that a schema from a request is a valid JSON Schema and
+// converts it for storage. An absent schema is stored as NULL.
+func schemaColumn(field string, schema json.RawMessage) (sql.NullString, error) {
+	if len(schema) == 0 || string(schema) == "null" {
+		return sql.NullString{}, nil
+	}
+
+	if err := validation.CheckSchema(string(schema)); err != nil {
+		return sql.NullString{}, fmt.Errorf("%s: %s: %w", apitools.MsgInvalidParameter, field, err)
+	}
+
+	return sql.NullString{String: string(schema), Valid: true}, nil
+}
+
+// checkRetryPolicy rejects a retry policy with negative values or more than
+// types.MaxRetryAttempts attempts. An invalid policy aborts the request with a
+// 400 and false is returned.
+func checkRetryPolicy(c *gin.Context, maxAttempts, backoffMS int64) bool {
+	problems := []string{}
+	if maxAttempts < 0 || backoffMS < 0 {
+		problems = append(problems, "retry_policy values must not be negative")
+	}
+	if maxAttempts > types.MaxRetryAttempts {
+		problems = append(problems, fmt.Sprintf("retry_policy.max_attempts must not be more than %d", types.MaxRetryAttempts))
+	}
+	if len(problems) == 0 {
+		return true
+	}
+
+	c.AbortWithStatusJSON(http.StatusBadRequest, &gin.H{
+		"message": "invalid retry policy",
+		"errors":  problems,
+	})
+	return false
+}
+
+// validateAgainstSchema checks document against a function's schema, if it has
+// one. A document that does not match aborts the request with status and the
+// structured validation errors, and false is returned.
+func validateAgainstSchema(c *gin.Context, schema sql.NullString, document []byte, status int, message string) (bool, error) {
+	if !schema.Valid {
+		return true, nil
+	}
+
+	err := validation.Validate(schema.String, document)
+	var validationErr *validation.Error
+	if errors.As(err, &validationErr) {
+		c.AbortWithStatusJSON(status, &gin.H{
+			"message": message,
+			"errors":  validationErr.Errors,
+		})
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to validate against function schema: %w", err)
+	}
+
+	return true, nil
+}
+
+// writeFunctionCode stores a function's code archive under the functions path,
+// replacing any code stored for the function before
+func writeFunctionCode(storage types.StorageConfig, functionID string, code []byte) (string, error) {
+	codePath, stagedPath, err := stageFunctionCode(storage, functionID, code)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.Rename(stagedPath, codePath); err != nil {
+		os.Remove(stagedPath)
+		return "", fmt.Errorf("failed to write function code: %w", err)
+	}
+
+	return codePath, nil
+}
+
+// stageFunctionCode writes a function's code archive next to where it is
+// stored, without replacing the stored code. It returns the path the code is
+// stored at and the staged file, which the caller renames into place once the
+// function's record is saved, or removes if it isn't.
+func stageFunctionCode(storage types.StorageConfig, functionID string, code []byte) (string, string, error) {
+	functionDir := filepath.Join(storage.FunctionsPath, functionID)
+	if err := os.MkdirAll(functionDir, 0755); err != nil {
+		return "", "", fmt.Errorf("failed to create function directory: %w", err)
+	}
+
+	staged, err := os.CreateTemp(functionDir, "code-*.zip.tmp")
+	if err != nil {
+		return "", "", fmt.Errorf("failed to write function code: %w", err)
+	}
+	if _, err := staged.Write(code); err != nil {
+		staged.Close()
+		os.Remove(staged.Name())
+		return "", "", fmt.Errorf("failed to write function code: %w", err)
+	}
+	if err := staged.Close(); err != nil {
+		os.Remove(staged.Name())
+		return "", "", fmt.Errorf("failed to write function code: %w", err)
+	}
+
+	// Store code (for now, just save as code.zip)
+	return filepath.Join(functionDir, "code.zip"), staged.Name(), nil
+}
@@ -0,0 +1,178 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/pirogoeth/apps/functional/calltoken"
+	"github.com/pirogoeth/apps/functional/database"
+	"github.com/pirogoeth/apps/functional/types"
+	"github.com/pirogoeth/apps/pkg/apitools"
+)
+
+const (
+	// defaultMaxCallDepth bounds chains of function-to-function calls so that
+	// recursive functions can't invoke each other forever
+	defaultMaxCallDepth = 8
+	// callTokenGrace keeps tokens valid for calls made right before the timeout
+	callTokenGrace = 30 * time.Second
+)
+
+// internalInvocations serves the call path functions use to invoke other
+// functions. Requests are authenticated with the token issued to the calling
+// invocation instead of going through the public gateway.
+type internalInvocations struct {
+	*types.ApiContext
+}
+
+func (e *internalInvocations) RegisterRoutesTo(router *gin.RouterGroup) {
+	router.POST("/invoke/:function_name", apitools.ErrorWrapEndpoint(e.invokeFunction))
+}
+
+func (e *internalInvocations) invokeFunction(c *gin.Context) error {
+	functionName := c.Param("function_name")
+	if functionName == "" {
+		return fmt.Errorf("%s: function name is required", apitools.MsgInvalidParameter)
+	}
+
+	claims, err := e.verifyCaller(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, &gin.H{
+			"message": "invalid invoke token",
+			"errors":  []string{err.Error()},
+		})
+		return nil
+	}
+
+	maxDepth := e.Config.Internal.MaxCallDepth
+	if maxDepth <= 0 {
+		maxDepth = defaultMaxCallDepth
+	}
+
+	depth := claims.Depth + 1
+	if depth > maxDepth {
+		c.AbortWithStatusJSON(http.StatusLoopDetected, &gin.H{
+			"message": "maximum call depth exceeded",
+			"errors":  []string{fmt.Sprintf("call depth %d exceeds the limit of %d", depth, maxDepth)},
+		})
+		return nil
+	}
+
+	function, err := e.Querier.GetFunctionByName(c.Request.Context(), functionName)
+	if err != nil {
+		return fmt.Errorf("function not found: %w", err)
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read request body: %w", err)
+	}
+
+	invReq := newInvocationRequest(c, function.ID, body)
+	invReq.CallDepth = depth
+	// The caller's token must not reach the function it calls
+	delete(invReq.Headers, "Authorization")
+
+	if ok, err := validateAgainstSchema(c, function.InputSchema, invReq.Body, http.StatusBadRequest, "request body does not match function input schema"); !ok {
+		return err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"function_id":          function.ID,
+		"caller_function_id":   claims.FunctionID,
+		"caller_invocation_id": claims.InvocationID,
+		"call_depth":           depth,
+	}).Debug("internal function invocation")
+
+	outcome, err := (&invoker{e.ApiContext}).execute(c.Request.Context(), function, invReq, 1)
+	if err != nil {
+		return err
+	}
+
+	respondWithOutcome(c, outcome)
+	return nil
+}
+
+// verifyCaller checks the bearer token of an internal invocation
+func (e *internalInvocations) verifyCaller(c *gin.Context) (*calltoken.Claims, error) {
+	if !internalInvocationsEnabled(e.Config) {
+		return nil, errors.New("internal invocations are not enabled")
+	}
+
+	token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		return nil, errors.New("missing bearer token")
+	}
+
+	return calltoken.Verify([]byte(e.Config.Internal.SigningKey), token, time.Now())
+}
+
+// callHeaders returns the headers an invocation is executed with. Besides the
+// request headers they carry the trace context, the call depth and, when
+// internal invocations are enabled, a token the function can use to invoke
+// other functions for as long as it may run.
+func callHeaders(ctx context.Context, config *types.Config, function database.Function, invocationID string, req *types.InvocationRequest) map[string]string {
+	span := trace.SpanContextFromContext(ctx)
+
+	headers := make(map[string]string, len(req.Headers)+3)
+	for name, value := range req.Headers {
+		switch http.CanonicalHeaderKey(name) {
+		case calltoken.HeaderToken, calltoken.HeaderInvokeURL, calltoken.HeaderCallDepth:
+			// Callers can't hand the function a token or depth of their choosing
+			continue
+		case "Traceparent", "Tracestate":
+			if span.IsValid() {
+				continue
+			}
+		}
+		headers[name] = value
+	}
+
+	if span.IsValid() {
+		propagation.TraceContext{}.Inject(ctx, propagation.MapCarrier(headers))
+	}
+
+	headers[calltoken.HeaderCallDepth] = strconv.Itoa(req.CallDepth)
+
+	if !internalInvocationsEnabled(config) {
+		return headers
+	}
+
+	timeout := time.Duration(function.TimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = config.Runtime.DefaultTimeout.Duration
+	}
+
+	token, err := calltoken.Issue([]byte(config.Internal.SigningKey), calltoken.Claims{
+		FunctionID:   function.ID,
+		InvocationID: invocationID,
+		Depth:        req.CallDepth,
+		ExpiresAt:    time.Now().Add(timeout + callTokenGrace).Unix(),
+	})
+	if err != nil {
+		logrus.WithError(err).WithField("invocation_id", invocationID).Warn("failed to issue invoke token")
+		return headers
+	}
+
+	headers[calltoken.HeaderToken] = token
+	headers[calltoken.HeaderInvokeURL] = config.Internal.InvokeURL
+	return headers
+}
+
+// internalInvocationsEnabled reports whether functions may invoke each other.
+// It takes an invoke URL, the server can't tell how the provider's functions
+// reach it.
+func internalInvocationsEnabled(config *types.Config) bool {
+	return config.Internal.SigningKey != "" && config.Internal.InvokeURL != ""
+}
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pirogoeth/apps/functional/calltoken"
+)
+
+func TestInternalInvocations_CallPath(t *testing.T) {
+	router, apiContext := setupTestAPI(t)
+	apiContext.Config.Internal.SigningKey = "internal-test-key"
+	apiContext.Config.Internal.InvokeURL = "http://gateway:8080/internal/v1/invoke"
+	apiContext.Config.Internal.MaxCallDepth = 2
+	mock := mockProviderFrom(t, apiContext)
+
+	caller := createTestDeployedFunction(t, apiContext, "caller-function")
+	callee := createTestDeployedFunction(t, apiContext, "callee-function")
+
+	// Public callers can't forge a token or call depth
+	req := httptest.NewRequest(http.MethodPost, "/v1/invoke/"+caller.Name, bytes.NewReader([]byte(`{}`)))
+	req.Header.Set(calltoken.HeaderToken, "forged")
+	req.Header.Set(calltoken.HeaderCallDepth, "7")
+	req.Header.Set("Traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected public invocation to succeed, got %d: %s", w.Code, w.Body.String())
+	}
+
+	headers := mock.lastRequest.Headers
+	if headers[calltoken.HeaderCallDepth] != "0" {
+		t.Errorf("Expected call depth 0, got %q", headers[calltoken.HeaderCallDepth])
+	}
+	if headers[calltoken.HeaderInvokeURL] != "http://gateway:8080/internal/v1/invoke" {
+		t.Errorf("Expected the configured invoke URL to be passed to the function, got %q", headers[calltoken.HeaderInvokeURL])
+	}
+	if headers["Traceparent"] == "" {
+		t.Errorf("Expected trace context to be propagated")
+	}
+
+	token := headers[calltoken.HeaderToken]
+	claims, err := calltoken.Verify([]byte("internal-test-key"), token, time.Now())
+	if err != nil {
+		t.Fatalf("Expected a valid invoke token, got %v", err)
+	}
+	if claims.FunctionID != caller.ID || claims.Depth != 0 {
+		t.Errorf("Unexpected token claims: %+v", claims)
+	}
+
+	internalInvoke := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/internal/v1/invoke/"+callee.Name, bytes.NewReader([]byte(`{}`)))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w = internalInvoke(token)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected internal invocation to succeed, got %d: %s", w.Code, w.Body.String())
+	}
+
+	headers = mock.lastRequest.Headers
+	if headers[calltoken.HeaderCallDepth] != "1" {
+		t.Errorf("Expected call depth 1, got %q", headers[calltoken.HeaderCallDepth])
+	}
+	if _, ok := headers["Authorization"]; ok {
+		t.Errorf("Expected the caller's token not to reach the callee")
+	}
+
+	// The callee's own token allows one more call, the next one is too deep
+	w = internalInvoke(headers[calltoken.HeaderToken])
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected call at the maximum depth to succeed, got %d", w.Code)
+	}
+	if w = internalInvoke(mock.lastRequest.Headers[calltoken.HeaderToken]); w.Code != http.StatusLoopDetected {
+		t.Errorf("Expected call over the maximum depth to get 508, got %d", w.Code)
+	}
+
+	if w = internalInvoke(""); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected missing token to get 401, got %d", w.Code)
+	}
+	if w = internalInvoke("forged"); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected forged token to get 401, got %d", w.Code)
+	}
+}
+
+func TestInternalInvocations_RequireInvokeURL(t *testing.T) {
+	router, apiContext := setupTestAPI(t)
+	apiContext.Config.Internal.SigningKey = "internal-test-key"
+	mock := mockProviderFrom(t, apiContext)
+
+	caller := createTestDeployedFunction(t, apiContext, "caller-function")
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/invoke/"+caller.Name, bytes.NewReader([]byte(`{}`)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected public invocation to succeed, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// Without an invoke URL, the function isn't told how to call back
+	headers := mock.lastRequest.Headers
+	if _, ok := headers[calltoken.HeaderInvokeURL]; ok {
+		t.Errorf("Expected no invoke URL without one configured, got %q", headers[calltoken.HeaderInvokeURL])
+	}
+	if _, ok := headers[calltoken.HeaderToken]; ok {
+		t.Errorf("Expected no invoke token without an invoke URL")
+	}
+}
@@ -0,0 +1,232 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/pirogoeth/apps/functional/compute"
+	"github.com/pirogoeth/apps/functional/database"
+	"github.com/pirogoeth/apps/functional/types"
+	"github.com/pirogoeth/apps/functional/validation"
+)
+
+// invoker runs invocations against a function's active deployment and records
+// them. Async invocations are retried according to the function's retry policy
+// and land in the dead-letter list once it is exhausted.
+type invoker struct {
+	*types.ApiContext
+}
+
+// invocationOutcome is the result of a single attempt at invoking a function
+type invocationOutcome struct {
+	InvocationID string
+	Result       *types.InvocationResult
+	// OutputErr is set when a successful response does not match the function's output schema
+	OutputErr *validation.Error
+}
+
+// failed reports whether the attempt should be retried
+func (o *invocationOutcome) failed() bool {
+	return o.Result.StatusCode >= 500 || o.OutputErr != nil
+}
+
+// errorMessage describes why the attempt failed
+func (o *invocationOutcome) errorMessage() string {
+	if o.Result.Error != "" {
+		return o.Result.Error
+	}
+
+	return fmt.Sprintf("function responded with status %d", o.Result.StatusCode)
+}
+
+// retryPolicyFor returns the retry policy stored with the function
+func retryPolicyFor(function database.Function) types.RetryPolicy {
+	return types.RetryPolicy{
+		MaxAttempts: int32(function.RetryMaxAttempts),
+		BackoffMS:   function.RetryBackoffMs,
+	}
+}
+
+// execute makes a single attempt at invoking the function and records it as an
+// invocation. Errors are only returned when the attempt could not be made or
+// recorded; a failing function is reported through the outcome.
+func (i *invoker) execute(ctx context.Context, function database.Function, req *types.InvocationRequest, attempt int) (*invocationOutcome, error) {
+	deployment, err := i.Querier.GetActiveDeploymentByFunction(ctx, function.ID)
+	if err != nil {
+		return nil, fmt.Errorf("no active deployment found for function: %w", err)
+	}
+
+	invocationID := uuid.New().String()
+	_, err = i.Querier.CreateInvocation(ctx, database.CreateInvocationParams{
+		ID:           invocationID,
+		FunctionID:   function.ID,
+		DeploymentID: sql.NullString{String: deployment.ID, Valid: true},
+		Status:       string(types.InvocationStatusPending),
+		Attempt:      int64(attempt),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create invocation record: %w", err)
+	}
+
+	provider, err := i.Compute.Get(deployment.Provider)
+	if err != nil {
+		return nil, fmt.Errorf("compute provider not available: %w", err)
+	}
+
+	computeReq := typesInvocationRequestToComputeInvocationRequest(req)
+	computeReq.Headers = callHeaders(ctx, i.Config, function, invocationID, req)
+
+	result, err := provider.Execute(ctx, dbDeploymentToComputeDeployment(deployment), computeReq)
+	if err != nil {
+		i.Querier.UpdateInvocationComplete(ctx, database.UpdateInvocationCompleteParams{
+			ID:     invocationID,
+			Status: string(types.InvocationStatusError),
+			Error:  sql.NullString{String: err.Error(), Valid: true},
+		})
+		return nil, fmt.Errorf("function execution failed: %w", err)
+	}
+
+	compResult, ok := result.(*compute.InvocationResult)
+	if !ok {
+		return nil, fmt.Errorf("invalid invocation result type")
+	}
+
+	outcome := &invocationOutcome{
+		InvocationID: invocationID,
+		Result:       computeInvocationResultToTypesInvocationResult(compResult),
+	}
+
+	status := string(types.InvocationStatusSuccess)
+	if outcome.Result.StatusCode >= 400 {
+		status = string(types.InvocationStatusError)
+	}
+
+	// A successful response that breaks the function's output contract is
+	// treated as a failure rather than passed through
+	if function.OutputSchema.Valid && outcome.Result.StatusCode < 400 {
+		err := validation.Validate(function.OutputSchema.String, outcome.Result.Body)
+		if errors.As(err, &outcome.OutputErr) {
+			status = string(types.InvocationStatusError)
+			outcome.Result.Error = outcome.OutputErr.Error()
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to validate against function schema: %w", err)
+		}
+	}
+
+	invResult := outcome.Result
+	i.Querier.UpdateInvocationComplete(ctx, database.UpdateInvocationCompleteParams{
+		ID:                invocationID,
+		Status:            status,
+		DurationMs:        sql.NullInt64{Int64: invResult.DurationMS, Valid: true},
+		MemoryUsedMb:      sql.NullInt64{Int64: int64(invResult.MemoryUsedMB), Valid: true},
+		ResponseSizeBytes: sql.NullInt64{Int64: invResult.ResponseSize, Valid: true},
+		Logs:              sql.NullString{String: invResult.Logs, Valid: invResult.Logs != ""},
+		Error:             sql.NullString{String: invResult.Error, Valid: invResult.Error != ""},
+	})
+
+	return outcome, nil
+}
+
+// executeWithRetry attempts the invocation until it succeeds or the function's
+// retry policy is exhausted, waiting with exponential backoff between attempts.
+// When every attempt fails the request is moved to the dead-letter list.
+func (i *invoker) executeWithRetry(ctx context.Context, function database.Function, req *types.InvocationRequest) (*invocationOutcome, error) {
+	policy := retryPolicyFor(function)
+	maxAttempts := int(policy.MaxAttempts)
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	// Policies are capped when functions are created, this covers any stored
+	// before the cap existed
+	if maxAttempts > types.MaxRetryAttempts {
+		maxAttempts = types.MaxRetryAttempts
+	}
+
+	logger := logrus.WithField("function_id", function.ID)
+
+	var (
+		outcome   *invocationOutcome
+		lastError string
+	)
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		if attempt > 1 {
+			backoff := policy.Backoff(attempt - 1)
+			logger.WithFields(logrus.Fields{
+				"attempt": attempt,
+				"backoff": backoff,
+			}).Info("retrying failed invocation")
+
+			select {
+			case <-ctx.Done():
+				return outcome, ctx.Err()
+			case <-time.After(backoff):
+			}
+		}
+
+		var err error
+		outcome, err = i.execute(ctx, function, req, attempt)
+		if err != nil {
+			lastError = err.Error()
+			logger.WithError(err).WithField("attempt", attempt).Warn("invocation attempt failed")
+			continue
+		}
+
+		if !outcome.failed() {
+			return outcome, nil
+		}
+
+		lastError = outcome.errorMessage()
+		logger.WithFields(logrus.Fields{
+			"attempt":       attempt,
+			"invocation_id": outcome.InvocationID,
+			"error":         lastError,
+		}).Warn("invocation attempt failed")
+	}
+
+	var invocationID string
+	if outcome != nil {
+		invocationID = outcome.InvocationID
+	}
+
+	deadLetter, err := i.deadLetter(ctx, function, req, invocationID, maxAttempts, lastError)
+	if err != nil {
+		return outcome, err
+	}
+
+	logger.WithFields(logrus.Fields{
+		"dead_letter_id": deadLetter.ID,
+		"attempts":       maxAttempts,
+	}).Error("invocation moved to dead-letter list after exhausting retries")
+
+	return outcome, nil
+}
+
+// deadLetter stores a request whose retries were exhausted so it can be
+// inspected and replayed later
+func (i *invoker) deadLetter(ctx context.Context, function database.Function, req *types.InvocationRequest, invocationID string, attempts int, lastError string) (database.DeadLetter, error) {
+	request, err := json.Marshal(req)
+	if err != nil {
+		return database.DeadLetter{}, fmt.Errorf("failed to serialize invocation request: %w", err)
+	}
+
+	deadLetter, err := i.Querier.CreateDeadLetter(ctx, database.CreateDeadLetterParams{
+		ID:           uuid.New().String(),
+		FunctionID:   function.ID,
+		InvocationID: sql.NullString{String: invocationID, Valid: invocationID != ""},
+		Attempts:     int64(attempts),
+		LastError:    sql.NullString{String: lastError, Valid: lastError != ""},
+		Request:      string(request),
+	})
+	if err != nil {
+		return database.DeadLetter{}, fmt.Errorf("failed to create dead letter: %w", err)
+	}
+
+	return deadLetter, nil
+}
@@ -0,0 +1,136 @@
+package api
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/pirogoeth/apps/functional/database"
+	"github.com/pirogoeth/apps/functional/ratelimit"
+	"github.com/pirogoeth/apps/functional/types"
+)
+
+// apiKeyHeader identifies the caller that per-key limits and usage apply to
+const apiKeyHeader = "X-API-Key"
+
+// applyRateLimits enforces the rate limits and quotas that apply to the caller
+// of a function. Requests over a limit abort with 429 and false is returned.
+// Every limit is checked before any token is taken, so a rejected request
+// doesn't use up the caller's burst. Usage is counted separately by
+// recordInvocationUsage once the request is known to be valid, so concurrent
+// requests may overshoot a quota slightly.
+func applyRateLimits(c *gin.Context, apiContext *types.ApiContext, function database.Function) (bool, error) {
+	ctx := c.Request.Context()
+	now := time.Now()
+	keyHash := ratelimit.HashKey(c.GetHeader(apiKeyHeader))
+
+	limits, err := apiContext.Querier.ListApplicableRateLimits(ctx, database.ListApplicableRateLimitsParams{
+		FunctionID: function.ID,
+		ApiKeyHash: keyHash,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to list rate limits: %w", err)
+	}
+
+	for _, limit := range limits {
+		for _, quota := range []struct {
+			name   string
+			period string
+			limit  int64
+		}{
+			{"daily", ratelimit.PeriodDay, limit.DailyQuota},
+			{"monthly", ratelimit.PeriodMonth, limit.MonthlyQuota},
+		} {
+			if quota.limit <= 0 {
+				continue
+			}
+
+			used, err := usageFor(c, apiContext, function.ID, limit.ApiKeyHash, quota.period, now)
+			if err != nil {
+				return false, err
+			}
+			if used >= quota.limit {
+				abortTooManyRequests(c, quota.name+" quota exceeded", ratelimit.PeriodEnd(quota.period, now).Sub(now))
+				return false, nil
+			}
+		}
+	}
+
+	buckets := make([]ratelimit.Limit, 0, len(limits))
+	for _, limit := range limits {
+		buckets = append(buckets, ratelimit.Limit{
+			Key:   function.ID + "/" + limit.ApiKeyHash,
+			Rate:  limit.RequestsPerSecond,
+			Burst: limit.Burst,
+		})
+	}
+	if ok, wait := apiContext.Limiter.AllowAll(buckets, now); !ok {
+		abortTooManyRequests(c, "rate limit exceeded", wait)
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// recordInvocationUsage counts the request towards its caller's quotas. It's
+// called once the request has been validated, so requests rejected with a
+// client error don't use up the quota.
+func recordInvocationUsage(c *gin.Context, apiContext *types.ApiContext, function database.Function) error {
+	now := time.Now()
+	keyHash := ratelimit.HashKey(c.GetHeader(apiKeyHeader))
+
+	for _, period := range []string{ratelimit.PeriodDay, ratelimit.PeriodMonth} {
+		err := apiContext.Querier.IncrementInvocationUsage(c.Request.Context(), database.IncrementInvocationUsageParams{
+			FunctionID:  function.ID,
+			ApiKeyHash:  keyHash,
+			Period:      period,
+			PeriodStart: ratelimit.PeriodStart(period, now),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to record invocation usage: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// usageFor returns the requests counted in the current period for a caller, or
+// for all callers of the function if keyHash is empty
+func usageFor(c *gin.Context, apiContext *types.ApiContext, functionID, keyHash, period string, now time.Time) (int64, error) {
+	periodStart := ratelimit.PeriodStart(period, now)
+
+	var (
+		used int64
+		err  error
+	)
+	if keyHash == "" {
+		used, err = apiContext.Querier.GetTotalInvocationUsage(c.Request.Context(), database.GetTotalInvocationUsageParams{
+			FunctionID:  functionID,
+			Period:      period,
+			PeriodStart: periodStart,
+		})
+	} else {
+		used, err = apiContext.Querier.GetInvocationUsage(c.Request.Context(), database.GetInvocationUsageParams{
+			FunctionID:  functionID,
+			ApiKeyHash:  keyHash,
+			Period:      period,
+			PeriodStart: periodStart,
+		})
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get invocation usage: %w", err)
+	}
+
+	return used, nil
+}
+
+func abortTooManyRequests(c *gin.Context, message string, retryAfter time.Duration) {
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	c.AbortWithStatusJSON(http.StatusTooManyRequests, &gin.H{
+		"message": message,
+	})
+}
@@ -0,0 +1,294 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/pirogoeth/apps/functional/bundle"
+	"github.com/pirogoeth/apps/functional/database"
+	"github.com/pirogoeth/apps/functional/types"
+	"github.com/pirogoeth/apps/pkg/apitools"
+)
+
+// maxBundleSize bounds the size of an uploaded bundle
+const maxBundleSize = 512 * 1024 * 1024
+
+const (
+	importResultCreated   = "created"
+	importResultUpdated   = "updated"
+	importResultUnchanged = "unchanged"
+)
+
+// v1Bundles exports functions as signed bundles and imports them on another
+// server, for promoting functions between environments
+type v1Bundles struct {
+	*types.ApiContext
+}
+
+func (e *v1Bundles) RegisterRoutesTo(router *gin.RouterGroup) {
+	functions := router.Group("/functions")
+	functions.GET("/:id/export", apitools.ErrorWrapEndpoint(e.exportFunction))
+	functions.POST("/import", apitools.ErrorWrapEndpoint(e.importFunction))
+}
+
+func (e *v1Bundles) exportFunction(c *gin.Context) error {
+	id := c.Param("id")
+	if id == "" {
+		return fmt.Errorf("%s: function id is required", apitools.MsgInvalidParameter)
+	}
+
+	function, err := e.Querier.GetFunction(c.Request.Context(), id)
+	if err != nil {
+		return fmt.Errorf("function not found: %w", err)
+	}
+
+	code, err := os.ReadFile(function.CodePath)
+	if err != nil {
+		return fmt.Errorf("failed to read function code: %w", err)
+	}
+
+	manifest, err := functionManifest(function, code)
+	if err != nil {
+		return err
+	}
+	manifest.ExportedAt = time.Now().UTC()
+
+	var buf bytes.Buffer
+	if err := bundle.Write(&buf, manifest, code, []byte(e.Config.Bundles.SigningKey)); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", function.Name+".tar.gz"))
+	c.Data(http.StatusOK, "application/gzip", buf.Bytes())
+	return nil
+}
+
+// importFunction creates the function in a bundle, or updates the function of
+// the same name. Importing a bundle that matches the existing function changes
+// nothing, so the same bundle can be applied repeatedly.
+func (e *v1Bundles) importFunction(c *gin.Context) error {
+	ctx := c.Request.Context()
+
+	manifest, code, err := bundle.Read(io.LimitReader(c.Request.Body, maxBundleSize), []byte(e.Config.Bundles.SigningKey))
+	if errors.Is(err, bundle.ErrNoSigningKey) {
+		return err
+	}
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, &gin.H{
+			"message": "invalid function bundle",
+			"errors":  []string{err.Error()},
+		})
+		return nil
+	}
+
+	spec := manifest.Function
+	if spec.Name == "" || spec.Runtime == "" || spec.Handler == "" {
+		return fmt.Errorf("%s: bundle function must have a name, runtime and handler", apitools.MsgInvalidParameter)
+	}
+	if !checkRetryPolicy(c, spec.RetryPolicy.MaxAttempts, spec.RetryPolicy.BackoffMS) {
+		return nil
+	}
+
+	inputSchema, err := schemaColumn("input_schema", spec.InputSchema)
+	if err != nil {
+		return err
+	}
+	outputSchema, err := schemaColumn("output_schema", spec.OutputSchema)
+	if err != nil {
+		return err
+	}
+	inputAdapter, err := inputAdapterColumn(spec.InputAdapter)
+	if err != nil {
+		return err
+	}
+
+	existing, err := e.Querier.GetFunctionByName(ctx, spec.Name)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to look up function: %w", err)
+	}
+	exists := err == nil
+
+	// Values for the bundle's environment variables come from the function
+	// being replaced. Variables it has no value for are created empty and
+	// reported, so they can be set on this server.
+	existingEnv := make(map[string]string)
+	if exists && existing.EnvVars.Valid && existing.EnvVars.String != "" {
+		if err := json.Unmarshal([]byte(existing.EnvVars.String), &existingEnv); err != nil {
+			return fmt.Errorf("failed to parse environment variables: %w", err)
+		}
+	}
+
+	envVars := make(map[string]string)
+	missingEnvVars := []string{}
+	for _, name := range manifest.EnvVarNames {
+		value := existingEnv[name]
+		if value == "" {
+			missingEnvVars = append(missingEnvVars, name)
+		}
+		envVars[name] = value
+	}
+
+	envVarsJSON, err := json.Marshal(envVars)
+	if err != nil {
+		return fmt.Errorf("failed to serialize environment variables: %w", err)
+	}
+
+	if exists {
+		if current, err := e.currentManifest(existing); err == nil && current.SameAs(manifest) {
+			apitools.Ok(c, &apitools.Body{
+				"function":         existing,
+				"result":           importResultUnchanged,
+				"missing_env_vars": missingEnvVars,
+			})
+			return nil
+		}
+	}
+
+	functionID := uuid.New().String()
+	if exists {
+		functionID = existing.ID
+	}
+
+	// The code only replaces what is stored once the function's record is
+	// saved, so a failed import leaves both as they were
+	codePath, stagedPath, err := stageFunctionCode(e.Config.Storage, functionID, code)
+	if err != nil {
+		return fmt.Errorf("failed to store function code: %w", err)
+	}
+	defer os.Remove(stagedPath)
+
+	var function database.Function
+	result := importResultCreated
+	if exists {
+		result = importResultUpdated
+		function, err = e.Querier.UpdateFunction(ctx, database.UpdateFunctionParams{
+			ID:               existing.ID,
+			Description:      sql.NullString{String: spec.Description, Valid: spec.Description != ""},
+			CodePath:         codePath,
+			Runtime:          spec.Runtime,
+			Handler:          spec.Handler,
+			TimeoutSeconds:   spec.TimeoutSeconds,
+			MemoryMb:         spec.MemoryMB,
+			EnvVars:          sql.NullString{String: string(envVarsJSON), Valid: true},
+			InputSchema:      inputSchema,
+			OutputSchema:     outputSchema,
+			Public:           spec.Public,
+			RetryMaxAttempts: spec.RetryPolicy.MaxAttempts,
+			RetryBackoffMs:   spec.RetryPolicy.BackoffMS,
+			InputAdapter:     inputAdapter,
+		})
+	} else {
+		function, err = e.Querier.CreateFunction(ctx, database.CreateFunctionParams{
+			ID:               functionID,
+			Name:             spec.Name,
+			Description:      sql.NullString{String: spec.Description, Valid: spec.Description != ""},
+			CodePath:         codePath,
+			Runtime:          spec.Runtime,
+			Handler:          spec.Handler,
+			TimeoutSeconds:   spec.TimeoutSeconds,
+			MemoryMb:         spec.MemoryMB,
+			EnvVars:          sql.NullString{String: string(envVarsJSON), Valid: true},
+			InputSchema:      inputSchema,
+			OutputSchema:     outputSchema,
+			Public:           spec.Public,
+			RetryMaxAttempts: spec.RetryPolicy.MaxAttempts,
+			RetryBackoffMs:   spec.RetryPolicy.BackoffMS,
+			InputAdapter:     inputAdapter,
+		})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to import function: %w", err)
+	}
+
+	if err := os.Rename(stagedPath, codePath); err != nil {
+		return fmt.Errorf("failed to store function code: %w", err)
+	}
+
+	apitools.Ok(c, &apitools.Body{
+		"function":         function,
+		"result":           result,
+		"missing_env_vars": missingEnvVars,
+	})
+	return nil
+}
+
+// currentManifest builds the manifest the function would be exported with
+func (e *v1Bundles) currentManifest(function database.Function) (*bundle.Manifest, error) {
+	code, err := os.ReadFile(function.CodePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read function code: %w", err)
+	}
+
+	manifest, err := functionManifest(function, code)
+	if err != nil {
+		return nil, err
+	}
+	manifest.FormatVersion = bundle.FormatVersion
+
+	return manifest, nil
+}
+
+// functionManifest describes a function and its code for a bundle
+func functionManifest(function database.Function, code []byte) (*bundle.Manifest, error) {
+	envVars := make(map[string]string)
+	if function.EnvVars.Valid && function.EnvVars.String != "" {
+		if err := json.Unmarshal([]byte(function.EnvVars.String), &envVars); err != nil {
+			return nil, fmt.Errorf("failed to parse environment variables: %w", err)
+		}
+	}
+
+	envVarNames := make([]string, 0, len(envVars))
+	for name := range envVars {
+		envVarNames = append(envVarNames, name)
+	}
+	sort.Strings(envVarNames)
+
+	spec := bundle.Function{
+		Name:           function.Name,
+		Description:    function.Description.String,
+		Runtime:        function.Runtime,
+		Handler:        function.Handler,
+		TimeoutSeconds: function.TimeoutSeconds,
+		MemoryMB:       function.MemoryMb,
+		Public:         function.Public,
+		RetryPolicy: bundle.RetryPolicy{
+			MaxAttempts: function.RetryMaxAttempts,
+			BackoffMS:   function.RetryBackoffMs,
+		},
+	}
+	if function.InputSchema.Valid {
+		spec.InputSchema = json.RawMessage(function.InputSchema.String)
+	}
+	if function.OutputSchema.Valid {
+		spec.OutputSchema = json.RawMessage(function.OutputSchema.String)
+	}
+	if function.InputAdapter.Valid {
+		spec.InputAdapter = json.RawMessage(function.InputAdapter.String)
+	}
+
+	return &bundle.Manifest{
+		Function:    spec,
+		EnvVarNames: envVarNames,
+		Routes:      functionRoutes(function.Name),
+		CodeSHA256:  bundle.CodeDigest(code),
+	}, nil
+}
+
+// functionRoutes lists the gateway paths a function is invoked on
+func functionRoutes(name string) []string {
+	return []string{
+		"/v1/invoke/" + name,
+		"/v1/invoke/" + name + "/async",
+	}
+}
@@ -0,0 +1,173 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pirogoeth/apps/functional/database"
+	"github.com/pirogoeth/apps/functional/types"
+)
+
+func TestV1Bundles_ExportImport(t *testing.T) {
+	sourceRouter, sourceContext := setupTestAPI(t)
+	sourceContext.Config.Bundles.SigningKey = "shared-secret"
+	ctx := context.Background()
+
+	codePath, err := writeFunctionCode(sourceContext.Config.Storage, "bundle-function-id", []byte("PK fake zip"))
+	if err != nil {
+		t.Fatalf("Failed to write function code: %v", err)
+	}
+
+	_, err = sourceContext.Querier.CreateFunction(ctx, database.CreateFunctionParams{
+		ID:               "bundle-function-id",
+		Name:             "bundle-function",
+		CodePath:         codePath,
+		Runtime:          "nodejs",
+		Handler:          "index.js",
+		TimeoutSeconds:   30,
+		MemoryMb:         128,
+		EnvVars:          sql.NullString{String: `{"API_TOKEN": "dev-token"}`, Valid: true},
+		InputSchema:      sql.NullString{String: testInputSchema, Valid: true},
+		RetryMaxAttempts: 3,
+		RetryBackoffMs:   1000,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create function: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	sourceRouter.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/functions/bundle-function-id/export", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if bytes.Contains(w.Body.Bytes(), []byte("dev-token")) {
+		t.Errorf("Exported bundle must not contain environment variable values")
+	}
+	exported := w.Body.Bytes()
+
+	targetRouter, targetContext := setupTestAPI(t)
+	targetContext.Config.Bundles.SigningKey = "shared-secret"
+
+	importBundle := func(body []byte) (int, map[string]interface{}) {
+		w := httptest.NewRecorder()
+		targetRouter.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/functions/import", bytes.NewReader(body)))
+
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		return w.Code, response
+	}
+
+	status, response := importBundle(exported)
+	if status != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %v", http.StatusOK, status, response)
+	}
+	if response["result"] != importResultCreated {
+		t.Errorf("Expected result %q, got %v", importResultCreated, response["result"])
+	}
+	if missing, _ := response["missing_env_vars"].([]interface{}); len(missing) != 1 || missing[0] != "API_TOKEN" {
+		t.Errorf("Expected API_TOKEN to be reported missing, got %v", response["missing_env_vars"])
+	}
+
+	imported, err := targetContext.Querier.GetFunctionByName(ctx, "bundle-function")
+	if err != nil {
+		t.Fatalf("Imported function not found: %v", err)
+	}
+	if imported.RetryMaxAttempts != 3 || !imported.InputSchema.Valid {
+		t.Errorf("Imported function lost metadata: %+v", imported)
+	}
+
+	status, response = importBundle(exported)
+	if status != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %v", http.StatusOK, status, response)
+	}
+	if response["result"] != importResultUnchanged {
+		t.Errorf("Expected result %q on re-import, got %v", importResultUnchanged, response["result"])
+	}
+
+	// Bundles signed with another key are rejected
+	targetContext.Config.Bundles.SigningKey = "other-secret"
+	status, _ = importBundle(exported)
+	if status != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, status)
+	}
+}
+
+func TestV1Bundles_ImportKeepsCodeWhenSaveFails(t *testing.T) {
+	sourceRouter, sourceContext := setupTestAPI(t)
+	targetRouter, targetContext := setupTestAPI(t)
+	sourceContext.Config.Bundles.SigningKey = "shared-secret"
+	targetContext.Config.Bundles.SigningKey = "shared-secret"
+	ctx := context.Background()
+
+	createFunction := func(apiContext *types.ApiContext, code string) string {
+		codePath, err := writeFunctionCode(apiContext.Config.Storage, "bundle-function-id", []byte(code))
+		if err != nil {
+			t.Fatalf("Failed to write function code: %v", err)
+		}
+
+		_, err = apiContext.Querier.CreateFunction(ctx, database.CreateFunctionParams{
+			ID:               "bundle-function-id",
+			Name:             "bundle-function",
+			CodePath:         codePath,
+			Runtime:          "nodejs",
+			Handler:          "index.js",
+			TimeoutSeconds:   30,
+			MemoryMb:         128,
+			RetryMaxAttempts: 1,
+			RetryBackoffMs:   1000,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create function: %v", err)
+		}
+		return codePath
+	}
+	createFunction(sourceContext, "PK new code")
+	codePath := createFunction(targetContext, "PK old code")
+
+	w := httptest.NewRecorder()
+	sourceRouter.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/functions/bundle-function-id/export", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	exported := w.Body.Bytes()
+
+	// Make saving the imported function fail
+	db, err := sql.Open("sqlite3", filepath.Join(filepath.Dir(targetContext.Config.Storage.FunctionsPath), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	defer db.Close()
+	_, err = db.Exec(`CREATE TRIGGER functions_read_only BEFORE UPDATE ON functions BEGIN SELECT RAISE(ABORT, 'read only'); END`)
+	if err != nil {
+		t.Fatalf("Failed to create trigger: %v", err)
+	}
+
+	w = httptest.NewRecorder()
+	targetRouter.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/functions/import", bytes.NewReader(exported)))
+	if w.Code == http.StatusOK {
+		t.Fatalf("Expected the import to fail, got %d: %s", w.Code, w.Body.String())
+	}
+
+	code, err := os.ReadFile(codePath)
+	if err != nil {
+		t.Fatalf("Failed to read function code: %v", err)
+	}
+	if string(code) != "PK old code" {
+		t.Errorf("Expected the stored code to be kept, got %q", code)
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(codePath))
+	if err != nil {
+		t.Fatalf("Failed to read function directory: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("Expected the staged code to be removed, got %d files", len(entries))
+	}
+}
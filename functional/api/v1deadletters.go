@@ -0,0 +1,138 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/pirogoeth/apps/functional/database"
+	"github.com/pirogoeth/apps/functional/types"
+	"github.com/pirogoeth/apps/pkg/apitools"
+)
+
+type v1DeadLetters struct {
+	*types.ApiContext
+}
+
+func (e *v1DeadLetters) RegisterRoutesTo(router *gin.RouterGroup) {
+	router.GET("/functions/:id/deadletters", apitools.ErrorWrapEndpoint(e.listFunctionDeadLetters))
+
+	deadLetters := router.Group("/deadletters")
+	deadLetters.GET("/:id", apitools.ErrorWrapEndpoint(e.getDeadLetter))
+	deadLetters.POST("/:id/replay", apitools.ErrorWrapEndpoint(e.replayDeadLetter))
+	deadLetters.DELETE("/:id", apitools.ErrorWrapEndpoint(e.deleteDeadLetter))
+}
+
+func (e *v1DeadLetters) listFunctionDeadLetters(c *gin.Context) error {
+	functionID := c.Param("id")
+	if functionID == "" {
+		return fmt.Errorf("%s: function id is required", apitools.MsgInvalidParameter)
+	}
+
+	limit := GetQueryInt(c, "limit", 50)
+	offset := GetQueryInt(c, "offset", 0)
+
+	deadLetters, err := e.Querier.ListDeadLettersByFunction(c.Request.Context(), database.ListDeadLettersByFunctionParams{
+		FunctionID: functionID,
+		Limit:      int64(limit),
+		Offset:     int64(offset),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list dead letters: %w", err)
+	}
+
+	apitools.Ok(c, &apitools.Body{"dead_letters": deadLetters})
+	return nil
+}
+
+func (e *v1DeadLetters) getDeadLetter(c *gin.Context) error {
+	id := c.Param("id")
+	if id == "" {
+		return fmt.Errorf("%s: dead letter id is required", apitools.MsgInvalidParameter)
+	}
+
+	deadLetter, err := e.Querier.GetDeadLetter(c.Request.Context(), id)
+	if err != nil {
+		return fmt.Errorf("dead letter not found: %w", err)
+	}
+
+	apitools.Ok(c, &apitools.Body{"dead_letter": deadLetter})
+	return nil
+}
+
+// replayDeadLetter runs the stored request once more through the normal
+// execution path. A successful replay marks the dead letter as replayed, a
+// failed one counts as another attempt and keeps it pending.
+func (e *v1DeadLetters) replayDeadLetter(c *gin.Context) error {
+	id := c.Param("id")
+	if id == "" {
+		return fmt.Errorf("%s: dead letter id is required", apitools.MsgInvalidParameter)
+	}
+
+	deadLetter, err := e.Querier.GetDeadLetter(c.Request.Context(), id)
+	if err != nil {
+		return fmt.Errorf("dead letter not found: %w", err)
+	}
+
+	if deadLetter.Status == string(types.DeadLetterStatusReplayed) {
+		c.AbortWithStatusJSON(http.StatusConflict, &gin.H{
+			"message": "dead letter has already been replayed",
+		})
+		return nil
+	}
+
+	function, err := e.Querier.GetFunction(c.Request.Context(), deadLetter.FunctionID)
+	if err != nil {
+		return fmt.Errorf("function not found: %w", err)
+	}
+
+	var invReq types.InvocationRequest
+	if err := json.Unmarshal([]byte(deadLetter.Request), &invReq); err != nil {
+		return fmt.Errorf("failed to decode dead letter request: %w", err)
+	}
+
+	outcome, err := (&invoker{e.ApiContext}).execute(c.Request.Context(), function, &invReq, int(deadLetter.Attempts)+1)
+	if err != nil {
+		return err
+	}
+
+	invocationID := sql.NullString{String: outcome.InvocationID, Valid: true}
+	if outcome.failed() {
+		deadLetter, err = e.Querier.UpdateDeadLetterReplayFailed(c.Request.Context(), database.UpdateDeadLetterReplayFailedParams{
+			ID:           deadLetter.ID,
+			InvocationID: invocationID,
+			LastError:    sql.NullString{String: outcome.errorMessage(), Valid: true},
+		})
+	} else {
+		deadLetter, err = e.Querier.UpdateDeadLetterReplayed(c.Request.Context(), database.UpdateDeadLetterReplayedParams{
+			ID:                   deadLetter.ID,
+			ReplayedInvocationID: invocationID,
+		})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update dead letter: %w", err)
+	}
+
+	apitools.Ok(c, &apitools.Body{
+		"dead_letter": deadLetter,
+		"result":      outcome.Result,
+	})
+	return nil
+}
+
+func (e *v1DeadLetters) deleteDeadLetter(c *gin.Context) error {
+	id := c.Param("id")
+	if id == "" {
+		return fmt.Errorf("%s: dead letter id is required", apitools.MsgInvalidParameter)
+	}
+
+	if err := e.Querier.DeleteDeadLetter(c.Request.Context(), id); err != nil {
+		return fmt.Errorf("failed to delete dead letter: %w", err)
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+	return nil
+}
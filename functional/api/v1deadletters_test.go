@@ -0,0 +1,189 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pirogoeth/apps/functional/compute"
+	"github.com/pirogoeth/apps/functional/database"
+	"github.com/pirogoeth/apps/functional/types"
+)
+
+func mockProviderFrom(t *testing.T, apiContext *types.ApiContext) *MockComputeProvider {
+	provider, err := apiContext.Compute.Get("mock")
+	if err != nil {
+		t.Fatalf("Failed to get mock provider: %v", err)
+	}
+
+	return provider.(*MockComputeProvider)
+}
+
+func TestV1DeadLetters_RetryAndReplay(t *testing.T) {
+	router, apiContext := setupTestAPI(t)
+	function := createTestDeployedFunction(t, apiContext, "retry-function", withRetryPolicy(3, 1))
+	mock := mockProviderFrom(t, apiContext)
+	ctx := context.Background()
+
+	mock.executeResult = &compute.InvocationResult{
+		StatusCode: 500,
+		Body:       []byte(`{"error": "boom"}`),
+		Error:      "boom",
+	}
+
+	req := &types.InvocationRequest{
+		FunctionID: function.ID,
+		Body:       []byte(`{"name": "test"}`),
+		Method:     http.MethodPost,
+		Path:       "/v1/invoke/retry-function/async",
+	}
+	outcome, err := (&invoker{apiContext}).executeWithRetry(ctx, function, req)
+	if err != nil {
+		t.Fatalf("executeWithRetry returned error: %v", err)
+	}
+	if !outcome.failed() {
+		t.Fatalf("Expected the last attempt to fail")
+	}
+
+	invocations, err := apiContext.Querier.ListInvocationsByFunction(ctx, database.ListInvocationsByFunctionParams{
+		FunctionID: function.ID,
+		Limit:      10,
+	})
+	if err != nil {
+		t.Fatalf("Failed to list invocations: %v", err)
+	}
+	if len(invocations) != 3 {
+		t.Fatalf("Expected 3 attempts, got %d", len(invocations))
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/functions/"+function.ID+"/deadletters", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var listResponse struct {
+		DeadLetters []database.DeadLetter `json:"dead_letters"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &listResponse); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if len(listResponse.DeadLetters) != 1 {
+		t.Fatalf("Expected 1 dead letter, got %d", len(listResponse.DeadLetters))
+	}
+
+	deadLetter := listResponse.DeadLetters[0]
+	if deadLetter.Attempts != 3 {
+		t.Errorf("Expected 3 attempts, got %d", deadLetter.Attempts)
+	}
+	if deadLetter.LastError.String != "boom" {
+		t.Errorf("Expected last error %q, got %q", "boom", deadLetter.LastError.String)
+	}
+	if deadLetter.Status != string(types.DeadLetterStatusPending) {
+		t.Errorf("Expected status %q, got %q", types.DeadLetterStatusPending, deadLetter.Status)
+	}
+
+	// A replay that fails again counts as another attempt
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/deadletters/"+deadLetter.ID+"/replay", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	failed, err := apiContext.Querier.GetDeadLetter(ctx, deadLetter.ID)
+	if err != nil {
+		t.Fatalf("Failed to get dead letter: %v", err)
+	}
+	if failed.Attempts != 4 || failed.Status != string(types.DeadLetterStatusPending) {
+		t.Errorf("Expected pending dead letter with 4 attempts, got %s with %d", failed.Status, failed.Attempts)
+	}
+
+	mock.executeResult = nil
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/deadletters/"+deadLetter.ID+"/replay", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	replayed, err := apiContext.Querier.GetDeadLetter(ctx, deadLetter.ID)
+	if err != nil {
+		t.Fatalf("Failed to get dead letter: %v", err)
+	}
+	if replayed.Status != string(types.DeadLetterStatusReplayed) {
+		t.Errorf("Expected status %q, got %q", types.DeadLetterStatusReplayed, replayed.Status)
+	}
+	if !replayed.ReplayedInvocationID.Valid {
+		t.Errorf("Expected replayed invocation id to be set")
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/deadletters/"+deadLetter.ID+"/replay", nil))
+	if w.Code != http.StatusConflict {
+		t.Errorf("Expected status %d, got %d", http.StatusConflict, w.Code)
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := types.RetryPolicy{MaxAttempts: 5, BackoffMS: 100}
+
+	tests := []struct {
+		attempt  int
+		expected int64
+	}{
+		{1, 100},
+		{2, 200},
+		{3, 400},
+		{30, types.MaxRetryBackoff.Milliseconds()},
+	}
+
+	for _, tt := range tests {
+		if got := policy.Backoff(tt.attempt).Milliseconds(); got != tt.expected {
+			t.Errorf("Backoff(%d) = %dms, expected %dms", tt.attempt, got, tt.expected)
+		}
+	}
+}
+
+func TestV1Functions_RetryPolicyCap(t *testing.T) {
+	router, apiContext := setupTestAPI(t)
+
+	tests := []struct {
+		name           string
+		maxAttempts    int32
+		expectedStatus int
+	}{
+		{"at the cap", types.MaxRetryAttempts, http.StatusOK},
+		{"over the cap", types.MaxRetryAttempts + 1, http.StatusBadRequest},
+		{"negative", -1, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _ := json.Marshal(types.CreateFunctionRequest{
+				Name:        "retry-cap-" + strings.ReplaceAll(tt.name, " ", "-"),
+				Runtime:     "nodejs",
+				Handler:     "index.handler",
+				Code:        base64.StdEncoding.EncodeToString([]byte("PK fake zip")),
+				RetryPolicy: &types.RetryPolicy{MaxAttempts: tt.maxAttempts},
+			})
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/functions", bytes.NewReader(body)))
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+
+	functions, err := apiContext.Querier.ListFunctions(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to list functions: %v", err)
+	}
+	if len(functions) != 1 {
+		t.Errorf("Expected only the function at the cap to be created, got %d functions", len(functions))
+	}
+}
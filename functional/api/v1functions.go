@@ -6,13 +6,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"os"
-	"path/filepath"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/pirogoeth/apps/pkg/apitools"
-	"github.com/pirogoeth/apps/functional/compute"
 	"github.com/pirogoeth/apps/functional/database"
 	"github.com/pirogoeth/apps/functional/types"
 )
@@ -30,6 +27,7 @@ func (e *v1Functions) RegisterRoutesTo(router *gin.RouterGroup) {
 	functions.PUT("/:id", apitools.ErrorWrapEndpoint(e.updateFunction))
 	functions.DELETE("/:id", apitools.ErrorWrapEndpoint(e.deleteFunction))
 	functions.POST("/:id/deploy", apitools.ErrorWrapEndpoint(e.deployFunction))
+	functions.GET("/:id/deployments", apitools.ErrorWrapEndpoint(e.listDeployments))
 }
 
 func (e *v1Functions) createFunction(c *gin.Context) error {
@@ -49,6 +47,19 @@ func (e *v1Functions) createFunction(c *gin.Context) error {
 		return fmt.Errorf("%s: handler is required", apitools.MsgInvalidParameter)
 	}
 
+	inputSchema, err := schemaColumn("input_schema", req.InputSchema)
+	if err != nil {
+		return err
+	}
+	outputSchema, err := schemaColumn("output_schema", req.OutputSchema)
+	if err != nil {
+		return err
+	}
+	inputAdapter, err := inputAdapterColumn(req.InputAdapter)
+	if err != nil {
+		return err
+	}
+
 	// Generate function ID
 	functionID := uuid.New().String()
 
@@ -61,6 +72,18 @@ func (e *v1Functions) createFunction(c *gin.Context) error {
 	if memoryMB == 0 {
 		memoryMB = 128
 	}
+	retryPolicy := types.RetryPolicy{MaxAttempts: 1, BackoffMS: types.DefaultRetryBackoff.Milliseconds()}
+	if req.RetryPolicy != nil {
+		if !checkRetryPolicy(c, int64(req.RetryPolicy.MaxAttempts), req.RetryPolicy.BackoffMS) {
+			return nil
+		}
+		if req.RetryPolicy.MaxAttempts > 0 {
+			retryPolicy.MaxAttempts = req.RetryPolicy.MaxAttempts
+		}
+		if req.RetryPolicy.BackoffMS > 0 {
+			retryPolicy.BackoffMS = req.RetryPolicy.BackoffMS
+		}
+	}
 
 	// Store function code to filesystem
 	codePath, err := e.storeFunctionCode(functionID, req.Code, req.Runtime)
@@ -80,15 +103,21 @@ func (e *v1Functions) createFunction(c *gin.Context) error {
 
 	// Create database record
 	function, err := e.Querier.CreateFunction(c.Request.Context(), database.CreateFunctionParams{
-		ID:             functionID,
-		Name:           req.Name,
-		Description:    sql.NullString{String: req.Description, Valid: req.Description != ""},
-		CodePath:       codePath,
-		Runtime:        req.Runtime,
-		Handler:        req.Handler,
-		TimeoutSeconds: int64(timeoutSeconds),
-		MemoryMb:       int64(memoryMB),
-		EnvVars:        sql.NullString{String: envVarsJSON, Valid: true},
+		ID:               functionID,
+		Name:             req.Name,
+		Description:      sql.NullString{String: req.Description, Valid: req.Description != ""},
+		CodePath:         codePath,
+		Runtime:          req.Runtime,
+		Handler:          req.Handler,
+		TimeoutSeconds:   int64(timeoutSeconds),
+		MemoryMb:         int64(memoryMB),
+		EnvVars:          sql.NullString{String: envVarsJSON, Valid: true},
+		InputSchema:      inputSchema,
+		OutputSchema:     outputSchema,
+		Public:           req.Public,
+		RetryMaxAttempts: int64(retryPolicy.MaxAttempts),
+		RetryBackoffMs:   retryPolicy.BackoffMS,
+		InputAdapter:     inputAdapter,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to create function: %w", err)
@@ -173,21 +202,7 @@ func (e *v1Functions) storeFunctionCode(functionID, codeBase64, runtime string)
 		return "", fmt.Errorf("invalid base64 code: %w", err)
 	}
 
-	// Create function directory
-	functionsDir := e.Config.Storage.FunctionsPath
-	functionDir := filepath.Join(functionsDir, functionID)
-	
-	if err := os.MkdirAll(functionDir, 0755); err != nil {
-		return "", fmt.Errorf("failed to create function directory: %w", err)
-	}
-
-	// Store code (for now, just save as code.zip)
-	codePath := filepath.Join(functionDir, "code.zip")
-	if err := os.WriteFile(codePath, codeBytes, 0644); err != nil {
-		return "", fmt.Errorf("failed to write function code: %w", err)
-	}
-
-	return codePath, nil
+	return writeFunctionCode(e.Config.Storage, functionID, codeBytes)
 }
 
 func (e *v1Functions) deployFunction(c *gin.Context) error {
@@ -202,43 +217,32 @@ func (e *v1Functions) deployFunction(c *gin.Context) error {
 		return fmt.Errorf("function not found: %w", err)
 	}
 
-	// Get active compute provider
-	provider, err := e.Compute.Get(e.Config.Compute.Provider)
+	manager := &deploymentManager{e.ApiContext}
+	deployment, err := manager.deploy(c.Request.Context(), function)
 	if err != nil {
-		return fmt.Errorf("compute provider not available: %w", err)
+		return err
 	}
 
-	// Convert function to compute type
-	computeFunc := dbFunctionToComputeFunction(function)
+	apitools.Ok(c, &apitools.Body{"deployment": deployment})
+	return nil
+}
 
-	// Deploy to compute provider
-	deployResult, err := provider.Deploy(c.Request.Context(), computeFunc, "")
-	if err != nil {
-		return fmt.Errorf("deployment failed: %w", err)
+func (e *v1Functions) listDeployments(c *gin.Context) error {
+	id := c.Param("id")
+	if id == "" {
+		return fmt.Errorf("%s: function id is required", apitools.MsgInvalidParameter)
 	}
 
-	compResult, ok := deployResult.(*compute.DeployResult)
-	if !ok {
-		return fmt.Errorf("invalid deploy result type")
+	if _, err := e.Querier.GetFunction(c.Request.Context(), id); err != nil {
+		return fmt.Errorf("function not found: %w", err)
 	}
 
-	// Convert back to types
-	result := computeDeployResultToTypesDeployResult(compResult)
-
-	// Create deployment record
-	deployment, err := e.Querier.CreateDeployment(c.Request.Context(), database.CreateDeploymentParams{
-		ID:         result.DeploymentID,
-		FunctionID: id,
-		Provider:   e.Config.Compute.Provider,
-		ResourceID: result.ResourceID,
-		Status:     string(types.DeploymentStatusActive),
-		Replicas:   1,
-		ImageTag:   sql.NullString{String: result.ImageTag, Valid: true},
-	})
+	manager := &deploymentManager{e.ApiContext}
+	deployments, err := manager.history(c.Request.Context(), id)
 	if err != nil {
-		return fmt.Errorf("failed to create deployment record: %w", err)
+		return err
 	}
 
-	apitools.Ok(c, &apitools.Body{"deployment": deployment})
+	apitools.Ok(c, &apitools.Body{"deployments": deployments})
 	return nil
-}
\ No newline at end of file
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/pirogoeth/apps/functional/compute"
 	"github.com/pirogoeth/apps/functional/database"
+	"github.com/pirogoeth/apps/functional/ratelimit"
 	"github.com/pirogoeth/apps/functional/types"
 )
 
@@ -58,6 +59,7 @@ func setupTestAPI(t *testing.T) (*gin.Engine, *types.ApiContext) {
 		Config:  config,
 		Querier: db.Queries,
 		Compute: registry,
+		Limiter: ratelimit.NewLimiter(),
 	}
 	
 	// Setup router
@@ -70,14 +72,93 @@ func setupTestAPI(t *testing.T) (*gin.Engine, *types.ApiContext) {
 	return router, apiContext
 }
 
+// testFunctionOption customizes the function created by createTestDeployedFunction
+type testFunctionOption func(*database.CreateFunctionParams)
+
+func withInputSchema(schema string) testFunctionOption {
+	return func(params *database.CreateFunctionParams) {
+		params.InputSchema = sql.NullString{String: schema, Valid: true}
+	}
+}
+
+func withPublic() testFunctionOption {
+	return func(params *database.CreateFunctionParams) {
+		params.Public = true
+	}
+}
+
+func withRetryPolicy(maxAttempts, backoffMs int64) testFunctionOption {
+	return func(params *database.CreateFunctionParams) {
+		params.RetryMaxAttempts = maxAttempts
+		params.RetryBackoffMs = backoffMs
+	}
+}
+
+func withEnvVars(envVars string) testFunctionOption {
+	return func(params *database.CreateFunctionParams) {
+		params.EnvVars = sql.NullString{String: envVars, Valid: true}
+	}
+}
+
+func withInputAdapter(adapter string) testFunctionOption {
+	return func(params *database.CreateFunctionParams) {
+		params.InputAdapter = sql.NullString{String: adapter, Valid: true}
+	}
+}
+
+// createTestDeployedFunction creates a function with an active deployment on
+// the mock provider. Its id is the name suffixed with -id.
+func createTestDeployedFunction(t *testing.T, apiContext *types.ApiContext, name string, opts ...testFunctionOption) database.Function {
+	t.Helper()
+	ctx := context.Background()
+
+	params := database.CreateFunctionParams{
+		ID:               name + "-id",
+		Name:             name,
+		CodePath:         "/tmp/" + name,
+		Runtime:          "nodejs",
+		Handler:          "index.handler",
+		TimeoutSeconds:   30,
+		MemoryMb:         128,
+		RetryMaxAttempts: 1,
+		RetryBackoffMs:   1000,
+	}
+	for _, opt := range opts {
+		opt(&params)
+	}
+
+	function, err := apiContext.Querier.CreateFunction(ctx, params)
+	if err != nil {
+		t.Fatalf("Failed to create function: %v", err)
+	}
+
+	_, err = apiContext.Querier.CreateDeployment(ctx, database.CreateDeploymentParams{
+		ID:         name + "-deployment-id",
+		FunctionID: function.ID,
+		Provider:   "mock",
+		ResourceID: "mock-resource",
+		Status:     string(types.DeploymentStatusActive),
+		Replicas:   1,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create deployment: %v", err)
+	}
+
+	return function
+}
+
 // Mock compute provider for testing
 type MockComputeProvider struct {
 	name           string
 	deployError    error
 	deployResult   *compute.DeployResult
+	// deployBlocks makes Deploy wait for its context to be done
+	deployBlocks   bool
 	executeError   error
 	executeResult  *compute.InvocationResult
 	healthError    error
+	// lastRequest is the request of the most recent Execute call
+	lastRequest    *compute.InvocationRequest
 }
 
 // Ensure MockComputeProvider implements ComputeProvider interface
@@ -88,6 +169,10 @@ func (m *MockComputeProvider) Name() string {
 }
 
 func (m *MockComputeProvider) Deploy(ctx context.Context, fn interface{}, imageName string) (interface{}, error) {
+	if m.deployBlocks {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
 	if m.deployError != nil {
 		return nil, m.deployError
 	}
@@ -104,6 +189,8 @@ func (m *MockComputeProvider) Deploy(ctx context.Context, fn interface{}, imageN
 }
 
 func (m *MockComputeProvider) Execute(ctx context.Context, deployment interface{}, req interface{}) (interface{}, error) {
+	m.lastRequest, _ = req.(*compute.InvocationRequest)
+	
 	if m.executeError != nil {
 		return nil, m.executeError
 	}
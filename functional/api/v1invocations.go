@@ -1,14 +1,14 @@
 package api
 
 import (
-	"database/sql"
+	"context"
 	"fmt"
 	"io"
+	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
 	"github.com/pirogoeth/apps/pkg/apitools"
-	"github.com/pirogoeth/apps/functional/compute"
 	"github.com/pirogoeth/apps/functional/database"
 	"github.com/pirogoeth/apps/functional/types"
 )
@@ -20,6 +20,7 @@ type v1Invocations struct {
 func (e *v1Invocations) RegisterRoutesTo(router *gin.RouterGroup) {
 	// Function invocation endpoint
 	router.POST("/invoke/:function_name", apitools.ErrorWrapEndpoint(e.invokeFunction))
+	router.POST("/invoke/:function_name/async", apitools.ErrorWrapEndpoint(e.invokeFunctionAsync))
 	
 	// Invocation management endpoints
 	invocations := router.Group("/invocations")
@@ -44,30 +45,142 @@ func (e *v1Invocations) invokeFunction(c *gin.Context) error {
 		return fmt.Errorf("function not found: %w", err)
 	}
 
-	// Get active deployment
-	deployment, err := e.Querier.GetActiveDeploymentByFunction(c.Request.Context(), function.ID)
+	// Callers over their rate limit or quota are turned away before any work is done
+	if ok, err := applyRateLimits(c, e.ApiContext, function); !ok {
+		return err
+	}
+
+	// Read request body
+	body, err := io.ReadAll(c.Request.Body)
 	if err != nil {
+		return fmt.Errorf("failed to read request body: %w", err)
+	}
+
+	// Webhook deliveries are verified and normalized before anything else
+	invReq := newInvocationRequest(c, function.ID, body)
+	claim, ok, err := applyInputAdapter(c, e.ApiContext, function, invReq)
+	if !ok {
+		return err
+	}
+	// Webhook deliveries that aren't invoked successfully are released, so
+	// their sender can deliver them again
+	invoked := false
+	defer func() {
+		if !invoked {
+			claim.release(e.ApiContext)
+		}
+	}()
+
+	// Reject requests that don't match the function's input schema before
+	// anything is executed
+	if ok, err := validateAgainstSchema(c, function.InputSchema, invReq.Body, http.StatusBadRequest, "request body does not match function input schema"); !ok {
+		return err
+	}
+
+	if _, err := e.Querier.GetActiveDeploymentByFunction(c.Request.Context(), function.ID); err != nil {
 		return fmt.Errorf("no active deployment found for function: %w", err)
 	}
 
-	// Create invocation record
-	invocationID := uuid.New().String()
-	_, err = e.Querier.CreateInvocation(c.Request.Context(), database.CreateInvocationParams{
-		ID:           invocationID,
-		FunctionID:   function.ID,
-		DeploymentID: sql.NullString{String: deployment.ID, Valid: true},
-		Status:       string(types.InvocationStatusPending),
-	})
+	if err := recordInvocationUsage(c, e.ApiContext, function); err != nil {
+		return err
+	}
+
+	outcome, err := (&invoker{e.ApiContext}).execute(c.Request.Context(), function, invReq, 1)
 	if err != nil {
-		return fmt.Errorf("failed to create invocation record: %w", err)
+		return err
+	}
+	invoked = !outcome.failed()
+
+	respondWithOutcome(c, outcome)
+	return nil
+}
+
+// respondWithOutcome writes the function's response for a synchronous invocation
+func respondWithOutcome(c *gin.Context, outcome *invocationOutcome) {
+	// A successful response that breaks the function's output contract is
+	// reported as a gateway error rather than passed through
+	if outcome.OutputErr != nil {
+		c.AbortWithStatusJSON(http.StatusBadGateway, &gin.H{
+			"message": "function response does not match function output schema",
+			"errors":  outcome.OutputErr.Errors,
+		})
+		return
+	}
+
+	// Return the function's response
+	invResult := outcome.Result
+	for k, v := range invResult.Headers {
+		c.Header(k, v)
+	}
+	c.Data(invResult.StatusCode, "application/json", invResult.Body)
+}
+
+// invokeFunctionAsync accepts an invocation and runs it in the background,
+// retrying it according to the function's retry policy. Invocations that still
+// fail after the last attempt are moved to the function's dead-letter list.
+func (e *v1Invocations) invokeFunctionAsync(c *gin.Context) error {
+	functionName := c.Param("function_name")
+	if functionName == "" {
+		return fmt.Errorf("%s: function name is required", apitools.MsgInvalidParameter)
+	}
+
+	function, err := e.Querier.GetFunctionByName(c.Request.Context(), functionName)
+	if err != nil {
+		return fmt.Errorf("function not found: %w", err)
+	}
+
+	if ok, err := applyRateLimits(c, e.ApiContext, function); !ok {
+		return err
 	}
 
-	// Read request body
 	body, err := io.ReadAll(c.Request.Body)
 	if err != nil {
 		return fmt.Errorf("failed to read request body: %w", err)
 	}
 
+	if _, err := e.Querier.GetActiveDeploymentByFunction(c.Request.Context(), function.ID); err != nil {
+		return fmt.Errorf("no active deployment found for function: %w", err)
+	}
+
+	invReq := newInvocationRequest(c, function.ID, body)
+	claim, ok, err := applyInputAdapter(c, e.ApiContext, function, invReq)
+	if !ok {
+		return err
+	}
+	// Once accepted, failed attempts are retried and dead-lettered here, the
+	// sender doesn't need to deliver it again
+	accepted := false
+	defer func() {
+		if !accepted {
+			claim.release(e.ApiContext)
+		}
+	}()
+
+	// Input that doesn't match the schema would fail on every attempt
+	if ok, err := validateAgainstSchema(c, function.InputSchema, invReq.Body, http.StatusBadRequest, "request body does not match function input schema"); !ok {
+		return err
+	}
+
+	if err := recordInvocationUsage(c, e.ApiContext, function); err != nil {
+		return err
+	}
+	accepted = true
+
+	go func() {
+		if _, err := (&invoker{e.ApiContext}).executeWithRetry(context.Background(), function, invReq); err != nil {
+			logrus.WithError(err).WithField("function_id", function.ID).Error("async invocation failed")
+		}
+	}()
+
+	c.JSON(http.StatusAccepted, &apitools.Body{
+		"message":      "invocation accepted",
+		"retry_policy": retryPolicyFor(function),
+	})
+	return nil
+}
+
+// newInvocationRequest builds an invocation request from the incoming HTTP request
+func newInvocationRequest(c *gin.Context, functionID string, body []byte) *types.InvocationRequest {
 	// Convert headers
 	headers := make(map[string]string)
 	for k, v := range c.Request.Header {
@@ -84,68 +197,14 @@ func (e *v1Invocations) invokeFunction(c *gin.Context) error {
 		}
 	}
 
-	// Create invocation request
-	invReq := &types.InvocationRequest{
-		FunctionID: function.ID,
+	return &types.InvocationRequest{
+		FunctionID: functionID,
 		Body:       body,
 		Headers:    headers,
 		Method:     c.Request.Method,
 		Path:       c.Request.URL.Path,
 		QueryArgs:  queryArgs,
 	}
-
-	// Get compute provider
-	provider, err := e.Compute.Get(deployment.Provider)
-	if err != nil {
-		return fmt.Errorf("compute provider not available: %w", err)
-	}
-
-	// Convert types for compute provider
-	computeDep := dbDeploymentToComputeDeployment(deployment)
-	computeInvReq := typesInvocationRequestToComputeInvocationRequest(invReq)
-
-	// Execute function
-	result, err := provider.Execute(c.Request.Context(), computeDep, computeInvReq)
-	if err != nil {
-		// Update invocation with error
-		e.Querier.UpdateInvocationComplete(c.Request.Context(), database.UpdateInvocationCompleteParams{
-			ID:     invocationID,
-			Status: string(types.InvocationStatusError),
-			Error:  sql.NullString{String: err.Error(), Valid: true},
-		})
-		return fmt.Errorf("function execution failed: %w", err)
-	}
-
-	compResult, ok := result.(*compute.InvocationResult)
-	if !ok {
-		return fmt.Errorf("invalid invocation result type")
-	}
-
-	// Convert back to types
-	invResult := computeInvocationResultToTypesInvocationResult(compResult)
-
-	// Update invocation with results
-	status := string(types.InvocationStatusSuccess)
-	if invResult.StatusCode >= 400 {
-		status = string(types.InvocationStatusError)
-	}
-
-	e.Querier.UpdateInvocationComplete(c.Request.Context(), database.UpdateInvocationCompleteParams{
-		ID:                invocationID,
-		Status:            status,
-		DurationMs:        sql.NullInt64{Int64: invResult.DurationMS, Valid: true},
-		MemoryUsedMb:      sql.NullInt64{Int64: int64(invResult.MemoryUsedMB), Valid: true},
-		ResponseSizeBytes: sql.NullInt64{Int64: invResult.ResponseSize, Valid: true},
-		Logs:              sql.NullString{String: invResult.Logs, Valid: invResult.Logs != ""},
-		Error:             sql.NullString{String: invResult.Error, Valid: invResult.Error != ""},
-	})
-
-	// Return the function's response
-	for k, v := range invResult.Headers {
-		c.Header(k, v)
-	}
-	c.Data(invResult.StatusCode, "application/json", invResult.Body)
-	return nil
 }
 
 func (e *v1Invocations) listInvocations(c *gin.Context) error {
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const testInputSchema = `{"type": "object", "required": ["name"], "properties": {"name": {"type": "string"}}}`
+
+func TestV1Invocations_InvokeFunctionValidation(t *testing.T) {
+	router, apiContext := setupTestAPI(t)
+	createTestDeployedFunction(t, apiContext, "schema-function", withInputSchema(testInputSchema))
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+	}{
+		{
+			name:           "valid input",
+			body:           `{"name": "test"}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "missing required property",
+			body:           `{}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid JSON",
+			body:           `{"name":`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/v1/invoke/schema-function", bytes.NewBufferString(tt.body))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+
+			if tt.expectedStatus != http.StatusBadRequest {
+				return
+			}
+
+			var response struct {
+				Errors []struct {
+					Location string `json:"location"`
+					Message  string `json:"message"`
+				} `json:"errors"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+
+			if len(response.Errors) == 0 {
+				t.Errorf("Expected structured validation errors, got %s", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestV1OpenAPI_GetDocument(t *testing.T) {
+	router, apiContext := setupTestAPI(t)
+	createTestDeployedFunction(t, apiContext, "schema-function", withInputSchema(testInputSchema), withPublic())
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/openapi.json", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+
+	var document struct {
+		OpenAPI string                                `json:"openapi"`
+		Paths   map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &document); err != nil {
+		t.Fatalf("Failed to unmarshal document: %v", err)
+	}
+
+	if document.OpenAPI != "3.1.0" {
+		t.Errorf("Expected OpenAPI version 3.1.0, got %s", document.OpenAPI)
+	}
+
+	if _, ok := document.Paths["/v1/invoke/schema-function"]["post"]; !ok {
+		t.Errorf("Expected public function in document paths, got %v", document.Paths)
+	}
+}
@@ -0,0 +1,136 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pirogoeth/apps/functional/database"
+	"github.com/pirogoeth/apps/functional/types"
+	"github.com/pirogoeth/apps/pkg/apitools"
+)
+
+const validationErrorSchemaRef = "#/components/schemas/ValidationErrorResponse"
+
+type v1OpenAPI struct {
+	*types.ApiContext
+}
+
+func (e *v1OpenAPI) RegisterRoutesTo(router *gin.RouterGroup) {
+	router.GET("/openapi.json", apitools.ErrorWrapEndpoint(e.getOpenAPIDocument))
+}
+
+// getOpenAPIDocument serves an OpenAPI document describing the invoke endpoint
+// of every public function
+func (e *v1OpenAPI) getOpenAPIDocument(c *gin.Context) error {
+	functions, err := e.Querier.ListPublicFunctions(c.Request.Context())
+	if err != nil {
+		return fmt.Errorf("failed to list public functions: %w", err)
+	}
+
+	c.JSON(http.StatusOK, buildOpenAPIDocument(functions))
+	return nil
+}
+
+func buildOpenAPIDocument(functions []database.Function) gin.H {
+	paths := gin.H{}
+	for _, function := range functions {
+		paths["/v1/invoke/"+function.Name] = gin.H{
+			"post": functionOperation(function),
+		}
+	}
+
+	return gin.H{
+		"openapi": "3.1.0",
+		"info": gin.H{
+			"title":   "functional",
+			"version": "v1",
+		},
+		"paths": paths,
+		"components": gin.H{
+			"schemas": gin.H{
+				"ValidationErrorResponse": gin.H{
+					"type":     "object",
+					"required": []string{"message", "errors"},
+					"properties": gin.H{
+						"message": gin.H{"type": "string"},
+						"errors": gin.H{
+							"type": "array",
+							"items": gin.H{
+								"type":     "object",
+								"required": []string{"location", "schema_location", "message"},
+								"properties": gin.H{
+									"location":        gin.H{"type": "string", "description": "JSON pointer to the invalid value"},
+									"schema_location": gin.H{"type": "string", "description": "JSON pointer to the failing schema keyword"},
+									"message":         gin.H{"type": "string"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func functionOperation(function database.Function) gin.H {
+	operation := gin.H{
+		"operationId": "invoke-" + function.Name,
+		"summary":     "Invoke " + function.Name,
+	}
+	if function.Description.Valid {
+		operation["description"] = function.Description.String
+	}
+
+	responses := gin.H{
+		"200": gin.H{
+			"description": "Function response",
+			"content":     jsonContent(function.OutputSchema.String, function.OutputSchema.Valid),
+		},
+	}
+
+	if function.InputSchema.Valid {
+		operation["requestBody"] = gin.H{
+			"required": true,
+			"content":  jsonContent(function.InputSchema.String, true),
+		}
+		responses["400"] = gin.H{
+			"description": "Request body does not match the input schema",
+			"content": gin.H{
+				"application/json": gin.H{
+					"schema": gin.H{"$ref": validationErrorSchemaRef},
+				},
+			},
+		}
+	}
+
+	if function.OutputSchema.Valid {
+		responses["502"] = gin.H{
+			"description": "Function response does not match the output schema",
+			"content": gin.H{
+				"application/json": gin.H{
+					"schema": gin.H{"$ref": validationErrorSchemaRef},
+				},
+			},
+		}
+	}
+
+	operation["responses"] = responses
+	return operation
+}
+
+// jsonContent builds a media type map for a stored schema, falling back to an
+// unconstrained schema for functions without one
+func jsonContent(schema string, valid bool) gin.H {
+	var schemaValue interface{} = gin.H{}
+	if valid {
+		schemaValue = json.RawMessage(schema)
+	}
+
+	return gin.H{
+		"application/json": gin.H{
+			"schema": schemaValue,
+		},
+	}
+}
@@ -0,0 +1,177 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/pirogoeth/apps/functional/database"
+	"github.com/pirogoeth/apps/functional/ratelimit"
+	"github.com/pirogoeth/apps/functional/types"
+	"github.com/pirogoeth/apps/pkg/apitools"
+)
+
+type v1RateLimits struct {
+	*types.ApiContext
+}
+
+func (e *v1RateLimits) RegisterRoutesTo(router *gin.RouterGroup) {
+	functions := router.Group("/functions")
+	functions.GET("/:id/limits", apitools.ErrorWrapEndpoint(e.listRateLimits))
+	functions.PUT("/:id/limits", apitools.ErrorWrapEndpoint(e.setRateLimit))
+	functions.DELETE("/:id/limits/:limit_id", apitools.ErrorWrapEndpoint(e.deleteRateLimit))
+	functions.GET("/:id/usage", apitools.ErrorWrapEndpoint(e.getUsage))
+}
+
+func (e *v1RateLimits) listRateLimits(c *gin.Context) error {
+	functionID := c.Param("id")
+	if functionID == "" {
+		return fmt.Errorf("%s: function id is required", apitools.MsgInvalidParameter)
+	}
+
+	limits, err := e.Querier.ListRateLimitsByFunction(c.Request.Context(), functionID)
+	if err != nil {
+		return fmt.Errorf("failed to list rate limits: %w", err)
+	}
+
+	apitools.Ok(c, &apitools.Body{"limits": limits})
+	return nil
+}
+
+// setRateLimit creates or replaces the limit of a function for the API key in
+// the request, or for all of its callers if no key is given
+func (e *v1RateLimits) setRateLimit(c *gin.Context) error {
+	functionID := c.Param("id")
+	if functionID == "" {
+		return fmt.Errorf("%s: function id is required", apitools.MsgInvalidParameter)
+	}
+
+	var req types.SetRateLimitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return fmt.Errorf("%s: %w", apitools.MsgInvalidParameter, err)
+	}
+
+	if req.RequestsPerSecond < 0 || req.Burst < 0 || req.DailyQuota < 0 || req.MonthlyQuota < 0 {
+		return fmt.Errorf("%s: limits must not be negative", apitools.MsgInvalidParameter)
+	}
+
+	if _, err := e.Querier.GetFunction(c.Request.Context(), functionID); err != nil {
+		return fmt.Errorf("function not found: %w", err)
+	}
+
+	limit, err := e.Querier.UpsertRateLimit(c.Request.Context(), database.UpsertRateLimitParams{
+		ID:                uuid.New().String(),
+		FunctionID:        functionID,
+		ApiKeyHash:        ratelimit.HashKey(req.APIKey),
+		RequestsPerSecond: req.RequestsPerSecond,
+		Burst:             req.Burst,
+		DailyQuota:        req.DailyQuota,
+		MonthlyQuota:      req.MonthlyQuota,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set rate limit: %w", err)
+	}
+
+	apitools.Ok(c, &apitools.Body{"limit": limit})
+	return nil
+}
+
+func (e *v1RateLimits) deleteRateLimit(c *gin.Context) error {
+	functionID := c.Param("id")
+	limitID := c.Param("limit_id")
+	if functionID == "" || limitID == "" {
+		return fmt.Errorf("%s: function id and limit id are required", apitools.MsgInvalidParameter)
+	}
+
+	deleted, err := e.Querier.DeleteRateLimit(c.Request.Context(), database.DeleteRateLimitParams{
+		ID:         limitID,
+		FunctionID: functionID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete rate limit: %w", err)
+	}
+	if deleted == 0 {
+		c.AbortWithStatusJSON(http.StatusNotFound, &gin.H{
+			"message": "rate limit not found",
+		})
+		return nil
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+	return nil
+}
+
+// getUsage reports the requests counted for a function in the current day and
+// month, per API key and in total, along with how much of each quota is left
+func (e *v1RateLimits) getUsage(c *gin.Context) error {
+	functionID := c.Param("id")
+	if functionID == "" {
+		return fmt.Errorf("%s: function id is required", apitools.MsgInvalidParameter)
+	}
+
+	if _, err := e.Querier.GetFunction(c.Request.Context(), functionID); err != nil {
+		return fmt.Errorf("function not found: %w", err)
+	}
+
+	now := time.Now()
+	periods := make(map[string]gin.H)
+	for _, period := range []string{ratelimit.PeriodDay, ratelimit.PeriodMonth} {
+		periodStart := ratelimit.PeriodStart(period, now)
+
+		byKey, err := e.Querier.ListInvocationUsageByFunction(c.Request.Context(), database.ListInvocationUsageByFunctionParams{
+			FunctionID:  functionID,
+			Period:      period,
+			PeriodStart: periodStart,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to list invocation usage: %w", err)
+		}
+
+		var total int64
+		for _, usage := range byKey {
+			total += usage.Count
+		}
+
+		periods[period] = gin.H{
+			"start":  periodStart,
+			"resets": ratelimit.PeriodEnd(period, now),
+			"total":  total,
+			"by_key": byKey,
+		}
+	}
+
+	limits, err := e.Querier.ListRateLimitsByFunction(c.Request.Context(), functionID)
+	if err != nil {
+		return fmt.Errorf("failed to list rate limits: %w", err)
+	}
+
+	quotas := make([]gin.H, 0, len(limits))
+	for _, limit := range limits {
+		quota := gin.H{"limit_id": limit.ID, "api_key_hash": limit.ApiKeyHash}
+		if limit.DailyQuota > 0 {
+			used, err := usageFor(c, e.ApiContext, functionID, limit.ApiKeyHash, ratelimit.PeriodDay, now)
+			if err != nil {
+				return err
+			}
+			quota["daily_remaining"] = max(limit.DailyQuota-used, 0)
+		}
+		if limit.MonthlyQuota > 0 {
+			used, err := usageFor(c, e.ApiContext, functionID, limit.ApiKeyHash, ratelimit.PeriodMonth, now)
+			if err != nil {
+				return err
+			}
+			quota["monthly_remaining"] = max(limit.MonthlyQuota-used, 0)
+		}
+		quotas = append(quotas, quota)
+	}
+
+	apitools.Ok(c, &apitools.Body{
+		"function_id": functionID,
+		"usage":       periods,
+		"quotas":      quotas,
+	})
+	return nil
+}
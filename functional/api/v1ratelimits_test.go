@@ -0,0 +1,133 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pirogoeth/apps/functional/types"
+)
+
+func setTestRateLimit(t *testing.T, router http.Handler, functionID string, req types.SetRateLimitRequest) {
+	body, _ := json.Marshal(req)
+	httpReq := httptest.NewRequest(http.MethodPut, "/v1/functions/"+functionID+"/limits", bytes.NewReader(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httpReq)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Failed to set rate limit: %d %s", w.Code, w.Body.String())
+	}
+}
+
+func invokeTestFunction(router http.Handler, name, apiKey string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/v1/invoke/"+name, bytes.NewReader([]byte(`{}`)))
+	if apiKey != "" {
+		req.Header.Set(apiKeyHeader, apiKey)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestV1RateLimits_DailyQuotaPerKey(t *testing.T) {
+	router, apiContext := setupTestAPI(t)
+	function := createTestDeployedFunction(t, apiContext, "quota-function")
+
+	setTestRateLimit(t, router, function.ID, types.SetRateLimitRequest{
+		APIKey:     "key-a",
+		DailyQuota: 2,
+	})
+
+	for i := 0; i < 2; i++ {
+		if w := invokeTestFunction(router, function.Name, "key-a"); w.Code != http.StatusOK {
+			t.Fatalf("Expected invocation %d within quota to succeed, got %d: %s", i+1, w.Code, w.Body.String())
+		}
+	}
+
+	w := invokeTestFunction(router, function.Name, "key-a")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected invocation over quota to get 429, got %d", w.Code)
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Errorf("Expected a Retry-After header")
+	}
+
+	// Other callers aren't affected by the key's quota
+	if w := invokeTestFunction(router, function.Name, "key-b"); w.Code != http.StatusOK {
+		t.Errorf("Expected other key to succeed, got %d", w.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/functions/"+function.ID+"/usage", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected usage to be returned, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var response struct {
+		Usage map[string]struct {
+			Total int64 `json:"total"`
+		} `json:"usage"`
+		Quotas []map[string]interface{} `json:"quotas"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to decode usage: %v", err)
+	}
+
+	// The rejected request isn't counted
+	if total := response.Usage["day"].Total; total != 3 {
+		t.Errorf("Expected 3 requests today, got %d", total)
+	}
+	if len(response.Quotas) != 1 || response.Quotas[0]["daily_remaining"] != float64(0) {
+		t.Errorf("Expected the key's daily quota to be used up, got %v", response.Quotas)
+	}
+}
+
+func TestV1RateLimits_InvalidRequestsNotCounted(t *testing.T) {
+	router, apiContext := setupTestAPI(t)
+	function := createTestDeployedFunction(t, apiContext, "invalid-function", withInputSchema(testInputSchema))
+
+	setTestRateLimit(t, router, function.ID, types.SetRateLimitRequest{
+		DailyQuota: 1,
+	})
+
+	// The request doesn't match the input schema, so it doesn't use the quota
+	if w := invokeTestFunction(router, function.Name, ""); w.Code != http.StatusBadRequest {
+		t.Fatalf("Expected invalid input to get 400, got %d: %s", w.Code, w.Body.String())
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/invoke/"+function.Name, bytes.NewReader([]byte(`{"name": "test"}`)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected valid input within quota to succeed, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestV1RateLimits_RequestRate(t *testing.T) {
+	router, apiContext := setupTestAPI(t)
+	function := createTestDeployedFunction(t, apiContext, "rate-function")
+
+	setTestRateLimit(t, router, function.ID, types.SetRateLimitRequest{
+		RequestsPerSecond: 0.001,
+		Burst:             2,
+	})
+
+	for i, expected := range []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests} {
+		// A limit without a key applies to all callers combined
+		w := invokeTestFunction(router, function.Name, []string{"", "key-a", "key-b"}[i])
+		if w.Code != expected {
+			t.Errorf("Expected invocation %d to get %d, got %d", i+1, expected, w.Code)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/invoke/"+function.Name+"/async", bytes.NewReader([]byte(`{}`))))
+	if w.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected async invocation over the rate limit to get 429, got %d", w.Code)
+	}
+}
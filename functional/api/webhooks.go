@@ -0,0 +1,136 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"github.com/pirogoeth/apps/functional/database"
+	"github.com/pirogoeth/apps/functional/types"
+	"github.com/pirogoeth/apps/functional/webhook"
+	"github.com/pirogoeth/apps/pkg/apitools"
+)
+
+// webhookDeliveryRetention is how long delivery ids are remembered to reject replays
+const webhookDeliveryRetention = 7 * 24 * time.Hour
+
+// inputAdapterColumn checks an input adapter from a request and converts it for
+// storage. An absent adapter is stored as NULL.
+func inputAdapterColumn(adapter json.RawMessage) (sql.NullString, error) {
+	if len(adapter) == 0 || string(adapter) == "null" {
+		return sql.NullString{}, nil
+	}
+
+	if _, err := webhook.ParseConfig(adapter); err != nil {
+		return sql.NullString{}, fmt.Errorf("%s: %w", apitools.MsgInvalidParameter, err)
+	}
+
+	return sql.NullString{String: string(adapter), Valid: true}, nil
+}
+
+// deliveryClaim is a webhook delivery recorded as received, so that it can't be
+// replayed. A delivery that isn't invoked successfully is released again, so
+// the sender can redeliver it.
+type deliveryClaim struct {
+	functionID string
+	replayKey  string
+}
+
+// release forgets the delivery. It does nothing for requests without one.
+func (d *deliveryClaim) release(apiContext *types.ApiContext) {
+	if d == nil {
+		return
+	}
+
+	// The request may already be cancelled when its invocation failed
+	err := apiContext.Querier.DeleteWebhookDelivery(context.Background(), database.DeleteWebhookDeliveryParams{
+		FunctionID: d.functionID,
+		DeliveryID: d.replayKey,
+	})
+	if err != nil {
+		logrus.WithError(err).WithFields(logrus.Fields{
+			"function_id": d.functionID,
+			"replay_key":  d.replayKey,
+		}).Warn("failed to release webhook delivery")
+	}
+}
+
+// applyInputAdapter verifies a webhook delivery with the function's input
+// adapter, if it has one, and replaces the request body and headers with the
+// normalized delivery. Deliveries that fail verification or were seen before
+// abort the request and false is returned. The returned claim is nil for
+// functions without an adapter, and must be released if the invocation fails.
+func applyInputAdapter(c *gin.Context, apiContext *types.ApiContext, function database.Function, req *types.InvocationRequest) (*deliveryClaim, bool, error) {
+	if !function.InputAdapter.Valid {
+		return nil, true, nil
+	}
+
+	config, err := webhook.ParseConfig([]byte(function.InputAdapter.String))
+	if err != nil {
+		return nil, false, err
+	}
+
+	envVars := make(map[string]string)
+	if function.EnvVars.Valid && function.EnvVars.String != "" {
+		if err := json.Unmarshal([]byte(function.EnvVars.String), &envVars); err != nil {
+			return nil, false, fmt.Errorf("failed to parse environment variables: %w", err)
+		}
+	}
+
+	delivery, err := webhook.Verify(config, envVars[config.SecretEnv], c.Request.Header, req.Body, time.Now())
+	switch {
+	case errors.Is(err, webhook.ErrInvalidSignature), errors.Is(err, webhook.ErrMissingSignature), errors.Is(err, webhook.ErrStale):
+		c.AbortWithStatusJSON(http.StatusUnauthorized, &gin.H{
+			"message": "webhook verification failed",
+			"errors":  []string{err.Error()},
+		})
+		return nil, false, nil
+	case err != nil:
+		c.AbortWithStatusJSON(http.StatusBadRequest, &gin.H{
+			"message": "invalid webhook delivery",
+			"errors":  []string{err.Error()},
+		})
+		return nil, false, nil
+	}
+
+	// Deliveries are remembered by what their signature covers, the delivery id
+	// header usually isn't signed and could be changed on a replay
+	ctx := c.Request.Context()
+	recorded, err := apiContext.Querier.RecordWebhookDelivery(ctx, database.RecordWebhookDeliveryParams{
+		FunctionID: function.ID,
+		DeliveryID: delivery.ReplayKey,
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to record webhook delivery: %w", err)
+	}
+	if recorded == 0 {
+		c.AbortWithStatusJSON(http.StatusConflict, &gin.H{
+			"message": "webhook delivery has already been received",
+			"errors":  []string{delivery.ID},
+		})
+		return nil, false, nil
+	}
+
+	cutoff := time.Now().UTC().Add(-webhookDeliveryRetention)
+	if err := apiContext.Querier.DeleteWebhookDeliveriesBefore(ctx, sql.NullTime{Time: cutoff, Valid: true}); err != nil {
+		logrus.WithError(err).Warn("failed to prune webhook deliveries")
+	}
+
+	claim := &deliveryClaim{functionID: function.ID, replayKey: delivery.ReplayKey}
+	body, headers, err := webhook.Normalize(delivery)
+	if err != nil {
+		claim.release(apiContext)
+		return nil, false, err
+	}
+
+	req.Body = body
+	req.Headers = headers
+	return claim, true, nil
+}
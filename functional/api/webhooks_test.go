@@ -0,0 +1,136 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pirogoeth/apps/functional/compute"
+	"github.com/pirogoeth/apps/functional/database"
+)
+
+func TestV1Invocations_InvokeWebhookFunction(t *testing.T) {
+	router, apiContext := setupTestAPI(t)
+	ctx := context.Background()
+
+	function := createTestDeployedFunction(t, apiContext, "webhook-function",
+		withEnvVars(`{"GITHUB_SECRET": "s3cret"}`),
+		withInputAdapter(`{"provider": "github", "secret_env": "GITHUB_SECRET"}`),
+	)
+
+	body := []byte(`{"ref": "refs/heads/main"}`)
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write(body)
+	validSignature := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	tests := []struct {
+		name           string
+		signature      string
+		delivery       string
+		expectedStatus int
+	}{
+		{
+			name:           "valid delivery",
+			signature:      validSignature,
+			delivery:       "delivery-1",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "replayed delivery",
+			signature:      validSignature,
+			delivery:       "delivery-1",
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "replayed body with a new delivery id",
+			signature:      validSignature,
+			delivery:       "delivery-3",
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "invalid signature",
+			signature:      "sha256=" + hex.EncodeToString([]byte("not the signature")),
+			delivery:       "delivery-2",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "missing delivery id",
+			signature:      validSignature,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/v1/invoke/webhook-function", bytes.NewReader(body))
+			req.Header.Set("X-Hub-Signature-256", tt.signature)
+			req.Header.Set("X-GitHub-Event", "push")
+			if tt.delivery != "" {
+				req.Header.Set("X-GitHub-Delivery", tt.delivery)
+			}
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+
+	invocations, err := apiContext.Querier.ListInvocationsByFunction(ctx, database.ListInvocationsByFunctionParams{
+		FunctionID: function.ID,
+		Limit:      10,
+	})
+	if err != nil {
+		t.Fatalf("Failed to list invocations: %v", err)
+	}
+	if len(invocations) != 1 {
+		t.Errorf("Expected only the valid delivery to be executed, got %d invocations", len(invocations))
+	}
+}
+
+func TestV1Invocations_FailedWebhookDeliveryCanBeRedelivered(t *testing.T) {
+	router, apiContext := setupTestAPI(t)
+	mock := mockProviderFrom(t, apiContext)
+
+	createTestDeployedFunction(t, apiContext, "webhook-function",
+		withEnvVars(`{"GITHUB_SECRET": "s3cret"}`),
+		withInputAdapter(`{"provider": "github", "secret_env": "GITHUB_SECRET"}`),
+	)
+
+	body := []byte(`{"ref": "refs/heads/main"}`)
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write(body)
+	signature := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	deliver := func() int {
+		req := httptest.NewRequest(http.MethodPost, "/v1/invoke/webhook-function", bytes.NewReader(body))
+		req.Header.Set("X-Hub-Signature-256", signature)
+		req.Header.Set("X-GitHub-Event", "push")
+		req.Header.Set("X-GitHub-Delivery", "delivery-1")
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	mock.executeResult = &compute.InvocationResult{StatusCode: 500, Error: "boom"}
+	if status := deliver(); status != http.StatusInternalServerError {
+		t.Fatalf("Expected status %d, got %d", http.StatusInternalServerError, status)
+	}
+
+	mock.executeResult = nil
+	if status := deliver(); status != http.StatusOK {
+		t.Fatalf("Expected the failed delivery to be accepted again, got %d", status)
+	}
+
+	if status := deliver(); status != http.StatusConflict {
+		t.Errorf("Expected the successful delivery to be rejected as a replay, got %d", status)
+	}
+}
@@ -0,0 +1,221 @@
+// Package bundle reads and writes portable function bundles, signed tarballs
+// that carry a function's metadata and code between servers.
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// FormatVersion is the bundle layout written by this version
+const FormatVersion = 1
+
+const (
+	manifestFile  = "manifest.json"
+	codeFile      = "code.zip"
+	signatureFile = "manifest.sig"
+
+	// maxEntrySize bounds how much of a single tar entry is read
+	maxEntrySize = 256 * 1024 * 1024
+)
+
+var (
+	// ErrNoSigningKey is returned when a bundle is written or read without a key
+	ErrNoSigningKey = errors.New("bundle signing key is not configured")
+	// ErrInvalidSignature is returned when a bundle was not signed with the expected key
+	ErrInvalidSignature = errors.New("bundle signature is invalid")
+)
+
+// Function is the environment-independent metadata of a function
+type Function struct {
+	Name           string          `json:"name"`
+	Description    string          `json:"description,omitempty"`
+	Runtime        string          `json:"runtime"`
+	Handler        string          `json:"handler"`
+	TimeoutSeconds int64           `json:"timeout_seconds"`
+	MemoryMB       int64           `json:"memory_mb"`
+	InputSchema    json.RawMessage `json:"input_schema,omitempty"`
+	OutputSchema   json.RawMessage `json:"output_schema,omitempty"`
+	Public         bool            `json:"public"`
+	RetryPolicy    RetryPolicy     `json:"retry_policy"`
+	InputAdapter   json.RawMessage `json:"input_adapter,omitempty"`
+}
+
+// RetryPolicy mirrors the retry settings of the function
+type RetryPolicy struct {
+	MaxAttempts int64 `json:"max_attempts"`
+	BackoffMS   int64 `json:"backoff_ms"`
+}
+
+// Manifest describes the contents of a bundle. Only the names of environment
+// variables are exported, their values stay on the server they were set on.
+type Manifest struct {
+	FormatVersion int       `json:"format_version"`
+	ExportedAt    time.Time `json:"exported_at"`
+	Function      Function  `json:"function"`
+	EnvVarNames   []string  `json:"env_var_names"`
+	// Routes are the gateway paths the function is served on
+	Routes []string `json:"routes"`
+	// CodeSHA256 is the hex digest of the code archive in the bundle
+	CodeSHA256 string `json:"code_sha256"`
+}
+
+// SameAs reports whether two manifests describe the same function revision,
+// ignoring when they were exported
+func (m *Manifest) SameAs(other *Manifest) bool {
+	this, err := json.Marshal(m.comparable())
+	if err != nil {
+		return false
+	}
+
+	that, err := json.Marshal(other.comparable())
+	if err != nil {
+		return false
+	}
+
+	return bytes.Equal(this, that)
+}
+
+func (m *Manifest) comparable() Manifest {
+	comparable := *m
+	comparable.ExportedAt = time.Time{}
+	return comparable
+}
+
+// CodeDigest returns the hex SHA-256 digest of a code archive
+func CodeDigest(code []byte) string {
+	sum := sha256.Sum256(code)
+	return hex.EncodeToString(sum[:])
+}
+
+// Write writes a gzipped tarball holding the manifest, the code archive and an
+// HMAC-SHA256 signature of the manifest. The manifest pins the code by digest,
+// so the signature covers both.
+func Write(w io.Writer, manifest *Manifest, code []byte, key []byte) error {
+	if len(key) == 0 {
+		return ErrNoSigningKey
+	}
+
+	manifest.FormatVersion = FormatVersion
+	manifest.CodeSHA256 = CodeDigest(code)
+
+	manifestBytes, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	entries := []struct {
+		name string
+		data []byte
+	}{
+		{manifestFile, manifestBytes},
+		{codeFile, code},
+		{signatureFile, []byte(sign(manifestBytes, key))},
+	}
+	for _, entry := range entries {
+		header := &tar.Header{
+			Name:    entry.name,
+			Mode:    0644,
+			Size:    int64(len(entry.data)),
+			ModTime: manifest.ExportedAt,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return fmt.Errorf("failed to write %s header: %w", entry.name, err)
+		}
+		if _, err := tw.Write(entry.data); err != nil {
+			return fmt.Errorf("failed to write %s: %w", entry.name, err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to finish tarball: %w", err)
+	}
+
+	return gz.Close()
+}
+
+// Read reads a bundle written by Write, checking the manifest signature and
+// the digest of the code archive before returning either.
+func Read(r io.Reader, key []byte) (*Manifest, []byte, error) {
+	if len(key) == 0 {
+		return nil, nil, ErrNoSigningKey
+	}
+
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("bundle is not gzip compressed: %w", err)
+	}
+	defer gz.Close()
+
+	files := make(map[string][]byte)
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read bundle: %w", err)
+		}
+
+		switch header.Name {
+		case manifestFile, codeFile, signatureFile:
+		default:
+			return nil, nil, fmt.Errorf("unexpected file in bundle: %s", header.Name)
+		}
+
+		data, err := io.ReadAll(io.LimitReader(tr, maxEntrySize+1))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read %s: %w", header.Name, err)
+		}
+		if len(data) > maxEntrySize {
+			return nil, nil, fmt.Errorf("%s is too large", header.Name)
+		}
+		files[header.Name] = data
+	}
+
+	for _, name := range []string{manifestFile, codeFile, signatureFile} {
+		if _, ok := files[name]; !ok {
+			return nil, nil, fmt.Errorf("bundle is missing %s", name)
+		}
+	}
+
+	expected := sign(files[manifestFile], key)
+	if !hmac.Equal([]byte(expected), bytes.TrimSpace(files[signatureFile])) {
+		return nil, nil, ErrInvalidSignature
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(files[manifestFile], &manifest); err != nil {
+		return nil, nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+
+	if manifest.FormatVersion != FormatVersion {
+		return nil, nil, fmt.Errorf("unsupported bundle format version %d", manifest.FormatVersion)
+	}
+
+	code := files[codeFile]
+	if CodeDigest(code) != manifest.CodeSHA256 {
+		return nil, nil, fmt.Errorf("code archive does not match manifest digest")
+	}
+
+	return &manifest, code, nil
+}
+
+func sign(data, key []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil))
+}
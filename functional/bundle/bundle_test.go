@@ -0,0 +1,90 @@
+package bundle
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+func testManifest() *Manifest {
+	return &Manifest{
+		ExportedAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		Function: Function{
+			Name:           "hello",
+			Runtime:        "nodejs",
+			Handler:        "index.js",
+			TimeoutSeconds: 30,
+			MemoryMB:       128,
+			RetryPolicy:    RetryPolicy{MaxAttempts: 3, BackoffMS: 500},
+		},
+		EnvVarNames: []string{"API_TOKEN"},
+		Routes:      []string{"/v1/invoke/hello"},
+	}
+}
+
+func TestWriteRead_RoundTrip(t *testing.T) {
+	key := []byte("secret")
+	code := []byte("PK fake zip contents")
+
+	var buf bytes.Buffer
+	if err := Write(&buf, testManifest(), code, key); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	manifest, readCode, err := Read(&buf, key)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+
+	if !bytes.Equal(readCode, code) {
+		t.Errorf("Expected code %q, got %q", code, readCode)
+	}
+	if manifest.CodeSHA256 != CodeDigest(code) {
+		t.Errorf("Expected code digest %s, got %s", CodeDigest(code), manifest.CodeSHA256)
+	}
+
+	expected := testManifest()
+	expected.FormatVersion = FormatVersion
+	expected.CodeSHA256 = CodeDigest(code)
+	if !manifest.SameAs(expected) {
+		t.Errorf("Read manifest does not match written manifest: %+v", manifest)
+	}
+}
+
+func TestRead_RejectsWrongKey(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, testManifest(), []byte("code"), []byte("secret")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	_, _, err := Read(&buf, []byte("other secret"))
+	if !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Expected ErrInvalidSignature, got %v", err)
+	}
+}
+
+func TestRead_RequiresKey(t *testing.T) {
+	if err := Write(&bytes.Buffer{}, testManifest(), []byte("code"), nil); !errors.Is(err, ErrNoSigningKey) {
+		t.Errorf("Expected ErrNoSigningKey from Write, got %v", err)
+	}
+
+	if _, _, err := Read(&bytes.Buffer{}, nil); !errors.Is(err, ErrNoSigningKey) {
+		t.Errorf("Expected ErrNoSigningKey from Read, got %v", err)
+	}
+}
+
+func TestManifest_SameAsIgnoresExportTime(t *testing.T) {
+	a := testManifest()
+	b := testManifest()
+	b.ExportedAt = b.ExportedAt.Add(time.Hour)
+
+	if !a.SameAs(b) {
+		t.Errorf("Expected manifests exported at different times to be the same")
+	}
+
+	b.Function.MemoryMB = 256
+	if a.SameAs(b) {
+		t.Errorf("Expected manifests with different memory to differ")
+	}
+}
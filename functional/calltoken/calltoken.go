@@ -0,0 +1,88 @@
+package calltoken
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Headers the gateway passes to the function wrapper, which moves them into
+// the handler's environment instead of handing them to the function
+const (
+	HeaderToken     = "X-Functional-Invoke-Token"
+	HeaderInvokeURL = "X-Functional-Invoke-Url"
+	HeaderCallDepth = "X-Functional-Call-Depth"
+)
+
+// Environment variables the function wrapper sets for the handler
+const (
+	EnvToken       = "FUNCTIONAL_INVOKE_TOKEN"
+	EnvInvokeURL   = "FUNCTIONAL_INVOKE_URL"
+	EnvCallDepth   = "FUNCTIONAL_CALL_DEPTH"
+	EnvTraceParent = "TRACEPARENT"
+	EnvTraceState  = "TRACESTATE"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid invoke token")
+	ErrExpired      = errors.New("invoke token has expired")
+)
+
+// Claims identify the invocation a token was issued to
+type Claims struct {
+	FunctionID   string `json:"function_id"`
+	InvocationID string `json:"invocation_id"`
+	// Depth is how many function-to-function calls led to the invocation
+	Depth     int   `json:"depth"`
+	ExpiresAt int64 `json:"expires_at"`
+}
+
+// Issue signs the claims into a token
+func Issue(key []byte, claims Claims) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode claims: %w", err)
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + sign(key, encoded), nil
+}
+
+// Verify checks the token's signature and expiry and returns its claims
+func Verify(key []byte, token string, now time.Time) (*Claims, error) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(sign(key, encoded))) {
+		return nil, ErrInvalidToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	var claims Claims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	if now.Unix() >= claims.ExpiresAt {
+		return nil, ErrExpired
+	}
+
+	return &claims, nil
+}
+
+func sign(key []byte, encoded string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
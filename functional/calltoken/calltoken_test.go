@@ -0,0 +1,42 @@
+package calltoken
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestIssueVerify(t *testing.T) {
+	key := []byte("test-key")
+	now := time.Unix(1700000000, 0)
+
+	token, err := Issue(key, Claims{
+		FunctionID:   "fn-1",
+		InvocationID: "inv-1",
+		Depth:        2,
+		ExpiresAt:    now.Add(time.Minute).Unix(),
+	})
+	if err != nil {
+		t.Fatalf("Issue failed: %v", err)
+	}
+
+	claims, err := Verify(key, token, now)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if claims.FunctionID != "fn-1" || claims.InvocationID != "inv-1" || claims.Depth != 2 {
+		t.Errorf("Unexpected claims: %+v", claims)
+	}
+
+	if _, err := Verify([]byte("other-key"), token, now); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Expected token signed with another key to be invalid, got %v", err)
+	}
+
+	if _, err := Verify(key, token, now.Add(time.Hour)); !errors.Is(err, ErrExpired) {
+		t.Errorf("Expected expired token to be rejected, got %v", err)
+	}
+
+	if _, err := Verify(key, "not-a-token", now); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Expected malformed token to be invalid, got %v", err)
+	}
+}
@@ -0,0 +1,377 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/pirogoeth/apps/functional/compute"
+	"github.com/pirogoeth/apps/pkg/logging"
+	"github.com/pirogoeth/apps/pkg/system"
+)
+
+const ComponentDev = "dev"
+
+// devReloadDebounce coalesces bursts of file events (editor saves, builds) into one reload
+const devReloadDebounce = 250 * time.Millisecond
+
+var devCmd = &cobra.Command{
+	Use:   "dev [function directory]",
+	Short: "Run a function locally with hot reload",
+	Long: `Run the function in the given directory as a local process and serve it over
+HTTP. The function is redeployed whenever a file in the directory changes.
+Neither Docker nor Firecracker are required.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runDev,
+}
+
+var devOpts struct {
+	listenAddress string
+	runtime       string
+	handler       string
+	timeout       time.Duration
+}
+
+func init() {
+	devCmd.Flags().StringVar(&devOpts.listenAddress, "listen", "127.0.0.1:8090", "address to serve the function on")
+	devCmd.Flags().StringVar(&devOpts.runtime, "runtime", "", "function runtime, detected from the directory if empty")
+	devCmd.Flags().StringVar(&devOpts.handler, "handler", "", "function handler, detected from the directory if empty")
+	devCmd.Flags().DurationVar(&devOpts.timeout, "timeout", 30*time.Second, "invocation timeout")
+
+	rootCmd.AddCommand(devCmd)
+}
+
+// devServer serves the current deployment of a function and swaps it out on reload
+type devServer struct {
+	provider *compute.ProcessProvider
+	function *compute.Function
+
+	mu         sync.RWMutex
+	deployment *compute.Deployment
+}
+
+func runDev(cmd *cobra.Command, args []string) error {
+	logging.Setup(logging.WithAppName(AppName), logging.WithComponentName(ComponentDev))
+
+	dir := "."
+	if len(args) > 0 {
+		dir = args[0]
+	}
+
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return fmt.Errorf("invalid function directory: %w", err)
+	}
+
+	runtime, handler := devOpts.runtime, devOpts.handler
+	if runtime == "" || handler == "" {
+		detectedRuntime, detectedHandler, err := detectFunctionRuntime(dir)
+		if err != nil {
+			return err
+		}
+		if runtime == "" {
+			runtime = detectedRuntime
+		}
+		if handler == "" {
+			handler = detectedHandler
+		}
+	}
+
+	workDir, err := os.MkdirTemp("", "functional-dev-")
+	if err != nil {
+		return fmt.Errorf("failed to create work directory: %w", err)
+	}
+	defer os.RemoveAll(workDir)
+
+	server := &devServer{
+		provider: compute.NewProcessProvider(&compute.ProcessConfig{WorkDir: workDir}),
+		function: &compute.Function{
+			ID:             "dev",
+			Name:           filepath.Base(dir),
+			CodePath:       dir,
+			Runtime:        runtime,
+			Handler:        handler,
+			TimeoutSeconds: int32(devOpts.timeout.Seconds()),
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	if err := server.reload(ctx); err != nil {
+		logrus.WithError(err).Error("initial deployment failed, waiting for changes")
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		cancel()
+		return fmt.Errorf("failed to create file watcher: %w", err)
+	}
+	defer watcher.Close()
+
+	if err := watchTree(watcher, dir); err != nil {
+		cancel()
+		return fmt.Errorf("failed to watch function directory: %w", err)
+	}
+
+	go server.watch(ctx, watcher, dir)
+
+	router := system.DefaultRouter()
+	router.NoRoute(server.invoke)
+
+	httpServer := &http.Server{
+		Addr:    devOpts.listenAddress,
+		Handler: router,
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"dir":            dir,
+		"runtime":        runtime,
+		"handler":        handler,
+		"listen_address": devOpts.listenAddress,
+	}).Info("serving function in dev mode")
+
+	go func() {
+		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logrus.WithError(err).Fatal("dev server failed")
+		}
+	}()
+
+	sw := system.NewSignalWaiter(os.Interrupt)
+	sw.OnBeforeCancel(func(ctx context.Context) error {
+		if err := httpServer.Shutdown(ctx); err != nil {
+			logrus.WithError(err).Error("could not shut down dev server")
+		}
+		server.stop(ctx)
+		return nil
+	})
+	sw.Wait(ctx, cancel)
+
+	return nil
+}
+
+// reload deploys the function from disk and replaces the serving deployment
+func (s *devServer) reload(ctx context.Context) error {
+	result, err := s.provider.Deploy(ctx, s.function, "")
+	if err != nil {
+		return err
+	}
+
+	deployResult := result.(*compute.DeployResult)
+	deployment := &compute.Deployment{
+		ID:         deployResult.DeploymentID,
+		FunctionID: s.function.ID,
+		Provider:   s.provider.Name(),
+		ResourceID: deployResult.ResourceID,
+		Status:     "active",
+		Replicas:   1,
+	}
+
+	s.mu.Lock()
+	previous := s.deployment
+	s.deployment = deployment
+	s.mu.Unlock()
+
+	if previous != nil {
+		if err := s.provider.Remove(ctx, previous); err != nil {
+			logrus.WithError(err).Warn("failed to remove previous deployment")
+		}
+	}
+
+	logrus.WithField("deployment_id", deployment.ID).Info("function reloaded")
+	return nil
+}
+
+func (s *devServer) stop(ctx context.Context) {
+	s.mu.Lock()
+	deployment := s.deployment
+	s.deployment = nil
+	s.mu.Unlock()
+
+	if deployment != nil {
+		if err := s.provider.Remove(ctx, deployment); err != nil {
+			logrus.WithError(err).Warn("failed to remove deployment")
+		}
+	}
+}
+
+// watch reloads the function after changes in dir settle down
+func (s *devServer) watch(ctx context.Context, watcher *fsnotify.Watcher, dir string) {
+	var debounce <-chan time.Time
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+
+			if s.ignoredPath(dir, event.Name) {
+				continue
+			}
+
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					if err := watchTree(watcher, event.Name); err != nil {
+						logrus.WithError(err).WithField("path", event.Name).Warn("failed to watch new directory")
+					}
+				}
+			}
+
+			logrus.WithField("path", event.Name).Debug("function source changed")
+			debounce = time.After(devReloadDebounce)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			logrus.WithError(err).Warn("file watcher error")
+		case <-debounce:
+			debounce = nil
+			if err := s.reload(ctx); err != nil {
+				logrus.WithError(err).Error("failed to reload function")
+			}
+		}
+	}
+}
+
+// ignoredPath reports whether a change to path should not trigger a reload
+func (s *devServer) ignoredPath(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return true
+	}
+
+	// The go runtime builds its handler binary into the function directory
+	if rel == "main" && (s.function.Runtime == "go" || s.function.Runtime == "golang") {
+		return true
+	}
+
+	for _, part := range strings.Split(rel, string(os.PathSeparator)) {
+		if isIgnoredDevDir(part) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *devServer) invoke(c *gin.Context) {
+	s.mu.RLock()
+	deployment := s.deployment
+	s.mu.RUnlock()
+
+	if deployment == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "function is not deployed"})
+		return
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("failed to read request body: %s", err)})
+		return
+	}
+
+	headers := make(map[string]string)
+	for key, values := range c.Request.Header {
+		if len(values) > 0 {
+			headers[key] = values[0]
+		}
+	}
+
+	query := make(map[string]string)
+	for key, values := range c.Request.URL.Query() {
+		if len(values) > 0 {
+			query[key] = values[0]
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), devOpts.timeout)
+	defer cancel()
+
+	result, err := s.provider.Execute(ctx, deployment, &compute.InvocationRequest{
+		FunctionID: s.function.ID,
+		Body:       body,
+		Headers:    headers,
+		Method:     c.Request.Method,
+		Path:       c.Request.URL.Path,
+		QueryArgs:  query,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	invocation := result.(*compute.InvocationResult)
+	if invocation.Error != "" {
+		logrus.WithField("error", invocation.Error).Warn("function returned an error")
+	}
+
+	contentType := "application/octet-stream"
+	for key, value := range invocation.Headers {
+		if strings.EqualFold(key, "Content-Type") {
+			contentType = value
+			continue
+		}
+		c.Header(key, value)
+	}
+
+	c.Data(invocation.StatusCode, contentType, invocation.Body)
+}
+
+// detectFunctionRuntime guesses the runtime and handler from well-known files
+func detectFunctionRuntime(dir string) (string, string, error) {
+	candidates := []struct {
+		file    string
+		runtime string
+		handler string
+	}{
+		{"index.js", "nodejs", "index.js"},
+		{"package.json", "nodejs", "index.js"},
+		{"app.py", "python3", "app.py"},
+		{"main.py", "python3", "main.py"},
+		{"go.mod", "go", "main"},
+	}
+
+	for _, candidate := range candidates {
+		if _, err := os.Stat(filepath.Join(dir, candidate.file)); err == nil {
+			return candidate.runtime, candidate.handler, nil
+		}
+	}
+
+	return "", "", fmt.Errorf("could not detect function runtime in %s, use --runtime and --handler", dir)
+}
+
+// watchTree adds dir and its subdirectories to the watcher
+func watchTree(watcher *fsnotify.Watcher, dir string) error {
+	return filepath.WalkDir(dir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !entry.IsDir() {
+			return nil
+		}
+
+		if path != dir && isIgnoredDevDir(entry.Name()) {
+			return filepath.SkipDir
+		}
+
+		return watcher.Add(path)
+	})
+}
+
+func isIgnoredDevDir(name string) bool {
+	return name == "node_modules" || name == "__pycache__" || (strings.HasPrefix(name, ".") && name != ".")
+}
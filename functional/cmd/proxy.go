@@ -79,6 +79,7 @@ func runProxy(cmd *cobra.Command, args []string) error {
 		"traefik_api_url": cfg.Proxy.TraefikAPIURL,
 		"max_containers":  cfg.Proxy.MaxContainersPerFunction,
 		"idle_timeout":    cfg.Proxy.ContainerIdleTimeout,
+		"node_capacity":   cfg.Proxy.Cluster.Capacity,
 	}).Info("Starting function proxy service")
 
 	if err := proxyService.Start(ctx); err != nil {
@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"os"
 
 	"github.com/sirupsen/logrus"
@@ -10,6 +12,7 @@ import (
 	"github.com/pirogoeth/apps/functional/api"
 	"github.com/pirogoeth/apps/functional/compute"
 	"github.com/pirogoeth/apps/functional/database"
+	"github.com/pirogoeth/apps/functional/ratelimit"
 	"github.com/pirogoeth/apps/functional/types"
 )
 
@@ -66,15 +69,47 @@ func serveFunc(cmd *cobra.Command, args []string) {
 		}
 		firecrackerProvider := compute.NewFirecrackerProvider(firecrackerConfig)
 		computeRegistry.Register(firecrackerProvider)
+	case "process":
+		processConfig := &compute.ProcessConfig{}
+		if cfg.Compute.Process != nil {
+			processConfig.WorkDir = cfg.Compute.Process.WorkDir
+			processConfig.WrapperCommand = cfg.Compute.Process.WrapperCommand
+		}
+		processProvider := compute.NewProcessProvider(processConfig)
+		computeRegistry.Register(processProvider)
+	case "wasm":
+		// Registered below for all configurations
 	default:
 		logrus.WithField("provider", cfg.Compute.Provider).Fatal("unsupported compute provider")
 	}
 
+	// The wasm provider runs in-process, so functions with the wasm runtime can
+	// use it alongside whichever provider is configured
+	wasmConfig := &compute.WasmConfig{}
+	if cfg.Compute.Wasm != nil {
+		wasmConfig.MaxMemoryMB = cfg.Compute.Wasm.MaxMemoryMB
+	}
+	computeRegistry.Register(compute.NewWasmProvider(wasmConfig))
+
+	// Invoke tokens only have to be verified by the server that issued them,
+	// so a random key works when none is configured
+	if cfg.Internal.SigningKey == "" {
+		key := make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			logrus.WithError(err).Fatal("failed to generate internal signing key")
+		}
+		cfg.Internal.SigningKey = hex.EncodeToString(key)
+	}
+	if cfg.Internal.InvokeURL == "" {
+		logrus.Warn("internal invoke url is not configured, functions can't invoke each other")
+	}
+
 	// Create API context
 	apiContext := &types.ApiContext{
 		Config:  cfg,
 		Querier: db.Queries,
 		Compute: computeRegistry,
+		Limiter: ratelimit.NewLimiter(),
 	}
 
 	// Setup router
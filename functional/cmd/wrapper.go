@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/pirogoeth/apps/functional/proxy"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var wrapperCmd = &cobra.Command{
+	Use:   "wrapper",
+	Short: "Run the function wrapper on stdin/stdout",
+	Long: `Run the function wrapper, reading requests from stdin and writing responses
+to stdout. The function is configured through the FUNCTION_RUNTIME,
+FUNCTION_HANDLER and FUNCTION_DIR environment variables.`,
+	Hidden: true,
+	RunE:   runWrapper,
+}
+
+func init() {
+	rootCmd.AddCommand(wrapperCmd)
+}
+
+func runWrapper(cmd *cobra.Command, args []string) error {
+	// stdout carries the wrapper protocol, so logs must go elsewhere
+	logrus.SetOutput(os.Stderr)
+
+	runtime := os.Getenv("FUNCTION_RUNTIME")
+	handler := os.Getenv("FUNCTION_HANDLER")
+	dir := os.Getenv("FUNCTION_DIR")
+
+	if runtime == "" {
+		runtime = "nodejs"
+	}
+	if handler == "" {
+		handler = "index.js"
+	}
+	if dir == "" {
+		var err error
+		if dir, err = os.Getwd(); err != nil {
+			return err
+		}
+	}
+
+	return proxy.StartWrapper(runtime, handler, dir)
+}
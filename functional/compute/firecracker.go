@@ -158,8 +158,9 @@ func (f *FirecrackerProvider) Deploy(ctx context.Context, fn interface{}, imageN
 		return nil, fmt.Errorf("failed to start firecracker VM: %w", err)
 	}
 
-	// Store VM reference
-	f.vms[deploymentID] = vm
+	// Store VM reference by resource ID, deployment records are created before
+	// the provider is called and only learn the resource ID from the result
+	f.vms[vmID] = vm
 
 	logrus.
 		WithField("function_id", function.ID).
@@ -185,7 +186,7 @@ func (f *FirecrackerProvider) Execute(ctx context.Context, deployment interface{
 		return nil, fmt.Errorf("invalid invocation request type")
 	}
 
-	vm, exists := f.vms[dep.ID]
+	vm, exists := f.vms[dep.ResourceID]
 	if !exists {
 		return nil, fmt.Errorf("VM not found for deployment %s", dep.ID)
 	}
@@ -221,7 +222,7 @@ func (f *FirecrackerProvider) Remove(ctx context.Context, deployment interface{}
 		return fmt.Errorf("invalid deployment type")
 	}
 
-	vm, exists := f.vms[dep.ID]
+	vm, exists := f.vms[dep.ResourceID]
 	if !exists {
 		return fmt.Errorf("VM not found for deployment %s", dep.ID)
 	}
@@ -240,7 +241,7 @@ func (f *FirecrackerProvider) Remove(ctx context.Context, deployment interface{}
 	}
 
 	// Remove from tracking
-	delete(f.vms, dep.ID)
+	delete(f.vms, dep.ResourceID)
 
 	return nil
 }
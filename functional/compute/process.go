@@ -0,0 +1,428 @@
+package compute
+
+import (
+	"archive/zip"
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// ProcessConfig configures the process compute provider
+type ProcessConfig struct {
+	// WorkDir is where zipped function code is extracted before it is run
+	WorkDir string `json:"work_dir"`
+	// WrapperCommand starts the function wrapper. It defaults to running the
+	// `wrapper` subcommand of the current executable.
+	WrapperCommand []string `json:"wrapper_command"`
+}
+
+// processRequest is the wrapper protocol request, mirroring proxy.FunctionWrapperRequest
+type processRequest struct {
+	Method    string            `json:"method"`
+	Path      string            `json:"path"`
+	Headers   map[string]string `json:"headers"`
+	Query     map[string]string `json:"query"`
+	Body      string            `json:"body"`
+	RequestID string            `json:"request_id"`
+}
+
+// processResponse is the wrapper protocol response, mirroring proxy.FunctionWrapperResponse
+type processResponse struct {
+	StatusCode int               `json:"status_code"`
+	Headers    map[string]string `json:"headers"`
+	Body       string            `json:"body"`
+	Error      string            `json:"error,omitempty"`
+}
+
+// functionProcess is a running wrapper child process for a single deployment
+type functionProcess struct {
+	// mu serializes requests, the wrapper handles one request at a time
+	mu      sync.Mutex
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	stdout  *bufio.Scanner
+	dir     string
+	ownsDir bool
+}
+
+// ProcessProvider runs function handlers as plain child processes speaking the
+// wrapper stdin/stdout protocol. It needs neither Docker nor Firecracker, which
+// makes it suited for local development and tests.
+type ProcessProvider struct {
+	config *ProcessConfig
+
+	mu        sync.Mutex
+	processes map[string]*functionProcess
+}
+
+func NewProcessProvider(config interface{}) *ProcessProvider {
+	processConfig, ok := config.(*ProcessConfig)
+	if !ok {
+		logrus.Fatal("invalid process config type")
+	}
+
+	if processConfig.WorkDir == "" {
+		processConfig.WorkDir = filepath.Join(os.TempDir(), "functional-process")
+	}
+
+	if len(processConfig.WrapperCommand) == 0 {
+		executable, err := os.Executable()
+		if err != nil {
+			logrus.WithError(err).Fatal("failed to determine executable for process wrapper")
+		}
+		processConfig.WrapperCommand = []string{executable, "wrapper"}
+	}
+
+	return &ProcessProvider{
+		config:    processConfig,
+		processes: make(map[string]*functionProcess),
+	}
+}
+
+func (p *ProcessProvider) Name() string {
+	return "process"
+}
+
+func (p *ProcessProvider) Deploy(ctx context.Context, fn interface{}, imageName string) (interface{}, error) {
+	function, ok := fn.(*Function)
+	if !ok {
+		return nil, fmt.Errorf("invalid function type")
+	}
+
+	deploymentID := uuid.New().String()
+	resourceID := uuid.New().String()
+
+	logrus.WithFields(logrus.Fields{
+		"function_id": function.ID,
+		"resource_id": resourceID,
+		"code_path":   function.CodePath,
+	}).Info("deploying function as process")
+
+	dir, ownsDir, err := p.prepareCode(function, resourceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare function code: %w", err)
+	}
+
+	proc, err := p.startProcess(ctx, function, dir)
+	if err != nil {
+		if ownsDir {
+			os.RemoveAll(dir)
+		}
+		return nil, fmt.Errorf("failed to start function process: %w", err)
+	}
+	proc.ownsDir = ownsDir
+
+	p.mu.Lock()
+	p.processes[resourceID] = proc
+	p.mu.Unlock()
+
+	logrus.WithFields(logrus.Fields{
+		"function_id": function.ID,
+		"resource_id": resourceID,
+		"pid":         proc.cmd.Process.Pid,
+	}).Info("process function deployed successfully")
+
+	return &DeployResult{
+		DeploymentID: deploymentID,
+		ResourceID:   resourceID,
+		ImageTag:     "process",
+	}, nil
+}
+
+func (p *ProcessProvider) Execute(ctx context.Context, deployment interface{}, req interface{}) (interface{}, error) {
+	dep, ok := deployment.(*Deployment)
+	if !ok {
+		return nil, fmt.Errorf("invalid deployment type")
+	}
+
+	invReq, ok := req.(*InvocationRequest)
+	if !ok {
+		return nil, fmt.Errorf("invalid invocation request type")
+	}
+
+	p.mu.Lock()
+	proc, exists := p.processes[dep.ResourceID]
+	p.mu.Unlock()
+	if !exists {
+		return nil, fmt.Errorf("process not found for deployment %s", dep.ID)
+	}
+
+	request := &processRequest{
+		Method:    invReq.Method,
+		Path:      invReq.Path,
+		Headers:   invReq.Headers,
+		Query:     invReq.QueryArgs,
+		Body:      string(invReq.Body),
+		RequestID: uuid.New().String(),
+	}
+
+	start := time.Now()
+	response, err := p.roundTrip(ctx, dep.ResourceID, proc, request)
+	duration := time.Since(start)
+
+	if err != nil {
+		return &InvocationResult{
+			StatusCode: 500,
+			Body:       []byte(fmt.Sprintf("Function execution failed: %v", err)),
+			DurationMS: duration.Milliseconds(),
+			Error:      err.Error(),
+		}, nil
+	}
+
+	return &InvocationResult{
+		StatusCode:   response.StatusCode,
+		Body:         []byte(response.Body),
+		Headers:      response.Headers,
+		DurationMS:   duration.Milliseconds(),
+		ResponseSize: int64(len(response.Body)),
+		Error:        response.Error,
+	}, nil
+}
+
+func (p *ProcessProvider) Scale(ctx context.Context, deployment interface{}, replicas int) error {
+	// A process deployment is a single wrapper process
+	logrus.WithField("replicas", replicas).Warn("process provider scaling not implemented")
+	return nil
+}
+
+func (p *ProcessProvider) Remove(ctx context.Context, deployment interface{}) error {
+	dep, ok := deployment.(*Deployment)
+	if !ok {
+		return fmt.Errorf("invalid deployment type")
+	}
+
+	p.mu.Lock()
+	proc, exists := p.processes[dep.ResourceID]
+	delete(p.processes, dep.ResourceID)
+	p.mu.Unlock()
+	if !exists {
+		return fmt.Errorf("process not found for deployment %s", dep.ID)
+	}
+
+	logrus.WithField("resource_id", dep.ResourceID).Info("stopping function process")
+	p.stopProcess(proc)
+
+	return nil
+}
+
+func (p *ProcessProvider) Health(ctx context.Context) error {
+	if _, err := exec.LookPath(p.config.WrapperCommand[0]); err != nil {
+		return fmt.Errorf("process wrapper command not found: %w", err)
+	}
+
+	return nil
+}
+
+// prepareCode returns the directory to run the function from. Directories are
+// used in place, zip archives are extracted into the work dir.
+func (p *ProcessProvider) prepareCode(function *Function, resourceID string) (string, bool, error) {
+	info, err := os.Stat(function.CodePath)
+	if err != nil {
+		return "", false, err
+	}
+
+	if info.IsDir() {
+		dir, err := filepath.Abs(function.CodePath)
+		if err != nil {
+			return "", false, err
+		}
+		return dir, false, nil
+	}
+
+	dir, err := filepath.Abs(filepath.Join(p.config.WorkDir, resourceID))
+	if err != nil {
+		return "", false, err
+	}
+
+	if err := extractZip(function.CodePath, dir); err != nil {
+		os.RemoveAll(dir)
+		return "", false, err
+	}
+
+	return dir, true, nil
+}
+
+func (p *ProcessProvider) startProcess(ctx context.Context, function *Function, dir string) (*functionProcess, error) {
+	// Go handlers are run as a prebuilt ./main binary by the wrapper
+	if strings.EqualFold(function.Runtime, "go") || strings.EqualFold(function.Runtime, "golang") {
+		build := exec.CommandContext(ctx, "go", "build", "-o", "main", ".")
+		build.Dir = dir
+		if output, err := build.CombinedOutput(); err != nil {
+			return nil, fmt.Errorf("go build failed: %w: %s", err, output)
+		}
+	}
+
+	cmd := exec.Command(p.config.WrapperCommand[0], p.config.WrapperCommand[1:]...)
+	cmd.Dir = dir
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(),
+		"FUNCTION_RUNTIME="+function.Runtime,
+		"FUNCTION_HANDLER="+function.Handler,
+		"FUNCTION_DIR="+dir,
+	)
+
+	if function.EnvVars != "" {
+		var envVars map[string]string
+		if err := json.Unmarshal([]byte(function.EnvVars), &envVars); err != nil {
+			return nil, fmt.Errorf("invalid function environment variables: %w", err)
+		}
+		for key, value := range envVars {
+			cmd.Env = append(cmd.Env, key+"="+value)
+		}
+	}
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open stdin pipe: %w", err)
+	}
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open stdout pipe: %w", err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+
+	return &functionProcess{
+		cmd:    cmd,
+		stdin:  stdin,
+		stdout: scanner,
+		dir:    dir,
+	}, nil
+}
+
+// roundTrip writes one request line to the process and reads one response line.
+// A process that does not answer before ctx is done is stopped, since its
+// stdout can no longer be matched up with requests.
+func (p *ProcessProvider) roundTrip(ctx context.Context, resourceID string, proc *functionProcess, request *processRequest) (*processResponse, error) {
+	proc.mu.Lock()
+	defer proc.mu.Unlock()
+
+	reqJSON, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	if _, err := proc.stdin.Write(append(reqJSON, '\n')); err != nil {
+		return nil, fmt.Errorf("failed to write request to process: %w", err)
+	}
+
+	type readResult struct {
+		line []byte
+		err  error
+	}
+	results := make(chan readResult, 1)
+	go func() {
+		if proc.stdout.Scan() {
+			results <- readResult{line: append([]byte(nil), proc.stdout.Bytes()...)}
+			return
+		}
+		err := proc.stdout.Err()
+		if err == nil {
+			err = io.EOF
+		}
+		results <- readResult{err: err}
+	}()
+
+	select {
+	case result := <-results:
+		if result.err != nil {
+			return nil, fmt.Errorf("failed to read response from process: %w", result.err)
+		}
+
+		var response processResponse
+		if err := json.Unmarshal(result.line, &response); err != nil {
+			return nil, fmt.Errorf("failed to parse response: %w", err)
+		}
+		return &response, nil
+	case <-ctx.Done():
+		p.mu.Lock()
+		if p.processes[resourceID] == proc {
+			delete(p.processes, resourceID)
+		}
+		p.mu.Unlock()
+		p.stopProcess(proc)
+		return nil, fmt.Errorf("function timed out: %w", ctx.Err())
+	}
+}
+
+func (p *ProcessProvider) stopProcess(proc *functionProcess) {
+	proc.stdin.Close()
+	if proc.cmd.Process != nil {
+		proc.cmd.Process.Kill()
+	}
+	proc.cmd.Wait()
+
+	if proc.ownsDir {
+		if err := os.RemoveAll(proc.dir); err != nil {
+			logrus.WithError(err).Warn("failed to clean up process directory")
+		}
+	}
+}
+
+// extractZip extracts the archive at src into dst, rejecting entries that
+// would escape dst
+func extractZip(src, dst string) error {
+	reader, err := zip.OpenReader(src)
+	if err != nil {
+		return fmt.Errorf("failed to open code archive: %w", err)
+	}
+	defer reader.Close()
+
+	for _, file := range reader.File {
+		target := filepath.Join(dst, file.Name)
+		if !strings.HasPrefix(target, filepath.Clean(dst)+string(os.PathSeparator)) {
+			return fmt.Errorf("invalid path in code archive: %s", file.Name)
+		}
+
+		if file.FileInfo().IsDir() {
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+
+		if err := extractZipFile(file, target); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func extractZipFile(file *zip.File, target string) error {
+	src, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, file.Mode())
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	_, err = io.Copy(dst, src)
+	return err
+}
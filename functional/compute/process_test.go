@@ -0,0 +1,151 @@
+package compute
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+)
+
+// When set, the test binary acts as a wrapper that echoes request bodies back
+const echoWrapperEnv = "PROCESS_PROVIDER_ECHO_WRAPPER"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(echoWrapperEnv) == "1" {
+		runEchoWrapper()
+		return
+	}
+
+	os.Exit(m.Run())
+}
+
+func runEchoWrapper() {
+	scanner := bufio.NewScanner(os.Stdin)
+	encoder := json.NewEncoder(os.Stdout)
+
+	for scanner.Scan() {
+		var request processRequest
+		if err := json.Unmarshal(scanner.Bytes(), &request); err != nil {
+			continue
+		}
+
+		if request.Path == "/hang" {
+			select {}
+		}
+
+		encoder.Encode(&processResponse{
+			StatusCode: 200,
+			Headers:    map[string]string{"X-Handler": os.Getenv("FUNCTION_HANDLER")},
+			Body:       request.Method + " " + request.Path + " " + request.Body,
+		})
+	}
+}
+
+func newEchoProcessProvider(t *testing.T) *ProcessProvider {
+	t.Setenv(echoWrapperEnv, "1")
+
+	executable, err := os.Executable()
+	if err != nil {
+		t.Fatalf("Failed to get test executable: %v", err)
+	}
+
+	return NewProcessProvider(&ProcessConfig{
+		WorkDir:        t.TempDir(),
+		WrapperCommand: []string{executable},
+	})
+}
+
+func deployEchoFunction(t *testing.T, provider *ProcessProvider) *Deployment {
+	result, err := provider.Deploy(context.Background(), &Function{
+		ID:       "echo-function",
+		Name:     "echo",
+		CodePath: t.TempDir(),
+		Runtime:  "nodejs",
+		Handler:  "index.js",
+	}, "")
+	if err != nil {
+		t.Fatalf("Failed to deploy function: %v", err)
+	}
+
+	deployResult := result.(*DeployResult)
+	return &Deployment{
+		ID:         deployResult.DeploymentID,
+		ResourceID: deployResult.ResourceID,
+	}
+}
+
+func TestProcessProvider_Name(t *testing.T) {
+	provider := NewProcessProvider(&ProcessConfig{})
+
+	if provider.Name() != "process" {
+		t.Errorf("Expected provider name to be 'process', got %s", provider.Name())
+	}
+}
+
+func TestProcessProvider_Execute(t *testing.T) {
+	provider := newEchoProcessProvider(t)
+	deployment := deployEchoFunction(t, provider)
+	defer provider.Remove(context.Background(), deployment)
+
+	for _, body := range []string{"first", "second"} {
+		result, err := provider.Execute(context.Background(), deployment, &InvocationRequest{
+			FunctionID: "echo-function",
+			Body:       []byte(body),
+			Method:     "POST",
+			Path:       "/echo",
+		})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		invResult := result.(*InvocationResult)
+		if invResult.StatusCode != 200 {
+			t.Errorf("Expected status code 200, got %d", invResult.StatusCode)
+		}
+
+		if expected := "POST /echo " + body; string(invResult.Body) != expected {
+			t.Errorf("Expected body %q, got %q", expected, invResult.Body)
+		}
+
+		if invResult.Headers["X-Handler"] != "index.js" {
+			t.Errorf("Expected handler to be passed to wrapper, got %q", invResult.Headers["X-Handler"])
+		}
+	}
+}
+
+func TestProcessProvider_ExecuteTimeout(t *testing.T) {
+	provider := newEchoProcessProvider(t)
+	deployment := deployEchoFunction(t, provider)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	result, err := provider.Execute(ctx, deployment, &InvocationRequest{Method: "GET", Path: "/hang"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if invResult := result.(*InvocationResult); invResult.StatusCode != 500 {
+		t.Errorf("Expected status code 500, got %d", invResult.StatusCode)
+	}
+
+	// A timed out process is stopped and cannot serve further requests
+	if _, err := provider.Execute(context.Background(), deployment, &InvocationRequest{Method: "GET", Path: "/"}); err == nil {
+		t.Errorf("Expected error executing against stopped process")
+	}
+}
+
+func TestProcessProvider_Remove(t *testing.T) {
+	provider := newEchoProcessProvider(t)
+	deployment := deployEchoFunction(t, provider)
+
+	if err := provider.Remove(context.Background(), deployment); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if err := provider.Remove(context.Background(), deployment); err == nil {
+		t.Errorf("Expected error removing deployment twice")
+	}
+}
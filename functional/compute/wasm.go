@@ -0,0 +1,333 @@
+package compute
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// WasmRuntime is the function runtime served by the wasm provider
+const WasmRuntime = "wasm"
+
+const (
+	// wasmPageSize is the size of a WebAssembly memory page
+	wasmPageSize = 64 * 1024
+	// wasmMaxPages is the most pages a 32-bit WebAssembly memory can hold
+	wasmMaxPages = 65536
+	// wasmDefaultTimeout applies when a function does not set a timeout
+	wasmDefaultTimeout = 30 * time.Second
+)
+
+// wasmMagic is the header every WebAssembly binary module starts with
+var wasmMagic = []byte{0x00, 0x61, 0x73, 0x6d}
+
+// WasmConfig configures the wasm compute provider
+type WasmConfig struct {
+	// MaxMemoryMB caps the memory of every module, regardless of the function's MemoryMB
+	MaxMemoryMB int32 `json:"max_memory_mb"`
+}
+
+// wasmModule is a compiled WASI module that is instantiated fresh for each
+// invocation
+type wasmModule interface {
+	// Run instantiates the module and runs it to completion
+	Run(ctx context.Context, stdin []byte, env map[string]string, stdout, stderr io.Writer) (exitCode uint32, err error)
+	Close(ctx context.Context) error
+}
+
+// wasmDeployment is a compiled module along with the limits it runs under
+type wasmDeployment struct {
+	module  wasmModule
+	timeout time.Duration
+	env     map[string]string
+}
+
+// WasmProvider runs WASI modules in-process. Each invocation gets a new module
+// instance, with memory capped by the function's MemoryMB and execution bounded
+// by its TimeoutSeconds. The request is written to the module's stdin in the
+// wrapper protocol format; stdout is either a wrapper response or the raw body.
+type WasmProvider struct {
+	config *WasmConfig
+
+	mu          sync.RWMutex
+	deployments map[string]*wasmDeployment
+}
+
+func NewWasmProvider(config interface{}) *WasmProvider {
+	wasmConfig, ok := config.(*WasmConfig)
+	if !ok {
+		logrus.Fatal("invalid wasm config type")
+	}
+
+	return &WasmProvider{
+		config:      wasmConfig,
+		deployments: make(map[string]*wasmDeployment),
+	}
+}
+
+func (w *WasmProvider) Name() string {
+	return "wasm"
+}
+
+func (w *WasmProvider) Deploy(ctx context.Context, fn interface{}, imageName string) (interface{}, error) {
+	function, ok := fn.(*Function)
+	if !ok {
+		return nil, fmt.Errorf("invalid function type")
+	}
+
+	code, err := loadWasmCode(function.CodePath, function.Handler)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load wasm module: %w", err)
+	}
+
+	memoryMB := function.MemoryMB
+	if w.config.MaxMemoryMB > 0 && (memoryMB == 0 || memoryMB > w.config.MaxMemoryMB) {
+		memoryMB = w.config.MaxMemoryMB
+	}
+
+	module, err := compileWasmModule(ctx, code, wasmMemoryPages(memoryMB))
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile wasm module: %w", err)
+	}
+
+	env := make(map[string]string)
+	if function.EnvVars != "" {
+		if err := json.Unmarshal([]byte(function.EnvVars), &env); err != nil {
+			module.Close(ctx)
+			return nil, fmt.Errorf("invalid function environment variables: %w", err)
+		}
+	}
+
+	timeout := time.Duration(function.TimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = wasmDefaultTimeout
+	}
+
+	deploymentID := uuid.New().String()
+	resourceID := uuid.New().String()
+
+	w.mu.Lock()
+	w.deployments[resourceID] = &wasmDeployment{
+		module:  module,
+		timeout: timeout,
+		env:     env,
+	}
+	w.mu.Unlock()
+
+	logrus.WithFields(logrus.Fields{
+		"function_id": function.ID,
+		"resource_id": resourceID,
+		"memory_mb":   memoryMB,
+		"timeout":     timeout,
+	}).Info("wasm function deployed successfully")
+
+	return &DeployResult{
+		DeploymentID: deploymentID,
+		ResourceID:   resourceID,
+		ImageTag:     "wasm",
+	}, nil
+}
+
+func (w *WasmProvider) Execute(ctx context.Context, deployment interface{}, req interface{}) (interface{}, error) {
+	dep, ok := deployment.(*Deployment)
+	if !ok {
+		return nil, fmt.Errorf("invalid deployment type")
+	}
+
+	invReq, ok := req.(*InvocationRequest)
+	if !ok {
+		return nil, fmt.Errorf("invalid invocation request type")
+	}
+
+	w.mu.RLock()
+	wasmDep, exists := w.deployments[dep.ResourceID]
+	w.mu.RUnlock()
+	if !exists {
+		return nil, fmt.Errorf("wasm module not found for deployment %s", dep.ID)
+	}
+
+	stdin, err := json.Marshal(&processRequest{
+		Method:    invReq.Method,
+		Path:      invReq.Path,
+		Headers:   invReq.Headers,
+		Query:     invReq.QueryArgs,
+		Body:      string(invReq.Body),
+		RequestID: uuid.New().String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, wasmDep.timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	start := time.Now()
+	exitCode, err := wasmDep.module.Run(ctx, stdin, wasmDep.env, &stdout, &stderr)
+	duration := time.Since(start)
+
+	if err == nil && exitCode != 0 {
+		err = fmt.Errorf("module exited with code %d", exitCode)
+	}
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			err = fmt.Errorf("function timed out after %s: %w", wasmDep.timeout, err)
+		}
+
+		return &InvocationResult{
+			StatusCode: 500,
+			Body:       []byte(fmt.Sprintf("Function execution failed: %v", err)),
+			DurationMS: duration.Milliseconds(),
+			Logs:       stderr.String(),
+			Error:      err.Error(),
+		}, nil
+	}
+
+	result := wasmOutputToInvocationResult(stdout.Bytes())
+	result.DurationMS = duration.Milliseconds()
+	result.Logs = stderr.String()
+	return result, nil
+}
+
+func (w *WasmProvider) Scale(ctx context.Context, deployment interface{}, replicas int) error {
+	// Modules are instantiated per invocation, so there is nothing to scale
+	return nil
+}
+
+func (w *WasmProvider) Remove(ctx context.Context, deployment interface{}) error {
+	dep, ok := deployment.(*Deployment)
+	if !ok {
+		return fmt.Errorf("invalid deployment type")
+	}
+
+	w.mu.Lock()
+	wasmDep, exists := w.deployments[dep.ResourceID]
+	delete(w.deployments, dep.ResourceID)
+	w.mu.Unlock()
+	if !exists {
+		return fmt.Errorf("wasm module not found for deployment %s", dep.ID)
+	}
+
+	return wasmDep.module.Close(ctx)
+}
+
+func (w *WasmProvider) Health(ctx context.Context) error {
+	// The runtime is in-process, it's available whenever the provider is
+	return nil
+}
+
+// wasmMemoryPages converts a memory limit in megabytes to WebAssembly pages.
+// Zero means no limit beyond the 4GiB addressable by a 32-bit memory.
+func wasmMemoryPages(memoryMB int32) uint32 {
+	if memoryMB <= 0 {
+		return wasmMaxPages
+	}
+
+	pages := int64(memoryMB) * 1024 * 1024 / wasmPageSize
+	if pages > wasmMaxPages {
+		return wasmMaxPages
+	}
+
+	return uint32(pages)
+}
+
+// wasmOutputToInvocationResult interprets module stdout. Modules that speak the
+// wrapper protocol write a response object, anything else is returned as the body.
+func wasmOutputToInvocationResult(stdout []byte) *InvocationResult {
+	var response processResponse
+	if err := json.Unmarshal(bytes.TrimSpace(stdout), &response); err == nil && response.StatusCode != 0 {
+		return &InvocationResult{
+			StatusCode:   response.StatusCode,
+			Body:         []byte(response.Body),
+			Headers:      response.Headers,
+			ResponseSize: int64(len(response.Body)),
+			Error:        response.Error,
+		}
+	}
+
+	return &InvocationResult{
+		StatusCode:   200,
+		Body:         stdout,
+		Headers:      map[string]string{},
+		ResponseSize: int64(len(stdout)),
+	}
+}
+
+// loadWasmCode reads the module binary from codePath, which may be the module
+// itself, a directory or a zip archive containing the handler module.
+func loadWasmCode(codePath, handler string) ([]byte, error) {
+	info, err := os.Stat(codePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if info.IsDir() {
+		return readWasmFile(filepath.Join(codePath, handler))
+	}
+
+	code, err := os.ReadFile(codePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if bytes.HasPrefix(code, wasmMagic) {
+		return code, nil
+	}
+
+	reader, err := zip.NewReader(bytes.NewReader(code), int64(len(code)))
+	if err != nil {
+		return nil, fmt.Errorf("%s is neither a wasm module nor a zip archive", codePath)
+	}
+
+	names := make([]string, 0, len(reader.File))
+	for _, file := range reader.File {
+		if filepath.Clean(file.Name) != filepath.Clean(handler) {
+			names = append(names, file.Name)
+			continue
+		}
+
+		src, err := file.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer src.Close()
+
+		code, err := io.ReadAll(src)
+		if err != nil {
+			return nil, err
+		}
+		return checkWasmMagic(handler, code)
+	}
+
+	sort.Strings(names)
+	return nil, fmt.Errorf("handler %s not found in code archive (found: %s)", handler, strings.Join(names, ", "))
+}
+
+func readWasmFile(path string) ([]byte, error) {
+	code, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return checkWasmMagic(path, code)
+}
+
+func checkWasmMagic(name string, code []byte) ([]byte, error) {
+	if !bytes.HasPrefix(code, wasmMagic) {
+		return nil, fmt.Errorf("%s is not a wasm module", name)
+	}
+
+	return code, nil
+}
@@ -0,0 +1,349 @@
+package compute
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var testWasmModule = append(append([]byte{}, wasmMagic...), 0x01, 0x00, 0x00, 0x00)
+
+func TestWasmProvider_Name(t *testing.T) {
+	provider := NewWasmProvider(&WasmConfig{})
+
+	if provider.Name() != "wasm" {
+		t.Errorf("Expected provider name to be 'wasm', got %s", provider.Name())
+	}
+}
+
+func TestWasmMemoryPages(t *testing.T) {
+	tests := []struct {
+		name     string
+		memoryMB int32
+		expected uint32
+	}{
+		{name: "unset", memoryMB: 0, expected: wasmMaxPages},
+		{name: "128MB", memoryMB: 128, expected: 2048},
+		{name: "above 4GiB", memoryMB: 8192, expected: wasmMaxPages},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if pages := wasmMemoryPages(tt.memoryMB); pages != tt.expected {
+				t.Errorf("wasmMemoryPages(%d) = %d, want %d", tt.memoryMB, pages, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLoadWasmCode(t *testing.T) {
+	dir := t.TempDir()
+
+	modulePath := filepath.Join(dir, "handler.wasm")
+	if err := os.WriteFile(modulePath, testWasmModule, 0644); err != nil {
+		t.Fatalf("Failed to write module: %v", err)
+	}
+
+	var archive bytes.Buffer
+	writer := zip.NewWriter(&archive)
+	entry, _ := writer.Create("handler.wasm")
+	entry.Write(testWasmModule)
+	writer.Close()
+
+	zipPath := filepath.Join(dir, "code.zip")
+	if err := os.WriteFile(zipPath, archive.Bytes(), 0644); err != nil {
+		t.Fatalf("Failed to write archive: %v", err)
+	}
+
+	notWasmPath := filepath.Join(dir, "index.js")
+	if err := os.WriteFile(notWasmPath, []byte("module.exports = {}"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		codePath    string
+		handler     string
+		expectError bool
+	}{
+		{name: "module file", codePath: modulePath, handler: "handler.wasm"},
+		{name: "directory", codePath: dir, handler: "handler.wasm"},
+		{name: "zip archive", codePath: zipPath, handler: "handler.wasm"},
+		{name: "handler missing from archive", codePath: zipPath, handler: "other.wasm", expectError: true},
+		{name: "not a module", codePath: dir, handler: "index.js", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, err := loadWasmCode(tt.codePath, tt.handler)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error, got none")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if !bytes.Equal(code, testWasmModule) {
+				t.Errorf("Expected module bytes %v, got %v", testWasmModule, code)
+			}
+		})
+	}
+}
+
+func TestWasmOutputToInvocationResult(t *testing.T) {
+	t.Run("wrapper response", func(t *testing.T) {
+		result := wasmOutputToInvocationResult([]byte(`{"status_code": 201, "body": "created", "headers": {"X-Test": "yes"}}` + "\n"))
+
+		if result.StatusCode != 201 {
+			t.Errorf("Expected status code 201, got %d", result.StatusCode)
+		}
+
+		if string(result.Body) != "created" {
+			t.Errorf("Expected body 'created', got %q", result.Body)
+		}
+
+		if result.Headers["X-Test"] != "yes" {
+			t.Errorf("Expected header X-Test to be passed through")
+		}
+	})
+
+	t.Run("raw output", func(t *testing.T) {
+		result := wasmOutputToInvocationResult([]byte("hello"))
+
+		if result.StatusCode != 200 {
+			t.Errorf("Expected status code 200, got %d", result.StatusCode)
+		}
+
+		if string(result.Body) != "hello" {
+			t.Errorf("Expected body 'hello', got %q", result.Body)
+		}
+	})
+}
+
+// wasiModule assembles a WASI command module whose _start runs body. The
+// module imports fd_read and fd_write as functions 0 and 1 and exports one
+// page of memory with no maximum, so only the runtime limits its growth.
+func wasiModule(body ...byte) []byte {
+	fdType := []byte{0x60, 4, 0x7f, 0x7f, 0x7f, 0x7f, 1, 0x7f}
+	startType := []byte{0x60, 0, 0}
+
+	module := append([]byte{}, wasmMagic...)
+	module = append(module, 0x01, 0x00, 0x00, 0x00)
+	module = append(module, wasmSection(1, wasmVector(fdType, startType))...)
+	module = append(module, wasmSection(2, wasmVector(
+		wasmImport("fd_read"),
+		wasmImport("fd_write"),
+	))...)
+	module = append(module, wasmSection(3, wasmVector([]byte{1}))...)
+	module = append(module, wasmSection(5, wasmVector([]byte{0x00, 1}))...)
+	module = append(module, wasmSection(7, wasmVector(
+		append(wasmName("memory"), 0x02, 0),
+		append(wasmName("_start"), 0x00, 2),
+	))...)
+
+	code := append([]byte{0}, body...)
+	code = append(code, 0x0b)
+	module = append(module, wasmSection(10, wasmVector(append(wasmUleb(uint32(len(code))), code...)))...)
+
+	return module
+}
+
+func wasmImport(name string) []byte {
+	entry := append(wasmName("wasi_snapshot_preview1"), wasmName(name)...)
+	return append(entry, 0x00, 0)
+}
+
+func wasmSection(id byte, contents []byte) []byte {
+	return append(append([]byte{id}, wasmUleb(uint32(len(contents)))...), contents...)
+}
+
+func wasmVector(items ...[]byte) []byte {
+	vector := wasmUleb(uint32(len(items)))
+	for _, item := range items {
+		vector = append(vector, item...)
+	}
+	return vector
+}
+
+func wasmName(name string) []byte {
+	return append(wasmUleb(uint32(len(name))), name...)
+}
+
+func wasmUleb(value uint32) []byte {
+	var encoded []byte
+	for {
+		b := byte(value & 0x7f)
+		value >>= 7
+		if value == 0 {
+			return append(encoded, b)
+		}
+		encoded = append(encoded, b|0x80)
+	}
+}
+
+// wasmI32Const encodes i32.const, whose operand is a signed LEB128
+func wasmI32Const(value int32) []byte {
+	encoded := []byte{0x41}
+	for {
+		b := byte(value & 0x7f)
+		value >>= 7
+		if (value == 0 && b&0x40 == 0) || (value == -1 && b&0x40 != 0) {
+			return append(encoded, b)
+		}
+		encoded = append(encoded, b|0x80)
+	}
+}
+
+func wasmInstructions(instructions ...[]byte) []byte {
+	var body []byte
+	for _, instruction := range instructions {
+		body = append(body, instruction...)
+	}
+	return body
+}
+
+var (
+	wasmI32Store = []byte{0x36, 0x02, 0x00}
+	wasmI32Load  = []byte{0x28, 0x02, 0x00}
+	wasmDrop     = []byte{0x1a}
+)
+
+// wasmEchoModule copies up to 4KiB of stdin to stdout
+var wasmEchoModule = wasiModule(wasmInstructions(
+	// iovec at 0 pointing at a 4KiB buffer at 64
+	wasmI32Const(0), wasmI32Const(64), wasmI32Store,
+	wasmI32Const(4), wasmI32Const(4096), wasmI32Store,
+	// fd_read(stdin, iovs=0, 1, nread=8)
+	wasmI32Const(0), wasmI32Const(0), wasmI32Const(1), wasmI32Const(8), []byte{0x10, 0}, wasmDrop,
+	// iovec at 16 pointing at the nread bytes read
+	wasmI32Const(16), wasmI32Const(64), wasmI32Store,
+	wasmI32Const(20), wasmI32Const(8), wasmI32Load, wasmI32Store,
+	// fd_write(stdout, iovs=16, 1, nwritten=24)
+	wasmI32Const(1), wasmI32Const(16), wasmI32Const(1), wasmI32Const(24), []byte{0x10, 1}, wasmDrop,
+)...)
+
+// wasmSpinModule loops forever
+var wasmSpinModule = wasiModule(0x03, 0x40, 0x0c, 0, 0x0b)
+
+// wasmGrowModule grows its memory to 2MiB, trapping if it can't
+var wasmGrowModule = wasiModule(wasmInstructions(
+	wasmI32Const(31), []byte{0x40, 0x00},
+	wasmI32Const(-1), []byte{0x46},
+	[]byte{0x04, 0x40, 0x00, 0x0b},
+)...)
+
+func deployTestWasmModule(t *testing.T, provider *WasmProvider, module []byte, timeoutSeconds, memoryMB int32) *Deployment {
+	t.Helper()
+
+	codePath := filepath.Join(t.TempDir(), "handler.wasm")
+	if err := os.WriteFile(codePath, module, 0644); err != nil {
+		t.Fatalf("Failed to write module: %v", err)
+	}
+
+	result, err := provider.Deploy(context.Background(), &Function{
+		ID:             "wasm-function",
+		CodePath:       codePath,
+		Runtime:        WasmRuntime,
+		Handler:        "handler.wasm",
+		TimeoutSeconds: timeoutSeconds,
+		MemoryMB:       memoryMB,
+	}, "")
+	if err != nil {
+		t.Fatalf("Failed to deploy module: %v", err)
+	}
+
+	deployment := &Deployment{
+		ID:         "wasm-deployment",
+		FunctionID: "wasm-function",
+		Provider:   "wasm",
+		ResourceID: result.(*DeployResult).ResourceID,
+	}
+	t.Cleanup(func() {
+		provider.Remove(context.Background(), deployment)
+	})
+
+	return deployment
+}
+
+func executeTestWasmModule(t *testing.T, provider *WasmProvider, deployment *Deployment, body string) *InvocationResult {
+	t.Helper()
+
+	result, err := provider.Execute(context.Background(), deployment, &InvocationRequest{
+		FunctionID: deployment.FunctionID,
+		Method:     "POST",
+		Path:       "/",
+		Body:       []byte(body),
+	})
+	if err != nil {
+		t.Fatalf("Failed to execute module: %v", err)
+	}
+
+	return result.(*InvocationResult)
+}
+
+func TestWasmProvider_Execute(t *testing.T) {
+	provider := NewWasmProvider(&WasmConfig{})
+
+	t.Run("request on stdin, response on stdout", func(t *testing.T) {
+		deployment := deployTestWasmModule(t, provider, wasmEchoModule, 5, 16)
+		result := executeTestWasmModule(t, provider, deployment, "hello wasm")
+
+		if result.StatusCode != 200 {
+			t.Fatalf("Expected status code 200, got %d (%s)", result.StatusCode, result.Error)
+		}
+
+		var request processRequest
+		if err := json.Unmarshal(result.Body, &request); err != nil {
+			t.Fatalf("Expected the echoed request, got %q: %v", result.Body, err)
+		}
+		if request.Method != "POST" || request.Body != "hello wasm" {
+			t.Errorf("Unexpected request on stdin: %+v", request)
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		deployment := deployTestWasmModule(t, provider, wasmSpinModule, 1, 16)
+		result := executeTestWasmModule(t, provider, deployment, "")
+
+		if result.StatusCode != 500 || !strings.Contains(result.Error, "timed out") {
+			t.Errorf("Expected a timeout, got %d (%s)", result.StatusCode, result.Error)
+		}
+	})
+
+	t.Run("within memory limit", func(t *testing.T) {
+		deployment := deployTestWasmModule(t, provider, wasmGrowModule, 5, 4)
+		result := executeTestWasmModule(t, provider, deployment, "")
+
+		if result.StatusCode != 200 {
+			t.Errorf("Expected status code 200, got %d (%s)", result.StatusCode, result.Error)
+		}
+	})
+
+	t.Run("above memory limit", func(t *testing.T) {
+		deployment := deployTestWasmModule(t, provider, wasmGrowModule, 5, 1)
+		result := executeTestWasmModule(t, provider, deployment, "")
+
+		if result.StatusCode != 500 || result.Error == "" {
+			t.Errorf("Expected the module to fail growing memory, got %d (%s)", result.StatusCode, result.Error)
+		}
+	})
+
+	t.Run("memory limit capped by config", func(t *testing.T) {
+		capped := NewWasmProvider(&WasmConfig{MaxMemoryMB: 1})
+		deployment := deployTestWasmModule(t, capped, wasmGrowModule, 5, 4)
+		result := executeTestWasmModule(t, capped, deployment, "")
+
+		if result.StatusCode != 500 {
+			t.Errorf("Expected MaxMemoryMB to cap the module, got %d", result.StatusCode)
+		}
+	})
+}
@@ -0,0 +1,81 @@
+package compute
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"github.com/tetratelabs/wazero/sys"
+)
+
+// wazeroModule is a module compiled by a runtime dedicated to one deployment,
+// so the runtime's memory limit applies to every instance of the module
+type wazeroModule struct {
+	runtime  wazero.Runtime
+	compiled wazero.CompiledModule
+}
+
+func compileWasmModule(ctx context.Context, code []byte, memoryPages uint32) (wasmModule, error) {
+	runtimeConfig := wazero.NewRuntimeConfig().
+		WithMemoryLimitPages(memoryPages).
+		WithCloseOnContextDone(true)
+
+	runtime := wazero.NewRuntimeWithConfig(ctx, runtimeConfig)
+	if _, err := wasi_snapshot_preview1.Instantiate(ctx, runtime); err != nil {
+		runtime.Close(ctx)
+		return nil, err
+	}
+
+	compiled, err := runtime.CompileModule(ctx, code)
+	if err != nil {
+		runtime.Close(ctx)
+		return nil, err
+	}
+
+	return &wazeroModule{
+		runtime:  runtime,
+		compiled: compiled,
+	}, nil
+}
+
+func (m *wazeroModule) Run(ctx context.Context, stdin []byte, env map[string]string, stdout, stderr io.Writer) (uint32, error) {
+	// An empty name lets concurrent invocations instantiate the same module
+	moduleConfig := wazero.NewModuleConfig().
+		WithName("").
+		WithArgs("function").
+		WithStdin(bytes.NewReader(stdin)).
+		WithStdout(stdout).
+		WithStderr(stderr).
+		WithSysWalltime().
+		WithSysNanotime()
+
+	for key, value := range env {
+		moduleConfig = moduleConfig.WithEnv(key, value)
+	}
+
+	module, err := m.runtime.InstantiateModule(ctx, m.compiled, moduleConfig)
+	if module != nil {
+		defer module.Close(ctx)
+	}
+
+	var exitErr *sys.ExitError
+	if errors.As(err, &exitErr) {
+		switch exitErr.ExitCode() {
+		case sys.ExitCodeDeadlineExceeded:
+			return exitErr.ExitCode(), context.DeadlineExceeded
+		case sys.ExitCodeContextCanceled:
+			return exitErr.ExitCode(), context.Canceled
+		default:
+			return exitErr.ExitCode(), nil
+		}
+	}
+
+	return 0, err
+}
+
+func (m *wazeroModule) Close(ctx context.Context) error {
+	return m.runtime.Close(ctx)
+}
@@ -299,6 +299,67 @@ func TestQueries_CreateDeployment(t *testing.T) {
 	}
 }
 
+func TestQueries_DeploymentEvents(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+
+	function, err := db.CreateFunction(ctx, CreateFunctionParams{
+		ID:             "events-test-function",
+		Name:           "events-test",
+		CodePath:       "/tmp/events-test",
+		Runtime:        "nodejs",
+		Handler:        "index.handler",
+		TimeoutSeconds: 30,
+		MemoryMb:       128,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create function: %v", err)
+	}
+
+	deployment, err := db.CreateDeployment(ctx, CreateDeploymentParams{
+		ID:         "events-test-deployment",
+		FunctionID: function.ID,
+		Provider:   "docker",
+		Status:     "pending",
+		Replicas:   1,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create deployment: %v", err)
+	}
+
+	transitions := []CreateDeploymentEventParams{
+		{DeploymentID: deployment.ID, ToStatus: "pending"},
+		{DeploymentID: deployment.ID, FromStatus: sql.NullString{String: "pending", Valid: true}, ToStatus: "building"},
+		{DeploymentID: deployment.ID, FromStatus: sql.NullString{String: "building", Valid: true}, ToStatus: "failed", Message: sql.NullString{String: "build failed", Valid: true}},
+	}
+	for _, params := range transitions {
+		if _, err := db.CreateDeploymentEvent(ctx, params); err != nil {
+			t.Fatalf("Failed to create deployment event: %v", err)
+		}
+	}
+
+	events, err := db.ListDeploymentEventsByFunction(ctx, function.ID)
+	if err != nil {
+		t.Fatalf("Failed to list deployment events: %v", err)
+	}
+
+	if len(events) != len(transitions) {
+		t.Fatalf("Expected %d events, got %d", len(transitions), len(events))
+	}
+
+	for i, event := range events {
+		if event.ToStatus != transitions[i].ToStatus {
+			t.Errorf("Expected event %d to status %s, got %s", i, transitions[i].ToStatus, event.ToStatus)
+		}
+	}
+
+	if events[0].FromStatus.Valid {
+		t.Errorf("Expected first event to have no from status, got %s", events[0].FromStatus.String)
+	}
+}
+
 func TestQueries_CreateInvocation(t *testing.T) {
 	db := setupTestDB(t)
 	defer db.Close()
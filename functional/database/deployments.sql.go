@@ -53,6 +53,40 @@ func (q *Queries) CreateDeployment(ctx context.Context, arg CreateDeploymentPara
 	return i, err
 }
 
+const createDeploymentEvent = `-- name: CreateDeploymentEvent :one
+INSERT INTO deployment_events (
+    deployment_id, from_status, to_status, message
+) VALUES (
+    ?, ?, ?, ?
+) RETURNING id, deployment_id, from_status, to_status, message, created_at
+`
+
+type CreateDeploymentEventParams struct {
+	DeploymentID string         `db:"deployment_id" json:"deployment_id"`
+	FromStatus   sql.NullString `db:"from_status" json:"from_status"`
+	ToStatus     string         `db:"to_status" json:"to_status"`
+	Message      sql.NullString `db:"message" json:"message"`
+}
+
+func (q *Queries) CreateDeploymentEvent(ctx context.Context, arg CreateDeploymentEventParams) (DeploymentEvent, error) {
+	row := q.db.QueryRowContext(ctx, createDeploymentEvent,
+		arg.DeploymentID,
+		arg.FromStatus,
+		arg.ToStatus,
+		arg.Message,
+	)
+	var i DeploymentEvent
+	err := row.Scan(
+		&i.ID,
+		&i.DeploymentID,
+		&i.FromStatus,
+		&i.ToStatus,
+		&i.Message,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
 const deleteDeployment = `-- name: DeleteDeployment :exec
 DELETE FROM deployments WHERE id = ?
 `
@@ -144,6 +178,123 @@ func (q *Queries) GetDeploymentsByFunction(ctx context.Context, functionID strin
 	return items, nil
 }
 
+const listDeploymentEventsByDeployment = `-- name: ListDeploymentEventsByDeployment :many
+SELECT id, deployment_id, from_status, to_status, message, created_at FROM deployment_events
+WHERE deployment_id = ?
+ORDER BY id ASC
+`
+
+func (q *Queries) ListDeploymentEventsByDeployment(ctx context.Context, deploymentID string) ([]DeploymentEvent, error) {
+	rows, err := q.db.QueryContext(ctx, listDeploymentEventsByDeployment, deploymentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []DeploymentEvent{}
+	for rows.Next() {
+		var i DeploymentEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.DeploymentID,
+			&i.FromStatus,
+			&i.ToStatus,
+			&i.Message,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDeploymentEventsByFunction = `-- name: ListDeploymentEventsByFunction :many
+SELECT deployment_events.id, deployment_events.deployment_id, deployment_events.from_status, deployment_events.to_status, deployment_events.message, deployment_events.created_at FROM deployment_events
+JOIN deployments ON deployments.id = deployment_events.deployment_id
+WHERE deployments.function_id = ?
+ORDER BY deployment_events.id ASC
+`
+
+func (q *Queries) ListDeploymentEventsByFunction(ctx context.Context, functionID string) ([]DeploymentEvent, error) {
+	rows, err := q.db.QueryContext(ctx, listDeploymentEventsByFunction, functionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []DeploymentEvent{}
+	for rows.Next() {
+		var i DeploymentEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.DeploymentID,
+			&i.FromStatus,
+			&i.ToStatus,
+			&i.Message,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDeploymentsByFunctionAndStatus = `-- name: ListDeploymentsByFunctionAndStatus :many
+SELECT id, function_id, provider, resource_id, status, replicas, image_tag, created_at, updated_at FROM deployments
+WHERE function_id = ? AND status = ?
+ORDER BY created_at DESC
+`
+
+type ListDeploymentsByFunctionAndStatusParams struct {
+	FunctionID string `db:"function_id" json:"function_id"`
+	Status     string `db:"status" json:"status"`
+}
+
+func (q *Queries) ListDeploymentsByFunctionAndStatus(ctx context.Context, arg ListDeploymentsByFunctionAndStatusParams) ([]Deployment, error) {
+	rows, err := q.db.QueryContext(ctx, listDeploymentsByFunctionAndStatus, arg.FunctionID, arg.Status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Deployment{}
+	for rows.Next() {
+		var i Deployment
+		if err := rows.Scan(
+			&i.ID,
+			&i.FunctionID,
+			&i.Provider,
+			&i.ResourceID,
+			&i.Status,
+			&i.Replicas,
+			&i.ImageTag,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const updateDeploymentReplicas = `-- name: UpdateDeploymentReplicas :one
 UPDATE deployments 
 SET 
@@ -175,6 +326,39 @@ func (q *Queries) UpdateDeploymentReplicas(ctx context.Context, arg UpdateDeploy
 	return i, err
 }
 
+const updateDeploymentResource = `-- name: UpdateDeploymentResource :one
+UPDATE deployments 
+SET 
+    resource_id = ?,
+    image_tag = ?,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+RETURNING id, function_id, provider, resource_id, status, replicas, image_tag, created_at, updated_at
+`
+
+type UpdateDeploymentResourceParams struct {
+	ResourceID string         `db:"resource_id" json:"resource_id"`
+	ImageTag   sql.NullString `db:"image_tag" json:"image_tag"`
+	ID         string         `db:"id" json:"id"`
+}
+
+func (q *Queries) UpdateDeploymentResource(ctx context.Context, arg UpdateDeploymentResourceParams) (Deployment, error) {
+	row := q.db.QueryRowContext(ctx, updateDeploymentResource, arg.ResourceID, arg.ImageTag, arg.ID)
+	var i Deployment
+	err := row.Scan(
+		&i.ID,
+		&i.FunctionID,
+		&i.Provider,
+		&i.ResourceID,
+		&i.Status,
+		&i.Replicas,
+		&i.ImageTag,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const updateDeploymentStatus = `-- name: UpdateDeploymentStatus :one
 UPDATE deployments 
 SET 
@@ -13,22 +13,29 @@ import (
 const createFunction = `-- name: CreateFunction :one
 INSERT INTO functions (
     id, name, description, code_path, runtime, handler, 
-    timeout_seconds, memory_mb, env_vars
+    timeout_seconds, memory_mb, env_vars, input_schema, output_schema, public,
+    retry_max_attempts, retry_backoff_ms, input_adapter
 ) VALUES (
-    ?, ?, ?, ?, ?, ?, ?, ?, ?
-) RETURNING id, name, description, code_path, runtime, handler, timeout_seconds, memory_mb, env_vars, created_at, updated_at
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+) RETURNING id, name, description, code_path, runtime, handler, timeout_seconds, memory_mb, env_vars, created_at, updated_at, input_schema, output_schema, public, retry_max_attempts, retry_backoff_ms, input_adapter
 `
 
 type CreateFunctionParams struct {
-	ID             string         `db:"id" json:"id"`
-	Name           string         `db:"name" json:"name"`
-	Description    sql.NullString `db:"description" json:"description"`
-	CodePath       string         `db:"code_path" json:"code_path"`
-	Runtime        string         `db:"runtime" json:"runtime"`
-	Handler        string         `db:"handler" json:"handler"`
-	TimeoutSeconds int64          `db:"timeout_seconds" json:"timeout_seconds"`
-	MemoryMb       int64          `db:"memory_mb" json:"memory_mb"`
-	EnvVars        sql.NullString `db:"env_vars" json:"env_vars"`
+	ID               string         `db:"id" json:"id"`
+	Name             string         `db:"name" json:"name"`
+	Description      sql.NullString `db:"description" json:"description"`
+	CodePath         string         `db:"code_path" json:"code_path"`
+	Runtime          string         `db:"runtime" json:"runtime"`
+	Handler          string         `db:"handler" json:"handler"`
+	TimeoutSeconds   int64          `db:"timeout_seconds" json:"timeout_seconds"`
+	MemoryMb         int64          `db:"memory_mb" json:"memory_mb"`
+	EnvVars          sql.NullString `db:"env_vars" json:"env_vars"`
+	InputSchema      sql.NullString `db:"input_schema" json:"input_schema"`
+	OutputSchema     sql.NullString `db:"output_schema" json:"output_schema"`
+	Public           bool           `db:"public" json:"public"`
+	RetryMaxAttempts int64          `db:"retry_max_attempts" json:"retry_max_attempts"`
+	RetryBackoffMs   int64          `db:"retry_backoff_ms" json:"retry_backoff_ms"`
+	InputAdapter     sql.NullString `db:"input_adapter" json:"input_adapter"`
 }
 
 func (q *Queries) CreateFunction(ctx context.Context, arg CreateFunctionParams) (Function, error) {
@@ -42,6 +49,12 @@ func (q *Queries) CreateFunction(ctx context.Context, arg CreateFunctionParams)
 		arg.TimeoutSeconds,
 		arg.MemoryMb,
 		arg.EnvVars,
+		arg.InputSchema,
+		arg.OutputSchema,
+		arg.Public,
+		arg.RetryMaxAttempts,
+		arg.RetryBackoffMs,
+		arg.InputAdapter,
 	)
 	var i Function
 	err := row.Scan(
@@ -56,6 +69,12 @@ func (q *Queries) CreateFunction(ctx context.Context, arg CreateFunctionParams)
 		&i.EnvVars,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.InputSchema,
+		&i.OutputSchema,
+		&i.Public,
+		&i.RetryMaxAttempts,
+		&i.RetryBackoffMs,
+		&i.InputAdapter,
 	)
 	return i, err
 }
@@ -70,7 +89,7 @@ func (q *Queries) DeleteFunction(ctx context.Context, id string) error {
 }
 
 const getFunction = `-- name: GetFunction :one
-SELECT id, name, description, code_path, runtime, handler, timeout_seconds, memory_mb, env_vars, created_at, updated_at FROM functions WHERE id = ?
+SELECT id, name, description, code_path, runtime, handler, timeout_seconds, memory_mb, env_vars, created_at, updated_at, input_schema, output_schema, public, retry_max_attempts, retry_backoff_ms, input_adapter FROM functions WHERE id = ?
 `
 
 func (q *Queries) GetFunction(ctx context.Context, id string) (Function, error) {
@@ -88,12 +107,18 @@ func (q *Queries) GetFunction(ctx context.Context, id string) (Function, error)
 		&i.EnvVars,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.InputSchema,
+		&i.OutputSchema,
+		&i.Public,
+		&i.RetryMaxAttempts,
+		&i.RetryBackoffMs,
+		&i.InputAdapter,
 	)
 	return i, err
 }
 
 const getFunctionByName = `-- name: GetFunctionByName :one
-SELECT id, name, description, code_path, runtime, handler, timeout_seconds, memory_mb, env_vars, created_at, updated_at FROM functions WHERE name = ?
+SELECT id, name, description, code_path, runtime, handler, timeout_seconds, memory_mb, env_vars, created_at, updated_at, input_schema, output_schema, public, retry_max_attempts, retry_backoff_ms, input_adapter FROM functions WHERE name = ?
 `
 
 func (q *Queries) GetFunctionByName(ctx context.Context, name string) (Function, error) {
@@ -111,12 +136,18 @@ func (q *Queries) GetFunctionByName(ctx context.Context, name string) (Function,
 		&i.EnvVars,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.InputSchema,
+		&i.OutputSchema,
+		&i.Public,
+		&i.RetryMaxAttempts,
+		&i.RetryBackoffMs,
+		&i.InputAdapter,
 	)
 	return i, err
 }
 
 const listFunctions = `-- name: ListFunctions :many
-SELECT id, name, description, code_path, runtime, handler, timeout_seconds, memory_mb, env_vars, created_at, updated_at FROM functions ORDER BY created_at DESC
+SELECT id, name, description, code_path, runtime, handler, timeout_seconds, memory_mb, env_vars, created_at, updated_at, input_schema, output_schema, public, retry_max_attempts, retry_backoff_ms, input_adapter FROM functions ORDER BY created_at DESC
 `
 
 func (q *Queries) ListFunctions(ctx context.Context) ([]Function, error) {
@@ -140,6 +171,57 @@ func (q *Queries) ListFunctions(ctx context.Context) ([]Function, error) {
 			&i.EnvVars,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.InputSchema,
+			&i.OutputSchema,
+			&i.Public,
+			&i.RetryMaxAttempts,
+			&i.RetryBackoffMs,
+			&i.InputAdapter,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPublicFunctions = `-- name: ListPublicFunctions :many
+SELECT id, name, description, code_path, runtime, handler, timeout_seconds, memory_mb, env_vars, created_at, updated_at, input_schema, output_schema, public, retry_max_attempts, retry_backoff_ms, input_adapter FROM functions WHERE public = 1 ORDER BY name ASC
+`
+
+func (q *Queries) ListPublicFunctions(ctx context.Context) ([]Function, error) {
+	rows, err := q.db.QueryContext(ctx, listPublicFunctions)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Function{}
+	for rows.Next() {
+		var i Function
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.CodePath,
+			&i.Runtime,
+			&i.Handler,
+			&i.TimeoutSeconds,
+			&i.MemoryMb,
+			&i.EnvVars,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.InputSchema,
+			&i.OutputSchema,
+			&i.Public,
+			&i.RetryMaxAttempts,
+			&i.RetryBackoffMs,
+			&i.InputAdapter,
 		); err != nil {
 			return nil, err
 		}
@@ -164,20 +246,32 @@ SET
     timeout_seconds = ?,
     memory_mb = ?,
     env_vars = ?,
+    input_schema = ?,
+    output_schema = ?,
+    public = ?,
+    retry_max_attempts = ?,
+    retry_backoff_ms = ?,
+    input_adapter = ?,
     updated_at = CURRENT_TIMESTAMP
 WHERE id = ?
-RETURNING id, name, description, code_path, runtime, handler, timeout_seconds, memory_mb, env_vars, created_at, updated_at
+RETURNING id, name, description, code_path, runtime, handler, timeout_seconds, memory_mb, env_vars, created_at, updated_at, input_schema, output_schema, public, retry_max_attempts, retry_backoff_ms, input_adapter
 `
 
 type UpdateFunctionParams struct {
-	Description    sql.NullString `db:"description" json:"description"`
-	CodePath       string         `db:"code_path" json:"code_path"`
-	Runtime        string         `db:"runtime" json:"runtime"`
-	Handler        string         `db:"handler" json:"handler"`
-	TimeoutSeconds int64          `db:"timeout_seconds" json:"timeout_seconds"`
-	MemoryMb       int64          `db:"memory_mb" json:"memory_mb"`
-	EnvVars        sql.NullString `db:"env_vars" json:"env_vars"`
-	ID             string         `db:"id" json:"id"`
+	Description      sql.NullString `db:"description" json:"description"`
+	CodePath         string         `db:"code_path" json:"code_path"`
+	Runtime          string         `db:"runtime" json:"runtime"`
+	Handler          string         `db:"handler" json:"handler"`
+	TimeoutSeconds   int64          `db:"timeout_seconds" json:"timeout_seconds"`
+	MemoryMb         int64          `db:"memory_mb" json:"memory_mb"`
+	EnvVars          sql.NullString `db:"env_vars" json:"env_vars"`
+	InputSchema      sql.NullString `db:"input_schema" json:"input_schema"`
+	OutputSchema     sql.NullString `db:"output_schema" json:"output_schema"`
+	Public           bool           `db:"public" json:"public"`
+	RetryMaxAttempts int64          `db:"retry_max_attempts" json:"retry_max_attempts"`
+	RetryBackoffMs   int64          `db:"retry_backoff_ms" json:"retry_backoff_ms"`
+	InputAdapter     sql.NullString `db:"input_adapter" json:"input_adapter"`
+	ID               string         `db:"id" json:"id"`
 }
 
 func (q *Queries) UpdateFunction(ctx context.Context, arg UpdateFunctionParams) (Function, error) {
@@ -189,6 +283,12 @@ func (q *Queries) UpdateFunction(ctx context.Context, arg UpdateFunctionParams)
 		arg.TimeoutSeconds,
 		arg.MemoryMb,
 		arg.EnvVars,
+		arg.InputSchema,
+		arg.OutputSchema,
+		arg.Public,
+		arg.RetryMaxAttempts,
+		arg.RetryBackoffMs,
+		arg.InputAdapter,
 		arg.ID,
 	)
 	var i Function
@@ -204,6 +304,12 @@ func (q *Queries) UpdateFunction(ctx context.Context, arg UpdateFunctionParams)
 		&i.EnvVars,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.InputSchema,
+		&i.OutputSchema,
+		&i.Public,
+		&i.RetryMaxAttempts,
+		&i.RetryBackoffMs,
+		&i.InputAdapter,
 	)
 	return i, err
 }
@@ -10,12 +10,24 @@ import (
 	"database/sql"
 )
 
+const countInFlightInvocationsByDeployment = `-- name: CountInFlightInvocationsByDeployment :one
+SELECT COUNT(*) FROM invocations
+WHERE deployment_id = ? AND status IN ('pending', 'running')
+`
+
+func (q *Queries) CountInFlightInvocationsByDeployment(ctx context.Context, deploymentID sql.NullString) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countInFlightInvocationsByDeployment, deploymentID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createInvocation = `-- name: CreateInvocation :one
 INSERT INTO invocations (
-    id, function_id, deployment_id, status
+    id, function_id, deployment_id, status, attempt
 ) VALUES (
-    ?, ?, ?, ?
-) RETURNING id, function_id, deployment_id, status, duration_ms, memory_used_mb, response_size_bytes, logs, error, created_at, completed_at
+    ?, ?, ?, ?, ?
+) RETURNING id, function_id, deployment_id, status, duration_ms, memory_used_mb, response_size_bytes, logs, error, created_at, completed_at, attempt
 `
 
 type CreateInvocationParams struct {
@@ -23,6 +35,7 @@ type CreateInvocationParams struct {
 	FunctionID   string         `db:"function_id" json:"function_id"`
 	DeploymentID sql.NullString `db:"deployment_id" json:"deployment_id"`
 	Status       string         `db:"status" json:"status"`
+	Attempt      int64          `db:"attempt" json:"attempt"`
 }
 
 func (q *Queries) CreateInvocation(ctx context.Context, arg CreateInvocationParams) (Invocation, error) {
@@ -31,6 +44,7 @@ func (q *Queries) CreateInvocation(ctx context.Context, arg CreateInvocationPara
 		arg.FunctionID,
 		arg.DeploymentID,
 		arg.Status,
+		arg.Attempt,
 	)
 	var i Invocation
 	err := row.Scan(
@@ -45,12 +59,13 @@ func (q *Queries) CreateInvocation(ctx context.Context, arg CreateInvocationPara
 		&i.Error,
 		&i.CreatedAt,
 		&i.CompletedAt,
+		&i.Attempt,
 	)
 	return i, err
 }
 
 const getInvocation = `-- name: GetInvocation :one
-SELECT id, function_id, deployment_id, status, duration_ms, memory_used_mb, response_size_bytes, logs, error, created_at, completed_at FROM invocations WHERE id = ?
+SELECT id, function_id, deployment_id, status, duration_ms, memory_used_mb, response_size_bytes, logs, error, created_at, completed_at, attempt FROM invocations WHERE id = ?
 `
 
 func (q *Queries) GetInvocation(ctx context.Context, id string) (Invocation, error) {
@@ -68,6 +83,7 @@ func (q *Queries) GetInvocation(ctx context.Context, id string) (Invocation, err
 		&i.Error,
 		&i.CreatedAt,
 		&i.CompletedAt,
+		&i.Attempt,
 	)
 	return i, err
 }
@@ -110,7 +126,7 @@ func (q *Queries) GetInvocationStats(ctx context.Context, arg GetInvocationStats
 }
 
 const listInvocations = `-- name: ListInvocations :many
-SELECT id, function_id, deployment_id, status, duration_ms, memory_used_mb, response_size_bytes, logs, error, created_at, completed_at FROM invocations ORDER BY created_at DESC LIMIT ? OFFSET ?
+SELECT id, function_id, deployment_id, status, duration_ms, memory_used_mb, response_size_bytes, logs, error, created_at, completed_at, attempt FROM invocations ORDER BY created_at DESC LIMIT ? OFFSET ?
 `
 
 type ListInvocationsParams struct {
@@ -139,6 +155,7 @@ func (q *Queries) ListInvocations(ctx context.Context, arg ListInvocationsParams
 			&i.Error,
 			&i.CreatedAt,
 			&i.CompletedAt,
+			&i.Attempt,
 		); err != nil {
 			return nil, err
 		}
@@ -154,7 +171,7 @@ func (q *Queries) ListInvocations(ctx context.Context, arg ListInvocationsParams
 }
 
 const listInvocationsByFunction = `-- name: ListInvocationsByFunction :many
-SELECT id, function_id, deployment_id, status, duration_ms, memory_used_mb, response_size_bytes, logs, error, created_at, completed_at FROM invocations 
+SELECT id, function_id, deployment_id, status, duration_ms, memory_used_mb, response_size_bytes, logs, error, created_at, completed_at, attempt FROM invocations 
 WHERE function_id = ? 
 ORDER BY created_at DESC 
 LIMIT ? OFFSET ?
@@ -187,6 +204,7 @@ func (q *Queries) ListInvocationsByFunction(ctx context.Context, arg ListInvocat
 			&i.Error,
 			&i.CreatedAt,
 			&i.CompletedAt,
+			&i.Attempt,
 		); err != nil {
 			return nil, err
 		}
@@ -212,7 +230,7 @@ SET
     error = ?,
     completed_at = CURRENT_TIMESTAMP
 WHERE id = ?
-RETURNING id, function_id, deployment_id, status, duration_ms, memory_used_mb, response_size_bytes, logs, error, created_at, completed_at
+RETURNING id, function_id, deployment_id, status, duration_ms, memory_used_mb, response_size_bytes, logs, error, created_at, completed_at, attempt
 `
 
 type UpdateInvocationCompleteParams struct {
@@ -248,6 +266,7 @@ func (q *Queries) UpdateInvocationComplete(ctx context.Context, arg UpdateInvoca
 		&i.Error,
 		&i.CreatedAt,
 		&i.CompletedAt,
+		&i.Attempt,
 	)
 	return i, err
 }
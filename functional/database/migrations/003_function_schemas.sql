@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE functions ADD COLUMN input_schema TEXT; -- JSON Schema
+ALTER TABLE functions ADD COLUMN output_schema TEXT; -- JSON Schema
+ALTER TABLE functions ADD COLUMN public BOOLEAN NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_functions_public ON functions(public);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_functions_public;
+ALTER TABLE functions DROP COLUMN public;
+ALTER TABLE functions DROP COLUMN output_schema;
+ALTER TABLE functions DROP COLUMN input_schema;
+-- +goose StatementEnd
@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE functions ADD COLUMN retry_max_attempts INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE functions ADD COLUMN retry_backoff_ms INTEGER NOT NULL DEFAULT 1000;
+
+ALTER TABLE invocations ADD COLUMN attempt INTEGER NOT NULL DEFAULT 1;
+
+CREATE TABLE dead_letters (
+    id TEXT PRIMARY KEY,
+    function_id TEXT NOT NULL,
+    invocation_id TEXT,
+    attempts INTEGER NOT NULL,
+    last_error TEXT,
+    request TEXT NOT NULL, -- JSON
+    status TEXT NOT NULL DEFAULT 'pending',
+    replayed_invocation_id TEXT,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    replayed_at DATETIME,
+    FOREIGN KEY (function_id) REFERENCES functions(id) ON DELETE CASCADE,
+    FOREIGN KEY (invocation_id) REFERENCES invocations(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_dead_letters_function_id ON dead_letters(function_id);
+CREATE INDEX idx_dead_letters_status ON dead_letters(status);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_dead_letters_status;
+DROP INDEX IF EXISTS idx_dead_letters_function_id;
+DROP TABLE IF EXISTS dead_letters;
+ALTER TABLE invocations DROP COLUMN attempt;
+ALTER TABLE functions DROP COLUMN retry_backoff_ms;
+ALTER TABLE functions DROP COLUMN retry_max_attempts;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE functions ADD COLUMN input_adapter TEXT; -- JSON
+
+CREATE TABLE webhook_deliveries (
+    function_id TEXT NOT NULL,
+    delivery_id TEXT NOT NULL,
+    received_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (function_id, delivery_id),
+    FOREIGN KEY (function_id) REFERENCES functions(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_webhook_deliveries_received_at ON webhook_deliveries(received_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_webhook_deliveries_received_at;
+DROP TABLE IF EXISTS webhook_deliveries;
+ALTER TABLE functions DROP COLUMN input_adapter;
+-- +goose StatementEnd
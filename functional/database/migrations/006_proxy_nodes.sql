@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE proxy_nodes (
+    id TEXT PRIMARY KEY,
+    address TEXT NOT NULL,
+    capacity INTEGER NOT NULL,
+    containers INTEGER NOT NULL DEFAULT 0,
+    in_flight INTEGER NOT NULL DEFAULT 0,
+    status TEXT NOT NULL DEFAULT 'active',
+    started_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    last_heartbeat DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE proxy_containers (
+    container_id TEXT PRIMARY KEY,
+    node_id TEXT NOT NULL,
+    function_id TEXT NOT NULL,
+    status TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (node_id) REFERENCES proxy_nodes(id) ON DELETE CASCADE,
+    FOREIGN KEY (function_id) REFERENCES functions(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_proxy_nodes_status ON proxy_nodes(status);
+CREATE INDEX idx_proxy_containers_node_id ON proxy_containers(node_id);
+CREATE INDEX idx_proxy_containers_function_id ON proxy_containers(function_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_proxy_containers_function_id;
+DROP INDEX IF EXISTS idx_proxy_containers_node_id;
+DROP INDEX IF EXISTS idx_proxy_nodes_status;
+DROP TABLE IF EXISTS proxy_containers;
+DROP TABLE IF EXISTS proxy_nodes;
+-- +goose StatementEnd
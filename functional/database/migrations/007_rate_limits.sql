@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE rate_limits (
+    id TEXT PRIMARY KEY,
+    function_id TEXT NOT NULL,
+    api_key_hash TEXT NOT NULL DEFAULT '', -- empty applies to all callers of the function combined
+    requests_per_second REAL NOT NULL DEFAULT 0, -- 0 means unlimited
+    burst INTEGER NOT NULL DEFAULT 0,
+    daily_quota INTEGER NOT NULL DEFAULT 0,
+    monthly_quota INTEGER NOT NULL DEFAULT 0,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (function_id, api_key_hash),
+    FOREIGN KEY (function_id) REFERENCES functions(id) ON DELETE CASCADE
+);
+
+CREATE TABLE invocation_usage (
+    function_id TEXT NOT NULL,
+    api_key_hash TEXT NOT NULL DEFAULT '',
+    period TEXT NOT NULL, -- day, month
+    period_start TEXT NOT NULL, -- 2006-01-02 for days, 2006-01 for months
+    count INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (function_id, api_key_hash, period, period_start),
+    FOREIGN KEY (function_id) REFERENCES functions(id) ON DELETE CASCADE
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS invocation_usage;
+DROP TABLE IF EXISTS rate_limits;
+-- +goose StatementEnd
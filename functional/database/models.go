@@ -8,6 +8,19 @@ import (
 	"database/sql"
 )
 
+type DeadLetter struct {
+	ID                   string         `db:"id" json:"id"`
+	FunctionID           string         `db:"function_id" json:"function_id"`
+	InvocationID         sql.NullString `db:"invocation_id" json:"invocation_id"`
+	Attempts             int64          `db:"attempts" json:"attempts"`
+	LastError            sql.NullString `db:"last_error" json:"last_error"`
+	Request              string         `db:"request" json:"request"`
+	Status               string         `db:"status" json:"status"`
+	ReplayedInvocationID sql.NullString `db:"replayed_invocation_id" json:"replayed_invocation_id"`
+	CreatedAt            sql.NullTime   `db:"created_at" json:"created_at"`
+	ReplayedAt           sql.NullTime   `db:"replayed_at" json:"replayed_at"`
+}
+
 type Deployment struct {
 	ID         string         `db:"id" json:"id"`
 	FunctionID string         `db:"function_id" json:"function_id"`
@@ -20,18 +33,33 @@ type Deployment struct {
 	UpdatedAt  sql.NullTime   `db:"updated_at" json:"updated_at"`
 }
 
+type DeploymentEvent struct {
+	ID           int64          `db:"id" json:"id"`
+	DeploymentID string         `db:"deployment_id" json:"deployment_id"`
+	FromStatus   sql.NullString `db:"from_status" json:"from_status"`
+	ToStatus     string         `db:"to_status" json:"to_status"`
+	Message      sql.NullString `db:"message" json:"message"`
+	CreatedAt    sql.NullTime   `db:"created_at" json:"created_at"`
+}
+
 type Function struct {
-	ID             string         `db:"id" json:"id"`
-	Name           string         `db:"name" json:"name"`
-	Description    sql.NullString `db:"description" json:"description"`
-	CodePath       string         `db:"code_path" json:"code_path"`
-	Runtime        string         `db:"runtime" json:"runtime"`
-	Handler        string         `db:"handler" json:"handler"`
-	TimeoutSeconds int64          `db:"timeout_seconds" json:"timeout_seconds"`
-	MemoryMb       int64          `db:"memory_mb" json:"memory_mb"`
-	EnvVars        sql.NullString `db:"env_vars" json:"env_vars"`
-	CreatedAt      sql.NullTime   `db:"created_at" json:"created_at"`
-	UpdatedAt      sql.NullTime   `db:"updated_at" json:"updated_at"`
+	ID               string         `db:"id" json:"id"`
+	Name             string         `db:"name" json:"name"`
+	Description      sql.NullString `db:"description" json:"description"`
+	CodePath         string         `db:"code_path" json:"code_path"`
+	Runtime          string         `db:"runtime" json:"runtime"`
+	Handler          string         `db:"handler" json:"handler"`
+	TimeoutSeconds   int64          `db:"timeout_seconds" json:"timeout_seconds"`
+	MemoryMb         int64          `db:"memory_mb" json:"memory_mb"`
+	EnvVars          sql.NullString `db:"env_vars" json:"env_vars"`
+	CreatedAt        sql.NullTime   `db:"created_at" json:"created_at"`
+	UpdatedAt        sql.NullTime   `db:"updated_at" json:"updated_at"`
+	InputSchema      sql.NullString `db:"input_schema" json:"input_schema"`
+	OutputSchema     sql.NullString `db:"output_schema" json:"output_schema"`
+	Public           bool           `db:"public" json:"public"`
+	RetryMaxAttempts int64          `db:"retry_max_attempts" json:"retry_max_attempts"`
+	RetryBackoffMs   int64          `db:"retry_backoff_ms" json:"retry_backoff_ms"`
+	InputAdapter     sql.NullString `db:"input_adapter" json:"input_adapter"`
 }
 
 type Invocation struct {
@@ -46,4 +74,51 @@ type Invocation struct {
 	Error             sql.NullString `db:"error" json:"error"`
 	CreatedAt         sql.NullTime   `db:"created_at" json:"created_at"`
 	CompletedAt       sql.NullTime   `db:"completed_at" json:"completed_at"`
+	Attempt           int64          `db:"attempt" json:"attempt"`
+}
+
+type InvocationUsage struct {
+	FunctionID  string `db:"function_id" json:"function_id"`
+	ApiKeyHash  string `db:"api_key_hash" json:"api_key_hash"`
+	Period      string `db:"period" json:"period"`
+	PeriodStart string `db:"period_start" json:"period_start"`
+	Count       int64  `db:"count" json:"count"`
+}
+
+type ProxyContainer struct {
+	ContainerID string       `db:"container_id" json:"container_id"`
+	NodeID      string       `db:"node_id" json:"node_id"`
+	FunctionID  string       `db:"function_id" json:"function_id"`
+	Status      string       `db:"status" json:"status"`
+	CreatedAt   sql.NullTime `db:"created_at" json:"created_at"`
+	UpdatedAt   sql.NullTime `db:"updated_at" json:"updated_at"`
+}
+
+type ProxyNode struct {
+	ID            string       `db:"id" json:"id"`
+	Address       string       `db:"address" json:"address"`
+	Capacity      int64        `db:"capacity" json:"capacity"`
+	Containers    int64        `db:"containers" json:"containers"`
+	InFlight      int64        `db:"in_flight" json:"in_flight"`
+	Status        string       `db:"status" json:"status"`
+	StartedAt     sql.NullTime `db:"started_at" json:"started_at"`
+	LastHeartbeat sql.NullTime `db:"last_heartbeat" json:"last_heartbeat"`
+}
+
+type RateLimit struct {
+	ID                string       `db:"id" json:"id"`
+	FunctionID        string       `db:"function_id" json:"function_id"`
+	ApiKeyHash        string       `db:"api_key_hash" json:"api_key_hash"`
+	RequestsPerSecond float64      `db:"requests_per_second" json:"requests_per_second"`
+	Burst             int64        `db:"burst" json:"burst"`
+	DailyQuota        int64        `db:"daily_quota" json:"daily_quota"`
+	MonthlyQuota      int64        `db:"monthly_quota" json:"monthly_quota"`
+	CreatedAt         sql.NullTime `db:"created_at" json:"created_at"`
+	UpdatedAt         sql.NullTime `db:"updated_at" json:"updated_at"`
+}
+
+type WebhookDelivery struct {
+	FunctionID string       `db:"function_id" json:"function_id"`
+	DeliveryID string       `db:"delivery_id" json:"delivery_id"`
+	ReceivedAt sql.NullTime `db:"received_at" json:"received_at"`
 }
@@ -0,0 +1,36 @@
+-- name: CreateDeadLetter :one
+INSERT INTO dead_letters (
+    id, function_id, invocation_id, attempts, last_error, request
+) VALUES (
+    ?, ?, ?, ?, ?, ?
+) RETURNING *;
+
+-- name: GetDeadLetter :one
+SELECT * FROM dead_letters WHERE id = ?;
+
+-- name: ListDeadLettersByFunction :many
+SELECT * FROM dead_letters
+WHERE function_id = ?
+ORDER BY created_at DESC
+LIMIT ? OFFSET ?;
+
+-- name: UpdateDeadLetterReplayed :one
+UPDATE dead_letters
+SET
+    status = 'replayed',
+    replayed_invocation_id = ?,
+    replayed_at = CURRENT_TIMESTAMP
+WHERE id = ?
+RETURNING *;
+
+-- name: UpdateDeadLetterReplayFailed :one
+UPDATE dead_letters
+SET
+    attempts = attempts + 1,
+    invocation_id = ?,
+    last_error = ?
+WHERE id = ?
+RETURNING *;
+
+-- name: DeleteDeadLetter :exec
+DELETE FROM dead_letters WHERE id = ?;
@@ -25,6 +25,20 @@ SET
 WHERE id = ?
 RETURNING *;
 
+-- name: ListDeploymentsByFunctionAndStatus :many
+SELECT * FROM deployments
+WHERE function_id = ? AND status = ?
+ORDER BY created_at DESC;
+
+-- name: UpdateDeploymentResource :one
+UPDATE deployments 
+SET 
+    resource_id = ?,
+    image_tag = ?,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+RETURNING *;
+
 -- name: UpdateDeploymentReplicas :one
 UPDATE deployments 
 SET 
@@ -34,4 +48,22 @@ WHERE id = ?
 RETURNING *;
 
 -- name: DeleteDeployment :exec
-DELETE FROM deployments WHERE id = ?;
\ No newline at end of file
+DELETE FROM deployments WHERE id = ?;
+
+-- name: CreateDeploymentEvent :one
+INSERT INTO deployment_events (
+    deployment_id, from_status, to_status, message
+) VALUES (
+    ?, ?, ?, ?
+) RETURNING *;
+
+-- name: ListDeploymentEventsByDeployment :many
+SELECT * FROM deployment_events
+WHERE deployment_id = ?
+ORDER BY id ASC;
+
+-- name: ListDeploymentEventsByFunction :many
+SELECT deployment_events.* FROM deployment_events
+JOIN deployments ON deployments.id = deployment_events.deployment_id
+WHERE deployments.function_id = ?
+ORDER BY deployment_events.id ASC;
\ No newline at end of file
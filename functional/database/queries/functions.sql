@@ -1,9 +1,10 @@
 -- name: CreateFunction :one
 INSERT INTO functions (
     id, name, description, code_path, runtime, handler, 
-    timeout_seconds, memory_mb, env_vars
+    timeout_seconds, memory_mb, env_vars, input_schema, output_schema, public,
+    retry_max_attempts, retry_backoff_ms, input_adapter
 ) VALUES (
-    ?, ?, ?, ?, ?, ?, ?, ?, ?
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
 ) RETURNING *;
 
 -- name: GetFunction :one
@@ -15,6 +16,9 @@ SELECT * FROM functions WHERE name = ?;
 -- name: ListFunctions :many
 SELECT * FROM functions ORDER BY created_at DESC;
 
+-- name: ListPublicFunctions :many
+SELECT * FROM functions WHERE public = 1 ORDER BY name ASC;
+
 -- name: UpdateFunction :one
 UPDATE functions 
 SET 
@@ -25,6 +29,12 @@ SET
     timeout_seconds = ?,
     memory_mb = ?,
     env_vars = ?,
+    input_schema = ?,
+    output_schema = ?,
+    public = ?,
+    retry_max_attempts = ?,
+    retry_backoff_ms = ?,
+    input_adapter = ?,
     updated_at = CURRENT_TIMESTAMP
 WHERE id = ?
 RETURNING *;
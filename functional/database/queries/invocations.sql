@@ -1,8 +1,8 @@
 -- name: CreateInvocation :one
 INSERT INTO invocations (
-    id, function_id, deployment_id, status
+    id, function_id, deployment_id, status, attempt
 ) VALUES (
-    ?, ?, ?, ?
+    ?, ?, ?, ?, ?
 ) RETURNING *;
 
 -- name: GetInvocation :one
@@ -17,6 +17,10 @@ WHERE function_id = ?
 ORDER BY created_at DESC 
 LIMIT ? OFFSET ?;
 
+-- name: CountInFlightInvocationsByDeployment :one
+SELECT COUNT(*) FROM invocations
+WHERE deployment_id = ? AND status IN ('pending', 'running');
+
 -- name: UpdateInvocationComplete :one
 UPDATE invocations 
 SET 
@@ -0,0 +1,64 @@
+-- name: UpsertProxyNode :one
+INSERT INTO proxy_nodes (id, address, capacity)
+VALUES (?, ?, ?)
+ON CONFLICT (id) DO UPDATE SET
+    address = excluded.address,
+    capacity = excluded.capacity,
+    containers = 0,
+    in_flight = 0,
+    status = 'active',
+    started_at = CURRENT_TIMESTAMP,
+    last_heartbeat = CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: HeartbeatProxyNode :execrows
+UPDATE proxy_nodes
+SET
+    containers = ?,
+    in_flight = ?,
+    status = 'active',
+    last_heartbeat = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: GetProxyNode :one
+SELECT * FROM proxy_nodes WHERE id = ?;
+
+-- name: ListActiveProxyNodes :many
+SELECT * FROM proxy_nodes WHERE status = 'active' ORDER BY id;
+
+-- name: ListWarmProxyNodesByFunction :many
+SELECT * FROM proxy_nodes
+WHERE status = 'active'
+  AND id IN (
+    SELECT node_id FROM proxy_containers
+    WHERE function_id = ? AND status = 'ready'
+  )
+ORDER BY in_flight ASC, id;
+
+-- name: MarkStaleProxyNodesDead :many
+UPDATE proxy_nodes
+SET status = 'dead'
+WHERE status = 'active' AND last_heartbeat < ?
+RETURNING *;
+
+-- name: ListDeadProxyNodes :many
+SELECT * FROM proxy_nodes WHERE status = 'dead' ORDER BY id;
+
+-- name: DeleteProxyNode :exec
+DELETE FROM proxy_nodes WHERE id = ?;
+
+-- name: UpsertProxyContainer :exec
+INSERT INTO proxy_containers (container_id, node_id, function_id, status)
+VALUES (?, ?, ?, ?)
+ON CONFLICT (container_id) DO UPDATE SET
+    status = excluded.status,
+    updated_at = CURRENT_TIMESTAMP;
+
+-- name: ListProxyContainersByNode :many
+SELECT * FROM proxy_containers WHERE node_id = ? ORDER BY created_at;
+
+-- name: DeleteProxyContainer :exec
+DELETE FROM proxy_containers WHERE container_id = ?;
+
+-- name: DeleteProxyContainersByNode :exec
+DELETE FROM proxy_containers WHERE node_id = ?;
@@ -0,0 +1,45 @@
+-- name: UpsertRateLimit :one
+INSERT INTO rate_limits (
+    id, function_id, api_key_hash, requests_per_second, burst, daily_quota, monthly_quota
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?
+)
+ON CONFLICT (function_id, api_key_hash) DO UPDATE SET
+    requests_per_second = excluded.requests_per_second,
+    burst = excluded.burst,
+    daily_quota = excluded.daily_quota,
+    monthly_quota = excluded.monthly_quota,
+    updated_at = CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: ListRateLimitsByFunction :many
+SELECT * FROM rate_limits
+WHERE function_id = ?
+ORDER BY api_key_hash;
+
+-- name: ListApplicableRateLimits :many
+SELECT * FROM rate_limits
+WHERE function_id = ? AND api_key_hash IN ('', ?)
+ORDER BY api_key_hash DESC;
+
+-- name: DeleteRateLimit :execrows
+DELETE FROM rate_limits WHERE id = ? AND function_id = ?;
+
+-- name: IncrementInvocationUsage :exec
+INSERT INTO invocation_usage (function_id, api_key_hash, period, period_start, count)
+VALUES (?, ?, ?, ?, 1)
+ON CONFLICT (function_id, api_key_hash, period, period_start) DO UPDATE SET
+    count = count + 1;
+
+-- name: GetInvocationUsage :one
+SELECT CAST(COALESCE(SUM(count), 0) AS INTEGER) FROM invocation_usage
+WHERE function_id = ? AND api_key_hash = ? AND period = ? AND period_start = ?;
+
+-- name: GetTotalInvocationUsage :one
+SELECT CAST(COALESCE(SUM(count), 0) AS INTEGER) FROM invocation_usage
+WHERE function_id = ? AND period = ? AND period_start = ?;
+
+-- name: ListInvocationUsageByFunction :many
+SELECT * FROM invocation_usage
+WHERE function_id = ? AND period = ? AND period_start = ?
+ORDER BY count DESC;
@@ -0,0 +1,10 @@
+-- name: RecordWebhookDelivery :execrows
+INSERT INTO webhook_deliveries (function_id, delivery_id)
+VALUES (?, ?)
+ON CONFLICT (function_id, delivery_id) DO NOTHING;
+
+-- name: DeleteWebhookDelivery :exec
+DELETE FROM webhook_deliveries WHERE function_id = ? AND delivery_id = ?;
+
+-- name: DeleteWebhookDeliveriesBefore :exec
+DELETE FROM webhook_deliveries WHERE received_at < ?;
@@ -0,0 +1,108 @@
+package proxy
+
+import (
+	"net/http"
+
+	"github.com/pirogoeth/apps/functional/calltoken"
+)
+
+// nodeCallHelper defines `functional.invoke(name, body, headers)` for Node.js
+// handlers. It resolves to the callee's status code, headers and body.
+const nodeCallHelper = `
+global.functional = {
+	invoke: async (name, body, headers = {}) => {
+		if (!process.env.FUNCTIONAL_INVOKE_TOKEN) {
+			throw new Error('function-to-function calls are not enabled');
+		}
+		const callHeaders = {
+			'Content-Type': 'application/json',
+			'Authorization': 'Bearer ' + process.env.FUNCTIONAL_INVOKE_TOKEN,
+			'X-Functional-Call-Depth': process.env.FUNCTIONAL_CALL_DEPTH || '0',
+		};
+		if (process.env.TRACEPARENT) {
+			callHeaders['traceparent'] = process.env.TRACEPARENT;
+		}
+		if (process.env.TRACESTATE) {
+			callHeaders['tracestate'] = process.env.TRACESTATE;
+		}
+		const response = await fetch(process.env.FUNCTIONAL_INVOKE_URL + '/' + encodeURIComponent(name), {
+			method: 'POST',
+			headers: Object.assign(callHeaders, headers),
+			body: typeof body === 'string' ? body : JSON.stringify(body === undefined ? null : body),
+		});
+		return {
+			statusCode: response.status,
+			headers: Object.fromEntries(response.headers.entries()),
+			body: await response.text(),
+		};
+	},
+};
+`
+
+// pythonCallHelper provides a `functional` module with
+// `invoke(name, body=None, headers=None)` for Python handlers. It returns the
+// callee's status code, headers and body.
+const pythonCallHelper = `
+import os
+import types
+import urllib.error
+import urllib.parse
+import urllib.request
+
+def _functional_invoke(name, body=None, headers=None):
+    token = os.environ.get("FUNCTIONAL_INVOKE_TOKEN")
+    if not token:
+        raise RuntimeError("function-to-function calls are not enabled")
+
+    call_headers = {
+        "Content-Type": "application/json",
+        "Authorization": "Bearer " + token,
+        "X-Functional-Call-Depth": os.environ.get("FUNCTIONAL_CALL_DEPTH", "0"),
+    }
+    if os.environ.get("TRACEPARENT"):
+        call_headers["traceparent"] = os.environ["TRACEPARENT"]
+    if os.environ.get("TRACESTATE"):
+        call_headers["tracestate"] = os.environ["TRACESTATE"]
+    call_headers.update(headers or {})
+
+    data = body if isinstance(body, (str, bytes)) else json.dumps(body)
+    if isinstance(data, str):
+        data = data.encode()
+
+    url = os.environ["FUNCTIONAL_INVOKE_URL"] + "/" + urllib.parse.quote(name, safe="")
+    request = urllib.request.Request(url, data=data, headers=call_headers, method="POST")
+    try:
+        with urllib.request.urlopen(request) as response:
+            return {"status_code": response.status, "headers": dict(response.headers), "body": response.read().decode()}
+    except urllib.error.HTTPError as error:
+        return {"status_code": error.code, "headers": dict(error.headers), "body": error.read().decode()}
+
+functional = types.ModuleType("functional")
+functional.invoke = _functional_invoke
+sys.modules["functional"] = functional
+`
+
+// callEnv removes the function-to-function call headers from the request and
+// returns them as environment variables for the handler, which is where the
+// runtime helpers pick them up
+func (ws *WrapperService) callEnv(request *FunctionWrapperRequest) []string {
+	var env []string
+	for name, value := range request.Headers {
+		switch http.CanonicalHeaderKey(name) {
+		case calltoken.HeaderToken:
+			env = append(env, calltoken.EnvToken+"="+value)
+			delete(request.Headers, name)
+		case calltoken.HeaderInvokeURL:
+			env = append(env, calltoken.EnvInvokeURL+"="+value)
+			delete(request.Headers, name)
+		case calltoken.HeaderCallDepth:
+			env = append(env, calltoken.EnvCallDepth+"="+value)
+			delete(request.Headers, name)
+		case "Traceparent":
+			env = append(env, calltoken.EnvTraceParent+"="+value)
+		case "Tracestate":
+			env = append(env, calltoken.EnvTraceState+"="+value)
+		}
+	}
+	return env
+}
@@ -0,0 +1,335 @@
+package proxy
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"hash/fnv"
+	"net"
+	"os"
+	"time"
+
+	"github.com/pirogoeth/apps/functional/database"
+	"github.com/pirogoeth/apps/functional/types"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultHeartbeatInterval = 10 * time.Second
+	// defaultNodeTimeoutHeartbeats is how many heartbeats a node may miss by default
+	defaultNodeTimeoutHeartbeats = 3
+
+	// ForwardedByHeader marks invocations forwarded by another proxy instance so
+	// they are handled where they land instead of being routed again
+	ForwardedByHeader = "X-Functional-Forwarded-By"
+)
+
+// Cluster lets proxy instances that share a database cooperate. Each instance
+// registers itself as a node with its capacity, records its containers so
+// other nodes can route to warm ones, and reclaims the containers of nodes that
+// stop heartbeating.
+type Cluster struct {
+	db   *database.DbWrapper
+	pool *ContainerPool
+
+	nodeID            string
+	address           string
+	capacity          int
+	heartbeatInterval time.Duration
+	nodeTimeout       time.Duration
+
+	// removeContainer removes a container left behind by a dead node
+	removeContainer func(ctx context.Context, containerID string) error
+}
+
+// NewCluster creates the cluster membership for this proxy instance
+func NewCluster(config *types.Config, db *database.DbWrapper, pool *ContainerPool) *Cluster {
+	clusterConfig := config.Proxy.Cluster
+
+	nodeID := clusterConfig.NodeID
+	if nodeID == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			logrus.WithError(err).Fatal("Failed to determine node id from hostname")
+		}
+		nodeID = hostname
+	}
+
+	address := clusterConfig.AdvertiseAddress
+	if address == "" {
+		address = advertiseAddress(nodeID, config.Proxy.ListenAddress)
+	}
+
+	heartbeatInterval := clusterConfig.HeartbeatInterval
+	if heartbeatInterval == 0 {
+		heartbeatInterval = defaultHeartbeatInterval
+	}
+
+	nodeTimeout := clusterConfig.NodeTimeout
+	if nodeTimeout == 0 {
+		nodeTimeout = defaultNodeTimeoutHeartbeats * heartbeatInterval
+	}
+
+	return &Cluster{
+		db:                db,
+		pool:              pool,
+		nodeID:            nodeID,
+		address:           address,
+		capacity:          clusterConfig.Capacity,
+		heartbeatInterval: heartbeatInterval,
+		nodeTimeout:       nodeTimeout,
+		removeContainer:   pool.RemoveContainerByID,
+	}
+}
+
+// advertiseAddress is where other nodes reach this one when no address is
+// configured: the node id, as a host name, on the port the proxy listens on
+func advertiseAddress(nodeID, listenAddress string) string {
+	_, port, err := net.SplitHostPort(listenAddress)
+	if err != nil {
+		logrus.WithError(err).Warnf("Failed to parse listen address %q, advertising port 8080", listenAddress)
+		port = "8080"
+	}
+
+	return "http://" + net.JoinHostPort(nodeID, port)
+}
+
+// NodeID returns the id this instance is registered under
+func (cl *Cluster) NodeID() string {
+	return cl.nodeID
+}
+
+// Join registers this instance as a node. Containers recorded under the same
+// node id by a previous run are reclaimed first, since this process can't
+// have attached to them.
+func (cl *Cluster) Join(ctx context.Context) error {
+	if _, err := cl.db.GetProxyNode(ctx, cl.nodeID); err == nil {
+		cl.reclaimNode(ctx, cl.nodeID)
+	}
+
+	node, err := cl.db.UpsertProxyNode(ctx, database.UpsertProxyNodeParams{
+		ID:       cl.nodeID,
+		Address:  cl.address,
+		Capacity: int64(cl.capacity),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to register proxy node: %w", err)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"node_id":  node.ID,
+		"address":  node.Address,
+		"capacity": node.Capacity,
+	}).Info("Joined proxy cluster")
+
+	return nil
+}
+
+// Leave removes this instance and its containers from the cluster
+func (cl *Cluster) Leave(ctx context.Context) error {
+	if err := cl.db.DeleteProxyNode(ctx, cl.nodeID); err != nil {
+		return fmt.Errorf("failed to deregister proxy node: %w", err)
+	}
+
+	return nil
+}
+
+// Run heartbeats and reclaims dead nodes until ctx is done. inFlight reports
+// the number of invocations this instance is currently handling.
+func (cl *Cluster) Run(ctx context.Context, inFlight func() int) {
+	ticker := time.NewTicker(cl.heartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			cl.heartbeat(ctx, inFlight())
+			cl.reapDeadNodes(ctx)
+		}
+	}
+}
+
+// heartbeat reports this node as alive. A node that was reaped after missing
+// its heartbeats, say during a long pause, registers itself again.
+func (cl *Cluster) heartbeat(ctx context.Context, inFlight int) {
+	updated, err := cl.db.HeartbeatProxyNode(ctx, database.HeartbeatProxyNodeParams{
+		ID:         cl.nodeID,
+		Containers: int64(cl.pool.ContainerCount()),
+		InFlight:   int64(inFlight),
+	})
+	if err != nil {
+		logrus.WithError(err).WithField("node_id", cl.nodeID).Warn("Failed to send proxy node heartbeat")
+		return
+	}
+	if updated > 0 {
+		return
+	}
+
+	logrus.WithField("node_id", cl.nodeID).Warn("Proxy node was removed from the cluster, registering again")
+	_, err = cl.db.UpsertProxyNode(ctx, database.UpsertProxyNodeParams{
+		ID:       cl.nodeID,
+		Address:  cl.address,
+		Capacity: int64(cl.capacity),
+	})
+	if err != nil {
+		logrus.WithError(err).WithField("node_id", cl.nodeID).Warn("Failed to register proxy node again")
+	}
+}
+
+// reapDeadNodes marks nodes that missed their heartbeats as dead and reclaims
+// the containers of every dead node
+func (cl *Cluster) reapDeadNodes(ctx context.Context) {
+	cutoff := time.Now().UTC().Add(-cl.nodeTimeout)
+	stale, err := cl.db.MarkStaleProxyNodesDead(ctx, sql.NullTime{Time: cutoff, Valid: true})
+	if err != nil {
+		logrus.WithError(err).Warn("Failed to mark stale proxy nodes as dead")
+		return
+	}
+
+	for _, node := range stale {
+		logrus.WithFields(logrus.Fields{
+			"node_id":        node.ID,
+			"last_heartbeat": node.LastHeartbeat.Time,
+		}).Warn("Proxy node stopped heartbeating")
+	}
+
+	dead, err := cl.db.ListDeadProxyNodes(ctx)
+	if err != nil {
+		logrus.WithError(err).Warn("Failed to list dead proxy nodes")
+		return
+	}
+
+	for _, node := range dead {
+		if node.ID == cl.nodeID {
+			continue
+		}
+
+		cl.reclaimNode(ctx, node.ID)
+		if err := cl.db.DeleteProxyNode(ctx, node.ID); err != nil {
+			logrus.WithError(err).WithField("node_id", node.ID).Warn("Failed to remove dead proxy node")
+		}
+	}
+}
+
+// reclaimNode removes the containers recorded for a node and forgets them
+func (cl *Cluster) reclaimNode(ctx context.Context, nodeID string) {
+	containers, err := cl.db.ListProxyContainersByNode(ctx, nodeID)
+	if err != nil {
+		logrus.WithError(err).WithField("node_id", nodeID).Warn("Failed to list containers of proxy node")
+		return
+	}
+
+	for _, container := range containers {
+		if err := cl.removeContainer(ctx, container.ContainerID); err != nil {
+			logrus.WithError(err).WithFields(logrus.Fields{
+				"node_id":      nodeID,
+				"container_id": container.ContainerID,
+			}).Warn("Failed to reclaim container")
+		}
+	}
+
+	if err := cl.db.DeleteProxyContainersByNode(ctx, nodeID); err != nil {
+		logrus.WithError(err).WithField("node_id", nodeID).Warn("Failed to forget containers of proxy node")
+		return
+	}
+
+	if len(containers) > 0 {
+		logrus.WithFields(logrus.Fields{
+			"node_id":    nodeID,
+			"containers": len(containers),
+		}).Info("Reclaimed containers of proxy node")
+	}
+}
+
+// Route picks the node that should handle an invocation of the function. Nodes
+// with a warm container for the function are preferred, this node first;
+// otherwise the function is consistently hashed to an owner among the nodes
+// with spare capacity.
+func (cl *Cluster) Route(ctx context.Context, functionID string) (database.ProxyNode, error) {
+	self := database.ProxyNode{ID: cl.nodeID, Address: cl.address}
+
+	warm, err := cl.db.ListWarmProxyNodesByFunction(ctx, functionID)
+	if err != nil {
+		return self, fmt.Errorf("failed to list warm proxy nodes: %w", err)
+	}
+
+	for _, node := range warm {
+		if node.ID == cl.nodeID {
+			return node, nil
+		}
+	}
+	if len(warm) > 0 {
+		return warm[0], nil
+	}
+
+	nodes, err := cl.db.ListActiveProxyNodes(ctx)
+	if err != nil {
+		return self, fmt.Errorf("failed to list proxy nodes: %w", err)
+	}
+
+	candidates := make([]database.ProxyNode, 0, len(nodes))
+	for _, node := range nodes {
+		if node.Capacity == 0 || node.Containers < node.Capacity {
+			candidates = append(candidates, node)
+		}
+	}
+
+	owner, ok := ownerNode(functionID, candidates)
+	if !ok {
+		return self, nil
+	}
+
+	return owner, nil
+}
+
+// IsLocal reports whether node is this instance
+func (cl *Cluster) IsLocal(node database.ProxyNode) bool {
+	return node.ID == cl.nodeID
+}
+
+// ContainerStateChanged records a container of this node in the shared pool state
+func (cl *Cluster) ContainerStateChanged(container *PooledContainer) {
+	err := cl.db.UpsertProxyContainer(context.Background(), database.UpsertProxyContainerParams{
+		ContainerID: container.ContainerID,
+		NodeID:      cl.nodeID,
+		FunctionID:  container.FunctionID,
+		Status:      container.Status.String(),
+	})
+	if err != nil {
+		logrus.WithError(err).WithField("container_id", container.ContainerID).Warn("Failed to record container state")
+	}
+}
+
+// ContainerRemoved drops a container of this node from the shared pool state
+func (cl *Cluster) ContainerRemoved(container *PooledContainer) {
+	if err := cl.db.DeleteProxyContainer(context.Background(), container.ContainerID); err != nil {
+		logrus.WithError(err).WithField("container_id", container.ContainerID).Warn("Failed to forget container")
+	}
+}
+
+// ownerNode picks the owner of a function with rendezvous hashing, so that
+// each function keeps its owner as long as that node is available and only
+// the functions of a departing node move elsewhere.
+func ownerNode(functionID string, nodes []database.ProxyNode) (database.ProxyNode, bool) {
+	var (
+		owner     database.ProxyNode
+		bestScore uint64
+		found     bool
+	)
+
+	for _, node := range nodes {
+		hash := fnv.New64a()
+		hash.Write([]byte(node.ID))
+		hash.Write([]byte{0})
+		hash.Write([]byte(functionID))
+
+		score := hash.Sum64()
+		if !found || score > bestScore {
+			owner, bestScore, found = node, score, true
+		}
+	}
+
+	return owner, found
+}
@@ -0,0 +1,251 @@
+package proxy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pirogoeth/apps/functional/database"
+)
+
+func setupTestCluster(t *testing.T, nodeIDs ...string) (*Cluster, *database.DbWrapper) {
+	proxyService, db := setupTestProxyService(t)
+	ctx := context.Background()
+
+	cluster := proxyService.cluster
+	cluster.nodeID = nodeIDs[0]
+	if err := cluster.Join(ctx); err != nil {
+		t.Fatalf("Failed to join cluster: %v", err)
+	}
+
+	for _, nodeID := range nodeIDs[1:] {
+		_, err := db.UpsertProxyNode(ctx, database.UpsertProxyNodeParams{
+			ID:       nodeID,
+			Address:  "http://" + nodeID + ":8080",
+			Capacity: 10,
+		})
+		if err != nil {
+			t.Fatalf("Failed to register node %s: %v", nodeID, err)
+		}
+	}
+
+	_, err := db.CreateFunction(ctx, database.CreateFunctionParams{
+		ID:               "cluster-function",
+		Name:             "cluster-function",
+		CodePath:         "/tmp/cluster-function",
+		Runtime:          "nodejs",
+		Handler:          "index.handler",
+		TimeoutSeconds:   30,
+		MemoryMb:         128,
+		RetryMaxAttempts: 1,
+		RetryBackoffMs:   1000,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create function: %v", err)
+	}
+
+	return cluster, db
+}
+
+func TestCluster_RouteHashesToOwner(t *testing.T) {
+	cluster, db := setupTestCluster(t, "node-a", "node-b", "node-c")
+	defer db.Close()
+	ctx := context.Background()
+
+	nodes, err := db.ListActiveProxyNodes(ctx)
+	if err != nil {
+		t.Fatalf("Failed to list nodes: %v", err)
+	}
+	expected, _ := ownerNode("cluster-function", nodes)
+
+	for i := 0; i < 3; i++ {
+		node, err := cluster.Route(ctx, "cluster-function")
+		if err != nil {
+			t.Fatalf("Route failed: %v", err)
+		}
+		if node.ID != expected.ID {
+			t.Errorf("Expected owner %s, got %s", expected.ID, node.ID)
+		}
+	}
+
+	// Removing a node that doesn't own the function keeps its owner
+	for _, node := range nodes {
+		if node.ID != expected.ID && node.ID != cluster.NodeID() {
+			if err := db.DeleteProxyNode(ctx, node.ID); err != nil {
+				t.Fatalf("Failed to delete node: %v", err)
+			}
+			break
+		}
+	}
+
+	node, err := cluster.Route(ctx, "cluster-function")
+	if err != nil {
+		t.Fatalf("Route failed: %v", err)
+	}
+	if node.ID != expected.ID {
+		t.Errorf("Expected owner %s to be kept, got %s", expected.ID, node.ID)
+	}
+}
+
+func TestCluster_RoutePrefersWarmNode(t *testing.T) {
+	cluster, db := setupTestCluster(t, "node-a", "node-b", "node-c")
+	defer db.Close()
+	ctx := context.Background()
+
+	nodes, err := db.ListActiveProxyNodes(ctx)
+	if err != nil {
+		t.Fatalf("Failed to list nodes: %v", err)
+	}
+	owner, _ := ownerNode("cluster-function", nodes)
+
+	warmNode := "node-b"
+	if owner.ID == warmNode {
+		warmNode = "node-c"
+	}
+
+	err = db.UpsertProxyContainer(ctx, database.UpsertProxyContainerParams{
+		ContainerID: "container-1",
+		NodeID:      warmNode,
+		FunctionID:  "cluster-function",
+		Status:      ContainerStatusReady.String(),
+	})
+	if err != nil {
+		t.Fatalf("Failed to record container: %v", err)
+	}
+
+	node, err := cluster.Route(ctx, "cluster-function")
+	if err != nil {
+		t.Fatalf("Route failed: %v", err)
+	}
+	if node.ID != warmNode {
+		t.Errorf("Expected warm node %s, got %s", warmNode, node.ID)
+	}
+
+	// A busy container doesn't make its node warm
+	err = db.UpsertProxyContainer(ctx, database.UpsertProxyContainerParams{
+		ContainerID: "container-1",
+		NodeID:      warmNode,
+		FunctionID:  "cluster-function",
+		Status:      ContainerStatusInUse.String(),
+	})
+	if err != nil {
+		t.Fatalf("Failed to record container: %v", err)
+	}
+
+	node, err = cluster.Route(ctx, "cluster-function")
+	if err != nil {
+		t.Fatalf("Route failed: %v", err)
+	}
+	if node.ID != owner.ID {
+		t.Errorf("Expected owner %s, got %s", owner.ID, node.ID)
+	}
+}
+
+func TestCluster_ReapDeadNodes(t *testing.T) {
+	cluster, db := setupTestCluster(t, "node-a", "node-b")
+	defer db.Close()
+	ctx := context.Background()
+
+	var removed []string
+	cluster.removeContainer = func(ctx context.Context, containerID string) error {
+		removed = append(removed, containerID)
+		return nil
+	}
+
+	err := db.UpsertProxyContainer(ctx, database.UpsertProxyContainerParams{
+		ContainerID: "container-b",
+		NodeID:      "node-b",
+		FunctionID:  "cluster-function",
+		Status:      ContainerStatusReady.String(),
+	})
+	if err != nil {
+		t.Fatalf("Failed to record container: %v", err)
+	}
+
+	if _, err := db.DB().ExecContext(ctx, "UPDATE proxy_nodes SET last_heartbeat = '2000-01-01 00:00:00' WHERE id = 'node-b'"); err != nil {
+		t.Fatalf("Failed to age node heartbeat: %v", err)
+	}
+
+	cluster.reapDeadNodes(ctx)
+
+	if len(removed) != 1 || removed[0] != "container-b" {
+		t.Errorf("Expected container-b to be reclaimed, got %v", removed)
+	}
+
+	if _, err := db.GetProxyNode(ctx, "node-b"); err == nil {
+		t.Errorf("Expected dead node to be removed")
+	}
+
+	if _, err := db.GetProxyNode(ctx, "node-a"); err != nil {
+		t.Errorf("Expected live node to remain: %v", err)
+	}
+
+	node, err := cluster.Route(ctx, "cluster-function")
+	if err != nil {
+		t.Fatalf("Route failed: %v", err)
+	}
+	if node.ID != "node-a" {
+		t.Errorf("Expected invocations to route to the remaining node, got %s", node.ID)
+	}
+}
+
+func TestCluster_ReapedNodeRejoinsOnHeartbeat(t *testing.T) {
+	cluster, db := setupTestCluster(t, "node-a")
+	defer db.Close()
+	ctx := context.Background()
+
+	// node-b reaps node-a while node-a is paused
+	other := *cluster
+	other.nodeID = "node-b"
+	other.removeContainer = func(ctx context.Context, containerID string) error {
+		return nil
+	}
+	if err := other.Join(ctx); err != nil {
+		t.Fatalf("Failed to join cluster: %v", err)
+	}
+
+	if _, err := db.DB().ExecContext(ctx, "UPDATE proxy_nodes SET last_heartbeat = '2000-01-01 00:00:00' WHERE id = 'node-a'"); err != nil {
+		t.Fatalf("Failed to age node heartbeat: %v", err)
+	}
+	other.reapDeadNodes(ctx)
+	if _, err := db.GetProxyNode(ctx, "node-a"); err == nil {
+		t.Fatalf("Expected node-a to be reaped")
+	}
+
+	cluster.heartbeat(ctx, 0)
+
+	node, err := db.GetProxyNode(ctx, "node-a")
+	if err != nil {
+		t.Fatalf("Expected node-a to register again: %v", err)
+	}
+	if node.Status != "active" {
+		t.Errorf("Expected node-a to be active, got %s", node.Status)
+	}
+
+	err = db.UpsertProxyContainer(ctx, database.UpsertProxyContainerParams{
+		ContainerID: "container-a",
+		NodeID:      "node-a",
+		FunctionID:  "cluster-function",
+		Status:      ContainerStatusReady.String(),
+	})
+	if err != nil {
+		t.Errorf("Expected node-a to record containers again: %v", err)
+	}
+}
+
+func TestAdvertiseAddress(t *testing.T) {
+	cases := []struct {
+		listenAddress string
+		expected      string
+	}{
+		{":8080", "http://node-a:8080"},
+		{"0.0.0.0:8080", "http://node-a:8080"},
+		{"10.0.0.5:9000", "http://node-a:9000"},
+		{"[::]:9000", "http://node-a:9000"},
+	}
+
+	for _, c := range cases {
+		if actual := advertiseAddress("node-a", c.listenAddress); actual != c.expected {
+			t.Errorf("advertiseAddress(%q): expected %s, got %s", c.listenAddress, c.expected, actual)
+		}
+	}
+}
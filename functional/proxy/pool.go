@@ -26,6 +26,17 @@ type ContainerPool struct {
 	// Container tracking
 	containers     map[string]*PooledContainer // containerID -> container
 	containerMutex sync.RWMutex
+
+	// capacity caps the containers across all pools, zero means unlimited
+	capacity int
+	observer PoolObserver
+}
+
+// PoolObserver is notified of container lifecycle changes so pool state can be
+// shared with other proxy instances
+type PoolObserver interface {
+	ContainerStateChanged(container *PooledContainer)
+	ContainerRemoved(container *PooledContainer)
 }
 
 // FunctionPool represents a pool of containers for a specific function
@@ -67,6 +78,23 @@ const (
 	ContainerStatusStopped
 )
 
+func (s ContainerStatus) String() string {
+	switch s {
+	case ContainerStatusStarting:
+		return "starting"
+	case ContainerStatusReady:
+		return "ready"
+	case ContainerStatusInUse:
+		return "in_use"
+	case ContainerStatusStopping:
+		return "stopping"
+	case ContainerStatusStopped:
+		return "stopped"
+	default:
+		return "unknown"
+	}
+}
+
 // NewContainerPool creates a new container pool
 func NewContainerPool(config *functypes.Config) *ContainerPool {
 	dockerClient, err := client.NewClientWithOpts(
@@ -82,9 +110,22 @@ func NewContainerPool(config *functypes.Config) *ContainerPool {
 		client:     dockerClient,
 		pools:      make(map[string]*FunctionPool),
 		containers: make(map[string]*PooledContainer),
+		capacity:   config.Proxy.Cluster.Capacity,
 	}
 }
 
+// SetObserver registers the observer notified of container lifecycle changes
+func (cp *ContainerPool) SetObserver(observer PoolObserver) {
+	cp.observer = observer
+}
+
+// ContainerCount returns the number of containers across all pools
+func (cp *ContainerPool) ContainerCount() int {
+	cp.containerMutex.RLock()
+	defer cp.containerMutex.RUnlock()
+	return len(cp.containers)
+}
+
 // GetContainer gets or creates a container for the function
 func (cp *ContainerPool) GetContainer(ctx context.Context, function *database.Function) (*PooledContainer, error) {
 	pool := cp.getOrCreatePool(function.ID)
@@ -101,6 +142,7 @@ func (cp *ContainerPool) GetContainer(ctx context.Context, function *database.Fu
 		container.Status = ContainerStatusInUse
 		container.LastUsed = time.Now()
 		container.UseCount++
+		cp.notifyStateChanged(container)
 
 		logrus.WithFields(logrus.Fields{
 			"function_id":  function.ID,
@@ -111,7 +153,11 @@ func (cp *ContainerPool) GetContainer(ctx context.Context, function *database.Fu
 		return container, nil
 	}
 
-	// Create new container if pool isn't at capacity
+	// Create new container if neither the pool nor the node are at capacity
+	if cp.capacity > 0 && cp.ContainerCount() >= cp.capacity {
+		return nil, fmt.Errorf("node at capacity of %d containers", cp.capacity)
+	}
+
 	if len(pool.InUse) < pool.MaxSize {
 		container, err := cp.createContainer(ctx, function)
 		if err != nil {
@@ -120,6 +166,7 @@ func (cp *ContainerPool) GetContainer(ctx context.Context, function *database.Fu
 
 		pool.InUse = append(pool.InUse, container)
 		pool.CreatedCount++
+		cp.notifyStateChanged(container)
 
 		logrus.WithFields(logrus.Fields{
 			"function_id":  function.ID,
@@ -152,6 +199,7 @@ func (cp *ContainerPool) ReturnContainer(container *PooledContainer) {
 	if container.Status == ContainerStatusInUse {
 		container.Status = ContainerStatusReady
 		pool.Available = append(pool.Available, container)
+		cp.notifyStateChanged(container)
 
 		logrus.WithFields(logrus.Fields{
 			"function_id":  container.FunctionID,
@@ -347,6 +395,31 @@ func (cp *ContainerPool) removeContainer(container *PooledContainer) {
 	cp.containerMutex.Unlock()
 
 	container.Status = ContainerStatusStopped
+
+	if cp.observer != nil {
+		cp.observer.ContainerRemoved(container)
+	}
+}
+
+// RemoveContainerByID stops and removes a container this pool does not track,
+// such as one left behind by a proxy instance that died
+func (cp *ContainerPool) RemoveContainerByID(ctx context.Context, containerID string) error {
+	timeoutSeconds := 5
+	if err := cp.client.ContainerStop(ctx, containerID, containerTypes.StopOptions{Timeout: &timeoutSeconds}); err != nil {
+		logrus.WithError(err).WithField("container_id", containerID).Warn("Failed to stop container")
+	}
+
+	if err := cp.client.ContainerRemove(ctx, containerID, containerTypes.RemoveOptions{Force: true}); err != nil {
+		return fmt.Errorf("failed to remove container %s: %w", containerID, err)
+	}
+
+	return nil
+}
+
+func (cp *ContainerPool) notifyStateChanged(container *PooledContainer) {
+	if cp.observer != nil {
+		cp.observer.ContainerStateChanged(container)
+	}
 }
 
 // GetPoolStats returns statistics about the container pools
@@ -1,8 +1,10 @@
 package proxy
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -13,6 +15,7 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/pirogoeth/apps/functional/database"
 	"github.com/pirogoeth/apps/functional/types"
+	"github.com/pirogoeth/apps/functional/validation"
 )
 
 // ProxyService handles function invocations via container pools
@@ -21,6 +24,8 @@ type ProxyService struct {
 	db            *database.DbWrapper
 	containerPool *ContainerPool
 	traefik       *TraefikClient
+	cluster       *Cluster
+	httpClient    *http.Client
 	
 	// In-flight request tracking
 	inFlightMutex sync.RWMutex
@@ -57,18 +62,28 @@ type FunctionResponse struct {
 func NewProxyService(config *types.Config, db *database.DbWrapper) *ProxyService {
 	containerPool := NewContainerPool(config)
 	traefik := NewTraefikClient(config.Proxy.TraefikAPIURL)
+	cluster := NewCluster(config, db, containerPool)
+	containerPool.SetObserver(cluster)
 	
 	return &ProxyService{
 		config:        config,
 		db:            db,
 		containerPool: containerPool,
 		traefik:       traefik,
+		cluster:       cluster,
+		httpClient:    &http.Client{},
 		inFlight:      make(map[string]*InFlightRequest),
 	}
 }
 
 // Start starts the proxy service
 func (ps *ProxyService) Start(ctx context.Context) error {
+	// Register with the other proxy instances before taking invocations
+	if err := ps.cluster.Join(ctx); err != nil {
+		return err
+	}
+	go ps.cluster.Run(ctx, ps.inFlightCount)
+	
 	// Start container pool cleanup routine
 	go ps.containerPool.StartCleanup(ctx)
 	
@@ -87,6 +102,9 @@ func (ps *ProxyService) Start(ctx context.Context) error {
 	// Metrics endpoint
 	router.GET("/metrics", ps.handleMetrics)
 	
+	// Cluster membership endpoint
+	router.GET("/nodes", ps.handleNodes)
+	
 	server := &http.Server{
 		Addr:    ps.config.Proxy.ListenAddress,
 		Handler: router,
@@ -98,6 +116,9 @@ func (ps *ProxyService) Start(ctx context.Context) error {
 		<-ctx.Done()
 		logrus.Info("Shutting down proxy service")
 		server.Shutdown(context.Background())
+		if err := ps.cluster.Leave(context.Background()); err != nil {
+			logrus.WithError(err).Warn("Failed to leave proxy cluster")
+		}
 	}()
 	
 	return server.ListenAndServe()
@@ -123,6 +144,22 @@ func (ps *ProxyService) handleInvocation(c *gin.Context) {
 		return
 	}
 	
+	// Hand the invocation to the node that owns the function, unless another
+	// node already routed it here
+	if c.GetHeader(ForwardedByHeader) == "" {
+		node, err := ps.cluster.Route(ctx, functionID)
+		if err != nil {
+			logrus.WithError(err).WithField("function_id", functionID).Warn("Failed to route invocation, handling locally")
+		} else if !ps.cluster.IsLocal(node) && ps.forwardInvocation(c, node) {
+			return
+		}
+	}
+	
+	// Validate the body before a container is woken up for it
+	if !ps.validateRequestBody(c, &function) {
+		return
+	}
+	
 	// Create in-flight request tracking
 	requestID := ps.generateRequestID()
 	inFlightReq := &InFlightRequest{
@@ -161,10 +198,62 @@ func (ps *ProxyService) handleInvocation(c *gin.Context) {
 		return
 	}
 	
+	// Hold the function to its output contract
+	if function.OutputSchema.Valid && response.StatusCode < 400 {
+		err := validation.Validate(function.OutputSchema.String, []byte(response.Body))
+		var validationErr *validation.Error
+		if errors.As(err, &validationErr) {
+			logrus.WithError(err).WithField("function_id", functionID).Warn("Function response does not match output schema")
+			c.JSON(http.StatusBadGateway, gin.H{
+				"error":  "Function response does not match output schema",
+				"errors": validationErr.Errors,
+			})
+			return
+		}
+		if err != nil {
+			logrus.WithError(err).WithField("function_id", functionID).Error("Failed to validate function response")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate function response"})
+			return
+		}
+	}
+	
 	// Return response
 	ps.returnResponse(c, response)
 }
 
+// validateRequestBody checks the request body against the function's input
+// schema, responding with 400 and the validation errors if it does not match.
+// The body is restored so it can be read again.
+func (ps *ProxyService) validateRequestBody(c *gin.Context, function *database.Function) bool {
+	if !function.InputSchema.Valid {
+		return true
+	}
+	
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return false
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	
+	err = validation.Validate(function.InputSchema.String, body)
+	var validationErr *validation.Error
+	if errors.As(err, &validationErr) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "Request body does not match input schema",
+			"errors": validationErr.Errors,
+		})
+		return false
+	}
+	if err != nil {
+		logrus.WithError(err).WithField("function_id", function.ID).Error("Failed to validate request body")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate request body"})
+		return false
+	}
+	
+	return true
+}
+
 // serializeRequest converts HTTP request to FunctionRequest
 func (ps *ProxyService) serializeRequest(c *gin.Context, requestID, path string) (*FunctionRequest, error) {
 	// Read body
@@ -233,6 +322,45 @@ func (ps *ProxyService) returnResponse(c *gin.Context, response *FunctionRespons
 	c.Data(response.StatusCode, c.GetHeader("Content-Type"), []byte(response.Body))
 }
 
+// forwardInvocation sends the invocation to another proxy node and relays its
+// response. If the node can't be reached false is returned, with the request
+// body restored so the invocation can be handled locally instead.
+func (ps *ProxyService) forwardInvocation(c *gin.Context, node database.ProxyNode) bool {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return true
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	
+	req, err := http.NewRequestWithContext(c.Request.Context(), c.Request.Method, node.Address+c.Request.URL.RequestURI(), bytes.NewReader(body))
+	if err != nil {
+		logrus.WithError(err).WithField("node_id", node.ID).Warn("Failed to build forwarded request")
+		return false
+	}
+	req.Header = c.Request.Header.Clone()
+	req.Header.Set(ForwardedByHeader, ps.cluster.NodeID())
+	
+	resp, err := ps.httpClient.Do(req)
+	if err != nil {
+		logrus.WithError(err).WithField("node_id", node.ID).Warn("Failed to forward invocation, handling locally")
+		return false
+	}
+	defer resp.Body.Close()
+	
+	for key, values := range resp.Header {
+		for _, value := range values {
+			c.Writer.Header().Add(key, value)
+		}
+	}
+	c.Status(resp.StatusCode)
+	if _, err := io.Copy(c.Writer, resp.Body); err != nil {
+		logrus.WithError(err).WithField("node_id", node.ID).Warn("Failed to relay forwarded response")
+	}
+	
+	return true
+}
+
 // RegisterFunction registers a function with Traefik
 func (ps *ProxyService) RegisterFunction(ctx context.Context, functionID string) error {
 	// Create Traefik route for this function
@@ -267,16 +395,38 @@ func (ps *ProxyService) removeInFlightRequest(requestID string) {
 	delete(ps.inFlight, requestID)
 }
 
+func (ps *ProxyService) inFlightCount() int {
+	ps.inFlightMutex.RLock()
+	defer ps.inFlightMutex.RUnlock()
+	return len(ps.inFlight)
+}
+
 // handleMetrics returns proxy metrics
 func (ps *ProxyService) handleMetrics(c *gin.Context) {
 	ps.inFlightMutex.RLock()
 	defer ps.inFlightMutex.RUnlock()
 	
 	metrics := gin.H{
+		"node_id":            ps.cluster.NodeID(),
 		"in_flight_requests": len(ps.inFlight),
 		"container_pools":    ps.containerPool.GetPoolStats(),
 		"timestamp":         time.Now(),
 	}
 	
 	c.JSON(http.StatusOK, metrics)
+}
+
+// handleNodes lists the proxy instances in the cluster
+func (ps *ProxyService) handleNodes(c *gin.Context) {
+	nodes, err := ps.db.ListActiveProxyNodes(c.Request.Context())
+	if err != nil {
+		logrus.WithError(err).Error("Failed to list proxy nodes")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list proxy nodes"})
+		return
+	}
+	
+	c.JSON(http.StatusOK, gin.H{
+		"node_id": ps.cluster.NodeID(),
+		"nodes":   nodes,
+	})
 }
\ No newline at end of file
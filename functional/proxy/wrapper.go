@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -16,6 +17,8 @@ import (
 type WrapperService struct {
 	Runtime string
 	Handler string
+	// Dir is the directory holding the function code
+	Dir string
 }
 
 // FunctionWrapperRequest represents a request from the proxy
@@ -36,16 +39,18 @@ type FunctionWrapperResponse struct {
 	Error      string            `json:"error,omitempty"`
 }
 
-// StartWrapper starts the wrapper service for function containers
-func StartWrapper(runtime, handler string) error {
+// StartWrapper starts the wrapper service for function code in dir
+func StartWrapper(runtime, handler, dir string) error {
 	wrapper := &WrapperService{
 		Runtime: runtime,
 		Handler: handler,
+		Dir:     dir,
 	}
 	
 	logrus.WithFields(logrus.Fields{
 		"runtime": runtime,
 		"handler": handler,
+		"dir":     dir,
 	}).Info("Starting function wrapper")
 	
 	scanner := bufio.NewScanner(os.Stdin)
@@ -95,6 +100,10 @@ func (ws *WrapperService) executeFunction(request *FunctionWrapperRequest) *Func
 		Headers: make(map[string]string),
 	}
 	
+	// Function-to-function call details reach the handler through its
+	// environment rather than the request
+	callEnv := ws.callEnv(request)
+	
 	// Execute based on runtime
 	var cmd *exec.Cmd
 	var err error
@@ -117,6 +126,7 @@ func (ws *WrapperService) executeFunction(request *FunctionWrapperRequest) *Func
 		response.Error = fmt.Sprintf("Failed to setup execution: %v", err)
 		return response
 	}
+	cmd.Env = append(cmd.Environ(), callEnv...)
 	
 	// Execute command
 	output, err := cmd.Output()
@@ -148,18 +158,21 @@ func (ws *WrapperService) executeNodeJS(request *FunctionWrapperRequest) (*exec.
 	// Create a simple Node.js script that processes the request
 	script := fmt.Sprintf(`
 const request = %s;
-try {
-	const handler = require('./%s');
-	const result = handler(request);
-	console.log(JSON.stringify({message: 'Hello from Node.js', request: request, result: result}));
-} catch (error) {
-	console.error(JSON.stringify({error: error.message}));
-	process.exit(1);
-}
-`, ws.jsonString(request), ws.Handler)
+%s
+(async () => {
+	try {
+		const handler = require('./%s');
+		const result = await handler(request);
+		console.log(JSON.stringify({message: 'Hello from Node.js', request: request, result: result}));
+	} catch (error) {
+		console.error(JSON.stringify({error: error.message}));
+		process.exit(1);
+	}
+})();
+`, ws.jsonString(request), nodeCallHelper, ws.Handler)
 	
 	cmd := exec.Command("node", "-e", script)
-	cmd.Dir = "/app"
+	cmd.Dir = ws.Dir
 	return cmd, nil
 }
 
@@ -172,9 +185,10 @@ import sys
 import importlib.util
 
 request = %s
+%s
 try:
     # Load the handler module
-    spec = importlib.util.spec_from_file_location("handler", "/app/%s")
+    spec = importlib.util.spec_from_file_location("handler", %q)
     handler_module = importlib.util.module_from_spec(spec)
     spec.loader.exec_module(handler_module)
     
@@ -184,10 +198,10 @@ try:
 except Exception as error:
     print(json.dumps({"error": str(error)}), file=sys.stderr)
     sys.exit(1)
-`, ws.jsonString(request), ws.Handler)
+`, ws.jsonString(request), pythonCallHelper, filepath.Join(ws.Dir, ws.Handler))
 	
 	cmd := exec.Command("python3", "-c", script)
-	cmd.Dir = "/app"
+	cmd.Dir = ws.Dir
 	return cmd, nil
 }
 
@@ -198,7 +212,7 @@ func (ws *WrapperService) executeGo(request *FunctionWrapperRequest) (*exec.Cmd,
 	requestJSON := ws.jsonString(request)
 	
 	cmd := exec.Command("./main")
-	cmd.Dir = "/app"
+	cmd.Dir = ws.Dir
 	cmd.Env = append(os.Environ(), "FUNCTION_REQUEST="+requestJSON)
 	return cmd, nil
 }
@@ -213,6 +227,7 @@ func (ws *WrapperService) jsonString(request *FunctionWrapperRequest) string {
 func main() {
 	runtime := os.Getenv("FUNCTION_RUNTIME")
 	handler := os.Getenv("FUNCTION_HANDLER")
+	dir := os.Getenv("FUNCTION_DIR")
 	
 	if runtime == "" {
 		runtime = "nodejs"
@@ -220,8 +235,11 @@ func main() {
 	if handler == "" {
 		handler = "index.js"
 	}
+	if dir == "" {
+		dir = "/app"
+	}
 	
-	if err := StartWrapper(runtime, handler); err != nil {
+	if err := StartWrapper(runtime, handler, dir); err != nil {
 		logrus.WithError(err).Fatal("Wrapper failed")
 	}
 }
\ No newline at end of file
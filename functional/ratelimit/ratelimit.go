@@ -0,0 +1,160 @@
+package ratelimit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	PeriodDay   = "day"
+	PeriodMonth = "month"
+
+	// idleSweepInterval is how often buckets that have refilled completely are dropped
+	idleSweepInterval = time.Minute
+)
+
+// bucket is a token bucket that refills at rate tokens per second up to burst
+type bucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// refill adds the tokens accrued since the bucket was last used
+func (b *bucket) refill(now time.Time) {
+	elapsed := now.Sub(b.last).Seconds()
+	if elapsed > 0 {
+		b.tokens = math.Min(b.burst, b.tokens+elapsed*b.rate)
+		b.last = now
+	}
+}
+
+// Limiter holds the token buckets of every rate limit in memory. Buckets are
+// created on first use and reconfigured when the limit they belong to changes,
+// so limits can be edited in the database without invalidating anything.
+type Limiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+func NewLimiter() *Limiter {
+	return &Limiter{
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// Limit identifies a token bucket, which refills at Rate requests per second
+// and holds up to Burst tokens. A burst of zero allows one second worth of
+// requests and a rate of zero is unlimited.
+type Limit struct {
+	Key   string
+	Rate  float64
+	Burst int64
+}
+
+// Allow takes a token from the bucket identified by key. If no token is
+// available, Allow returns false and how long until one will be.
+func (l *Limiter) Allow(key string, rate float64, burst int64, now time.Time) (bool, time.Duration) {
+	return l.AllowAll([]Limit{{Key: key, Rate: rate, Burst: burst}}, now)
+}
+
+// AllowAll takes a token from every limit's bucket, or from none of them if
+// any is empty, so a request rejected by one limit doesn't use up the others.
+// If a token is missing, AllowAll returns false and how long until one will be.
+func (l *Limiter) AllowAll(limits []Limit, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	buckets := make([]*bucket, 0, len(limits))
+	for _, limit := range limits {
+		if limit.Rate <= 0 {
+			continue
+		}
+
+		b := l.bucketFor(limit, now)
+		if b.tokens < 1 {
+			return false, time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+		}
+		buckets = append(buckets, b)
+	}
+
+	for _, b := range buckets {
+		b.tokens--
+	}
+
+	return true, 0
+}
+
+// bucketFor returns the limit's bucket, refilled and configured as the limit is
+func (l *Limiter) bucketFor(limit Limit, now time.Time) *bucket {
+	capacity := float64(limit.Burst)
+	if capacity <= 0 {
+		capacity = math.Max(1, math.Ceil(limit.Rate))
+	}
+
+	b, ok := l.buckets[limit.Key]
+	if !ok {
+		b = &bucket{tokens: capacity, last: now}
+		l.buckets[limit.Key] = b
+	} else {
+		b.refill(now)
+	}
+
+	if b.rate != limit.Rate || b.burst != capacity {
+		b.rate = limit.Rate
+		b.burst = capacity
+		b.tokens = math.Min(b.tokens, capacity)
+	}
+
+	return b
+}
+
+// sweep drops buckets that are full again, since a new bucket is equivalent
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < idleSweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	for key, b := range l.buckets {
+		b.refill(now)
+		if b.tokens >= b.burst {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// PeriodStart returns the key of the quota period that contains now
+func PeriodStart(period string, now time.Time) string {
+	now = now.UTC()
+	if period == PeriodMonth {
+		return now.Format("2006-01")
+	}
+	return now.Format("2006-01-02")
+}
+
+// PeriodEnd returns when the quota period that contains now resets
+func PeriodEnd(period string, now time.Time) time.Time {
+	now = now.UTC()
+	if period == PeriodMonth {
+		return time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	return time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+}
+
+// HashKey returns the form an API key is stored in. Requests without a key are
+// stored under the empty string.
+func HashKey(apiKey string) string {
+	if apiKey == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:])
+}
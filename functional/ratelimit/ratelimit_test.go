@@ -0,0 +1,98 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLimiter_Allow(t *testing.T) {
+	limiter := NewLimiter()
+	now := time.Unix(1700000000, 0)
+
+	for i := 0; i < 3; i++ {
+		if ok, _ := limiter.Allow("fn", 1, 3, now); !ok {
+			t.Fatalf("Expected request %d within burst to be allowed", i+1)
+		}
+	}
+
+	ok, wait := limiter.Allow("fn", 1, 3, now)
+	if ok {
+		t.Fatalf("Expected request over burst to be rejected")
+	}
+	if wait != time.Second {
+		t.Errorf("Expected to wait 1s for the next token, got %s", wait)
+	}
+
+	if ok, _ := limiter.Allow("other", 1, 3, now); !ok {
+		t.Errorf("Expected buckets to be independent")
+	}
+
+	if ok, _ := limiter.Allow("fn", 1, 3, now.Add(time.Second)); !ok {
+		t.Errorf("Expected bucket to refill over time")
+	}
+
+	// Lowering the burst caps the tokens that are left
+	if ok, _ := limiter.Allow("other", 1, 1, now); !ok {
+		t.Fatalf("Expected the capped token to be allowed")
+	}
+	if ok, _ := limiter.Allow("other", 1, 1, now); ok {
+		t.Errorf("Expected reconfigured bucket to be capped at its new burst")
+	}
+}
+
+func TestLimiter_AllowUnlimited(t *testing.T) {
+	limiter := NewLimiter()
+	now := time.Unix(1700000000, 0)
+
+	for i := 0; i < 100; i++ {
+		if ok, _ := limiter.Allow("fn", 0, 0, now); !ok {
+			t.Fatalf("Expected a zero rate to be unlimited")
+		}
+	}
+}
+
+func TestLimiter_AllowAll(t *testing.T) {
+	limiter := NewLimiter()
+	now := time.Unix(1700000000, 0)
+
+	caller := Limit{Key: "fn/key", Rate: 1, Burst: 3}
+	function := Limit{Key: "fn/", Rate: 1, Burst: 1}
+
+	if ok, _ := limiter.AllowAll([]Limit{caller, function}, now); !ok {
+		t.Fatalf("Expected the first request to be allowed")
+	}
+
+	// The function-wide bucket is empty, so the caller's keeps its tokens
+	for i := 0; i < 3; i++ {
+		if ok, _ := limiter.AllowAll([]Limit{caller, function}, now); ok {
+			t.Fatalf("Expected request over the function-wide limit to be rejected")
+		}
+	}
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := limiter.Allow(caller.Key, caller.Rate, caller.Burst, now); !ok {
+			t.Fatalf("Expected the caller's remaining token %d to be allowed", i+1)
+		}
+	}
+	if ok, _ := limiter.Allow(caller.Key, caller.Rate, caller.Burst, now); ok {
+		t.Errorf("Expected the caller's bucket to be empty")
+	}
+}
+
+func TestPeriods(t *testing.T) {
+	now := time.Date(2024, time.December, 31, 18, 30, 0, 0, time.UTC)
+
+	if start := PeriodStart(PeriodDay, now); start != "2024-12-31" {
+		t.Errorf("Unexpected day period %q", start)
+	}
+	if start := PeriodStart(PeriodMonth, now); start != "2024-12" {
+		t.Errorf("Unexpected month period %q", start)
+	}
+
+	if end := PeriodEnd(PeriodDay, now); !end.Equal(time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected day period end %s", end)
+	}
+	if end := PeriodEnd(PeriodMonth, now); !end.Equal(time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected month period end %s", end)
+	}
+}
@@ -0,0 +1,70 @@
+// Package sdk lets Go functions invoke other functions through the internal
+// call path. The function wrapper provides the invoke URL, the invocation's
+// token, its call depth and trace context as environment variables.
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/pirogoeth/apps/functional/calltoken"
+)
+
+// ErrNotEnabled is returned when the function wasn't given an invoke token
+var ErrNotEnabled = errors.New("function-to-function calls are not enabled")
+
+// Response is the response of an invoked function
+type Response struct {
+	StatusCode int
+	Headers    http.Header
+	Body       []byte
+}
+
+// Invoke calls the named function with body and returns its response
+func Invoke(ctx context.Context, name string, body []byte) (*Response, error) {
+	token := os.Getenv(calltoken.EnvToken)
+	invokeURL := os.Getenv(calltoken.EnvInvokeURL)
+	if token == "" || invokeURL == "" {
+		return nil, ErrNotEnabled
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, invokeURL+"/"+url.PathEscape(name), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	if depth := os.Getenv(calltoken.EnvCallDepth); depth != "" {
+		req.Header.Set(calltoken.HeaderCallDepth, depth)
+	}
+	if traceParent := os.Getenv(calltoken.EnvTraceParent); traceParent != "" {
+		req.Header.Set("traceparent", traceParent)
+	}
+	if traceState := os.Getenv(calltoken.EnvTraceState); traceState != "" {
+		req.Header.Set("tracestate", traceState)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to invoke %s: %w", name, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response of %s: %w", name, err)
+	}
+
+	return &Response{
+		StatusCode: resp.StatusCode,
+		Headers:    resp.Header,
+		Body:       respBody,
+	}, nil
+}
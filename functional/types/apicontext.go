@@ -3,10 +3,13 @@ package types
 import (
 	"github.com/pirogoeth/apps/functional/database"
 	"github.com/pirogoeth/apps/functional/compute"
+	"github.com/pirogoeth/apps/functional/ratelimit"
 )
 
 type ApiContext struct {
 	Config    *Config
 	Querier   *database.Queries
 	Compute   *compute.Registry
+	// Limiter holds the token buckets of the functions' rate limits
+	Limiter   *ratelimit.Limiter
 }
\ No newline at end of file
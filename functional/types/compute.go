@@ -29,13 +29,42 @@ type Deployment struct {
 type DeploymentStatus string
 
 const (
-	DeploymentStatusPending   DeploymentStatus = "pending"
-	DeploymentStatusBuilding  DeploymentStatus = "building"
-	DeploymentStatusActive    DeploymentStatus = "active"
-	DeploymentStatusFailed    DeploymentStatus = "failed"
-	DeploymentStatusStopped   DeploymentStatus = "stopped"
+	DeploymentStatusPending  DeploymentStatus = "pending"
+	DeploymentStatusBuilding DeploymentStatus = "building"
+	DeploymentStatusActive   DeploymentStatus = "active"
+	DeploymentStatusFailed   DeploymentStatus = "failed"
+	DeploymentStatusDraining DeploymentStatus = "draining"
+	DeploymentStatusStopped  DeploymentStatus = "stopped"
 )
 
+// deploymentTransitions lists the statuses each deployment status may move to.
+// A superseded deployment goes active -> draining -> stopped so in-flight
+// invocations can finish before its resources are removed.
+var deploymentTransitions = map[DeploymentStatus][]DeploymentStatus{
+	DeploymentStatusPending:  {DeploymentStatusBuilding, DeploymentStatusFailed},
+	DeploymentStatusBuilding: {DeploymentStatusActive, DeploymentStatusFailed},
+	DeploymentStatusActive:   {DeploymentStatusDraining, DeploymentStatusStopped, DeploymentStatusFailed},
+	DeploymentStatusDraining: {DeploymentStatusStopped, DeploymentStatusFailed},
+	DeploymentStatusFailed:   {},
+	DeploymentStatusStopped:  {},
+}
+
+// CanTransitionTo reports whether a deployment in status s may move to next.
+func (s DeploymentStatus) CanTransitionTo(next DeploymentStatus) bool {
+	for _, allowed := range deploymentTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// IsTerminal reports whether no further transitions are possible from s.
+func (s DeploymentStatus) IsTerminal() bool {
+	allowed, ok := deploymentTransitions[s]
+	return ok && len(allowed) == 0
+}
+
 type DeployResult struct {
 	DeploymentID string `json:"deployment_id"`
 	ResourceID   string `json:"resource_id"`
@@ -49,6 +78,8 @@ type InvocationRequest struct {
 	Method     string            `json:"method"`
 	Path       string            `json:"path"`
 	QueryArgs  map[string]string `json:"query_args"`
+	// CallDepth counts the function-to-function calls that led to the request
+	CallDepth int `json:"call_depth,omitempty"`
 }
 
 type InvocationResult struct {
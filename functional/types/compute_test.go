@@ -0,0 +1,61 @@
+package types
+
+import "testing"
+
+func TestDeploymentStatus_CanTransitionTo(t *testing.T) {
+	statuses := []DeploymentStatus{
+		DeploymentStatusPending,
+		DeploymentStatusBuilding,
+		DeploymentStatusActive,
+		DeploymentStatusDraining,
+		DeploymentStatusFailed,
+		DeploymentStatusStopped,
+	}
+
+	allowed := map[DeploymentStatus][]DeploymentStatus{
+		DeploymentStatusPending:  {DeploymentStatusBuilding, DeploymentStatusFailed},
+		DeploymentStatusBuilding: {DeploymentStatusActive, DeploymentStatusFailed},
+		DeploymentStatusActive:   {DeploymentStatusDraining, DeploymentStatusStopped, DeploymentStatusFailed},
+		DeploymentStatusDraining: {DeploymentStatusStopped, DeploymentStatusFailed},
+	}
+
+	for _, from := range statuses {
+		for _, to := range statuses {
+			expected := false
+			for _, next := range allowed[from] {
+				if next == to {
+					expected = true
+				}
+			}
+
+			if actual := from.CanTransitionTo(to); actual != expected {
+				t.Errorf("%s -> %s: expected %t, got %t", from, to, expected, actual)
+			}
+		}
+	}
+
+	if DeploymentStatus("unknown").CanTransitionTo(DeploymentStatusActive) {
+		t.Errorf("Expected an unknown status to have no transitions")
+	}
+}
+
+func TestDeploymentStatus_IsTerminal(t *testing.T) {
+	tests := []struct {
+		status   DeploymentStatus
+		expected bool
+	}{
+		{DeploymentStatusPending, false},
+		{DeploymentStatusBuilding, false},
+		{DeploymentStatusActive, false},
+		{DeploymentStatusDraining, false},
+		{DeploymentStatusFailed, true},
+		{DeploymentStatusStopped, true},
+		{DeploymentStatus("unknown"), false},
+	}
+
+	for _, tt := range tests {
+		if actual := tt.status.IsTerminal(); actual != tt.expected {
+			t.Errorf("%s: expected IsTerminal to be %t, got %t", tt.status, tt.expected, actual)
+		}
+	}
+}
@@ -15,12 +15,16 @@ type Config struct {
 	Storage  StorageConfig    `json:"storage"`
 	Runtime  RuntimeConfig    `json:"runtime"`
 	Proxy    ProxyConfig      `json:"proxy"`
+	Bundles  BundlesConfig    `json:"bundles"`
+	Internal InternalConfig   `json:"internal"`
 }
 
 type ComputeConfig struct {
 	Provider    string             `json:"provider" envconfig:"COMPUTE_PROVIDER"`
 	Docker      *DockerConfig      `json:"docker,omitempty"`
 	Firecracker *FirecrackerConfig `json:"firecracker,omitempty"`
+	Process     *ProcessConfig     `json:"process,omitempty"`
+	Wasm        *WasmConfig        `json:"wasm,omitempty"`
 }
 
 type DockerConfig struct {
@@ -36,14 +40,44 @@ type FirecrackerConfig struct {
 	NetworkDevice   string `json:"network_device" envconfig:"FIRECRACKER_NETWORK_DEVICE"`
 }
 
+type ProcessConfig struct {
+	WorkDir        string   `json:"work_dir" envconfig:"PROCESS_WORK_DIR"`
+	WrapperCommand []string `json:"wrapper_command" envconfig:"PROCESS_WRAPPER_COMMAND"`
+}
+
+type WasmConfig struct {
+	MaxMemoryMB int32 `json:"max_memory_mb" envconfig:"WASM_MAX_MEMORY_MB"`
+}
+
 type StorageConfig struct {
 	FunctionsPath string `json:"functions_path" envconfig:"STORAGE_FUNCTIONS_PATH"`
 	TempPath      string `json:"temp_path" envconfig:"STORAGE_TEMP_PATH"`
 }
 
+// BundlesConfig configures function export and import. Servers that exchange
+// bundles must share the signing key.
+type BundlesConfig struct {
+	SigningKey string `json:"signing_key" envconfig:"BUNDLES_SIGNING_KEY"`
+}
+
+// InternalConfig configures the call path functions use to invoke each other
+// without going back out through the public gateway
+type InternalConfig struct {
+	// InvokeURL is where function containers reach the internal invoke endpoint,
+	// e.g. through the provider's host gateway. Functions can't invoke each
+	// other unless it is set.
+	InvokeURL string `json:"invoke_url" envconfig:"INTERNAL_INVOKE_URL"`
+	// SigningKey signs the per-invocation tokens. A random key is generated on
+	// startup if it is unset.
+	SigningKey   string `json:"signing_key" envconfig:"INTERNAL_SIGNING_KEY"`
+	MaxCallDepth int    `json:"max_call_depth" envconfig:"INTERNAL_MAX_CALL_DEPTH"`
+}
+
 type RuntimeConfig struct {
 	MaxConcurrentExecutions int                 `json:"max_concurrent_executions" envconfig:"RUNTIME_MAX_CONCURRENT_EXECUTIONS"`
 	DefaultTimeout          config.TimeDuration `json:"default_timeout" envconfig:"RUNTIME_DEFAULT_TIMEOUT"`
+	DrainTimeout            config.TimeDuration `json:"drain_timeout" envconfig:"RUNTIME_DRAIN_TIMEOUT"`
+	DeployTimeout           config.TimeDuration `json:"deploy_timeout" envconfig:"RUNTIME_DEPLOY_TIMEOUT"`
 	Scaling                 ScalingConfig       `json:"scaling"`
 }
 
@@ -60,4 +94,18 @@ type ProxyConfig struct {
 	TraefikAPIURL            string        `json:"traefik_api_url" envconfig:"PROXY_TRAEFIK_API_URL"`
 	MaxContainersPerFunction int           `json:"max_containers_per_function" envconfig:"PROXY_MAX_CONTAINERS_PER_FUNCTION"`
 	ContainerIdleTimeout     time.Duration `json:"container_idle_timeout" envconfig:"PROXY_CONTAINER_IDLE_TIMEOUT"`
+	Cluster                  ClusterConfig `json:"cluster"`
+}
+
+// ClusterConfig configures how proxy instances sharing a database cooperate
+type ClusterConfig struct {
+	// NodeID identifies this proxy instance, defaults to the hostname
+	NodeID string `json:"node_id" envconfig:"PROXY_CLUSTER_NODE_ID"`
+	// AdvertiseAddress is the base URL other proxy instances forward invocations to
+	AdvertiseAddress string `json:"advertise_address" envconfig:"PROXY_CLUSTER_ADVERTISE_ADDRESS"`
+	// Capacity is the most containers this instance runs across all functions
+	Capacity          int           `json:"capacity" envconfig:"PROXY_CLUSTER_CAPACITY"`
+	HeartbeatInterval time.Duration `json:"heartbeat_interval" envconfig:"PROXY_CLUSTER_HEARTBEAT_INTERVAL"`
+	// NodeTimeout is how long a node may miss heartbeats before its containers are reclaimed
+	NodeTimeout time.Duration `json:"node_timeout" envconfig:"PROXY_CLUSTER_NODE_TIMEOUT"`
 }
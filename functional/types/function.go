@@ -1,6 +1,7 @@
 package types
 
 import (
+	"encoding/json"
 	"time"
 )
 
@@ -27,6 +28,69 @@ type CreateFunctionRequest struct {
 	MemoryMB       int32             `json:"memory_mb"`
 	EnvVars        map[string]string `json:"env_vars"`
 	Code           string            `json:"code" binding:"required"` // Base64 encoded ZIP
+	// InputSchema and OutputSchema are optional JSON Schemas (or OpenAPI 3.1
+	// schema objects) for the invocation request and response bodies
+	InputSchema  json.RawMessage `json:"input_schema,omitempty"`
+	OutputSchema json.RawMessage `json:"output_schema,omitempty"`
+	// Public functions are listed in the gateway's OpenAPI document
+	Public bool `json:"public"`
+	// RetryPolicy applies to async invocations, which are attempted once if unset
+	RetryPolicy *RetryPolicy `json:"retry_policy,omitempty"`
+	// InputAdapter verifies and normalizes webhook deliveries before the function runs
+	InputAdapter json.RawMessage `json:"input_adapter,omitempty"`
+}
+
+const (
+	// DefaultRetryBackoff is the delay before the first retry when a policy doesn't set one
+	DefaultRetryBackoff = time.Second
+	// MaxRetryBackoff caps the delay between attempts
+	MaxRetryBackoff = 5 * time.Minute
+	// MaxRetryAttempts caps how many times an async invocation is attempted, so
+	// that a single request can't keep retrying for hours
+	MaxRetryAttempts = 10
+)
+
+// RetryPolicy controls how failed async invocations of a function are retried
+// before they are moved to the dead-letter list. The delay between attempts
+// starts at BackoffMS and doubles after every attempt.
+type RetryPolicy struct {
+	MaxAttempts int32 `json:"max_attempts"`
+	BackoffMS   int64 `json:"backoff_ms"`
+}
+
+// Backoff returns how long to wait after the given failed attempt, counting from 1
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	backoff := time.Duration(p.BackoffMS) * time.Millisecond
+	if backoff <= 0 {
+		backoff = DefaultRetryBackoff
+	}
+
+	for i := 1; i < attempt; i++ {
+		backoff *= 2
+		if backoff >= MaxRetryBackoff {
+			return MaxRetryBackoff
+		}
+	}
+
+	return backoff
+}
+
+type DeadLetterStatus string
+
+const (
+	DeadLetterStatusPending  DeadLetterStatus = "pending"
+	DeadLetterStatusReplayed DeadLetterStatus = "replayed"
+)
+
+// SetRateLimitRequest sets a function's rate limit and quotas, either for all
+// of its callers combined or for the callers using APIKey. Zero values are
+// unlimited.
+type SetRateLimitRequest struct {
+	APIKey            string  `json:"api_key"`
+	RequestsPerSecond float64 `json:"requests_per_second"`
+	Burst             int64   `json:"burst"`
+	DailyQuota        int64   `json:"daily_quota"`
+	MonthlyQuota      int64   `json:"monthly_quota"`
 }
 
 type UpdateFunctionRequest struct {
@@ -0,0 +1,159 @@
+// Package validation checks function input and output documents against the
+// JSON Schemas functions ship with.
+package validation
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// FieldError describes one way a document does not match its schema
+type FieldError struct {
+	// Location is a JSON pointer to the offending value in the document
+	Location string `json:"location"`
+	// SchemaLocation is a JSON pointer to the failing keyword in the schema
+	SchemaLocation string `json:"schema_location"`
+	Message        string `json:"message"`
+}
+
+// Error is returned when a document does not match its schema
+type Error struct {
+	Errors []FieldError `json:"errors"`
+}
+
+func (e *Error) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, fieldErr := range e.Errors {
+		messages = append(messages, fmt.Sprintf("%s: %s", locationOrRoot(fieldErr.Location), fieldErr.Message))
+	}
+
+	return "document does not match schema: " + strings.Join(messages, "; ")
+}
+
+// ErrExternalRef is returned for a schema referencing a document other than
+// itself. Schemas come from whoever deploys a function, so nothing is ever
+// loaded from files or the network on their behalf.
+var ErrExternalRef = errors.New("schema references an external document")
+
+// maxCachedSchemas bounds the compiled schema cache. Schemas change with
+// deploys, the least recently used ones are evicted first.
+const maxCachedSchemas = 256
+
+type cacheEntry struct {
+	key    string
+	schema *jsonschema.Schema
+}
+
+var (
+	cacheMutex sync.Mutex
+	cache      = make(map[string]*list.Element)
+	cacheOrder = list.New()
+)
+
+// CheckSchema reports whether schemaDoc is a valid JSON Schema
+func CheckSchema(schemaDoc string) error {
+	_, err := compile(schemaDoc)
+	return err
+}
+
+// Validate checks document against schemaDoc. A document that is not valid
+// JSON or does not match the schema results in an *Error, problems with the
+// schema itself are returned as plain errors.
+func Validate(schemaDoc string, document []byte) error {
+	schema, err := compile(schemaDoc)
+	if err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(document))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return &Error{Errors: []FieldError{{Message: fmt.Sprintf("invalid JSON: %s", err)}}}
+	}
+
+	if err := schema.Validate(value); err != nil {
+		validationErr, ok := err.(*jsonschema.ValidationError)
+		if !ok {
+			return err
+		}
+
+		return &Error{Errors: leafErrors(validationErr, nil)}
+	}
+
+	return nil
+}
+
+// compile compiles schemaDoc, reusing earlier compilations of the same document
+func compile(schemaDoc string) (*jsonschema.Schema, error) {
+	sum := sha256.Sum256([]byte(schemaDoc))
+	key := hex.EncodeToString(sum[:])
+
+	cacheMutex.Lock()
+	defer cacheMutex.Unlock()
+
+	if element, ok := cache[key]; ok {
+		cacheOrder.MoveToFront(element)
+		return element.Value.(*cacheEntry).schema, nil
+	}
+
+	url := "mem://schemas/" + key + ".json"
+	compiler := jsonschema.NewCompiler()
+	compiler.LoadURL = func(ref string) (io.ReadCloser, error) {
+		return nil, fmt.Errorf("%w: %s", ErrExternalRef, ref)
+	}
+	if err := compiler.AddResource(url, strings.NewReader(schemaDoc)); err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+
+	schema, err := compiler.Compile(url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+
+	cache[key] = cacheOrder.PushFront(&cacheEntry{key: key, schema: schema})
+	if cacheOrder.Len() > maxCachedSchemas {
+		oldest := cacheOrder.Back()
+		cacheOrder.Remove(oldest)
+		delete(cache, oldest.Value.(*cacheEntry).key)
+	}
+
+	return schema, nil
+}
+
+// leafErrors flattens a validation error tree into the errors that caused it,
+// skipping the intermediate "doesn't validate with" wrappers
+func leafErrors(validationErr *jsonschema.ValidationError, errors []FieldError) []FieldError {
+	if len(validationErr.Causes) == 0 {
+		return append(errors, FieldError{
+			Location:       validationErr.InstanceLocation,
+			SchemaLocation: validationErr.KeywordLocation,
+			Message:        validationErr.Message,
+		})
+	}
+
+	for _, cause := range validationErr.Causes {
+		errors = leafErrors(cause, errors)
+	}
+
+	return errors
+}
+
+func locationOrRoot(location string) string {
+	if location == "" {
+		return "/"
+	}
+
+	return location
+}
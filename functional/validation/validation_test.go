@@ -0,0 +1,102 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+const testSchema = `{
+	"type": "object",
+	"required": ["name"],
+	"properties": {
+		"name": {"type": "string"},
+		"count": {"type": "integer", "minimum": 1}
+	}
+}`
+
+func TestCheckSchema(t *testing.T) {
+	if err := CheckSchema(testSchema); err != nil {
+		t.Errorf("Expected valid schema, got %v", err)
+	}
+
+	if err := CheckSchema(`{"type": 12}`); err == nil {
+		t.Errorf("Expected invalid schema to be rejected")
+	}
+
+	if err := CheckSchema(`not json`); err == nil {
+		t.Errorf("Expected malformed schema to be rejected")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name           string
+		document       string
+		expectErrors   int
+		expectLocation string
+	}{
+		{name: "valid document", document: `{"name": "test", "count": 2}`},
+		{name: "missing required property", document: `{"count": 2}`, expectErrors: 1, expectLocation: ""},
+		{name: "wrong property type", document: `{"name": 5}`, expectErrors: 1, expectLocation: "/name"},
+		{name: "multiple problems", document: `{"name": 5, "count": 0}`, expectErrors: 2},
+		{name: "invalid JSON", document: `{"name":`, expectErrors: 1, expectLocation: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(testSchema, []byte(tt.document))
+			if tt.expectErrors == 0 {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+
+			var validationErr *Error
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("Expected validation error, got %v", err)
+			}
+
+			if len(validationErr.Errors) != tt.expectErrors {
+				t.Fatalf("Expected %d errors, got %d: %v", tt.expectErrors, len(validationErr.Errors), validationErr)
+			}
+
+			if tt.expectErrors == 1 && validationErr.Errors[0].Location != tt.expectLocation {
+				t.Errorf("Expected error at %q, got %q", tt.expectLocation, validationErr.Errors[0].Location)
+			}
+		})
+	}
+}
+
+func TestCheckSchema_ExternalRefs(t *testing.T) {
+	for _, ref := range []string{"file:///etc/passwd", "http://example.com/schema.json", "other.json"} {
+		err := CheckSchema(`{"$ref": "` + ref + `"}`)
+		if !errors.Is(err, ErrExternalRef) {
+			t.Errorf("Expected $ref %s to be rejected, got %v", ref, err)
+		}
+	}
+
+	internal := `{
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"definitions": {"name": {"type": "string"}},
+		"properties": {"name": {"$ref": "#/definitions/name"}}
+	}`
+	if err := Validate(internal, []byte(`{"name": 5}`)); err == nil {
+		t.Errorf("Expected a reference within the schema to be followed")
+	}
+}
+
+func TestCompile_CacheIsBounded(t *testing.T) {
+	for i := 0; i < maxCachedSchemas+10; i++ {
+		if err := CheckSchema(fmt.Sprintf(`{"maxLength": %d}`, i)); err != nil {
+			t.Fatalf("Expected valid schema, got %v", err)
+		}
+	}
+
+	cacheMutex.Lock()
+	defer cacheMutex.Unlock()
+	if len(cache) != maxCachedSchemas || cacheOrder.Len() != maxCachedSchemas {
+		t.Errorf("Expected the cache to hold %d schemas, got %d", maxCachedSchemas, len(cache))
+	}
+}
@@ -0,0 +1,289 @@
+// Package webhook verifies webhook deliveries from GitHub, Gitea and Stripe and
+// normalizes them into a single envelope that functions can rely on.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	ProviderGitHub = "github"
+	ProviderGitea  = "gitea"
+	ProviderStripe = "stripe"
+)
+
+// DefaultTolerance is how far a signed timestamp may be from the current time
+const DefaultTolerance = 5 * time.Minute
+
+// Headers set on normalized invocation requests
+const (
+	HeaderProvider = "X-Webhook-Provider"
+	HeaderEvent    = "X-Webhook-Event"
+	HeaderDelivery = "X-Webhook-Delivery"
+)
+
+var (
+	// ErrInvalidSignature is returned when a delivery was not signed with the adapter's secret
+	ErrInvalidSignature = errors.New("webhook signature is invalid")
+	// ErrMissingSignature is returned when a delivery carries no signature
+	ErrMissingSignature = errors.New("webhook signature is missing")
+	// ErrStale is returned when a signed timestamp is outside the tolerance
+	ErrStale = errors.New("webhook timestamp is outside the tolerance")
+	// ErrMissingDeliveryID is returned when a delivery carries no id
+	ErrMissingDeliveryID = errors.New("webhook delivery has no id")
+)
+
+// Config is the input adapter configured on a function
+type Config struct {
+	// Provider is one of github, gitea or stripe
+	Provider string `json:"provider"`
+	// SecretEnv names the function environment variable holding the signing secret
+	SecretEnv string `json:"secret_env"`
+	// ToleranceSeconds bounds the age of signed timestamps, for providers that sign one
+	ToleranceSeconds int64 `json:"tolerance_seconds,omitempty"`
+}
+
+// Tolerance returns the configured timestamp tolerance or the default
+func (c *Config) Tolerance() time.Duration {
+	if c.ToleranceSeconds > 0 {
+		return time.Duration(c.ToleranceSeconds) * time.Second
+	}
+
+	return DefaultTolerance
+}
+
+// ParseConfig reads an adapter config as stored on a function
+func ParseConfig(raw []byte) (*Config, error) {
+	var config Config
+	if err := json.Unmarshal(raw, &config); err != nil {
+		return nil, fmt.Errorf("invalid input adapter: %w", err)
+	}
+
+	if _, ok := verifiers[config.Provider]; !ok {
+		return nil, fmt.Errorf("unsupported input adapter provider %q (supported: %s)", config.Provider, strings.Join(Providers(), ", "))
+	}
+
+	if config.SecretEnv == "" {
+		return nil, fmt.Errorf("input adapter secret_env is required")
+	}
+
+	if config.ToleranceSeconds < 0 {
+		return nil, fmt.Errorf("input adapter tolerance_seconds must not be negative")
+	}
+
+	return &config, nil
+}
+
+// Providers lists the supported webhook providers
+func Providers() []string {
+	providers := make([]string, 0, len(verifiers))
+	for provider := range verifiers {
+		providers = append(providers, provider)
+	}
+	sort.Strings(providers)
+	return providers
+}
+
+// Delivery is a verified webhook delivery
+type Delivery struct {
+	Provider string `json:"provider"`
+	Event    string `json:"event"`
+	// ID is the provider's id for the delivery
+	ID         string          `json:"delivery_id"`
+	ReceivedAt time.Time       `json:"received_at"`
+	Payload    json.RawMessage `json:"payload"`
+	// ReplayKey identifies the delivery by what the signature covers, so that
+	// replays can't dodge detection by changing an unsigned header
+	ReplayKey string `json:"-"`
+}
+
+// verifier checks a delivery's signature and extracts its identity
+type verifier func(config *Config, secret []byte, header http.Header, body []byte, now time.Time) (*Delivery, error)
+
+var verifiers = map[string]verifier{
+	ProviderGitHub: verifyGitHub,
+	ProviderGitea:  verifyGitea,
+	ProviderStripe: verifyStripe,
+}
+
+// Verify checks the delivery's signature with secret and returns the verified
+// delivery. Replays are not detected here, callers must check Delivery.ReplayKey.
+func Verify(config *Config, secret string, header http.Header, body []byte, now time.Time) (*Delivery, error) {
+	verify, ok := verifiers[config.Provider]
+	if !ok {
+		return nil, fmt.Errorf("unsupported input adapter provider %q", config.Provider)
+	}
+
+	if secret == "" {
+		return nil, fmt.Errorf("webhook secret %s is not set", config.SecretEnv)
+	}
+
+	if !json.Valid(body) {
+		return nil, fmt.Errorf("webhook payload is not valid JSON")
+	}
+
+	delivery, err := verify(config, []byte(secret), header, body, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if delivery.ID == "" || delivery.ReplayKey == "" {
+		return nil, ErrMissingDeliveryID
+	}
+
+	delivery.Provider = config.Provider
+	delivery.ReceivedAt = now.UTC()
+	delivery.Payload = json.RawMessage(body)
+	return delivery, nil
+}
+
+// Normalize returns the body and headers functions receive for a delivery.
+// The body is the delivery envelope; the headers identify the delivery
+// without the provider-specific signature headers.
+func Normalize(delivery *Delivery) ([]byte, map[string]string, error) {
+	body, err := json.Marshal(delivery)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal webhook delivery: %w", err)
+	}
+
+	headers := map[string]string{
+		"Content-Type": "application/json",
+		HeaderProvider: delivery.Provider,
+		HeaderEvent:    delivery.Event,
+		HeaderDelivery: delivery.ID,
+	}
+
+	return body, headers, nil
+}
+
+func verifyGitHub(config *Config, secret []byte, header http.Header, body []byte, now time.Time) (*Delivery, error) {
+	signature := header.Get("X-Hub-Signature-256")
+	if signature == "" {
+		return nil, ErrMissingSignature
+	}
+
+	if !strings.HasPrefix(signature, "sha256=") || !hmacEqual(signature[len("sha256="):], secret, body) {
+		return nil, ErrInvalidSignature
+	}
+
+	// The delivery id header isn't signed, only the body is
+	return &Delivery{
+		Event:     header.Get("X-GitHub-Event"),
+		ID:        header.Get("X-GitHub-Delivery"),
+		ReplayKey: bodyDigest(body),
+	}, nil
+}
+
+func verifyGitea(config *Config, secret []byte, header http.Header, body []byte, now time.Time) (*Delivery, error) {
+	signature := header.Get("X-Gitea-Signature")
+	if signature == "" {
+		return nil, ErrMissingSignature
+	}
+
+	if !hmacEqual(signature, secret, body) {
+		return nil, ErrInvalidSignature
+	}
+
+	return &Delivery{
+		Event:     header.Get("X-Gitea-Event"),
+		ID:        header.Get("X-Gitea-Delivery"),
+		ReplayKey: bodyDigest(body),
+	}, nil
+}
+
+// verifyStripe checks the Stripe-Signature header, which signs the timestamp
+// along with the payload so that old deliveries can be rejected
+func verifyStripe(config *Config, secret []byte, header http.Header, body []byte, now time.Time) (*Delivery, error) {
+	signatureHeader := header.Get("Stripe-Signature")
+	if signatureHeader == "" {
+		return nil, ErrMissingSignature
+	}
+
+	var (
+		timestamp  string
+		signatures []string
+	)
+	for _, part := range strings.Split(signatureHeader, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+
+	if timestamp == "" || len(signatures) == 0 {
+		return nil, ErrMissingSignature
+	}
+
+	signedPayload := append([]byte(timestamp+"."), body...)
+	valid := false
+	for _, signature := range signatures {
+		if hmacEqual(signature, secret, signedPayload) {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return nil, ErrInvalidSignature
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidSignature
+	}
+
+	signedAt := time.Unix(unix, 0)
+	if age := now.Sub(signedAt); age > config.Tolerance() || age < -config.Tolerance() {
+		return nil, ErrStale
+	}
+
+	var event struct {
+		ID   string `json:"id"`
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, fmt.Errorf("invalid stripe event: %w", err)
+	}
+
+	// The event id is part of the signed payload, and stays the same when
+	// Stripe retries a delivery with a new timestamp
+	return &Delivery{
+		Event:     event.Type,
+		ID:        event.ID,
+		ReplayKey: event.ID,
+	}, nil
+}
+
+// bodyDigest identifies a delivery whose provider only signs the body
+func bodyDigest(body []byte) string {
+	digest := sha256.Sum256(body)
+	return "sha256:" + hex.EncodeToString(digest[:])
+}
+
+// hmacEqual reports whether hexSignature is the HMAC-SHA256 of data under secret
+func hmacEqual(hexSignature string, secret, data []byte) bool {
+	signature, err := hex.DecodeString(hexSignature)
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(data)
+	return hmac.Equal(signature, mac.Sum(nil))
+}
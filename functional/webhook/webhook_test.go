@@ -0,0 +1,202 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+const testSecret = "s3cret"
+
+func testSign(secret string, data []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	body := []byte(`{"id": "evt_123", "type": "invoice.paid", "action": "opened"}`)
+	stripeTimestamp := strconv.FormatInt(now.Unix(), 10)
+	staleTimestamp := strconv.FormatInt(now.Add(-time.Hour).Unix(), 10)
+
+	tests := []struct {
+		name          string
+		provider      string
+		header        http.Header
+		expectedErr   error
+		expectedEvent string
+		expectedID    string
+	}{
+		{
+			name:     "github valid",
+			provider: ProviderGitHub,
+			header: http.Header{
+				"X-Hub-Signature-256": {"sha256=" + testSign(testSecret, body)},
+				"X-Github-Event":      {"pull_request"},
+				"X-Github-Delivery":   {"gh-1"},
+			},
+			expectedEvent: "pull_request",
+			expectedID:    "gh-1",
+		},
+		{
+			name:     "github wrong secret",
+			provider: ProviderGitHub,
+			header: http.Header{
+				"X-Hub-Signature-256": {"sha256=" + testSign("other", body)},
+				"X-Github-Delivery":   {"gh-1"},
+			},
+			expectedErr: ErrInvalidSignature,
+		},
+		{
+			name:     "github without delivery id",
+			provider: ProviderGitHub,
+			header: http.Header{
+				"X-Hub-Signature-256": {"sha256=" + testSign(testSecret, body)},
+			},
+			expectedErr: ErrMissingDeliveryID,
+		},
+		{
+			name:        "github unsigned",
+			provider:    ProviderGitHub,
+			header:      http.Header{"X-Github-Delivery": {"gh-1"}},
+			expectedErr: ErrMissingSignature,
+		},
+		{
+			name:     "gitea valid",
+			provider: ProviderGitea,
+			header: http.Header{
+				"X-Gitea-Signature": {testSign(testSecret, body)},
+				"X-Gitea-Event":     {"push"},
+				"X-Gitea-Delivery":  {"gitea-1"},
+			},
+			expectedEvent: "push",
+			expectedID:    "gitea-1",
+		},
+		{
+			name:     "stripe valid",
+			provider: ProviderStripe,
+			header: http.Header{
+				"Stripe-Signature": {"t=" + stripeTimestamp + ",v1=" + testSign(testSecret, []byte(stripeTimestamp+"."+string(body)))},
+			},
+			expectedEvent: "invoice.paid",
+			expectedID:    "evt_123",
+		},
+		{
+			name:     "stripe stale",
+			provider: ProviderStripe,
+			header: http.Header{
+				"Stripe-Signature": {"t=" + staleTimestamp + ",v1=" + testSign(testSecret, []byte(staleTimestamp+"."+string(body)))},
+			},
+			expectedErr: ErrStale,
+		},
+		{
+			name:     "stripe signature over other timestamp",
+			provider: ProviderStripe,
+			header: http.Header{
+				"Stripe-Signature": {"t=" + stripeTimestamp + ",v1=" + testSign(testSecret, []byte(staleTimestamp+"."+string(body)))},
+			},
+			expectedErr: ErrInvalidSignature,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{Provider: tt.provider, SecretEnv: "WEBHOOK_SECRET"}
+
+			delivery, err := Verify(config, testSecret, tt.header, body, now)
+			if tt.expectedErr != nil {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Fatalf("Expected error %v, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Verify failed: %v", err)
+			}
+
+			if delivery.Event != tt.expectedEvent {
+				t.Errorf("Expected event %q, got %q", tt.expectedEvent, delivery.Event)
+			}
+			if delivery.ID != tt.expectedID {
+				t.Errorf("Expected delivery id %q, got %q", tt.expectedID, delivery.ID)
+			}
+			if delivery.Provider != tt.provider {
+				t.Errorf("Expected provider %q, got %q", tt.provider, delivery.Provider)
+			}
+			if delivery.ReplayKey == "" {
+				t.Errorf("Expected a replay key")
+			}
+		})
+	}
+}
+
+func TestVerify_ReplayKeyIgnoresDeliveryID(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	body := []byte(`{"action": "opened"}`)
+	config := &Config{Provider: ProviderGitHub, SecretEnv: "WEBHOOK_SECRET"}
+
+	verify := func(deliveryID string) *Delivery {
+		delivery, err := Verify(config, testSecret, http.Header{
+			"X-Hub-Signature-256": {"sha256=" + testSign(testSecret, body)},
+			"X-Github-Delivery":   {deliveryID},
+		}, body, now)
+		if err != nil {
+			t.Fatalf("Verify failed: %v", err)
+		}
+		return delivery
+	}
+
+	first, second := verify("gh-1"), verify("gh-2")
+	if first.ReplayKey != second.ReplayKey {
+		t.Errorf("Expected the same body to have the same replay key, got %q and %q", first.ReplayKey, second.ReplayKey)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	delivery := &Delivery{
+		Provider:   ProviderGitHub,
+		Event:      "push",
+		ID:         "gh-1",
+		ReceivedAt: time.Unix(1700000000, 0).UTC(),
+		Payload:    json.RawMessage(`{"ref":"refs/heads/main"}`),
+	}
+
+	body, headers, err := Normalize(delivery)
+	if err != nil {
+		t.Fatalf("Normalize failed: %v", err)
+	}
+
+	var envelope map[string]interface{}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		t.Fatalf("Normalized body is not JSON: %v", err)
+	}
+	if envelope["event"] != "push" || envelope["delivery_id"] != "gh-1" {
+		t.Errorf("Unexpected envelope: %s", body)
+	}
+	if payload, ok := envelope["payload"].(map[string]interface{}); !ok || payload["ref"] != "refs/heads/main" {
+		t.Errorf("Expected payload to be passed through, got %v", envelope["payload"])
+	}
+
+	if headers[HeaderEvent] != "push" || headers[HeaderDelivery] != "gh-1" || headers[HeaderProvider] != ProviderGitHub {
+		t.Errorf("Unexpected headers: %v", headers)
+	}
+}
+
+func TestParseConfig(t *testing.T) {
+	if _, err := ParseConfig([]byte(`{"provider": "github", "secret_env": "SECRET"}`)); err != nil {
+		t.Errorf("Expected valid config, got %v", err)
+	}
+	if _, err := ParseConfig([]byte(`{"provider": "bitbucket", "secret_env": "SECRET"}`)); err == nil {
+		t.Errorf("Expected unsupported provider to be rejected")
+	}
+	if _, err := ParseConfig([]byte(`{"provider": "stripe"}`)); err == nil {
+		t.Errorf("Expected missing secret_env to be rejected")
+	}
+}
@@ -0,0 +1,83 @@
+package alerting
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/pirogoeth/apps/maparoon/database"
+	"github.com/pirogoeth/apps/maparoon/scandiff"
+)
+
+// Evaluator applies a network's alert rules to the changes found by a scan
+type Evaluator struct {
+	querier  *database.Queries
+	notifier *Notifier
+}
+
+func NewEvaluator(querier *database.Queries, notifier *Notifier) *Evaluator {
+	return &Evaluator{
+		querier:  querier,
+		notifier: notifier,
+	}
+}
+
+// Evaluate records an alert for every change matching an enabled rule and
+// returns the alerts that are new. A change that already has an open alert
+// only bumps that alert's count, so it's notified once until acknowledged.
+func (e *Evaluator) Evaluate(ctx context.Context, network database.Network, scanId int64, changes *scandiff.Changes) ([]database.Alert, error) {
+	rules, err := e.querier.ListEnabledAlertRulesForNetwork(ctx, network.ID)
+	if err != nil {
+		return nil, fmt.Errorf("could not list alert rules: %w", err)
+	}
+
+	fired := make([]database.Alert, 0)
+	for _, rule := range rules {
+		for _, event := range Match(rule, network, changes) {
+			existing, err := e.querier.GetOpenAlertByFingerprint(ctx, database.GetOpenAlertByFingerprintParams{
+				RuleID:      rule.ID,
+				Fingerprint: event.Fingerprint,
+			})
+			if err == nil {
+				_, err := e.querier.TouchAlert(ctx, database.TouchAlertParams{
+					ScanID: scanId,
+					ID:     existing.ID,
+				})
+				if err != nil {
+					return nil, fmt.Errorf("could not update alert %d: %w", existing.ID, err)
+				}
+				continue
+			} else if !errors.Is(err, sql.ErrNoRows) {
+				return nil, fmt.Errorf("could not look up open alert: %w", err)
+			}
+
+			alert, err := e.querier.CreateAlert(ctx, database.CreateAlertParams{
+				RuleID:      rule.ID,
+				NetworkID:   network.ID,
+				ScanID:      scanId,
+				Fingerprint: event.Fingerprint,
+				Summary:     event.Summary,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("could not create alert: %w", err)
+			}
+			fired = append(fired, alert)
+
+			// Notifications are delivered in the background, a dropped one
+			// doesn't lose the alert, it can still be seen through the API
+			queued := e.notifier.Enqueue(&Notification{
+				Rule:    rule,
+				Network: network,
+				Alert:   alert,
+			})
+			if !queued {
+				logrus.Errorf("could not deliver alert %d (rule %s): notification queue is full", alert.ID, rule.Name)
+			}
+		}
+	}
+
+	return fired, nil
+}
@@ -0,0 +1,131 @@
+package alerting
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pirogoeth/apps/maparoon/database"
+	"github.com/pirogoeth/apps/maparoon/scandiff"
+)
+
+// setupEvaluator returns an evaluator against an in-memory database with a
+// new host rule that posts to a webhook responding with status. The webhook's
+// request count is returned along with it.
+func setupEvaluator(t *testing.T, status int) (*Evaluator, *database.DbWrapper, database.Network, *atomic.Int32) {
+	ctx := context.Background()
+
+	db, err := database.Open(ctx, fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name()))
+	if err != nil {
+		t.Fatalf("could not open database: %s", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	network, err := db.CreateNetwork(ctx, database.CreateNetworkParams{Name: "lan", Address: "10.0.0.0", Cidr: 24})
+	if err != nil {
+		t.Fatalf("could not create network: %s", err)
+	}
+
+	notified := &atomic.Int32{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		notified.Add(1)
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+
+	_, err = db.CreateAlertRule(ctx, database.CreateAlertRuleParams{
+		Name:       "new hosts",
+		Kind:       KindNewHost,
+		WebhookUrl: server.URL,
+		Enabled:    true,
+	})
+	if err != nil {
+		t.Fatalf("could not create alert rule: %s", err)
+	}
+
+	notifier := NewNotifier(&NotifierOpts{WebhookTimeout: time.Second})
+	return NewEvaluator(db.Querier(), notifier), db, network, notified
+}
+
+func createScan(t *testing.T, db *database.DbWrapper, network database.Network) int64 {
+	scanId, err := db.CreateNetworkScan(context.Background(), database.CreateNetworkScanParams{NetworkID: network.ID})
+	if err != nil {
+		t.Fatalf("could not create network scan: %s", err)
+	}
+
+	return scanId
+}
+
+func TestEvaluateDedupesOpenAlerts(t *testing.T) {
+	evaluator, db, network, notified := setupEvaluator(t, http.StatusOK)
+	ctx := context.Background()
+	changes := &scandiff.Changes{NewHosts: []string{"10.0.0.5"}}
+
+	fired, err := evaluator.Evaluate(ctx, network, createScan(t, db, network), changes)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	evaluator.notifier.Wait()
+	if len(fired) != 1 || notified.Load() != 1 {
+		t.Fatalf("expected 1 alert fired and notified, got %d fired and %d notified", len(fired), notified.Load())
+	}
+
+	// The alert is still open, so it's only touched
+	secondScan := createScan(t, db, network)
+	fired, err = evaluator.Evaluate(ctx, network, secondScan, changes)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	evaluator.notifier.Wait()
+	if len(fired) != 0 || notified.Load() != 1 {
+		t.Errorf("expected the open alert not to fire again, got %d fired and %d notified", len(fired), notified.Load())
+	}
+
+	alerts, err := db.ListAlerts(ctx)
+	if err != nil {
+		t.Fatalf("could not list alerts: %s", err)
+	}
+	if len(alerts) != 1 || alerts[0].Count != 2 || alerts[0].ScanID != secondScan {
+		t.Fatalf("expected one alert seen twice, last by the second scan, got %+v", alerts)
+	}
+
+	// Once acknowledged, the same change fires a new alert
+	if _, err := db.AcknowledgeAlert(ctx, alerts[0].ID); err != nil {
+		t.Fatalf("could not acknowledge alert: %s", err)
+	}
+
+	fired, err = evaluator.Evaluate(ctx, network, createScan(t, db, network), changes)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	evaluator.notifier.Wait()
+	if len(fired) != 1 || fired[0].ID == alerts[0].ID || notified.Load() != 2 {
+		t.Errorf("expected a new alert after acknowledging, got %+v and %d notified", fired, notified.Load())
+	}
+}
+
+func TestEvaluateKeepsAlertWhenNotificationFails(t *testing.T) {
+	evaluator, db, network, notified := setupEvaluator(t, http.StatusInternalServerError)
+	ctx := context.Background()
+
+	fired, err := evaluator.Evaluate(ctx, network, createScan(t, db, network), &scandiff.Changes{NewHosts: []string{"10.0.0.5"}})
+	if err != nil {
+		t.Fatalf("expected a failed notification not to fail the evaluation, got %s", err)
+	}
+	evaluator.notifier.Wait()
+	if len(fired) != 1 || notified.Load() != 1 {
+		t.Fatalf("expected 1 alert fired and a delivery attempted, got %d fired and %d notified", len(fired), notified.Load())
+	}
+
+	alerts, err := db.ListOpenAlerts(ctx)
+	if err != nil {
+		t.Fatalf("could not list alerts: %s", err)
+	}
+	if len(alerts) != 1 || alerts[0].ID != fired[0].ID {
+		t.Errorf("expected the alert to be kept, got %+v", alerts)
+	}
+}
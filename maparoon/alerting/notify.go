@@ -0,0 +1,261 @@
+package alerting
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/pirogoeth/apps/maparoon/database"
+)
+
+const (
+	// notifyWorkers is how many notifications are delivered at once
+	notifyWorkers = 4
+	// notifyQueueSize is how many notifications may wait for delivery, more
+	// are dropped rather than holding up alert evaluation
+	notifyQueueSize = 256
+)
+
+type SmtpOpts struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	// Timeout bounds a whole email delivery, from connecting to QUIT
+	Timeout time.Duration
+}
+
+type NotifierOpts struct {
+	WebhookTimeout time.Duration
+	Smtp           SmtpOpts
+}
+
+// Notifier delivers alerts to the webhook and email recipients of their rule.
+// Queued notifications are delivered in the background by a fixed number of
+// workers, so a slow webhook or mail server can't stall alert evaluation.
+type Notifier struct {
+	opts       *NotifierOpts
+	httpClient *http.Client
+
+	queue   chan *Notification
+	pending sync.WaitGroup
+}
+
+// Notification is the payload posted to alert webhooks
+type Notification struct {
+	Rule    database.AlertRule `json:"rule"`
+	Network database.Network   `json:"network"`
+	Alert   database.Alert     `json:"alert"`
+}
+
+func NewNotifier(opts *NotifierOpts) *Notifier {
+	n := &Notifier{
+		opts:       opts,
+		httpClient: &http.Client{Timeout: opts.WebhookTimeout},
+		queue:      make(chan *Notification, notifyQueueSize),
+	}
+
+	for i := 0; i < notifyWorkers; i++ {
+		go n.deliver()
+	}
+
+	return n
+}
+
+// Enqueue queues the notification for delivery. It returns false when the
+// queue is full and the notification was dropped.
+func (n *Notifier) Enqueue(notification *Notification) bool {
+	n.pending.Add(1)
+	select {
+	case n.queue <- notification:
+		return true
+	default:
+		n.pending.Done()
+		return false
+	}
+}
+
+// Wait blocks until every queued notification was delivered or failed
+func (n *Notifier) Wait() {
+	n.pending.Wait()
+}
+
+func (n *Notifier) deliver() {
+	for notification := range n.queue {
+		// A failed delivery doesn't lose the alert, it can still be seen
+		// through the API
+		if err := n.Notify(context.Background(), notification); err != nil {
+			logrus.Errorf("could not deliver alert %d (rule %s): %s", notification.Alert.ID, notification.Rule.Name, err)
+		}
+		n.pending.Done()
+	}
+}
+
+// Notify delivers the alert to every destination configured on its rule
+func (n *Notifier) Notify(ctx context.Context, notification *Notification) error {
+	var errs []error
+	if notification.Rule.WebhookUrl != "" {
+		if err := n.sendWebhook(ctx, notification); err != nil {
+			errs = append(errs, fmt.Errorf("webhook delivery failed: %w", err))
+		}
+	}
+
+	if notification.Rule.EmailTo != "" {
+		if err := n.sendEmail(notification); err != nil {
+			errs = append(errs, fmt.Errorf("email delivery failed: %w", err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (n *Notifier) sendWebhook(ctx context.Context, notification *Notification) error {
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return fmt.Errorf("could not marshal notification: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, notification.Rule.WebhookUrl, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("could not create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+func (n *Notifier) sendEmail(notification *Notification) error {
+	smtpOpts := n.opts.Smtp
+	if smtpOpts.Host == "" {
+		return fmt.Errorf("smtp is not configured")
+	}
+
+	recipients := strings.Split(notification.Rule.EmailTo, ",")
+	for i := range recipients {
+		recipients[i] = strings.TrimSpace(recipients[i])
+	}
+
+	var auth smtp.Auth
+	if smtpOpts.Username != "" {
+		auth = smtp.PlainAuth("", smtpOpts.Username, smtpOpts.Password, smtpOpts.Host)
+	}
+
+	addr := net.JoinHostPort(smtpOpts.Host, strconv.Itoa(smtpOpts.Port))
+	return sendMail(addr, smtpOpts, auth, recipients, emailMessage(smtpOpts.From, recipients, notification))
+}
+
+// sendMail is smtp.SendMail, with the whole exchange bounded by the configured
+// timeout so that a mail server that stops responding can't hold a worker
+func sendMail(addr string, smtpOpts SmtpOpts, auth smtp.Auth, recipients []string, msg []byte) error {
+	timeout := smtpOpts.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+
+	conn, err := net.DialTimeout("tcp", addr, timeout)
+	if err != nil {
+		return err
+	}
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		conn.Close()
+		return err
+	}
+
+	client, err := smtp.NewClient(conn, smtpOpts.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: smtpOpts.Host}); err != nil {
+			return err
+		}
+	}
+	if auth != nil {
+		if err := client.Auth(auth); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(smtpOpts.From); err != nil {
+		return err
+	}
+	for _, recipient := range recipients {
+		if err := client.Rcpt(recipient); err != nil {
+			return err
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+func emailMessage(from string, recipients []string, notification *Notification) []byte {
+	alert := notification.Alert
+
+	// Summaries carry values reported by devices, which must not be able to
+	// add headers of their own
+	msg := &bytes.Buffer{}
+	fmt.Fprintf(msg, "From: %s\r\n", headerValue(from))
+	fmt.Fprintf(msg, "To: %s\r\n", headerValue(strings.Join(recipients, ", ")))
+	fmt.Fprintf(msg, "Subject: %s\r\n", headerValue(fmt.Sprintf("[maparoon] %s: %s", notification.Rule.Name, alert.Summary)))
+	fmt.Fprintf(msg, "Date: %s\r\n", time.Unix(alert.FirstSeenAt, 0).Format(time.RFC1123Z))
+	fmt.Fprintf(msg, "Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	fmt.Fprintf(msg, "%s\r\n\r\n", alert.Summary)
+	fmt.Fprintf(msg, "Rule: %s (%s)\r\n", notification.Rule.Name, notification.Rule.Kind)
+	fmt.Fprintf(msg, "Network: %s (%s)\r\n", notification.Network.Name, notification.Network.CidrString())
+	fmt.Fprintf(msg, "Alert: %d, scan %d\r\n", alert.ID, alert.ScanID)
+
+	return msg.Bytes()
+}
+
+// headerValue makes a value safe to use in a mail header. Control characters,
+// CR and LF among them, are replaced with spaces and anything that isn't ASCII
+// is RFC 2047 encoded.
+func headerValue(value string) string {
+	value = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return ' '
+		}
+		return r
+	}, value)
+
+	return mime.QEncoding.Encode("utf-8", value)
+}
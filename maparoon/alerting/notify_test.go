@@ -0,0 +1,55 @@
+package alerting
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pirogoeth/apps/maparoon/database"
+)
+
+func TestEmailMessageEscapesHeaders(t *testing.T) {
+	notification := &Notification{
+		Rule: database.AlertRule{Name: "new hosts", Kind: KindNewHost},
+		Alert: database.Alert{
+			ID:      1,
+			Summary: "new host router\r\nBcc: victim@example.com\r\n\r\nforged body",
+		},
+	}
+
+	msg := string(emailMessage("maparoon@localhost", []string{"ops@example.com"}, notification))
+	headers, _, ok := strings.Cut(msg, "\r\n\r\n")
+	if !ok {
+		t.Fatalf("expected headers and a body, got %q", msg)
+	}
+
+	lines := strings.Split(headers, "\r\n")
+	if len(lines) != 5 {
+		t.Fatalf("expected 5 header lines, got %d: %q", len(lines), lines)
+	}
+	for _, line := range lines {
+		if strings.HasPrefix(line, "Bcc:") {
+			t.Errorf("summary injected a header: %q", line)
+		}
+	}
+	if !strings.HasPrefix(lines[2], "Subject: [maparoon] new hosts: new host router  Bcc: victim@example.com") {
+		t.Errorf("expected the summary to stay in the subject, got %q", lines[2])
+	}
+}
+
+func TestHeaderValue(t *testing.T) {
+	cases := []struct {
+		value    string
+		expected string
+	}{
+		{"plain subject", "plain subject"},
+		{"line\r\nbreak", "line  break"},
+		{"tab\tand\x00nul", "tab and nul"},
+		{"café", "=?utf-8?q?caf=C3=A9?="},
+	}
+
+	for _, c := range cases {
+		if got := headerValue(c.value); got != c.expected {
+			t.Errorf("headerValue(%q) = %q, expected %q", c.value, got, c.expected)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package alerting
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pirogoeth/apps/maparoon/database"
+	"github.com/pirogoeth/apps/maparoon/scandiff"
+)
+
+const (
+	KindNewHost            = "new_host"
+	KindHostDisappeared    = "host_disappeared"
+	KindPortOpened         = "port_opened"
+	KindPortClosed         = "port_closed"
+	KindServiceChanged     = "service_changed"
+	KindMeasurementChanged = "snmp_changed"
+)
+
+var Kinds = []string{
+	KindNewHost,
+	KindHostDisappeared,
+	KindPortOpened,
+	KindPortClosed,
+	KindServiceChanged,
+	KindMeasurementChanged,
+}
+
+// Event is a change found by a scan that matches an alert rule
+type Event struct {
+	// Fingerprint identifies the change so that it's only alerted once while
+	// the alert is unacknowledged
+	Fingerprint string
+	Address     string
+	Summary     string
+}
+
+// ValidateRule checks that an alert rule can be evaluated
+func ValidateRule(kind, protocol string) error {
+	validKind := false
+	for _, k := range Kinds {
+		if k == kind {
+			validKind = true
+			break
+		}
+	}
+	if !validKind {
+		return fmt.Errorf("unknown alert rule kind `%s`, expected one of: %s", kind, strings.Join(Kinds, ", "))
+	}
+
+	switch protocol {
+	case "", "tcp", "udp":
+	default:
+		return fmt.Errorf("unknown protocol `%s`, expected tcp or udp", protocol)
+	}
+
+	return nil
+}
+
+// Match returns the changes in a network's scan that the rule alerts on
+func Match(rule database.AlertRule, network database.Network, changes *scandiff.Changes) []Event {
+	if rule.NetworkID != 0 && rule.NetworkID != network.ID {
+		return nil
+	}
+
+	events := make([]Event, 0)
+	switch rule.Kind {
+	case KindNewHost:
+		for _, address := range changes.NewHosts {
+			events = append(events, Event{
+				Fingerprint: fmt.Sprintf("%s:%s", rule.Kind, address),
+				Address:     address,
+				Summary:     fmt.Sprintf("new host %s appeared in network %s", address, network.Name),
+			})
+		}
+	case KindHostDisappeared:
+		for _, address := range changes.DisappearedHosts {
+			events = append(events, Event{
+				Fingerprint: fmt.Sprintf("%s:%s", rule.Kind, address),
+				Address:     address,
+				Summary:     fmt.Sprintf("host %s disappeared from network %s", address, network.Name),
+			})
+		}
+	case KindPortOpened, KindPortClosed:
+		portChanges, verb := changes.OpenedPorts, "opened"
+		if rule.Kind == KindPortClosed {
+			portChanges, verb = changes.ClosedPorts, "closed"
+		}
+
+		for _, change := range portChanges {
+			if !portMatches(rule, change.Port.Port, change.Protocol) {
+				continue
+			}
+
+			events = append(events, Event{
+				Fingerprint: fmt.Sprintf("%s:%s:%d/%s", rule.Kind, change.Address, change.Port.Port, change.Protocol),
+				Address:     change.Address,
+				Summary:     fmt.Sprintf("port %d/%s %s on %s", change.Port.Port, change.Protocol, verb, change.Address),
+			})
+		}
+	case KindServiceChanged:
+		for _, change := range changes.ChangedServices {
+			if !portMatches(rule, change.Port, change.Protocol) {
+				continue
+			}
+
+			events = append(events, Event{
+				Fingerprint: fmt.Sprintf("%s:%s:%d/%s:%s", rule.Kind, change.Address, change.Port, change.Protocol, change.Current),
+				Address:     change.Address,
+				Summary: fmt.Sprintf(
+					"service on port %d/%s of %s changed from `%s` to `%s`",
+					change.Port, change.Protocol, change.Address, change.Previous, change.Current,
+				),
+			})
+		}
+	case KindMeasurementChanged:
+		for _, change := range changes.ChangedMeasurements {
+			if !measurementMatches(rule.Measurement, change) {
+				continue
+			}
+
+			events = append(events, Event{
+				Fingerprint: fmt.Sprintf("%s:%s:%s:%s", rule.Kind, change.Address, change.Oid, change.Current),
+				Address:     change.Address,
+				Summary: fmt.Sprintf(
+					"SNMP %s of %s changed from `%s` to `%s`",
+					change.Name, change.Address, change.Previous, change.Current,
+				),
+			})
+		}
+	}
+
+	return events
+}
+
+func portMatches(rule database.AlertRule, port int, protocol string) bool {
+	if rule.Port != 0 && rule.Port != int64(port) {
+		return false
+	}
+
+	return rule.Protocol == "" || rule.Protocol == protocol
+}
+
+// measurementMatches matches a measurement by its OID or its name, with or
+// without the MIB module and instance, so `sysName` matches `SNMPv2-MIB::sysName.0`
+func measurementMatches(measurement string, change scandiff.MeasurementChange) bool {
+	if measurement == "" || measurement == change.Name || measurement == change.Oid {
+		return true
+	}
+
+	name := change.Name
+	if idx := strings.LastIndex(name, "::"); idx >= 0 {
+		name = name[idx+2:]
+	}
+
+	return measurement == name || strings.HasPrefix(name, measurement+".")
+}
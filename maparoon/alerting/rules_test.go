@@ -0,0 +1,63 @@
+package alerting
+
+import (
+	"testing"
+
+	"github.com/pirogoeth/apps/maparoon/database"
+	"github.com/pirogoeth/apps/maparoon/scandiff"
+)
+
+func TestMatch(t *testing.T) {
+	dmz := database.Network{ID: 2, Name: "dmz"}
+	changes := &scandiff.Changes{
+		NewHosts: []string{"10.0.2.5"},
+		OpenedPorts: []scandiff.PortChange{
+			{Address: "10.0.2.1", Port: scandiff.Port{Port: 22, Protocol: "tcp"}},
+			{Address: "10.0.2.1", Port: scandiff.Port{Port: 443, Protocol: "tcp"}},
+		},
+		ChangedMeasurements: []scandiff.MeasurementChange{
+			{Address: "10.0.2.1", Oid: ".1.3.6.1.2.1.1.5.0", Name: "SNMPv2-MIB::sysName.0", Previous: "a", Current: "b"},
+			{Address: "10.0.2.1", Oid: ".1.3.6.1.2.1.1.3.0", Name: "SNMPv2-MIB::sysUpTime.0", Previous: "1", Current: "2"},
+		},
+	}
+
+	cases := []struct {
+		name     string
+		rule     database.AlertRule
+		expected []string
+	}{
+		{
+			name:     "new host in any network",
+			rule:     database.AlertRule{Kind: KindNewHost},
+			expected: []string{"new_host:10.0.2.5"},
+		},
+		{
+			name: "new host in another network",
+			rule: database.AlertRule{Kind: KindNewHost, NetworkID: 1},
+		},
+		{
+			name:     "ssh opened in the dmz",
+			rule:     database.AlertRule{Kind: KindPortOpened, NetworkID: 2, Port: 22, Protocol: "tcp"},
+			expected: []string{"port_opened:10.0.2.1:22/tcp"},
+		},
+		{
+			name:     "sysName changed",
+			rule:     database.AlertRule{Kind: KindMeasurementChanged, Measurement: "sysName"},
+			expected: []string{"snmp_changed:10.0.2.1:.1.3.6.1.2.1.1.5.0:b"},
+		},
+	}
+
+	for _, c := range cases {
+		events := Match(c.rule, dmz, changes)
+		if len(events) != len(c.expected) {
+			t.Errorf("%s: expected %d events, got %v", c.name, len(c.expected), events)
+			continue
+		}
+
+		for i, event := range events {
+			if event.Fingerprint != c.expected[i] {
+				t.Errorf("%s: expected fingerprint %s, got %s", c.name, c.expected[i], event.Fingerprint)
+			}
+		}
+	}
+}
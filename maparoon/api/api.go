@@ -3,6 +3,7 @@ package api
 import (
 	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
@@ -22,10 +23,14 @@ const (
 	ErrNoQueryProvided = "no query parameter provided"
 	// ErrFailedToBind is used when request body failed to bind to the destination object
 	ErrFailedToBind = "failed to bind parameters"
+	// ErrUnauthorized is used when the request has no valid api token
+	ErrUnauthorized = "missing or invalid api token"
+	// ErrForbidden is used when the api token's role doesn't allow the request
+	ErrForbidden = "api token not allowed to make this request"
 )
 
 func MustRegister(router *gin.Engine, apiContext *types.ApiContext) {
-	groupV1 := router.Group("/v1")
+	groupV1 := router.Group("/v1", authenticate(apiContext))
 
 	v1Network := &v1NetworkEndpoints{apiContext}
 	v1Network.RegisterRoutesTo(groupV1)
@@ -33,11 +38,40 @@ func MustRegister(router *gin.Engine, apiContext *types.ApiContext) {
 	v1Host := &v1HostEndpoints{apiContext}
 	v1Host.RegisterRoutesTo(groupV1)
 
+	v1HostTable := &v1HostTableEndpoints{apiContext}
+	v1HostTable.RegisterRoutesTo(groupV1)
+
 	v1HostPort := &v1HostPortEndpoints{apiContext}
 	v1HostPort.RegisterRoutesTo(groupV1)
 
 	v1HostScan := &v1HostScanEndpoints{apiContext}
 	v1HostScan.RegisterRoutesTo(groupV1)
+
+	// The evaluator's notifier delivers in the background, so there's one for
+	// the whole API
+	v1NetworkScan := &v1NetworkScanEndpoints{apiContext, newAlertEvaluator(apiContext)}
+	v1NetworkScan.RegisterRoutesTo(groupV1)
+
+	v1Alert := &v1AlertEndpoints{apiContext}
+	v1Alert.RegisterRoutesTo(groupV1)
+
+	v1ScanProfile := &v1ScanProfileEndpoints{apiContext}
+	v1ScanProfile.RegisterRoutesTo(groupV1)
+
+	v1SnmpCredential := &v1SnmpCredentialEndpoints{apiContext}
+	v1SnmpCredential.RegisterRoutesTo(groupV1)
+
+	v1Import := &v1ImportEndpoints{apiContext}
+	v1Import.RegisterRoutesTo(groupV1)
+
+	v1Topology := &v1TopologyEndpoints{apiContext}
+	v1Topology.RegisterRoutesTo(groupV1)
+
+	v1ScanLease := &v1ScanLeaseEndpoints{apiContext}
+	v1ScanLease.RegisterRoutesTo(groupV1)
+
+	v1Token := &v1TokenEndpoints{apiContext}
+	v1Token.RegisterRoutesTo(groupV1)
 }
 
 func assertContentTypeJson(ctx *gin.Context) bool {
@@ -61,6 +95,15 @@ func extractHostFromPathParam(ctx *gin.Context, endpointCtx *types.ApiContext, p
 		return nil, false
 	}
 
+	hostAddress, err := database.CanonicalAddress(hostAddress)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, &gin.H{
+			"message": fmt.Sprintf("%s: %s", ErrInvalidParameter, paramName),
+			"error":   err.Error(),
+		})
+		return nil, false
+	}
+
 	host, err := endpointCtx.Querier.GetHost(ctx, hostAddress)
 	if err != nil {
 		logrus.Errorf("error fetching host from database (by address): %s", err)
@@ -74,6 +117,52 @@ func extractHostFromPathParam(ctx *gin.Context, endpointCtx *types.ApiContext, p
 	return &host, true
 }
 
+func extractNetworkFromPathParam(ctx *gin.Context, endpointCtx *types.ApiContext, paramName string) (*database.Network, bool) {
+	networkIdStr := ctx.Param(paramName)
+	if networkIdStr == "" {
+		logrus.Debugf("blank `%s` parameter provided", paramName)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, &gin.H{
+			"message": fmt.Sprintf("%s: %s", ErrInvalidParameter, paramName),
+		})
+		return nil, false
+	}
+
+	networkId, err := strconv.ParseInt(networkIdStr, 10, 0)
+	if err != nil {
+		logrus.Debugf("invalid `%s` parameter provided", paramName)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, &gin.H{
+			"message": fmt.Sprintf("%s: %s", ErrInvalidParameter, paramName),
+			"error":   err.Error(),
+		})
+		return nil, false
+	}
+
+	network, err := endpointCtx.Querier.GetNetworkById(ctx, networkId)
+	if err != nil {
+		logrus.Errorf("error fetching network from database (by id): %s", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+			"message": ErrDatabaseLookup,
+			"error":   err.Error(),
+		})
+		return nil, false
+	}
+
+	return &network, true
+}
+
+func parseIdPathParam(ctx *gin.Context, paramName string) (int64, bool) {
+	id, err := strconv.ParseInt(ctx.Param(paramName), 10, 0)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, &gin.H{
+			"message": fmt.Sprintf("%s: %s", ErrInvalidParameter, paramName),
+			"error":   err.Error(),
+		})
+		return 0, false
+	}
+
+	return id, true
+}
+
 func queryOr(ctx *gin.Context, key, defaultValue string) string {
 	value := ctx.Query(key)
 	if value == "" {
@@ -0,0 +1,127 @@
+package api
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"github.com/pirogoeth/apps/maparoon/auth"
+	"github.com/pirogoeth/apps/maparoon/database"
+	"github.com/pirogoeth/apps/maparoon/types"
+)
+
+const principalKey = "maparoon.principal"
+
+// authenticate resolves the request's bearer token to a principal, checks that
+// its role allows the request and records every write in the audit log
+func authenticate(apiContext *types.ApiContext) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if apiContext.Config.Auth.Disabled {
+			ctx.Set(principalKey, &auth.Principal{Name: "anonymous", Role: auth.RoleAdmin})
+			ctx.Next()
+			return
+		}
+
+		token, ok := strings.CutPrefix(ctx.GetHeader("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, &gin.H{
+				"message": ErrUnauthorized,
+			})
+			return
+		}
+
+		principal, err := resolvePrincipal(ctx, apiContext, token)
+		if err != nil {
+			logrus.Errorf("could not resolve api token: %s", err)
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+				"message": ErrDatabaseLookup,
+				"error":   err.Error(),
+			})
+			return
+		} else if principal == nil {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, &gin.H{
+				"message": ErrUnauthorized,
+			})
+			return
+		}
+
+		if !principal.Role.Allows(ctx.Request.Method, ctx.FullPath()) {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, &gin.H{
+				"message": ErrForbidden,
+				"error":   "role " + string(principal.Role) + " may not " + ctx.Request.Method + " " + ctx.FullPath(),
+			})
+			return
+		}
+
+		ctx.Set(principalKey, principal)
+		ctx.Next()
+
+		if ctx.Request.Method == http.MethodGet || ctx.Request.Method == http.MethodHead {
+			return
+		}
+
+		err = apiContext.Querier.CreateAuditLogEntry(ctx, database.CreateAuditLogEntryParams{
+			TokenName: principal.Name,
+			Role:      string(principal.Role),
+			Method:    ctx.Request.Method,
+			Path:      ctx.Request.URL.Path,
+			Status:    int64(ctx.Writer.Status()),
+		})
+		if err != nil {
+			logrus.Errorf("could not record audit log entry for %s: %s", principal.Name, err)
+		}
+	}
+}
+
+// resolvePrincipal returns the holder of token, or nil if the token isn't known.
+// The tokens from the configuration are checked before the named tokens.
+func resolvePrincipal(ctx *gin.Context, apiContext *types.ApiContext, token string) (*auth.Principal, error) {
+	cfg := apiContext.Config
+	staticTokens := []struct {
+		token     string
+		principal auth.Principal
+	}{
+		{cfg.Auth.AdminToken, auth.Principal{Name: "config:admin", Role: auth.RoleAdmin}},
+		{cfg.Worker.Token, auth.Principal{Name: "config:worker", Role: auth.RoleWorker}},
+		{cfg.Auth.ReadOnlyToken, auth.Principal{Name: "config:read-only", Role: auth.RoleReadOnly}},
+	}
+	for _, static := range staticTokens {
+		if static.token != "" && subtle.ConstantTimeCompare([]byte(static.token), []byte(token)) == 1 {
+			principal := static.principal
+			return &principal, nil
+		}
+	}
+
+	apiToken, err := apiContext.Querier.GetApiTokenByHash(ctx, auth.HashToken(token))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	role, err := auth.ParseRole(apiToken.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := apiContext.Querier.TouchApiToken(ctx, apiToken.ID); err != nil {
+		logrus.Warnf("could not update last use of api token %s: %s", apiToken.Name, err)
+	}
+
+	return &auth.Principal{Name: apiToken.Name, Role: role}, nil
+}
+
+// principalFrom returns the principal the request was authenticated as
+func principalFrom(ctx *gin.Context) *auth.Principal {
+	principal, ok := ctx.Get(principalKey)
+	if !ok {
+		return nil
+	}
+
+	return principal.(*auth.Principal)
+}
@@ -0,0 +1,130 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/pirogoeth/apps/maparoon/auth"
+	"github.com/pirogoeth/apps/maparoon/database"
+	"github.com/pirogoeth/apps/maparoon/types"
+)
+
+// setupTestAuthAPI is setupTestAPI with authentication enabled
+func setupTestAuthAPI(t *testing.T) (*gin.Engine, *types.ApiContext) {
+	router, apiContext := setupTestAPI(t)
+	apiContext.Config.Auth.Disabled = false
+	apiContext.Config.Auth.AdminToken = "admin-token"
+	apiContext.Config.Auth.ReadOnlyToken = "read-only-token"
+	apiContext.Config.Worker.Token = "worker-token"
+
+	return router, apiContext
+}
+
+// serveWithToken serves the request with token as its bearer token, and body
+// as its JSON body if given
+func serveWithToken(router *gin.Engine, method, path, token string, body ...any) *httptest.ResponseRecorder {
+	var data []byte
+	if len(body) > 0 {
+		data, _ = json.Marshal(body[0])
+	}
+
+	req := httptest.NewRequest(method, path, bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestAuthenticate(t *testing.T) {
+	router, apiContext := setupTestAuthAPI(t)
+	network := createTestNetwork(t, apiContext, "lan", "10.0.0.0")
+	scansPath := fmt.Sprintf("/v1/networks/%d/scans", network.ID)
+
+	named, _, err := auth.CreateToken(context.Background(), apiContext.Querier, "ci", auth.RoleReadOnly)
+	if err != nil {
+		t.Fatalf("could not create api token: %s", err)
+	}
+
+	cases := []struct {
+		name     string
+		method   string
+		path     string
+		token    string
+		expected int
+	}{
+		{"missing token", http.MethodGet, "/v1/networks", "", http.StatusUnauthorized},
+		{"unknown token", http.MethodGet, "/v1/networks", "mrn_unknown", http.StatusUnauthorized},
+		{"read-only read", http.MethodGet, "/v1/networks", "read-only-token", http.StatusOK},
+		{"read-only write", http.MethodPost, scansPath, "read-only-token", http.StatusForbidden},
+		{"named read-only write", http.MethodPost, scansPath, named, http.StatusForbidden},
+		{"worker write", http.MethodPost, scansPath, "worker-token", http.StatusCreated},
+		{"worker admin read", http.MethodGet, "/v1/audit", "worker-token", http.StatusForbidden},
+		{"admin admin read", http.MethodGet, "/v1/audit", "admin-token", http.StatusOK},
+	}
+	for _, c := range cases {
+		if w := serveWithToken(router, c.method, c.path, c.token); w.Code != c.expected {
+			t.Errorf("%s: expected %d, got %d: %s", c.name, c.expected, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestAuthenticateRecordsPrincipal(t *testing.T) {
+	router, apiContext := setupTestAuthAPI(t)
+	ctx := context.Background()
+	network := createTestNetwork(t, apiContext, "lan", "10.0.0.0")
+	if _, err := apiContext.Querier.CreateHost(ctx, database.CreateHostParams{NetworkID: network.ID, Address: "10.0.0.5"}); err != nil {
+		t.Fatalf("could not create host: %s", err)
+	}
+
+	w := serveWithToken(router, http.MethodPost, fmt.Sprintf("/v1/networks/%d/scans", network.ID), "worker-token")
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201 starting a scan, got %d: %s", w.Code, w.Body.String())
+	}
+
+	scans, err := apiContext.Querier.ListNetworkScansByNetwork(ctx, network.ID)
+	if err != nil {
+		t.Fatalf("could not list network scans: %s", err)
+	}
+	if len(scans) != 1 || scans[0].StartedBy != "config:worker" {
+		t.Fatalf("expected the scan to be started by config:worker, got %+v", scans)
+	}
+
+	w = serveWithToken(router, http.MethodPost, "/v1/hostscans", "worker-token", &types.CreateHostScansRequest{
+		NetworkId: network.ID,
+		ScanId:    scans[0].ID,
+		HostScans: []*types.HostScanDocument{{Address: "10.0.0.5"}},
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201 submitting host scans, got %d: %s", w.Code, w.Body.String())
+	}
+
+	hostScan, err := apiContext.Querier.GetHostScan(ctx, "10.0.0.5")
+	if err != nil {
+		t.Fatalf("could not get host scan: %s", err)
+	}
+	if hostScan.SubmittedBy != "config:worker" {
+		t.Errorf("expected the host scan to be submitted by config:worker, got %q", hostScan.SubmittedBy)
+	}
+
+	entries, err := apiContext.Querier.ListAuditLogEntriesByToken(ctx, database.ListAuditLogEntriesByTokenParams{
+		TokenName: "config:worker",
+		Limit:     10,
+	})
+	if err != nil {
+		t.Fatalf("could not list audit log: %s", err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("expected both writes to be audited, got %+v", entries)
+	}
+}
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pirogoeth/apps/maparoon/classify"
+	"github.com/pirogoeth/apps/maparoon/database"
+	"github.com/pirogoeth/apps/maparoon/oui"
+	"github.com/pirogoeth/apps/maparoon/types"
+)
+
+// oidSysDescr is SNMPv2-MIB's sysDescr.0
+const oidSysDescr = "1.3.6.1.2.1.1.1.0"
+
+// classifyHost resolves the host's MAC address vendor and classifies it from
+// the vendor and the scan, if there is one. A class or vendor that can't be
+// worked out doesn't replace a known one, and a class only replaces the stored
+// one when it scores higher.
+func classifyHost(ctx context.Context, querier *database.Queries, address string, doc *types.HostScanDocument) (*database.Host, error) {
+	host, err := querier.GetHost(ctx, address)
+	if err != nil {
+		return nil, fmt.Errorf("could not look up host: %w", err)
+	}
+
+	attributes, err := querier.ListHostAttributes(ctx, address)
+	if err != nil {
+		return nil, fmt.Errorf("could not list host attributes: %w", err)
+	}
+
+	facts := classify.Facts{Vendor: host.Vendor}
+	for _, attribute := range attributes {
+		if attribute.Key != types.MacAddressAttribute {
+			continue
+		}
+
+		if vendor := oui.Lookup(attribute.Value); vendor != "" {
+			facts.Vendor = vendor
+		}
+	}
+
+	if doc != nil {
+		addScanFacts(&facts, doc)
+	}
+
+	// A result from fewer facts, like the vendor alone after a scan found
+	// the OS, scores lower and doesn't replace the class
+	class, score := host.DeviceClass, host.DeviceClassScore
+	if result := classify.Classify(classify.DefaultRules, facts); result.Class != "" && int64(result.Score) > score {
+		class, score = result.Class, int64(result.Score)
+	}
+
+	if facts.Vendor == host.Vendor && class == host.DeviceClass && score == host.DeviceClassScore {
+		return &host, nil
+	}
+
+	host, err = querier.SetHostClassification(ctx, database.SetHostClassificationParams{
+		Address:          address,
+		Vendor:           facts.Vendor,
+		DeviceClass:      class,
+		DeviceClassScore: score,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not store host classification: %w", err)
+	}
+
+	return &host, nil
+}
+
+func addScanFacts(facts *classify.Facts, doc *types.HostScanDocument) {
+	if doc.Nmap != nil {
+		details := doc.Nmap.HostDetails
+		for _, match := range details.Os.OsMatches {
+			facts.OsMatches = append(facts.OsMatches, match.Name)
+			for _, class := range match.OsClasses {
+				facts.OsMatches = append(facts.OsMatches, class.Vendor+" "+class.OsFamily)
+				if class.Type != "" {
+					facts.DeviceTypes = append(facts.DeviceTypes, class.Type)
+				}
+			}
+		}
+
+		for _, port := range details.Ports {
+			if port.State.State != "open" {
+				continue
+			}
+
+			facts.OpenPorts = append(facts.OpenPorts, port.PortId)
+			if port.Service.DeviceType != "" {
+				facts.DeviceTypes = append(facts.DeviceTypes, port.Service.DeviceType)
+			}
+		}
+	}
+
+	if doc.Snmp != nil {
+		for _, measurement := range doc.Snmp.Measurements {
+			if strings.TrimPrefix(measurement.Oid, ".") == oidSysDescr {
+				facts.SysDescr = measurement.Value
+			}
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"context"
+	"testing"
+
+	nmap "github.com/adifire/go-nmap"
+
+	"github.com/pirogoeth/apps/maparoon/classify"
+	"github.com/pirogoeth/apps/maparoon/database"
+	"github.com/pirogoeth/apps/maparoon/types"
+)
+
+func TestClassifyHostKeepsHigherScoringClass(t *testing.T) {
+	_, apiContext := setupTestAPI(t)
+	ctx := context.Background()
+	network := createTestNetwork(t, apiContext, "lan", "10.0.0.0")
+
+	if _, err := apiContext.Querier.CreateHost(ctx, database.CreateHostParams{NetworkID: network.ID, Address: "10.0.0.1"}); err != nil {
+		t.Fatalf("could not create host: %s", err)
+	}
+	_, err := apiContext.Querier.SetHostAttribute(ctx, database.SetHostAttributeParams{
+		Address: "10.0.0.1",
+		Key:     types.MacAddressAttribute,
+		Value:   "00:00:0c:11:22:33",
+	})
+	if err != nil {
+		t.Fatalf("could not set mac address: %s", err)
+	}
+
+	// nmap finds a router, its device type outscores the Cisco vendor's switch
+	doc := &types.HostScanDocument{
+		Address: "10.0.0.1",
+		Network: network,
+		Nmap:    &types.NmapHostScanDocument{},
+	}
+	doc.Nmap.HostDetails.Ports = []nmap.Port{{PortId: 22}}
+	doc.Nmap.HostDetails.Ports[0].State.State = "open"
+	doc.Nmap.HostDetails.Ports[0].Service.DeviceType = "router"
+
+	host, err := classifyHost(ctx, apiContext.Querier, "10.0.0.1", doc)
+	if err != nil {
+		t.Fatalf("could not classify host: %s", err)
+	}
+	if host.DeviceClass != classify.ClassRouter {
+		t.Fatalf("expected the scan to classify a router, got %q", host.DeviceClass)
+	}
+
+	// Learning the MAC address again only knows the vendor
+	host, err = classifyHost(ctx, apiContext.Querier, "10.0.0.1", nil)
+	if err != nil {
+		t.Fatalf("could not classify host: %s", err)
+	}
+	if host.DeviceClass != classify.ClassRouter || host.Vendor == "" {
+		t.Errorf("expected the router class to be kept with the vendor, got %q from %q", host.DeviceClass, host.Vendor)
+	}
+}
@@ -0,0 +1,99 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/pirogoeth/apps/maparoon/database"
+	"github.com/pirogoeth/apps/maparoon/scandiff"
+	"github.com/pirogoeth/apps/maparoon/types"
+)
+
+// markHostScanSeen records that the scan saw the host and its open ports, both
+// the ones host discovery found and the ones nmap found
+func markHostScanSeen(ctx context.Context, querier *database.Queries, doc *types.HostScanDocument, seenAt int64) error {
+	err := querier.MarkHostSeen(ctx, database.MarkHostSeenParams{
+		SeenAt:  seenAt,
+		Address: doc.Address,
+	})
+	if err != nil {
+		return fmt.Errorf("could not mark host seen: %w", err)
+	}
+
+	ports := make(map[types.DiscoveredPort]bool)
+	for _, port := range doc.OpenPorts {
+		ports[port] = true
+	}
+	for _, port := range scandiff.OpenPorts(doc) {
+		ports[types.DiscoveredPort{Port: int64(port.Port), Protocol: port.Protocol}] = true
+	}
+
+	for port := range ports {
+		err := querier.MarkHostPortSeen(ctx, database.MarkHostPortSeenParams{
+			SeenAt:   seenAt,
+			Address:  doc.Address,
+			Port:     port.Port,
+			Protocol: port.Protocol,
+		})
+		if err != nil {
+			return fmt.Errorf("could not mark port %d/%s seen: %w", port.Port, port.Protocol, err)
+		}
+	}
+
+	return nil
+}
+
+// expireHosts counts a missed scan against each of the network's hosts that
+// weren't seen since scannedAt, then marks the hosts that have expired as gone
+// or, if configured, deletes them. The deleted hosts are returned, their
+// indexed scans are left for the caller to remove.
+func expireHosts(ctx context.Context, querier *database.Queries, config *types.Config, network database.Network, scannedAt int64) ([]string, error) {
+	err := querier.MarkNetworkHostsMissed(ctx, database.MarkNetworkHostsMissedParams{
+		NetworkID: network.ID,
+		LastSeen:  scannedAt,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not count missed scans: %w", err)
+	}
+
+	cfg := config.Hosts
+	if cfg.ExpireAfterMissedScans <= 0 && cfg.ExpireAfter <= 0 {
+		return nil, nil
+	}
+
+	hosts, err := querier.ListHostsByNetwork(ctx, network.ID)
+	if err != nil {
+		return nil, fmt.Errorf("could not list hosts: %w", err)
+	}
+
+	deleted := make([]string, 0)
+	now := time.Unix(scannedAt, 0)
+	for _, host := range hosts {
+		if host.IsGone() || !host.Expired(cfg.ExpireAfterMissedScans, cfg.ExpireAfter, now) {
+			continue
+		}
+
+		if !cfg.DeleteExpired {
+			logrus.Infof("host %s in network %s has expired, marking it gone", host.Address, network.Name)
+			err := querier.MarkHostGone(ctx, database.MarkHostGoneParams{
+				GoneAt:  scannedAt,
+				Address: host.Address,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("could not mark host %s gone: %w", host.Address, err)
+			}
+			continue
+		}
+
+		logrus.Infof("host %s in network %s has expired, deleting it", host.Address, network.Name)
+		if err := querier.DeleteHost(ctx, host.Address); err != nil {
+			return nil, fmt.Errorf("could not delete host %s: %w", host.Address, err)
+		}
+		deleted = append(deleted, host.Address)
+	}
+
+	return deleted, nil
+}
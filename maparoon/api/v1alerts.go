@@ -0,0 +1,294 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pirogoeth/apps/maparoon/alerting"
+	"github.com/pirogoeth/apps/maparoon/database"
+	"github.com/pirogoeth/apps/maparoon/types"
+	"github.com/sirupsen/logrus"
+)
+
+type v1AlertEndpoints struct {
+	*types.ApiContext
+}
+
+func (e *v1AlertEndpoints) RegisterRoutesTo(router *gin.RouterGroup) {
+	router.GET("/alertrules", e.listAlertRules)
+	router.POST("/alertrules", e.createAlertRule)
+	router.GET("/alertrules/:id", e.getAlertRule)
+	router.PUT("/alertrules/:id", e.updateAlertRule)
+	router.DELETE("/alertrules/:id", e.deleteAlertRule)
+	router.GET("/alerts", e.listAlerts)
+	router.POST("/alerts/:id/ack", e.acknowledgeAlert)
+}
+
+func newAlertEvaluator(apiContext *types.ApiContext) *alerting.Evaluator {
+	cfg := apiContext.Config.Alerting
+	notifier := alerting.NewNotifier(&alerting.NotifierOpts{
+		WebhookTimeout: cfg.WebhookTimeout,
+		Smtp: alerting.SmtpOpts{
+			Host:     cfg.Smtp.Host,
+			Port:     cfg.Smtp.Port,
+			Username: cfg.Smtp.Username,
+			Password: cfg.Smtp.Password,
+			From:     cfg.Smtp.From,
+			Timeout:  cfg.Smtp.Timeout,
+		},
+	})
+
+	return alerting.NewEvaluator(apiContext.Querier, notifier)
+}
+
+func (e *v1AlertEndpoints) listAlertRules(ctx *gin.Context) {
+	rules, err := e.Querier.ListAlertRules(ctx)
+	if err != nil {
+		logrus.Errorf("could not list alert rules: %s", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+			"message": ErrDatabaseLookup,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	if rules == nil {
+		rules = []database.AlertRule{}
+	}
+
+	ctx.JSON(http.StatusOK, &gin.H{
+		"alert_rules": rules,
+	})
+}
+
+func (e *v1AlertEndpoints) createAlertRule(ctx *gin.Context) {
+	if ok := assertContentTypeJson(ctx); !ok {
+		return
+	}
+
+	ruleParams := database.CreateAlertRuleParams{
+		Enabled: true,
+	}
+	if err := ctx.BindJSON(&ruleParams); err != nil {
+		logrus.Errorf("failed to bind alert rule to database.CreateAlertRuleParams: %s", err)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, &gin.H{
+			"message": ErrFailedToBind,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	ruleParams.Protocol = strings.ToLower(ruleParams.Protocol)
+	if err := alerting.ValidateRule(ruleParams.Kind, ruleParams.Protocol); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, &gin.H{
+			"message": ErrInvalidParameter,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	rule, err := e.Querier.CreateAlertRule(ctx, ruleParams)
+	if err != nil {
+		logrus.Errorf("failed to create alert rule in database: %s", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+			"message": ErrDatabaseInsert,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &gin.H{
+		"message":     "Successfully created alert rule",
+		"alert_rules": []database.AlertRule{rule},
+	})
+}
+
+func (e *v1AlertEndpoints) getAlertRule(ctx *gin.Context) {
+	rule, ok := e.getAlertRuleByPathParam(ctx)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &gin.H{
+		"alert_rules": []*database.AlertRule{rule},
+	})
+}
+
+func (e *v1AlertEndpoints) updateAlertRule(ctx *gin.Context) {
+	rule, ok := e.getAlertRuleByPathParam(ctx)
+	if !ok {
+		return
+	}
+
+	ruleUpdate := database.UpdateAlertRuleParams{
+		Name:        rule.Name,
+		Kind:        rule.Kind,
+		NetworkID:   rule.NetworkID,
+		Port:        rule.Port,
+		Protocol:    rule.Protocol,
+		Measurement: rule.Measurement,
+		WebhookUrl:  rule.WebhookUrl,
+		EmailTo:     rule.EmailTo,
+		Enabled:     rule.Enabled,
+	}
+	if err := ctx.BindJSON(&ruleUpdate); err != nil {
+		logrus.Warnf("failed to bind alert rule to database.UpdateAlertRuleParams: %s", err)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, &gin.H{
+			"message": ErrFailedToBind,
+			"error":   err.Error(),
+		})
+		return
+	}
+	ruleUpdate.ID = rule.ID
+
+	ruleUpdate.Protocol = strings.ToLower(ruleUpdate.Protocol)
+	if err := alerting.ValidateRule(ruleUpdate.Kind, ruleUpdate.Protocol); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, &gin.H{
+			"message": ErrInvalidParameter,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	newRule, err := e.Querier.UpdateAlertRule(ctx, ruleUpdate)
+	if err != nil {
+		logrus.Errorf("failed to update alert rule: %s", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+			"message": ErrDatabaseUpdate,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &gin.H{
+		"message":     "Alert rule updated",
+		"alert_rules": []database.AlertRule{newRule},
+	})
+}
+
+func (e *v1AlertEndpoints) deleteAlertRule(ctx *gin.Context) {
+	rule, ok := e.getAlertRuleByPathParam(ctx)
+	if !ok {
+		return
+	}
+
+	if err := e.Querier.DeleteAlertRule(ctx, rule.ID); err != nil {
+		logrus.Errorf("failed to delete alert rule: %s", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+			"message": ErrDatabaseDelete,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &gin.H{
+		"message":     "Alert rule deleted",
+		"alert_rules": []*database.AlertRule{rule},
+	})
+}
+
+// listAlerts lists every alert, or only the unacknowledged ones with `state=open`
+func (e *v1AlertEndpoints) listAlerts(ctx *gin.Context) {
+	var alerts []database.Alert
+	var err error
+	switch state := ctx.Query("state"); state {
+	case "":
+		alerts, err = e.Querier.ListAlerts(ctx)
+	case "open":
+		alerts, err = e.Querier.ListOpenAlerts(ctx)
+	default:
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, &gin.H{
+			"message": fmt.Sprintf("%s: %s", ErrInvalidParameter, "state"),
+		})
+		return
+	}
+	if err != nil {
+		logrus.Errorf("could not list alerts: %s", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+			"message": ErrDatabaseLookup,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	if alerts == nil {
+		alerts = []database.Alert{}
+	}
+
+	ctx.JSON(http.StatusOK, &gin.H{
+		"alerts": alerts,
+	})
+}
+
+// acknowledgeAlert closes an alert. If the change it was raised for is seen
+// again, a new alert is raised and notified.
+func (e *v1AlertEndpoints) acknowledgeAlert(ctx *gin.Context) {
+	alertId, ok := parseIdPathParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	alert, err := e.Querier.GetAlert(ctx, alertId)
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.AbortWithStatusJSON(http.StatusNotFound, &gin.H{
+			"message": fmt.Sprintf("alert not found: %d", alertId),
+		})
+		return
+	} else if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+			"message": ErrDatabaseLookup,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	if alert.AcknowledgedAt != -1 {
+		ctx.AbortWithStatusJSON(http.StatusConflict, &gin.H{
+			"message": fmt.Sprintf("alert already acknowledged: %d", alertId),
+		})
+		return
+	}
+
+	alert, err = e.Querier.AcknowledgeAlert(ctx, alertId)
+	if err != nil {
+		logrus.Errorf("failed to acknowledge alert: %s", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+			"message": ErrDatabaseUpdate,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &gin.H{
+		"message": "Alert acknowledged",
+		"alerts":  []database.Alert{alert},
+	})
+}
+
+func (e *v1AlertEndpoints) getAlertRuleByPathParam(ctx *gin.Context) (*database.AlertRule, bool) {
+	ruleId, ok := parseIdPathParam(ctx, "id")
+	if !ok {
+		return nil, false
+	}
+
+	rule, err := e.Querier.GetAlertRule(ctx, ruleId)
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.AbortWithStatusJSON(http.StatusNotFound, &gin.H{
+			"message": fmt.Sprintf("alert rule not found: %d", ruleId),
+		})
+		return nil, false
+	} else if err != nil {
+		logrus.Errorf("error fetching alert rule from database: %s", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+			"message": ErrDatabaseLookup,
+			"error":   err.Error(),
+		})
+		return nil, false
+	}
+
+	return &rule, true
+}
@@ -5,8 +5,13 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/pirogoeth/apps/maparoon/classify"
 	"github.com/pirogoeth/apps/maparoon/database"
 	"github.com/pirogoeth/apps/maparoon/types"
 	"github.com/sirupsen/logrus"
@@ -22,10 +27,61 @@ func (e *v1HostEndpoints) RegisterRoutesTo(router *gin.RouterGroup) {
 	router.POST("/hosts", e.createHost)
 	router.PUT("/hosts/:address", e.updateHost)
 	router.DELETE("/hosts/:address", e.deleteHost)
+	router.GET("/hosts/:address/attributes", e.listHostAttributes)
+	router.PUT("/hosts/:address/attributes/:key", e.setHostAttribute)
+	router.DELETE("/hosts/:address/attributes/:key", e.deleteHostAttribute)
 }
 
+// listHosts lists all hosts, or the hosts of one device class with `class`.
+// `stale` only lists hosts that haven't been seen for the given duration, and
+// `gone` only the hosts that have, or haven't, expired.
 func (e *v1HostEndpoints) listHosts(ctx *gin.Context) {
-	hosts, err := e.Querier.ListHosts(ctx)
+	var (
+		hosts []database.Host
+		err   error
+	)
+
+	var staleBefore int64
+	if stale := ctx.Query("stale"); stale != "" {
+		staleFor, err := time.ParseDuration(stale)
+		if err != nil || staleFor <= 0 {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, &gin.H{
+				"message": fmt.Sprintf("%s: %s", ErrInvalidParameter, "stale"),
+				"error":   "expected a positive duration, e.g. 24h",
+			})
+			return
+		}
+
+		staleBefore = time.Now().Add(-staleFor).Unix()
+	}
+
+	var gone *bool
+	if goneParam := ctx.Query("gone"); goneParam != "" {
+		value, err := strconv.ParseBool(goneParam)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, &gin.H{
+				"message": fmt.Sprintf("%s: %s", ErrInvalidParameter, "gone"),
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		gone = &value
+	}
+
+	if class := ctx.Query("class"); class != "" {
+		if !slices.Contains(classify.Classes, class) {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, &gin.H{
+				"message": fmt.Sprintf("%s: %s", ErrInvalidParameter, "class"),
+				"error":   fmt.Sprintf("expected one of %s", strings.Join(classify.Classes, ", ")),
+			})
+			return
+		}
+
+		hosts, err = e.Querier.ListHostsByDeviceClass(ctx, class)
+	} else {
+		hosts, err = e.Querier.ListHosts(ctx)
+	}
 	if err != nil {
 		logrus.Errorf("could not list hosts: %s", err)
 		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
@@ -35,6 +91,14 @@ func (e *v1HostEndpoints) listHosts(ctx *gin.Context) {
 		return
 	}
 
+	hosts = slices.DeleteFunc(hosts, func(host database.Host) bool {
+		if staleBefore != 0 && host.LastSeen >= staleBefore {
+			return true
+		}
+
+		return gone != nil && host.IsGone() != *gone
+	})
+
 	if hosts == nil {
 		hosts = []database.Host{}
 	}
@@ -45,7 +109,15 @@ func (e *v1HostEndpoints) listHosts(ctx *gin.Context) {
 }
 
 func (e *v1HostEndpoints) getHost(ctx *gin.Context) {
-	address := ctx.Query("address")
+	address, err := database.CanonicalAddress(ctx.Query("address"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, &gin.H{
+			"message": fmt.Sprintf("%s: %s", ErrInvalidParameter, "address"),
+			"error":   err.Error(),
+		})
+		return
+	}
+
 	host, err := e.Querier.GetHost(ctx, address)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -84,7 +156,17 @@ func (e *v1HostEndpoints) createHost(ctx *gin.Context) {
 		return
 	}
 
-	_, err := e.Querier.GetHost(ctx, hostParams.Address)
+	address, err := database.CanonicalAddress(hostParams.Address)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, &gin.H{
+			"message": fmt.Sprintf("%s: %s", ErrInvalidParameter, "address"),
+			"error":   err.Error(),
+		})
+		return
+	}
+	hostParams.Address = address
+
+	_, err = e.Querier.GetHost(ctx, hostParams.Address)
 	if err != nil && !errors.Is(err, sql.ErrNoRows) {
 		logrus.Errorf("failed to check if host exists: %s", err)
 		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
@@ -173,8 +255,108 @@ func (e *v1HostEndpoints) deleteHost(ctx *gin.Context) {
 		return
 	}
 
+	// The host scan is only removed from the index once the host is gone, so
+	// a host that couldn't be deleted can still be found
+	logrus.Debugf("check-out searcher handle")
+	handle := e.Searcher.SearcherHandle()
+	defer handle.Close()
+
+	if err := deleteHostScans(ctx, e.ApiContext, handle.Index(), host.Address); err != nil {
+		logrus.Errorf("failed to delete host scan of host: %s", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+			"message": ErrDatabaseDelete,
+			"error":   err.Error(),
+		})
+		return
+	}
+
 	ctx.JSON(http.StatusOK, &gin.H{
 		"message": "Host deleted",
 		"hosts":   []*database.Host{host},
 	})
 }
+
+func (e *v1HostEndpoints) listHostAttributes(ctx *gin.Context) {
+	host, ok := extractHostFromPathParam(ctx, e.ApiContext, "address")
+	if !ok {
+		return
+	}
+
+	attributes, err := e.Querier.ListHostAttributes(ctx, host.Address)
+	if err != nil {
+		logrus.Errorf("could not list host attributes: %s", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+			"message": ErrDatabaseLookup,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	if attributes == nil {
+		attributes = []database.HostAttribute{}
+	}
+
+	ctx.JSON(http.StatusOK, &gin.H{
+		"attributes": attributes,
+	})
+}
+
+func (e *v1HostEndpoints) setHostAttribute(ctx *gin.Context) {
+	host, ok := extractHostFromPathParam(ctx, e.ApiContext, "address")
+	if !ok {
+		return
+	}
+
+	req := types.SetHostAttributeRequest{}
+	if err := ctx.BindJSON(&req); err != nil {
+		logrus.Warnf("failed to bind request to types.SetHostAttributeRequest: %s", err)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, &gin.H{
+			"message": ErrFailedToBind,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	attribute, err := e.Querier.SetHostAttribute(ctx, database.SetHostAttributeParams{
+		Address: host.Address,
+		Key:     ctx.Param("key"),
+		Value:   req.Value,
+	})
+	if err != nil {
+		logrus.Errorf("failed to set host attribute: %s", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+			"message": ErrDatabaseUpdate,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &gin.H{
+		"message":    "Host attribute set",
+		"attributes": []database.HostAttribute{attribute},
+	})
+}
+
+func (e *v1HostEndpoints) deleteHostAttribute(ctx *gin.Context) {
+	host, ok := extractHostFromPathParam(ctx, e.ApiContext, "address")
+	if !ok {
+		return
+	}
+
+	err := e.Querier.DeleteHostAttribute(ctx, database.DeleteHostAttributeParams{
+		Address: host.Address,
+		Key:     ctx.Param("key"),
+	})
+	if err != nil {
+		logrus.Errorf("failed to delete host attribute: %s", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+			"message": ErrDatabaseDelete,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &gin.H{
+		"message": "Host attribute deleted",
+	})
+}
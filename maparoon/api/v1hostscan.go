@@ -1,16 +1,23 @@
 package api
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
+	"github.com/blevesearch/bleve"
 	"github.com/blevesearch/bleve/search"
 	"github.com/gin-gonic/gin"
 	"github.com/pirogoeth/apps/maparoon/database"
+	"github.com/pirogoeth/apps/maparoon/hostindex"
+	"github.com/pirogoeth/apps/maparoon/scandiff"
 	"github.com/pirogoeth/apps/maparoon/types"
+	"github.com/pirogoeth/apps/maparoon/vulns"
 	"github.com/sirupsen/logrus"
 )
 
@@ -20,19 +27,142 @@ type v1HostScanEndpoints struct {
 
 func (e *v1HostScanEndpoints) RegisterRoutesTo(router *gin.RouterGroup) {
 	router.GET("/hostscan", e.getHostScan)
+	router.GET("/hostscan/history", e.getHostScanHistory)
 	router.GET("/hostscans/search", e.searchHostScans)
 	router.POST("/hostscans", e.createHostScans)
 	router.DELETE("/hostscans/:address", e.deleteHostScan)
 }
 
-// getHostScan retrieves a host scan by its address
+// getHostScan retrieves the latest host scan of a host by its address
 func (e *v1HostScanEndpoints) getHostScan(ctx *gin.Context) {
-	ctx.AbortWithStatusJSON(http.StatusNotImplemented, &gin.H{
-		"message": "not implemented",
+	address, err := database.CanonicalAddress(ctx.Query("address"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, &gin.H{
+			"message": fmt.Sprintf("%s: %s", ErrInvalidParameter, "address"),
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	hostScan, err := e.Querier.GetHostScan(ctx, address)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Hosts indexed before host scans were stored are only in the index
+		e.getIndexedHostScan(ctx, address)
+		return
+	} else if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+			"message": ErrDatabaseLookup,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	doc := &types.HostScanDocument{}
+	if err := json.Unmarshal([]byte(hostScan.Document), doc); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+			"message": ErrDatabaseLookup,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &gin.H{
+		"host_scans": []*types.HostScanDocument{doc},
+		"indexed_at": hostScan.IndexedAt,
+	})
+}
+
+// getIndexedHostScan responds with the indexed fields of a host scan that
+// isn't stored
+func (e *v1HostScanEndpoints) getIndexedHostScan(ctx *gin.Context, address string) {
+	logrus.Debugf("check-out searcher handle")
+	handle := e.Searcher.SearcherHandle()
+	defer handle.Close()
+
+	fields, err := indexedHostScan(handle.Index(), address)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+			"message": ErrDatabaseLookup,
+			"error":   err.Error(),
+		})
+		return
+	} else if fields == nil {
+		ctx.AbortWithStatusJSON(http.StatusNotFound, &gin.H{
+			"message": fmt.Sprintf("host scan not found with address: %s", address),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &gin.H{
+		"host_scans": []map[string]interface{}{fields},
+	})
+}
+
+type hostScanHistoryEntry struct {
+	ScanId     int64                   `json:"scan_id"`
+	StartedAt  int64                   `json:"started_at"`
+	FinishedAt int64                   `json:"finished_at"`
+	HostScan   *types.HostScanDocument `json:"host_scan"`
+}
+
+// getHostScanHistory lists the host scans a host's network scans recorded,
+// newest first, up to `limit`
+func (e *v1HostScanEndpoints) getHostScanHistory(ctx *gin.Context) {
+	address, err := database.CanonicalAddress(ctx.Query("address"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, &gin.H{
+			"message": fmt.Sprintf("%s: %s", ErrInvalidParameter, "address"),
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	limit, err := strconv.ParseInt(queryOr(ctx, "limit", "10"), 10, 32)
+	if err != nil || limit <= 0 {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, &gin.H{
+			"message": fmt.Sprintf("%s: %s", ErrInvalidParameter, "limit"),
+		})
+		return
+	}
+
+	snapshots, err := e.Querier.ListHostScanHistory(ctx, database.ListHostScanHistoryParams{
+		Address: address,
+		Limit:   limit,
+	})
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+			"message": ErrDatabaseLookup,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	history := make([]hostScanHistoryEntry, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		doc := &types.HostScanDocument{}
+		if err := json.Unmarshal([]byte(snapshot.Document), doc); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+				"message": ErrDatabaseLookup,
+				"error":   fmt.Sprintf("could not decode host scan of scan %d: %s", snapshot.ScanID, err),
+			})
+			return
+		}
+
+		history = append(history, hostScanHistoryEntry{
+			ScanId:     snapshot.ScanID,
+			StartedAt:  snapshot.StartedAt,
+			FinishedAt: snapshot.FinishedAt,
+			HostScan:   doc,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, &gin.H{
+		"history": history,
 	})
 }
 
-// searchHostScans retrieves host scans by a search query (Bleve syntax)
+// searchHostScans retrieves host scans by a search query (Bleve syntax). Each
+// `facet` counts the matching host scans by one of hostindex.Facets.
 func (e *v1HostScanEndpoints) searchHostScans(ctx *gin.Context) {
 	query := ctx.Query("query")
 
@@ -63,6 +193,28 @@ func (e *v1HostScanEndpoints) searchHostScans(ctx *gin.Context) {
 		sortOrder = search.ParseSortOrderStrings(ctx.QueryArray("sort"))
 	}
 
+	facetSize, err := strconv.ParseInt(queryOr(ctx, "facet_size", "10"), 10, 32)
+	if err != nil || facetSize <= 0 {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, &gin.H{
+			"message": fmt.Sprintf("%s: %s", ErrInvalidParameter, "facet_size"),
+		})
+		return
+	}
+
+	facets := make(bleve.FacetsRequest)
+	for _, name := range ctx.QueryArray("facet") {
+		field, ok := hostindex.Facets[name]
+		if !ok {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, &gin.H{
+				"message": fmt.Sprintf("%s: %s", ErrInvalidParameter, "facet"),
+				"error":   fmt.Sprintf("unknown facet: %s", name),
+			})
+			return
+		}
+
+		facets[name] = bleve.NewFacetRequest(field, int(facetSize))
+	}
+
 	logrus.Debugf("check-out searcher handle")
 	handle := e.Searcher.SearcherHandle()
 	defer handle.Close()
@@ -72,6 +224,9 @@ func (e *v1HostScanEndpoints) searchHostScans(ctx *gin.Context) {
 	searchReq.Explain = explain
 	searchReq.Sort = sortOrder
 	searchReq.Size = int(limit)
+	if len(facets) > 0 {
+		searchReq.Facets = facets
+	}
 
 	results, err := handle.Search(searchReq)
 	if err != nil {
@@ -109,8 +264,48 @@ func (e *v1HostScanEndpoints) createHostScans(ctx *gin.Context) {
 		return
 	}
 
-	// Check that hostscans were provided
-	if len(req.HostScans) == 0 {
+	// When the host scans complete a network scan, the scan must belong to the
+	// network and not be finished yet
+	var scan *database.NetworkScan
+	if req.ScanId != 0 {
+		networkScan, err := e.Querier.GetNetworkScan(ctx, database.GetNetworkScanParams{
+			ID:        req.ScanId,
+			NetworkID: network.ID,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, &gin.H{
+				"message": fmt.Sprintf("network scan not found in network: %d", req.ScanId),
+			})
+			return
+		} else if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+				"message": fmt.Sprintf("%s: %s", ErrDatabaseLookup, err.Error()),
+			})
+			return
+		}
+
+		if networkScan.FinishedAt != -1 {
+			ctx.AbortWithStatusJSON(http.StatusConflict, &gin.H{
+				"message": fmt.Sprintf("network scan already finished: %d", req.ScanId),
+			})
+			return
+		}
+		if networkScan.FailedAt != -1 {
+			ctx.AbortWithStatusJSON(http.StatusConflict, &gin.H{
+				"message": fmt.Sprintf("network scan has failed: %d", req.ScanId),
+			})
+			return
+		}
+
+		if ok := checkScanLease(ctx, e.ApiContext, network.ID, networkScan.ID, req.WorkerId); !ok {
+			return
+		}
+
+		scan = &networkScan
+	}
+
+	// Check that hostscans were provided. A network scan may find no hosts.
+	if len(req.HostScans) == 0 && scan == nil {
 		ctx.AbortWithStatusJSON(http.StatusBadRequest, &gin.H{
 			"message": fmt.Sprintf("%s: %s", ErrInvalidParameter, "host_scans"),
 		})
@@ -119,7 +314,17 @@ func (e *v1HostScanEndpoints) createHostScans(ctx *gin.Context) {
 
 	// Each host within the hostscans details MUST belong to the referenced network
 	for _, hostScan := range req.HostScans {
-		_, err := e.Querier.GetHostWithNetwork(ctx, database.GetHostWithNetworkParams{
+		address, err := database.CanonicalAddress(hostScan.Address)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, &gin.H{
+				"message": fmt.Sprintf("%s: %s", ErrInvalidParameter, "address"),
+				"error":   err.Error(),
+			})
+			return
+		}
+		hostScan.Address = address
+
+		_, err = e.Querier.GetHostWithNetwork(ctx, database.GetHostWithNetworkParams{
 			NetworkID: network.ID,
 			Address:   hostScan.Address,
 		})
@@ -137,39 +342,291 @@ func (e *v1HostScanEndpoints) createHostScans(ctx *gin.Context) {
 		}
 	}
 
-	// Index the hostscans
-	logrus.Debugf("check-out searcher handle")
-	handle := e.Searcher.SearcherHandle()
-	defer handle.Close()
+	logrus.Infof("indexing %d host scans for network %s, submitted by %s", len(req.HostScans), network.Name, principalFrom(ctx).Name)
 
-	batch := handle.Index().NewBatch()
+	docs := make([]*types.HostScanDocument, 0, len(req.HostScans))
 	for _, hostScan := range req.HostScans {
-		batch.Index(hostScan.Address, &types.HostScanDocument{
+		docs = append(docs, &types.HostScanDocument{
 			Address: hostScan.Address,
 			Network: network,
 			Nmap:    hostScan.Nmap,
 			Snmp:    hostScan.Snmp,
+			ScanId:  req.ScanId,
+
+			OpenPorts: hostScan.OpenPorts,
+		})
+	}
+
+	// The host scans are stored in one transaction, so a failure doesn't leave
+	// the scan half recorded. They're indexed once they're stored.
+	var (
+		finished *database.NetworkScan
+		expired  []string
+	)
+	err = e.Database.InTx(ctx, func(querier *database.Queries) error {
+		var err error
+		finished, expired, err = e.storeHostScans(ctx, querier, network, scan, docs, principalFrom(ctx).Name)
+		return err
+	})
+	if err != nil {
+		logrus.Errorf("failed to store host scans of network %s: %s", network.Name, err)
+		if scan != nil {
+			failNetworkScan(ctx, e.ApiContext, scan, err)
+		}
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+			"message": ErrDatabaseInsert,
+			"error":   err.Error(),
 		})
+		return
+	}
+
+	logrus.Debugf("check-out searcher handle")
+	handle := e.Searcher.SearcherHandle()
+	defer handle.Close()
+
+	batch := handle.Index().NewBatch()
+	for _, doc := range docs {
+		batch.Index(doc.Address, doc)
 	}
 
 	// Commit the batch
-	logrus.Debugf("committing batch with %d host scans", len(req.HostScans))
+	logrus.Debugf("committing batch with %d host scans", len(docs))
 	if err := handle.Index().Batch(batch); err != nil {
+		logrus.Errorf("failed to index host scans of network %s: %s", network.Name, err)
 		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
 			"message": ErrDatabaseInsert,
 			"error":   err.Error(),
 		})
+		return
+	}
 
+	if err := deleteHostScans(ctx, e.ApiContext, handle.Index(), expired...); err != nil {
+		logrus.Errorf("failed to delete host scans of expired hosts: %s", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+			"message": ErrDatabaseDelete,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	if finished == nil {
+		ctx.JSON(http.StatusCreated, &gin.H{
+			"message": fmt.Sprintf("successfully indexed hostscans: %d operations", batch.Size()),
+		})
 		return
 	}
 
 	ctx.JSON(http.StatusCreated, &gin.H{
 		"message": fmt.Sprintf("successfully indexed hostscans: %d operations", batch.Size()),
+		"scans":   []database.NetworkScan{*finished},
+	})
+}
+
+// storeHostScans stores the host scans and everything derived from them with
+// querier, recording who submitted them. If they complete a network scan, the network's hosts that weren't
+// found are expired and the scan is finished, which is returned along with
+// the expired hosts that were deleted.
+func (e *v1HostScanEndpoints) storeHostScans(ctx context.Context, querier *database.Queries, network database.Network, scan *database.NetworkScan, docs []*types.HostScanDocument, submittedBy string) (*database.NetworkScan, []string, error) {
+	// The tables are stored first, ARP entries teach other hosts their MAC
+	// address, which classifies them again before they're indexed
+	for _, doc := range docs {
+		if err := storeSnmpTables(ctx, querier, doc); err != nil {
+			return nil, nil, fmt.Errorf("could not store snmp tables of %s: %w", doc.Address, err)
+		}
+	}
+
+	seenAt := time.Now().Unix()
+	documents := make([][]byte, 0, len(docs))
+	for _, doc := range docs {
+		if host, err := classifyHost(ctx, querier, doc.Address, doc); err != nil {
+			logrus.Errorf("failed to classify %s: %s", doc.Address, err)
+		} else {
+			doc.Vendor, doc.DeviceClass = host.Vendor, host.DeviceClass
+		}
+
+		doc.Summary = hostindex.Summarize(doc)
+
+		document, err := json.Marshal(doc)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not encode host scan of %s: %w", doc.Address, err)
+		}
+		documents = append(documents, document)
+
+		err = querier.SetHostScan(ctx, database.SetHostScanParams{
+			Address:     doc.Address,
+			ScanID:      doc.ScanId,
+			Document:    string(document),
+			SubmittedBy: submittedBy,
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not store host scan of %s: %w", doc.Address, err)
+		}
+
+		if err := markHostScanSeen(ctx, querier, doc, seenAt); err != nil {
+			return nil, nil, fmt.Errorf("could not mark %s seen: %w", doc.Address, err)
+		}
+
+		if _, err := vulns.Correlate(ctx, querier, doc); err != nil {
+			return nil, nil, fmt.Errorf("could not match vulnerabilities of %s: %w", doc.Address, err)
+		}
+	}
+
+	if scan == nil {
+		return nil, nil, nil
+	}
+
+	// Hosts a whole network scan didn't find count towards their expiry
+	expired, err := expireHosts(ctx, querier, e.Config, network, seenAt)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not expire hosts: %w", err)
+	}
+
+	// Keep the scan's results so later scans can be compared against them
+	var portsFound int64
+	for i, doc := range docs {
+		err := querier.CreateHostScanSnapshot(ctx, database.CreateHostScanSnapshotParams{
+			ScanID:   scan.ID,
+			Address:  doc.Address,
+			Document: string(documents[i]),
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not store host scan snapshot of %s: %w", doc.Address, err)
+		}
+
+		portsFound += int64(len(scandiff.OpenPorts(doc)))
+	}
+
+	finished, err := querier.FinishNetworkScan(ctx, database.FinishNetworkScanParams{
+		HostsFound: int64(len(docs)),
+		PortsFound: portsFound,
+		ID:         scan.ID,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not finish network scan: %w", err)
+	}
+
+	// The network is free to be claimed again once its scan is finished
+	err = querier.DeleteScanLease(ctx, database.DeleteScanLeaseParams{
+		NetworkID: network.ID,
+		ScanID:    scan.ID,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not release scan lease: %w", err)
+	}
+
+	return &finished, expired, nil
+}
+
+// failNetworkScan marks a scan whose results couldn't be stored as failed and
+// releases its lease, so the network can be scanned again
+func failNetworkScan(ctx context.Context, apiContext *types.ApiContext, scan *database.NetworkScan, failure error) {
+	_, err := apiContext.Querier.FailNetworkScan(ctx, database.FailNetworkScanParams{
+		FailedAt: time.Now().Unix(),
+		Failure:  failure.Error(),
+		ID:       scan.ID,
 	})
+	if err != nil {
+		logrus.Errorf("failed to mark network scan %d failed: %s", scan.ID, err)
+	}
+
+	err = apiContext.Querier.DeleteScanLease(ctx, database.DeleteScanLeaseParams{
+		NetworkID: scan.NetworkID,
+		ScanID:    scan.ID,
+	})
+	if err != nil {
+		logrus.Errorf("failed to release scan lease of network scan %d: %s", scan.ID, err)
+	}
 }
 
+// deleteHostScan removes a host's latest host scan from the index. The host
+// scans recorded by network scans are kept.
 func (e *v1HostScanEndpoints) deleteHostScan(ctx *gin.Context) {
-	ctx.AbortWithStatusJSON(http.StatusNotImplemented, &gin.H{
-		"message": "not implemented",
+	address, err := database.CanonicalAddress(ctx.Param("address"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, &gin.H{
+			"message": fmt.Sprintf("%s: %s", ErrInvalidParameter, "address"),
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	logrus.Debugf("check-out searcher handle")
+	handle := e.Searcher.SearcherHandle()
+	defer handle.Close()
+
+	// A host scan may be indexed without being stored, or the other way
+	// around, it's only missing if it's in neither
+	_, err = e.Querier.GetHostScan(ctx, address)
+	if errors.Is(err, sql.ErrNoRows) {
+		var fields map[string]interface{}
+		if fields, err = indexedHostScan(handle.Index(), address); err == nil && fields == nil {
+			ctx.AbortWithStatusJSON(http.StatusNotFound, &gin.H{
+				"message": fmt.Sprintf("host scan not found with address: %s", address),
+			})
+			return
+		}
+	}
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+			"message": ErrDatabaseLookup,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	if err := deleteHostScans(ctx, e.ApiContext, handle.Index(), address); err != nil {
+		logrus.Errorf("failed to delete host scan: %s", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+			"message": ErrDatabaseDelete,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &gin.H{
+		"message": "Host scan deleted",
 	})
 }
+
+// indexedHostScan returns the indexed fields of the host's latest host scan,
+// nil if it isn't indexed
+func indexedHostScan(index bleve.Index, address string) (map[string]interface{}, error) {
+	searchReq := bleve.NewSearchRequest(bleve.NewDocIDQuery([]string{address}))
+	searchReq.Fields = []string{"*"}
+	searchReq.Size = 1
+
+	results, err := index.Search(searchReq)
+	if err != nil {
+		return nil, fmt.Errorf("could not look up indexed host scan: %w", err)
+	}
+	if len(results.Hits) == 0 {
+		return nil, nil
+	}
+
+	return results.Hits[0].Fields, nil
+}
+
+// deleteHostScans removes the hosts' latest host scans from the index, so that
+// deleted hosts don't keep turning up in searches
+func deleteHostScans(ctx context.Context, apiContext *types.ApiContext, index bleve.Index, addresses ...string) error {
+	if len(addresses) == 0 {
+		return nil
+	}
+
+	batch := index.NewBatch()
+	for _, address := range addresses {
+		batch.Delete(address)
+	}
+
+	if err := index.Batch(batch); err != nil {
+		return fmt.Errorf("could not delete host scans from index: %w", err)
+	}
+
+	for _, address := range addresses {
+		if err := apiContext.Querier.DeleteHostScan(ctx, address); err != nil {
+			return fmt.Errorf("could not delete host scan of %s: %w", address, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,365 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pirogoeth/apps/pkg/search"
+
+	"github.com/pirogoeth/apps/maparoon/database"
+	"github.com/pirogoeth/apps/maparoon/hostindex"
+	"github.com/pirogoeth/apps/maparoon/types"
+)
+
+func setupTestAPI(t *testing.T) (*gin.Engine, *types.ApiContext) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+
+	db, err := database.Open(ctx, fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name()))
+	if err != nil {
+		t.Fatalf("could not open database: %s", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	searcher, err := search.NewSearcher(search.SearcherOpts{
+		IndexDir:     t.TempDir(),
+		IndexMapping: hostindex.NewMapping(),
+	})
+	if err != nil {
+		t.Fatalf("could not create search index: %s", err)
+	}
+	t.Cleanup(func() { searcher.Close() })
+
+	cfg := &types.Config{}
+	cfg.Auth.Disabled = true
+
+	apiContext := &types.ApiContext{
+		Config:   cfg,
+		Querier:  db.Querier(),
+		Database: db,
+		Searcher: searcher,
+	}
+
+	router := gin.New()
+	MustRegister(router, apiContext)
+
+	return router, apiContext
+}
+
+// createTestHostScan creates a host in the network and stores and indexes a
+// host scan of it, as submitting a scan does
+func createTestHostScan(t *testing.T, apiContext *types.ApiContext, network database.Network, address string) {
+	ctx := context.Background()
+
+	if _, err := apiContext.Querier.CreateHost(ctx, database.CreateHostParams{NetworkID: network.ID, Address: address}); err != nil {
+		t.Fatalf("could not create host %s: %s", address, err)
+	}
+
+	doc := &types.HostScanDocument{Address: address, Network: network}
+	document, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatalf("could not marshal host scan: %s", err)
+	}
+
+	err = apiContext.Querier.SetHostScan(ctx, database.SetHostScanParams{Address: address, Document: string(document)})
+	if err != nil {
+		t.Fatalf("could not store host scan of %s: %s", address, err)
+	}
+
+	handle := apiContext.Searcher.IndexerHandle()
+	defer handle.Close()
+	if err := handle.Index().Index(address, doc); err != nil {
+		t.Fatalf("could not index host scan of %s: %s", address, err)
+	}
+}
+
+func createTestNetwork(t *testing.T, apiContext *types.ApiContext, name, address string) database.Network {
+	network, err := apiContext.Querier.CreateNetwork(context.Background(), database.CreateNetworkParams{
+		Name:    name,
+		Address: address,
+		Cidr:    24,
+	})
+	if err != nil {
+		t.Fatalf("could not create network %s: %s", name, err)
+	}
+
+	return network
+}
+
+func serve(router *gin.Engine, method, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+	return w
+}
+
+func serveJSON(t *testing.T, router *gin.Engine, method, path string, body any) *httptest.ResponseRecorder {
+	data, err := json.Marshal(body)
+	if err != nil {
+		t.Fatalf("could not marshal request: %s", err)
+	}
+
+	req := httptest.NewRequest(method, path, bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// execTestSQL runs a statement against the test's shared in-memory database,
+// e.g. to create a trigger that makes a write fail
+func execTestSQL(t *testing.T, stmt string) {
+	db, err := sql.Open("sqlite3", fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name()))
+	if err != nil {
+		t.Fatalf("could not open database: %s", err)
+	}
+	defer db.Close()
+
+	if _, err := db.ExecContext(context.Background(), stmt); err != nil {
+		t.Fatalf("could not run %q: %s", stmt, err)
+	}
+}
+
+// indexedAddresses lists the ids of every document in the index
+func indexedAddresses(t *testing.T, apiContext *types.ApiContext) []string {
+	handle := apiContext.Searcher.SearcherHandle()
+	defer handle.Close()
+
+	req := handle.PrepareSearchRequest("*")
+	req.Size = 100
+	results, err := handle.Search(req)
+	if err != nil {
+		t.Fatalf("could not search index: %s", err)
+	}
+
+	addresses := make([]string, 0, len(results.Hits))
+	for _, hit := range results.Hits {
+		addresses = append(addresses, hit.ID)
+	}
+
+	return addresses
+}
+
+func TestGetAndDeleteHostScan(t *testing.T) {
+	router, apiContext := setupTestAPI(t)
+	network := createTestNetwork(t, apiContext, "lan", "10.0.0.0")
+	createTestHostScan(t, apiContext, network, "10.0.0.5")
+
+	w := serve(router, http.MethodGet, "/v1/hostscan?address=10.0.0.5")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected the host scan, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var response struct {
+		HostScans []types.HostScanDocument `json:"host_scans"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("could not decode response: %s", err)
+	}
+	if len(response.HostScans) != 1 || response.HostScans[0].Address != "10.0.0.5" {
+		t.Errorf("unexpected host scans: %+v", response.HostScans)
+	}
+
+	if w := serve(router, http.MethodDelete, "/v1/hostscans/10.0.0.5"); w.Code != http.StatusOK {
+		t.Fatalf("expected the host scan to be deleted, got %d: %s", w.Code, w.Body.String())
+	}
+	if addresses := indexedAddresses(t, apiContext); len(addresses) != 0 {
+		t.Errorf("expected the index to be empty, got %v", addresses)
+	}
+
+	if w := serve(router, http.MethodGet, "/v1/hostscan?address=10.0.0.5"); w.Code != http.StatusNotFound {
+		t.Errorf("expected a deleted host scan to be gone, got %d", w.Code)
+	}
+	if w := serve(router, http.MethodDelete, "/v1/hostscans/10.0.0.5"); w.Code != http.StatusNotFound {
+		t.Errorf("expected deleting a missing host scan to get 404, got %d", w.Code)
+	}
+}
+
+func TestDeleteHostRemovesHostScan(t *testing.T) {
+	router, apiContext := setupTestAPI(t)
+	network := createTestNetwork(t, apiContext, "lan", "10.0.0.0")
+	createTestHostScan(t, apiContext, network, "10.0.0.5")
+	createTestHostScan(t, apiContext, network, "10.0.0.6")
+
+	if w := serve(router, http.MethodDelete, "/v1/hosts/10.0.0.5"); w.Code != http.StatusOK {
+		t.Fatalf("expected the host to be deleted, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if addresses := indexedAddresses(t, apiContext); len(addresses) != 1 || addresses[0] != "10.0.0.6" {
+		t.Errorf("expected only the other host to stay indexed, got %v", addresses)
+	}
+	if w := serve(router, http.MethodGet, "/v1/hostscan?address=10.0.0.5"); w.Code != http.StatusNotFound {
+		t.Errorf("expected the deleted host's scan to be gone, got %d", w.Code)
+	}
+}
+
+func TestDeleteNetworkRemovesHostScans(t *testing.T) {
+	router, apiContext := setupTestAPI(t)
+	lan := createTestNetwork(t, apiContext, "lan", "10.0.0.0")
+	dmz := createTestNetwork(t, apiContext, "dmz", "10.0.2.0")
+	createTestHostScan(t, apiContext, lan, "10.0.0.5")
+	createTestHostScan(t, apiContext, lan, "10.0.0.6")
+	createTestHostScan(t, apiContext, dmz, "10.0.2.5")
+
+	if w := serve(router, http.MethodDelete, fmt.Sprintf("/v1/networks/%d", lan.ID)); w.Code != http.StatusOK {
+		t.Fatalf("expected the network to be deleted, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if addresses := indexedAddresses(t, apiContext); len(addresses) != 1 || addresses[0] != "10.0.2.5" {
+		t.Errorf("expected only the other network's host to stay indexed, got %v", addresses)
+	}
+	for _, address := range []string{"10.0.0.5", "10.0.0.6"} {
+		if w := serve(router, http.MethodGet, "/v1/hostscan?address="+address); w.Code != http.StatusNotFound {
+			t.Errorf("expected the scan of %s to be gone, got %d", address, w.Code)
+		}
+	}
+}
+
+func TestIndexOnlyHostScan(t *testing.T) {
+	router, apiContext := setupTestAPI(t)
+	network := createTestNetwork(t, apiContext, "lan", "10.0.0.0")
+
+	// Hosts indexed before host scans were stored have no host scan row
+	handle := apiContext.Searcher.IndexerHandle()
+	err := handle.Index().Index("10.0.0.5", &types.HostScanDocument{Address: "10.0.0.5", Network: network})
+	handle.Close()
+	if err != nil {
+		t.Fatalf("could not index host scan: %s", err)
+	}
+
+	w := serve(router, http.MethodGet, "/v1/hostscan?address=10.0.0.5")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected the indexed host scan, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var response struct {
+		HostScans []map[string]interface{} `json:"host_scans"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("could not decode response: %s", err)
+	}
+	if len(response.HostScans) != 1 || response.HostScans[0]["address"] != "10.0.0.5" {
+		t.Errorf("unexpected host scans: %+v", response.HostScans)
+	}
+
+	if w := serve(router, http.MethodDelete, "/v1/hostscans/10.0.0.5"); w.Code != http.StatusOK {
+		t.Fatalf("expected the indexed host scan to be deleted, got %d: %s", w.Code, w.Body.String())
+	}
+	if addresses := indexedAddresses(t, apiContext); len(addresses) != 0 {
+		t.Errorf("expected the index to be empty, got %v", addresses)
+	}
+}
+
+func TestDeleteHostKeepsHostScanWhenDeleteFails(t *testing.T) {
+	router, apiContext := setupTestAPI(t)
+	network := createTestNetwork(t, apiContext, "lan", "10.0.0.0")
+	createTestHostScan(t, apiContext, network, "10.0.0.5")
+
+	execTestSQL(t, `create trigger keep_hosts before delete on hosts
+begin
+	select raise(abort, 'hosts are kept');
+end`)
+
+	if w := serve(router, http.MethodDelete, "/v1/hosts/10.0.0.5"); w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected the host delete to fail, got %d: %s", w.Code, w.Body.String())
+	}
+	if addresses := indexedAddresses(t, apiContext); len(addresses) != 1 || addresses[0] != "10.0.0.5" {
+		t.Errorf("expected the host to stay indexed, got %v", addresses)
+	}
+}
+
+func TestCreateHostScansFailsScanWhenStoreFails(t *testing.T) {
+	router, apiContext := setupTestAPI(t)
+	ctx := context.Background()
+	network := createTestNetwork(t, apiContext, "lan", "10.0.0.0")
+	if _, err := apiContext.Querier.CreateHost(ctx, database.CreateHostParams{NetworkID: network.ID, Address: "10.0.0.5"}); err != nil {
+		t.Fatalf("could not create host: %s", err)
+	}
+
+	scanId, err := apiContext.Querier.CreateNetworkScan(ctx, database.CreateNetworkScanParams{NetworkID: network.ID})
+	if err != nil {
+		t.Fatalf("could not create network scan: %s", err)
+	}
+
+	execTestSQL(t, `create trigger fail_snapshots before insert on host_scan_snapshots
+begin
+	select raise(abort, 'snapshots fail');
+end`)
+
+	req := &types.CreateHostScansRequest{
+		NetworkId: network.ID,
+		ScanId:    scanId,
+		HostScans: []*types.HostScanDocument{{Address: "10.0.0.5"}},
+	}
+	if w := serveJSON(t, router, http.MethodPost, "/v1/hostscans", req); w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected storing the host scans to fail, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// Nothing of the scan is kept
+	if _, err := apiContext.Querier.GetHostScan(ctx, "10.0.0.5"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected the host scan to be rolled back, got %v", err)
+	}
+	if host, err := apiContext.Querier.GetHost(ctx, "10.0.0.5"); err != nil || host.LastSeen != 0 {
+		t.Errorf("expected the host not to be marked seen, got %+v (%v)", host, err)
+	}
+	if addresses := indexedAddresses(t, apiContext); len(addresses) != 0 {
+		t.Errorf("expected nothing to be indexed, got %v", addresses)
+	}
+
+	scan, err := apiContext.Querier.GetNetworkScan(ctx, database.GetNetworkScanParams{ID: scanId, NetworkID: network.ID})
+	if err != nil {
+		t.Fatalf("could not get network scan: %s", err)
+	}
+	if scan.FailedAt == -1 || scan.FinishedAt != -1 || scan.Failure == "" {
+		t.Errorf("expected the scan to have failed, got %+v", scan)
+	}
+
+	if w := serveJSON(t, router, http.MethodPost, "/v1/hostscans", req); w.Code != http.StatusConflict {
+		t.Errorf("expected a failed scan not to take host scans, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestFailStaleNetworkScans(t *testing.T) {
+	_, apiContext := setupTestAPI(t)
+	apiContext.Config.Scans.StaleAfter = time.Hour
+	ctx := context.Background()
+	network := createTestNetwork(t, apiContext, "lan", "10.0.0.0")
+	leased := createTestNetwork(t, apiContext, "dmz", "10.0.2.0")
+
+	abandonedId, err := apiContext.Querier.CreateNetworkScan(ctx, database.CreateNetworkScanParams{NetworkID: network.ID})
+	if err != nil {
+		t.Fatalf("could not create network scan: %s", err)
+	}
+	leasedId, err := apiContext.Querier.CreateNetworkScan(ctx, database.CreateNetworkScanParams{NetworkID: leased.ID})
+	if err != nil {
+		t.Fatalf("could not create network scan: %s", err)
+	}
+
+	later := time.Now().Add(2 * time.Hour).Unix()
+	if _, err := apiContext.Querier.ClaimScanLease(ctx, database.ClaimScanLeaseParams{NetworkID: leased.ID, Worker: "worker", ExpiresAt: later + 60}); err != nil {
+		t.Fatalf("could not claim scan lease: %s", err)
+	}
+	if _, err := apiContext.Querier.SetScanLeaseScan(ctx, database.SetScanLeaseScanParams{ScanID: leasedId, NetworkID: leased.ID}); err != nil {
+		t.Fatalf("could not attach scan to lease: %s", err)
+	}
+
+	failStaleNetworkScans(ctx, apiContext, later)
+
+	abandoned, _ := apiContext.Querier.GetNetworkScan(ctx, database.GetNetworkScanParams{ID: abandonedId, NetworkID: network.ID})
+	if abandoned.FailedAt != later {
+		t.Errorf("expected the abandoned scan to have failed, got %+v", abandoned)
+	}
+	running, _ := apiContext.Querier.GetNetworkScan(ctx, database.GetNetworkScanParams{ID: leasedId, NetworkID: leased.ID})
+	if running.FailedAt != -1 {
+		t.Errorf("expected the leased scan to keep running, got %+v", running)
+	}
+}
@@ -0,0 +1,247 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pirogoeth/apps/maparoon/database"
+	"github.com/pirogoeth/apps/maparoon/types"
+	"github.com/sirupsen/logrus"
+)
+
+// v1HostTableEndpoints serve the tables walked from hosts over SNMP
+type v1HostTableEndpoints struct {
+	*types.ApiContext
+}
+
+func (e *v1HostTableEndpoints) RegisterRoutesTo(router *gin.RouterGroup) {
+	router.GET("/hosts/:address/interfaces", e.listHostInterfaces)
+	router.GET("/hosts/:address/arp", e.listHostArpEntries)
+	router.GET("/hosts/:address/fdb", e.listHostFdbEntries)
+	router.GET("/hosts/:address/neighbors", e.listHostNeighbors)
+}
+
+func (e *v1HostTableEndpoints) listHostInterfaces(ctx *gin.Context) {
+	host, ok := extractHostFromPathParam(ctx, e.ApiContext, "address")
+	if !ok {
+		return
+	}
+
+	interfaces, err := e.Querier.ListHostInterfaces(ctx, host.Address)
+	if ok := e.checkLookup(ctx, err); !ok {
+		return
+	}
+
+	if interfaces == nil {
+		interfaces = []database.HostInterface{}
+	}
+
+	ctx.JSON(http.StatusOK, &gin.H{
+		"interfaces": interfaces,
+	})
+}
+
+func (e *v1HostTableEndpoints) listHostArpEntries(ctx *gin.Context) {
+	host, ok := extractHostFromPathParam(ctx, e.ApiContext, "address")
+	if !ok {
+		return
+	}
+
+	entries, err := e.Querier.ListHostArpEntries(ctx, host.Address)
+	if ok := e.checkLookup(ctx, err); !ok {
+		return
+	}
+
+	if entries == nil {
+		entries = []database.HostArpEntry{}
+	}
+
+	ctx.JSON(http.StatusOK, &gin.H{
+		"arp_entries": entries,
+	})
+}
+
+func (e *v1HostTableEndpoints) listHostFdbEntries(ctx *gin.Context) {
+	host, ok := extractHostFromPathParam(ctx, e.ApiContext, "address")
+	if !ok {
+		return
+	}
+
+	entries, err := e.Querier.ListHostFdbEntries(ctx, host.Address)
+	if ok := e.checkLookup(ctx, err); !ok {
+		return
+	}
+
+	if entries == nil {
+		entries = []database.HostFdbEntry{}
+	}
+
+	ctx.JSON(http.StatusOK, &gin.H{
+		"fdb_entries": entries,
+	})
+}
+
+func (e *v1HostTableEndpoints) listHostNeighbors(ctx *gin.Context) {
+	host, ok := extractHostFromPathParam(ctx, e.ApiContext, "address")
+	if !ok {
+		return
+	}
+
+	neighbors, err := e.Querier.ListHostNeighbors(ctx, host.Address)
+	if ok := e.checkLookup(ctx, err); !ok {
+		return
+	}
+
+	if neighbors == nil {
+		neighbors = []database.HostNeighbor{}
+	}
+
+	ctx.JSON(http.StatusOK, &gin.H{
+		"neighbors": neighbors,
+	})
+}
+
+func (e *v1HostTableEndpoints) checkLookup(ctx *gin.Context, err error) bool {
+	if err != nil {
+		logrus.Errorf("could not list host table: %s", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+			"message": ErrDatabaseLookup,
+			"error":   err.Error(),
+		})
+		return false
+	}
+
+	return true
+}
+
+// storeSnmpTables replaces the host's stored tables with the ones walked in the
+// host scan. Tables that weren't walked are left alone. querier must be a
+// transaction's, so that a failed insert doesn't leave a table half replaced.
+func storeSnmpTables(ctx context.Context, querier *database.Queries, doc *types.HostScanDocument) error {
+	if doc.Snmp == nil {
+		return nil
+	}
+
+	address := doc.Address
+
+	if doc.Snmp.Interfaces != nil {
+		if err := querier.DeleteHostInterfaces(ctx, address); err != nil {
+			return fmt.Errorf("could not clear interfaces: %w", err)
+		}
+
+		for _, iface := range doc.Snmp.Interfaces {
+			err := querier.CreateHostInterface(ctx, database.CreateHostInterfaceParams{
+				Address:     address,
+				IfIndex:     iface.Index,
+				Name:        iface.Name,
+				Descr:       iface.Descr,
+				Alias:       iface.Alias,
+				Type:        iface.Type,
+				Mtu:         iface.Mtu,
+				Speed:       iface.Speed,
+				MacAddress:  iface.MacAddress,
+				AdminStatus: iface.AdminStatus,
+				OperStatus:  iface.OperStatus,
+			})
+			if err != nil {
+				return fmt.Errorf("could not store interface %d: %w", iface.Index, err)
+			}
+		}
+	}
+
+	if doc.Snmp.ArpEntries != nil {
+		if err := querier.DeleteHostArpEntries(ctx, address); err != nil {
+			return fmt.Errorf("could not clear arp entries: %w", err)
+		}
+
+		for _, entry := range doc.Snmp.ArpEntries {
+			err := querier.CreateHostArpEntry(ctx, database.CreateHostArpEntryParams{
+				Address:    address,
+				IfIndex:    entry.IfIndex,
+				IpAddress:  entry.IpAddress,
+				MacAddress: entry.MacAddress,
+			})
+			if err != nil {
+				return fmt.Errorf("could not store arp entry for %s: %w", entry.IpAddress, err)
+			}
+
+			if err := rememberMacAddress(ctx, querier, entry.IpAddress, entry.MacAddress); err != nil {
+				return err
+			}
+		}
+	}
+
+	if doc.Snmp.FdbEntries != nil {
+		if err := querier.DeleteHostFdbEntries(ctx, address); err != nil {
+			return fmt.Errorf("could not clear fdb entries: %w", err)
+		}
+
+		for _, entry := range doc.Snmp.FdbEntries {
+			err := querier.CreateHostFdbEntry(ctx, database.CreateHostFdbEntryParams{
+				Address:    address,
+				MacAddress: entry.MacAddress,
+				BridgePort: entry.BridgePort,
+				IfIndex:    entry.IfIndex,
+			})
+			if err != nil {
+				return fmt.Errorf("could not store fdb entry for %s: %w", entry.MacAddress, err)
+			}
+		}
+	}
+
+	if doc.Snmp.Neighbors != nil {
+		if err := querier.DeleteHostNeighbors(ctx, address); err != nil {
+			return fmt.Errorf("could not clear neighbors: %w", err)
+		}
+
+		for _, neighbor := range doc.Snmp.Neighbors {
+			err := querier.CreateHostNeighbor(ctx, database.CreateHostNeighborParams{
+				Address:           address,
+				LocalPort:         neighbor.LocalPort,
+				LocalPortID:       neighbor.LocalPortId,
+				LocalPortDescr:    neighbor.LocalPortDescr,
+				ChassisID:         neighbor.ChassisId,
+				PortID:            neighbor.PortId,
+				PortDescr:         neighbor.PortDescr,
+				SysName:           neighbor.SysName,
+				SysDescr:          neighbor.SysDescr,
+				ManagementAddress: neighbor.ManagementAddress,
+			})
+			if err != nil {
+				return fmt.Errorf("could not store neighbor %s: %w", neighbor.ChassisId, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// rememberMacAddress records the MAC address of a known host, as seen in
+// another host's ARP table
+func rememberMacAddress(ctx context.Context, querier *database.Queries, ipAddress, macAddress string) error {
+	_, err := querier.GetHost(ctx, ipAddress)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("could not look up host %s: %w", ipAddress, err)
+	}
+
+	_, err = querier.SetHostAttribute(ctx, database.SetHostAttributeParams{
+		Address: ipAddress,
+		Key:     types.MacAddressAttribute,
+		Value:   macAddress,
+	})
+	if err != nil {
+		return fmt.Errorf("could not remember mac address of %s: %w", ipAddress, err)
+	}
+
+	if _, err := classifyHost(ctx, querier, ipAddress, nil); err != nil {
+		return fmt.Errorf("could not classify %s: %w", ipAddress, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/pirogoeth/apps/maparoon/database"
+	"github.com/pirogoeth/apps/maparoon/types"
+)
+
+func TestStoreSnmpTablesKeepsTablesWhenStoreFails(t *testing.T) {
+	router, apiContext := setupTestAPI(t)
+	ctx := context.Background()
+	network := createTestNetwork(t, apiContext, "lan", "10.0.0.0")
+	if _, err := apiContext.Querier.CreateHost(ctx, database.CreateHostParams{NetworkID: network.ID, Address: "10.0.0.1"}); err != nil {
+		t.Fatalf("could not create host: %s", err)
+	}
+
+	submit := func(interfaces ...types.SnmpInterface) int {
+		scanId, err := apiContext.Querier.CreateNetworkScan(ctx, database.CreateNetworkScanParams{NetworkID: network.ID})
+		if err != nil {
+			t.Fatalf("could not create network scan: %s", err)
+		}
+
+		w := serveJSON(t, router, http.MethodPost, "/v1/hostscans", &types.CreateHostScansRequest{
+			NetworkId: network.ID,
+			ScanId:    scanId,
+			HostScans: []*types.HostScanDocument{{
+				Address: "10.0.0.1",
+				Snmp:    &types.SnmpHostScanDocument{Available: true, Interfaces: interfaces},
+			}},
+		})
+		return w.Code
+	}
+
+	if code := submit(types.SnmpInterface{Index: 1, Name: "eth0"}, types.SnmpInterface{Index: 2, Name: "eth1"}); code != http.StatusCreated {
+		t.Fatalf("expected 201 storing the first scan, got %d", code)
+	}
+
+	execTestSQL(t, `create trigger fail_interface before insert on host_interfaces
+when new.if_index = 3
+begin
+	select raise(abort, 'interface fails');
+end`)
+
+	if code := submit(types.SnmpInterface{Index: 1, Name: "eth0"}, types.SnmpInterface{Index: 3, Name: "eth2"}); code != http.StatusInternalServerError {
+		t.Fatalf("expected storing the second scan to fail, got %d", code)
+	}
+
+	interfaces, err := apiContext.Querier.ListHostInterfaces(ctx, "10.0.0.1")
+	if err != nil {
+		t.Fatalf("could not list interfaces: %s", err)
+	}
+	if len(interfaces) != 2 || interfaces[0].IfIndex != 1 || interfaces[1].IfIndex != 2 {
+		t.Errorf("expected the first scan's interfaces to be kept, got %+v", interfaces)
+	}
+}
@@ -0,0 +1,226 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"github.com/pirogoeth/apps/maparoon/database"
+	"github.com/pirogoeth/apps/maparoon/discovery"
+	"github.com/pirogoeth/apps/maparoon/types"
+)
+
+// maxImportSize bounds the size of an imported lease file or neighbor table
+const maxImportSize = 32 * 1024 * 1024
+
+// v1ImportEndpoints passively discover hosts from lease files and neighbor
+// tables
+type v1ImportEndpoints struct {
+	*types.ApiContext
+}
+
+func (e *v1ImportEndpoints) RegisterRoutesTo(router *gin.RouterGroup) {
+	router.POST("/imports/:format", e.importDiscoveries)
+}
+
+// importDiscoveries parses the request body in the given format and creates or
+// updates a host for every address seen in one of the known networks
+func (e *v1ImportEndpoints) importDiscoveries(ctx *gin.Context) {
+	format := ctx.Param("format")
+	if !slices.Contains(discovery.Formats, format) {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, &gin.H{
+			"message": fmt.Sprintf("%s: %s", ErrInvalidParameter, "format"),
+			"error":   fmt.Sprintf("expected one of %s", strings.Join(discovery.Formats, ", ")),
+		})
+		return
+	}
+
+	body := http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxImportSize)
+	observations, err := discovery.Parse(format, body, time.Now())
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		ctx.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, &gin.H{
+			"message": fmt.Sprintf("%s: %s", ErrInvalidParameter, "body"),
+			"error":   fmt.Sprintf("imports are limited to %d bytes", tooLarge.Limit),
+		})
+		return
+	} else if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, &gin.H{
+			"message": fmt.Sprintf("%s: %s", ErrInvalidParameter, "body"),
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	networks, err := e.Querier.ListNetworks(ctx)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+			"message": ErrDatabaseLookup,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	hosts := make([]database.Host, 0)
+	skipped := make([]string, 0)
+	created := 0
+	for _, observation := range observations {
+		network := containingNetwork(networks, observation.Address)
+		if network == nil {
+			skipped = append(skipped, observation.Address)
+			continue
+		}
+
+		host, isNew, err := e.recordObservation(ctx, *network, format, observation)
+		if err != nil {
+			logrus.Errorf("failed to import %s from %s: %s", observation.Address, format, err)
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+				"message": ErrDatabaseUpdate,
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		hosts = append(hosts, *host)
+		if isNew {
+			created++
+		}
+	}
+
+	logrus.Infof("imported %d hosts from %s, %d new, submitted by %s", len(hosts), format, created, principalFrom(ctx).Name)
+
+	ctx.JSON(http.StatusOK, &gin.H{
+		"message": fmt.Sprintf("Imported %d hosts, %d new", len(hosts), created),
+		"hosts":   hosts,
+		"skipped": skipped,
+	})
+}
+
+// recordObservation creates the observed host if it's new and records what was
+// seen of it as attributes. Attributes are only overwritten by newer
+// observations.
+func (e *v1ImportEndpoints) recordObservation(ctx *gin.Context, network database.Network, source string, observation discovery.Observation) (*database.Host, bool, error) {
+	host, err := e.Querier.GetHost(ctx, observation.Address)
+	isNew := errors.Is(err, sql.ErrNoRows)
+	if isNew {
+		host, err = e.Querier.CreateHost(ctx, database.CreateHostParams{
+			Address:   observation.Address,
+			NetworkID: network.ID,
+			Comments:  "",
+		})
+		if err != nil {
+			return nil, false, fmt.Errorf("could not create host: %w", err)
+		}
+	} else if err != nil {
+		return nil, false, fmt.Errorf("could not look up host: %w", err)
+	}
+
+	attributes, err := e.Querier.ListHostAttributes(ctx, host.Address)
+	if err != nil {
+		return nil, false, fmt.Errorf("could not list host attributes: %w", err)
+	}
+
+	current := make(map[string]string, len(attributes))
+	for _, attribute := range attributes {
+		current[attribute.Key] = attribute.Value
+	}
+
+	updates := map[string]string{
+		types.DiscoverySourceAttribute: addDiscoverySource(current[types.DiscoverySourceAttribute], source),
+	}
+	lastSeen, _ := strconv.ParseInt(current[types.LastSeenAttribute], 10, 64)
+	if observation.LastSeen.Unix() >= lastSeen {
+		updates[types.LastSeenAttribute] = strconv.FormatInt(observation.LastSeen.Unix(), 10)
+		if observation.MacAddress != "" {
+			updates[types.MacAddressAttribute] = observation.MacAddress
+		}
+		if observation.Hostname != "" {
+			updates[types.HostnameAttribute] = observation.Hostname
+		}
+	}
+
+	for key, value := range updates {
+		if current[key] == value {
+			continue
+		}
+
+		_, err := e.Querier.SetHostAttribute(ctx, database.SetHostAttributeParams{
+			Address: host.Address,
+			Key:     key,
+			Value:   value,
+		})
+		if err != nil {
+			return nil, false, fmt.Errorf("could not set host attribute %s: %w", key, err)
+		}
+	}
+
+	err = e.Querier.MarkHostSeen(ctx, database.MarkHostSeenParams{
+		SeenAt:  observation.LastSeen.Unix(),
+		Address: host.Address,
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("could not mark host seen: %w", err)
+	}
+
+	host, err = e.Querier.GetHost(ctx, host.Address)
+	if err != nil {
+		return nil, false, fmt.Errorf("could not look up host: %w", err)
+	}
+
+	if _, ok := updates[types.MacAddressAttribute]; ok {
+		classified, err := classifyHost(ctx, e.Querier, host.Address, nil)
+		if err != nil {
+			return nil, false, err
+		}
+		host = *classified
+	}
+
+	return &host, isNew, nil
+}
+
+// containingNetwork finds the most specific network the address is in
+func containingNetwork(networks []database.Network, address string) *database.Network {
+	var (
+		found *database.Network
+		bits  = -1
+	)
+	for i, network := range networks {
+		prefix, err := network.Prefix()
+		if err != nil || !network.Contains(address) {
+			continue
+		}
+
+		if prefix.Bits() > bits {
+			found, bits = &networks[i], prefix.Bits()
+		}
+	}
+
+	return found
+}
+
+func addDiscoverySource(sources, source string) string {
+	set := make(map[string]bool)
+	for _, existing := range strings.Split(sources, ",") {
+		if existing != "" {
+			set[existing] = true
+		}
+	}
+	set[source] = true
+
+	merged := make([]string, 0, len(set))
+	for existing := range set {
+		merged = append(merged, existing)
+	}
+	sort.Strings(merged)
+
+	return strings.Join(merged, ",")
+}
@@ -0,0 +1,30 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestImportDiscoveriesRejectsLargeBodies(t *testing.T) {
+	router, apiContext := setupTestAPI(t)
+	createTestNetwork(t, apiContext, "lan", "10.0.0.0")
+
+	line := "10.0.0.5 dev eth0 lladdr 00:11:22:33:44:55 REACHABLE\n"
+	body := strings.Repeat(line, maxImportSize/len(line)+1)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/imports/ip-neigh", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected 413, got %d: %s", w.Code, w.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/v1/imports/ip-neigh", strings.NewReader(line))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+}
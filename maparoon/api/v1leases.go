@@ -0,0 +1,396 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pirogoeth/apps/maparoon/auth"
+	"github.com/pirogoeth/apps/maparoon/database"
+	"github.com/pirogoeth/apps/maparoon/types"
+	"github.com/sirupsen/logrus"
+)
+
+// workerLabelAttribute is the network attribute pinning a network to the
+// workers with the same label
+const workerLabelAttribute = "worker_label"
+
+type v1ScanLeaseEndpoints struct {
+	*types.ApiContext
+}
+
+func (e *v1ScanLeaseEndpoints) RegisterRoutesTo(router *gin.RouterGroup) {
+	router.GET("/leases", e.listScanLeases)
+	router.POST("/leases", e.claimScanLeases)
+	router.POST("/leases/:network_id/heartbeat", e.heartbeatScanLease)
+	router.DELETE("/leases/:network_id", e.releaseScanLease)
+}
+
+func (e *v1ScanLeaseEndpoints) listScanLeases(ctx *gin.Context) {
+	leases, err := e.Querier.ListScanLeases(ctx)
+	if err != nil {
+		logrus.Errorf("could not list scan leases: %s", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+			"message": ErrDatabaseLookup,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	if leases == nil {
+		leases = []database.ScanLease{}
+	}
+
+	ctx.JSON(http.StatusOK, &gin.H{
+		"leases": leases,
+	})
+}
+
+// claimScanLeases leases networks that are due to be scanned to the calling
+// worker. Each claimed network gets a new network scan to submit results to.
+func (e *v1ScanLeaseEndpoints) claimScanLeases(ctx *gin.Context) {
+	if ok := assertContentTypeJson(ctx); !ok {
+		return
+	}
+
+	req := types.ClaimScanLeasesRequest{}
+	if err := ctx.BindJSON(&req); err != nil {
+		logrus.Errorf("failed to bind request to types.ClaimScanLeasesRequest: %s", err)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, &gin.H{
+			"message": fmt.Sprintf("%s: %s", ErrFailedToBind, err.Error()),
+		})
+		return
+	}
+
+	if err := types.ValidateWorkerId(req.WorkerId); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, &gin.H{
+			"message": fmt.Sprintf("%s: %s", ErrInvalidParameter, "worker_id"),
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	if req.Limit <= 0 {
+		req.Limit = 1
+	}
+	if req.ScanInterval <= 0 {
+		req.ScanInterval = int64(e.Config.Worker.ScanInterval.Seconds())
+	}
+
+	now := time.Now().Unix()
+	failStaleNetworkScans(ctx, e.ApiContext, now)
+
+	candidates, err := e.Querier.ListClaimableNetworks(ctx, now)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+			"message": ErrDatabaseLookup,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	pinnedLabels, ok := e.networkAttributeValues(ctx, workerLabelAttribute)
+	if !ok {
+		return
+	}
+
+	profileNames, ok := e.networkAttributeValues(ctx, scanProfileAttribute)
+	if !ok {
+		return
+	}
+
+	workerLabels := make(map[string]bool, len(req.Labels))
+	for _, label := range req.Labels {
+		workerLabels[label] = true
+	}
+
+	worker := leaseHolder(ctx, req.WorkerId)
+	leaseDuration := int64(e.Config.Leases.Duration.Seconds())
+	grants := make([]types.ScanLeaseGrant, 0)
+	for _, candidate := range candidates {
+		network := candidate.Network
+		if int64(len(grants)) >= req.Limit {
+			break
+		}
+
+		if label := pinnedLabels[network.ID]; label != "" && !workerLabels[label] {
+			continue
+		}
+
+		profile, err := networkScanProfile(ctx, e.ApiContext, profileNames[network.ID])
+		if err != nil {
+			logrus.Errorf("not claiming network %s: %s", network.Name, err)
+			continue
+		}
+
+		scanInterval := req.ScanInterval
+		if profile.ScanInterval > 0 {
+			scanInterval = profile.ScanInterval
+		}
+		if candidate.LastFinishedAt > now-scanInterval {
+			continue
+		}
+
+		grant, err := e.claimNetwork(ctx, network, worker, now, leaseDuration)
+		if err != nil {
+			logrus.Errorf("failed to claim network %s: %s", network.Name, err)
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+				"message": ErrDatabaseInsert,
+				"error":   err.Error(),
+			})
+			return
+		} else if grant == nil {
+			// Another worker claimed it first
+			continue
+		}
+		grant.Profile = profile
+
+		if !network.Sweepable() {
+			grant.Targets, err = networkScanTargets(ctx, e.ApiContext, network)
+			if err != nil {
+				logrus.Errorf("could not list scan targets for network %s: %s", network.Name, err)
+			}
+		}
+
+		if profile.SnmpEnabled {
+			grant.SnmpCredentials, grant.KnownSnmpCredentials, err = networkSnmpCredentials(ctx, e.ApiContext, network.ID)
+			if err != nil {
+				// The scan can still fall back to the worker's community
+				logrus.Errorf("could not hand out snmp credentials for network %s: %s", network.Name, err)
+			}
+		}
+
+		logrus.Infof("network %s leased to %s for scan %d with profile %s", network.Name, worker, grant.Scan.ID, profile.Name)
+		grants = append(grants, *grant)
+	}
+
+	ctx.JSON(http.StatusOK, &gin.H{
+		"leases": grants,
+	})
+}
+
+// failStaleNetworkScans fails the network scans that were abandoned, the ones
+// still running long after they started without a live lease. Workers sweep
+// them whenever they claim networks.
+func failStaleNetworkScans(ctx context.Context, apiContext *types.ApiContext, now int64) {
+	staleAfter := int64(apiContext.Config.Scans.StaleAfter.Seconds())
+	if staleAfter <= 0 {
+		return
+	}
+
+	failed, err := apiContext.Querier.FailStaleNetworkScans(ctx, database.FailStaleNetworkScansParams{
+		FailedAt:      now,
+		Failure:       "network scan was abandoned",
+		StartedBefore: now - staleAfter,
+	})
+	if err != nil {
+		logrus.Errorf("failed to fail stale network scans: %s", err)
+	} else if failed > 0 {
+		logrus.Warnf("failed %d network scans still running after %s", failed, apiContext.Config.Scans.StaleAfter)
+	}
+}
+
+// networkAttributeValues maps each network that has the attribute to its value
+func (e *v1ScanLeaseEndpoints) networkAttributeValues(ctx *gin.Context, key string) (map[int64]string, bool) {
+	attributes, err := e.Querier.ListNetworkAttributesByKey(ctx, key)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+			"message": ErrDatabaseLookup,
+			"error":   err.Error(),
+		})
+		return nil, false
+	}
+
+	values := make(map[int64]string, len(attributes))
+	for _, attribute := range attributes {
+		values[attribute.NetworkID] = attribute.Value
+	}
+
+	return values, true
+}
+
+// claimNetwork leases the network to worker and starts a scan for it. It
+// returns nil if the network is already leased. The lease and the scan are
+// created in one transaction, so a failure never leaves the network leased
+// without a scan.
+func (e *v1ScanLeaseEndpoints) claimNetwork(ctx *gin.Context, network database.Network, worker string, now, leaseDuration int64) (*types.ScanLeaseGrant, error) {
+	var grant *types.ScanLeaseGrant
+	err := e.Database.InTx(ctx, func(querier *database.Queries) error {
+		_, err := querier.ClaimScanLease(ctx, database.ClaimScanLeaseParams{
+			NetworkID:   network.ID,
+			Worker:      worker,
+			AcquiredAt:  now,
+			HeartbeatAt: now,
+			ExpiresAt:   now + leaseDuration,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("could not claim lease: %w", err)
+		}
+
+		scanId, err := querier.CreateNetworkScan(ctx, database.CreateNetworkScanParams{
+			NetworkID: network.ID,
+			StartedBy: worker,
+		})
+		if err != nil {
+			return fmt.Errorf("could not create network scan: %w", err)
+		}
+
+		lease, err := querier.SetScanLeaseScan(ctx, database.SetScanLeaseScanParams{
+			ScanID:    scanId,
+			NetworkID: network.ID,
+		})
+		if err != nil {
+			return fmt.Errorf("could not attach scan to lease: %w", err)
+		}
+
+		scan, err := querier.GetNetworkScan(ctx, database.GetNetworkScanParams{
+			ID:        scanId,
+			NetworkID: network.ID,
+		})
+		if err != nil {
+			return fmt.Errorf("could not fetch network scan: %w", err)
+		}
+
+		grant = &types.ScanLeaseGrant{
+			Lease:   lease,
+			Network: network,
+			Scan:    scan,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return grant, nil
+}
+
+// heartbeatScanLease extends the calling worker's lease. The lease can be
+// extended after it expired, as long as no other worker claimed the network.
+func (e *v1ScanLeaseEndpoints) heartbeatScanLease(ctx *gin.Context) {
+	networkId, ok := parseIdPathParam(ctx, "network_id")
+	if !ok {
+		return
+	}
+
+	req := types.HeartbeatScanLeaseRequest{}
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, &gin.H{
+			"message": fmt.Sprintf("%s: %s", ErrFailedToBind, err.Error()),
+		})
+		return
+	}
+
+	now := time.Now().Unix()
+	lease, err := e.Querier.HeartbeatScanLease(ctx, database.HeartbeatScanLeaseParams{
+		HeartbeatAt: now,
+		ExpiresAt:   now + int64(e.Config.Leases.Duration.Seconds()),
+		NetworkID:   networkId,
+		ScanID:      req.ScanId,
+		Worker:      leaseHolder(ctx, req.WorkerId),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.AbortWithStatusJSON(http.StatusConflict, &gin.H{
+			"message": fmt.Sprintf("scan lease for network %d was lost", networkId),
+		})
+		return
+	} else if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+			"message": ErrDatabaseUpdate,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &gin.H{
+		"leases": []database.ScanLease{lease},
+	})
+}
+
+// releaseScanLease gives a network back before its scan finished, e.g. because
+// the scan failed
+func (e *v1ScanLeaseEndpoints) releaseScanLease(ctx *gin.Context) {
+	networkId, ok := parseIdPathParam(ctx, "network_id")
+	if !ok {
+		return
+	}
+
+	scanId, err := strconv.ParseInt(ctx.Query("scan_id"), 10, 0)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, &gin.H{
+			"message": fmt.Sprintf("%s: %s", ErrInvalidParameter, "scan_id"),
+		})
+		return
+	}
+
+	if ok := checkScanLease(ctx, e.ApiContext, networkId, scanId, ctx.Query("worker_id")); !ok {
+		return
+	}
+
+	err = e.Querier.DeleteScanLease(ctx, database.DeleteScanLeaseParams{
+		NetworkID: networkId,
+		ScanID:    scanId,
+	})
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+			"message": ErrDatabaseDelete,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &gin.H{
+		"message": "Scan lease released",
+	})
+}
+
+// leaseHolder is who a lease is held by, the caller's principal and the id of
+// the worker making the request, as several workers may share a token
+func leaseHolder(ctx *gin.Context, workerId string) string {
+	return principalFrom(ctx).Name + "/" + workerId
+}
+
+// checkScanLease makes sure the caller may submit to or release a network's
+// scan. A scan that's leased may only be touched by its worker (or an admin),
+// and a scan whose network was claimed by another worker can't be touched at
+// all. Scans that were never leased aren't restricted.
+func checkScanLease(ctx *gin.Context, apiContext *types.ApiContext, networkId, scanId int64, workerId string) bool {
+	lease, err := apiContext.Querier.GetScanLease(ctx, networkId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return true
+	} else if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+			"message": ErrDatabaseLookup,
+			"error":   err.Error(),
+		})
+		return false
+	}
+
+	principal := principalFrom(ctx)
+	if lease.ScanID != scanId {
+		if lease.ExpiresAt <= time.Now().Unix() {
+			return true
+		}
+
+		ctx.AbortWithStatusJSON(http.StatusConflict, &gin.H{
+			"message": fmt.Sprintf("network %d is leased to %s for another scan", networkId, lease.Worker),
+		})
+		return false
+	}
+
+	if lease.Worker != leaseHolder(ctx, workerId) && principal.Role != auth.RoleAdmin {
+		ctx.AbortWithStatusJSON(http.StatusConflict, &gin.H{
+			"message": fmt.Sprintf("network scan %d is leased to %s", scanId, lease.Worker),
+		})
+		return false
+	}
+
+	return true
+}
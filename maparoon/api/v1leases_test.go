@@ -0,0 +1,57 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/pirogoeth/apps/maparoon/types"
+)
+
+func TestScanLeasesAreHeldByWorkerId(t *testing.T) {
+	router, apiContext := setupTestAuthAPI(t)
+	network := createTestNetwork(t, apiContext, "lan", "10.0.0.0")
+
+	w := serveWithToken(router, http.MethodPost, "/v1/leases", "worker-token", &types.ClaimScanLeasesRequest{})
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 claiming without a worker id, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = serveWithToken(router, http.MethodPost, "/v1/leases", "worker-token", &types.ClaimScanLeasesRequest{WorkerId: "scanner-a"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 claiming, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp struct {
+		Leases []types.ScanLeaseGrant `json:"leases"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("could not decode response: %s", err)
+	}
+	if len(resp.Leases) != 1 || resp.Leases[0].Lease.Worker != "config:worker/scanner-a" {
+		t.Fatalf("expected the network to be leased to config:worker/scanner-a, got %+v", resp.Leases)
+	}
+	scanId := resp.Leases[0].Scan.ID
+
+	// Another worker sharing the token doesn't hold the lease
+	heartbeatPath := fmt.Sprintf("/v1/leases/%d/heartbeat", network.ID)
+	w = serveWithToken(router, http.MethodPost, heartbeatPath, "worker-token", &types.HeartbeatScanLeaseRequest{ScanId: scanId, WorkerId: "scanner-b"})
+	if w.Code != http.StatusConflict {
+		t.Errorf("expected 409 heartbeating another worker's lease, got %d: %s", w.Code, w.Body.String())
+	}
+
+	releasePath := fmt.Sprintf("/v1/leases/%d?scan_id=%d", network.ID, scanId)
+	if w = serveWithToken(router, http.MethodDelete, releasePath+"&worker_id=scanner-b", "worker-token"); w.Code != http.StatusConflict {
+		t.Errorf("expected 409 releasing another worker's lease, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = serveWithToken(router, http.MethodPost, heartbeatPath, "worker-token", &types.HeartbeatScanLeaseRequest{ScanId: scanId, WorkerId: "scanner-a"})
+	if w.Code != http.StatusOK {
+		t.Errorf("expected 200 heartbeating the lease, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if w = serveWithToken(router, http.MethodDelete, releasePath+"&worker_id=scanner-a", "worker-token"); w.Code != http.StatusOK {
+		t.Errorf("expected 200 releasing the lease, got %d: %s", w.Code, w.Body.String())
+	}
+}
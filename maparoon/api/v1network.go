@@ -4,7 +4,6 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
-	"net"
 	"net/http"
 	"strconv"
 	"strings"
@@ -25,6 +24,12 @@ func (e *v1NetworkEndpoints) RegisterRoutesTo(router *gin.RouterGroup) {
 	router.POST("/networks", e.createNetwork)
 	router.PUT("/networks/:id", e.updateNetwork)
 	router.DELETE("/networks/:id", e.deleteNetwork)
+	router.GET("/networks/:id/hosts", e.listNetworkHosts)
+	router.POST("/networks/:id/hosts", e.importNetworkHosts)
+	router.GET("/networks/:id/vulnerabilities", e.listNetworkVulnerabilities)
+	router.GET("/networks/:id/attributes", e.listNetworkAttributes)
+	router.PUT("/networks/:id/attributes/:key", e.setNetworkAttribute)
+	router.DELETE("/networks/:id/attributes/:key", e.deleteNetworkAttribute)
 }
 
 func (e *v1NetworkEndpoints) listNetworks(ctx *gin.Context) {
@@ -71,23 +76,16 @@ func (e *v1NetworkEndpoints) getNetwork(ctx *gin.Context) {
 		})
 		return
 	} else if networkAddrStr := ctx.Query("address"); networkAddrStr != "" {
-		var addressStr string
-		if strings.Contains(networkAddrStr, "/") {
-			split := strings.Split(networkAddrStr, "/")
-			addressStr = split[0]
-		} else {
-			addressStr = networkAddrStr
-		}
-
-		addr := net.ParseIP(addressStr)
-		if addr == nil {
+		addressStr, _, _ := strings.Cut(networkAddrStr, "/")
+		addr, err := database.CanonicalAddress(addressStr)
+		if err != nil {
 			ctx.AbortWithStatusJSON(http.StatusBadRequest, &gin.H{
 				"message": fmt.Sprintf("%s: %s", ErrInvalidParameter, "address"),
 			})
 			return
 		}
 
-		network, err := e.Querier.GetNetworkByAddress(ctx, string(addr))
+		network, err := e.Querier.GetNetworkByAddress(ctx, addr)
 		if err != nil {
 			ctx.JSON(http.StatusInternalServerError, &gin.H{
 				"message": ErrDatabaseLookup,
@@ -125,7 +123,17 @@ func (e *v1NetworkEndpoints) createNetwork(ctx *gin.Context) {
 		return
 	}
 
-	_, err := e.Querier.GetNetworkByAddress(ctx, networkParams.Address)
+	address, err := database.CanonicalNetwork(networkParams.Address, networkParams.Cidr)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, &gin.H{
+			"message": fmt.Sprintf("%s: %s", ErrInvalidParameter, "address"),
+			"error":   err.Error(),
+		})
+		return
+	}
+	networkParams.Address = address
+
+	_, err = e.Querier.GetNetworkByAddress(ctx, networkParams.Address)
 	if err != nil && !errors.Is(err, sql.ErrNoRows) {
 		logrus.Errorf("failed to check if network exists: %s", err)
 		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
@@ -164,7 +172,22 @@ func (e *v1NetworkEndpoints) deleteNetwork(ctx *gin.Context) {
 		return
 	}
 
-	err := e.Querier.DeleteNetwork(ctx, network.ID)
+	hosts, err := e.Querier.ListHostsByNetwork(ctx, network.ID)
+	if err != nil {
+		logrus.Errorf("could not list hosts of network: %s", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+			"message": ErrDatabaseLookup,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	addresses := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		addresses = append(addresses, host.Address)
+	}
+
+	err = e.Querier.DeleteNetwork(ctx, network.ID)
 	if err != nil {
 		logrus.Errorf("failed to delete network: %s", err)
 		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
@@ -174,6 +197,19 @@ func (e *v1NetworkEndpoints) deleteNetwork(ctx *gin.Context) {
 		return
 	}
 
+	logrus.Debugf("check-out searcher handle")
+	handle := e.Searcher.SearcherHandle()
+	defer handle.Close()
+
+	if err := deleteHostScans(ctx, e.ApiContext, handle.Index(), addresses...); err != nil {
+		logrus.Errorf("failed to delete host scans of network: %s", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+			"message": ErrDatabaseDelete,
+			"error":   err.Error(),
+		})
+		return
+	}
+
 	ctx.JSON(http.StatusOK, &gin.H{
 		"message":  "Network deleted",
 		"networks": []*database.Network{network},
@@ -216,35 +252,107 @@ func (e *v1NetworkEndpoints) updateNetwork(ctx *gin.Context) {
 	})
 }
 
-func (e *v1NetworkEndpoints) getNetworkByPathParam(ctx *gin.Context) (*database.Network, bool) {
-	networkIdStr := ctx.Param("id")
-	if networkIdStr == "" {
-		logrus.Debugf("blank `id` parameter provided")
+func (e *v1NetworkEndpoints) listNetworkAttributes(ctx *gin.Context) {
+	network, ok := e.getNetworkByPathParam(ctx)
+	if !ok {
+		return
+	}
+
+	attributes, err := e.Querier.ListNetworkAttributes(ctx, network.ID)
+	if err != nil {
+		logrus.Errorf("could not list network attributes: %s", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+			"message": ErrDatabaseLookup,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	if attributes == nil {
+		attributes = []database.NetworkAttribute{}
+	}
+
+	ctx.JSON(http.StatusOK, &gin.H{
+		"attributes": attributes,
+	})
+}
+
+func (e *v1NetworkEndpoints) setNetworkAttribute(ctx *gin.Context) {
+	network, ok := e.getNetworkByPathParam(ctx)
+	if !ok {
+		return
+	}
+
+	req := types.SetNetworkAttributeRequest{}
+	if err := ctx.BindJSON(&req); err != nil {
+		logrus.Warnf("failed to bind request to types.SetNetworkAttributeRequest: %s", err)
 		ctx.AbortWithStatusJSON(http.StatusBadRequest, &gin.H{
-			"message": fmt.Sprintf("%s: %s", ErrInvalidParameter, "id"),
+			"message": ErrFailedToBind,
+			"error":   err.Error(),
 		})
-		return nil, false
+		return
 	}
 
-	networkId, err := strconv.ParseInt(networkIdStr, 10, 0)
+	if ctx.Param("key") == scanProfileAttribute {
+		_, err := e.Querier.GetScanProfileByName(ctx, req.Value)
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, &gin.H{
+				"message": fmt.Sprintf("scan profile not found: %s", req.Value),
+			})
+			return
+		} else if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+				"message": ErrDatabaseLookup,
+				"error":   err.Error(),
+			})
+			return
+		}
+	}
+
+	attribute, err := e.Querier.SetNetworkAttribute(ctx, database.SetNetworkAttributeParams{
+		NetworkID: network.ID,
+		Key:       ctx.Param("key"),
+		Value:     req.Value,
+	})
 	if err != nil {
-		logrus.Debugf("invalid `id` parameter provided")
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, &gin.H{
-			"message": fmt.Sprintf("%s: %s", ErrInvalidParameter, "id"),
+		logrus.Errorf("failed to set network attribute: %s", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+			"message": ErrDatabaseUpdate,
 			"error":   err.Error(),
 		})
-		return nil, false
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &gin.H{
+		"message":    "Network attribute set",
+		"attributes": []database.NetworkAttribute{attribute},
+	})
+}
+
+func (e *v1NetworkEndpoints) deleteNetworkAttribute(ctx *gin.Context) {
+	network, ok := e.getNetworkByPathParam(ctx)
+	if !ok {
+		return
 	}
 
-	network, err := e.Querier.GetNetworkById(ctx, networkId)
+	err := e.Querier.DeleteNetworkAttribute(ctx, database.DeleteNetworkAttributeParams{
+		NetworkID: network.ID,
+		Key:       ctx.Param("key"),
+	})
 	if err != nil {
-		logrus.Errorf("error fetching network from database (by id): %s", err)
+		logrus.Errorf("failed to delete network attribute: %s", err)
 		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
-			"message": ErrDatabaseLookup,
+			"message": ErrDatabaseDelete,
 			"error":   err.Error(),
 		})
-		return nil, false
+		return
 	}
 
-	return &network, true
+	ctx.JSON(http.StatusOK, &gin.H{
+		"message": "Network attribute deleted",
+	})
+}
+
+func (e *v1NetworkEndpoints) getNetworkByPathParam(ctx *gin.Context) (*database.Network, bool) {
+	return extractNetworkFromPathParam(ctx, e.ApiContext, "id")
 }
@@ -0,0 +1,187 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"github.com/pirogoeth/apps/maparoon/database"
+	"github.com/pirogoeth/apps/maparoon/types"
+)
+
+func (e *v1NetworkEndpoints) listNetworkHosts(ctx *gin.Context) {
+	network, ok := e.getNetworkByPathParam(ctx)
+	if !ok {
+		return
+	}
+
+	hosts, err := e.Querier.ListHostsByNetwork(ctx, network.ID)
+	if err != nil {
+		logrus.Errorf("could not list hosts of network %s: %s", network.Name, err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+			"message": ErrDatabaseLookup,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	if hosts == nil {
+		hosts = []database.Host{}
+	}
+
+	ctx.JSON(http.StatusOK, &gin.H{
+		"hosts": hosts,
+	})
+}
+
+// importNetworkHosts adds an explicit list of addresses and hostnames to the
+// network. Addresses outside of the network are skipped, as are existing hosts.
+func (e *v1NetworkEndpoints) importNetworkHosts(ctx *gin.Context) {
+	if ok := assertContentTypeJson(ctx); !ok {
+		return
+	}
+
+	network, ok := e.getNetworkByPathParam(ctx)
+	if !ok {
+		return
+	}
+
+	req := types.ImportHostsRequest{}
+	if err := ctx.BindJSON(&req); err != nil {
+		logrus.Errorf("failed to bind request to types.ImportHostsRequest: %s", err)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, &gin.H{
+			"message": ErrFailedToBind,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	// Maps each address to the comment to create its host with
+	candidates := make(map[string]string)
+	skipped := make([]string, 0)
+	for _, address := range req.Addresses {
+		canonical, err := database.CanonicalAddress(address)
+		if err != nil || !network.Contains(canonical) {
+			skipped = append(skipped, address)
+			continue
+		}
+		candidates[canonical] = ""
+	}
+
+	for _, hostname := range req.Hostnames {
+		addresses, err := resolveHostname(ctx, hostname)
+		if err != nil {
+			logrus.Warnf("could not resolve %s: %s", hostname, err)
+			skipped = append(skipped, hostname)
+			continue
+		}
+
+		found := false
+		for _, address := range addresses {
+			if network.Contains(address) {
+				candidates[address] = hostname
+				found = true
+			}
+		}
+		if !found {
+			skipped = append(skipped, hostname)
+		}
+	}
+
+	addresses := make([]string, 0, len(candidates))
+	for address := range candidates {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+
+	hosts := make([]database.Host, 0)
+	for _, address := range addresses {
+		_, err := e.Querier.GetHost(ctx, address)
+		if err == nil {
+			continue
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+				"message": ErrDatabaseLookup,
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		host, err := e.Querier.CreateHost(ctx, database.CreateHostParams{
+			Address:   address,
+			NetworkID: network.ID,
+			Comments:  candidates[address],
+		})
+		if err != nil {
+			logrus.Errorf("failed to create host in database: %s", err)
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+				"message": ErrDatabaseInsert,
+				"error":   err.Error(),
+			})
+			return
+		}
+		hosts = append(hosts, host)
+	}
+
+	ctx.JSON(http.StatusOK, &gin.H{
+		"message": fmt.Sprintf("Imported %d hosts", len(hosts)),
+		"hosts":   hosts,
+		"skipped": skipped,
+	})
+}
+
+func resolveHostname(ctx context.Context, hostname string) ([]string, error) {
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", hostname)
+	if err != nil {
+		return nil, err
+	}
+
+	addresses := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		addresses = append(addresses, addr.Unmap().String())
+	}
+
+	return addresses, nil
+}
+
+// networkScanTargets lists the addresses to scan in a network that's too large
+// to sweep. Those are its known hosts, along with any address in it that
+// another host has in its neighbor tables.
+func networkScanTargets(ctx context.Context, apiCtx *types.ApiContext, network database.Network) ([]string, error) {
+	hosts, err := apiCtx.Querier.ListHostsByNetwork(ctx, network.ID)
+	if err != nil {
+		return nil, fmt.Errorf("could not list hosts: %w", err)
+	}
+
+	arpEntries, err := apiCtx.Querier.ListAllHostArpEntries(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not list neighbor tables: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	targets := make([]string, 0, len(hosts))
+	add := func(address string) {
+		canonical, err := database.CanonicalAddress(address)
+		if err != nil || seen[canonical] || !network.Contains(canonical) {
+			return
+		}
+		seen[canonical] = true
+		targets = append(targets, canonical)
+	}
+
+	for _, host := range hosts {
+		add(host.Address)
+	}
+	for _, entry := range arpEntries {
+		add(entry.IpAddress)
+	}
+
+	return targets, nil
+}
@@ -0,0 +1,274 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pirogoeth/apps/maparoon/alerting"
+	"github.com/pirogoeth/apps/maparoon/database"
+	"github.com/pirogoeth/apps/maparoon/scandiff"
+	"github.com/pirogoeth/apps/maparoon/types"
+	"github.com/sirupsen/logrus"
+)
+
+type v1NetworkScanEndpoints struct {
+	*types.ApiContext
+
+	alerts *alerting.Evaluator
+}
+
+func (e *v1NetworkScanEndpoints) RegisterRoutesTo(router *gin.RouterGroup) {
+	router.GET("/networks/:id/scans", e.listNetworkScans)
+	router.POST("/networks/:id/scans", e.createNetworkScan)
+	router.GET("/networks/:id/scans/:scan_id", e.getNetworkScan)
+	router.GET("/networks/:id/scans/:scan_id/changes", e.getNetworkScanChanges)
+	router.POST("/networks/:id/scans/:scan_id/alerts", e.evaluateNetworkScanAlerts)
+}
+
+func (e *v1NetworkScanEndpoints) listNetworkScans(ctx *gin.Context) {
+	network, ok := extractNetworkFromPathParam(ctx, e.ApiContext, "id")
+	if !ok {
+		return
+	}
+
+	scans, err := e.Querier.ListNetworkScansByNetwork(ctx, network.ID)
+	if err != nil {
+		logrus.Errorf("could not list network scans: %s", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+			"message": ErrDatabaseLookup,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	if scans == nil {
+		scans = []database.NetworkScan{}
+	}
+
+	ctx.JSON(http.StatusOK, &gin.H{
+		"scans": scans,
+	})
+}
+
+// createNetworkScan starts a scan of the network. The scan is finished when
+// the worker submits its host scans with the scan's id.
+func (e *v1NetworkScanEndpoints) createNetworkScan(ctx *gin.Context) {
+	network, ok := extractNetworkFromPathParam(ctx, e.ApiContext, "id")
+	if !ok {
+		return
+	}
+
+	scanId, err := e.Querier.CreateNetworkScan(ctx, database.CreateNetworkScanParams{
+		NetworkID: network.ID,
+		StartedBy: principalFrom(ctx).Name,
+	})
+	if err != nil {
+		logrus.Errorf("failed to create network scan: %s", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+			"message": ErrDatabaseInsert,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	scan, err := e.Querier.GetNetworkScan(ctx, database.GetNetworkScanParams{
+		ID:        scanId,
+		NetworkID: network.ID,
+	})
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+			"message": ErrDatabaseLookup,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	logrus.Infof("network scan %d of %s started by %s", scan.ID, network.Name, principalFrom(ctx).Name)
+
+	ctx.JSON(http.StatusCreated, &gin.H{
+		"message": "Network scan started",
+		"scans":   []database.NetworkScan{scan},
+	})
+}
+
+func (e *v1NetworkScanEndpoints) getNetworkScan(ctx *gin.Context) {
+	scan, ok := e.getNetworkScanByPathParam(ctx)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &gin.H{
+		"scans": []*database.NetworkScan{scan},
+	})
+}
+
+// getNetworkScanChanges compares a scan with the network's previous finished scan
+func (e *v1NetworkScanEndpoints) getNetworkScanChanges(ctx *gin.Context) {
+	scan, ok := e.getNetworkScanByPathParam(ctx)
+	if !ok {
+		return
+	}
+
+	if scan.FinishedAt == -1 {
+		ctx.AbortWithStatusJSON(http.StatusConflict, &gin.H{
+			"message": fmt.Sprintf("network scan has not finished: %d", scan.ID),
+		})
+		return
+	}
+
+	previousScan, changes, err := compareWithPreviousScan(ctx, e.Querier, scan)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+			"message": ErrDatabaseLookup,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &gin.H{
+		"scan":          scan,
+		"previous_scan": previousScan,
+		"changes":       changes,
+	})
+}
+
+// evaluateNetworkScanAlerts applies the alert rules to the scan's changes and
+// returns the alerts it fired
+func (e *v1NetworkScanEndpoints) evaluateNetworkScanAlerts(ctx *gin.Context) {
+	scan, ok := e.getNetworkScanByPathParam(ctx)
+	if !ok {
+		return
+	}
+
+	if scan.FinishedAt == -1 {
+		ctx.AbortWithStatusJSON(http.StatusConflict, &gin.H{
+			"message": fmt.Sprintf("network scan has not finished: %d", scan.ID),
+		})
+		return
+	}
+
+	network, err := e.Querier.GetNetworkById(ctx, scan.NetworkID)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+			"message": ErrDatabaseLookup,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	previousScan, changes, err := compareWithPreviousScan(ctx, e.Querier, scan)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+			"message": ErrDatabaseLookup,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	// The network's first scan is its baseline, alerting on it would report
+	// every host in the network
+	if previousScan == nil {
+		ctx.JSON(http.StatusOK, &gin.H{
+			"message": "no previous network scan to compare with",
+			"alerts":  []database.Alert{},
+		})
+		return
+	}
+
+	alerts, err := e.alerts.Evaluate(ctx, network, scan.ID, changes)
+	if err != nil {
+		logrus.Errorf("failed to evaluate alert rules: %s", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+			"message": ErrDatabaseInsert,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &gin.H{
+		"message": fmt.Sprintf("fired %d alerts", len(alerts)),
+		"alerts":  alerts,
+	})
+}
+
+func (e *v1NetworkScanEndpoints) getNetworkScanByPathParam(ctx *gin.Context) (*database.NetworkScan, bool) {
+	network, ok := extractNetworkFromPathParam(ctx, e.ApiContext, "id")
+	if !ok {
+		return nil, false
+	}
+
+	scanId, ok := parseIdPathParam(ctx, "scan_id")
+	if !ok {
+		return nil, false
+	}
+
+	scan, err := e.Querier.GetNetworkScan(ctx, database.GetNetworkScanParams{
+		ID:        scanId,
+		NetworkID: network.ID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.AbortWithStatusJSON(http.StatusNotFound, &gin.H{
+			"message": fmt.Sprintf("network scan not found: %d", scanId),
+		})
+		return nil, false
+	} else if err != nil {
+		logrus.Errorf("error fetching network scan from database: %s", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+			"message": ErrDatabaseLookup,
+			"error":   err.Error(),
+		})
+		return nil, false
+	}
+
+	return &scan, true
+}
+
+// compareWithPreviousScan diffs a scan against the network's previous finished
+// scan. The first scan of a network has nothing to be compared with, so every
+// host it found is new.
+func compareWithPreviousScan(ctx *gin.Context, querier *database.Queries, scan *database.NetworkScan) (*database.NetworkScan, *scandiff.Changes, error) {
+	current, err := loadScanDocuments(ctx, querier, scan.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	prev, err := querier.GetPreviousNetworkScan(ctx, database.GetPreviousNetworkScanParams{
+		NetworkID: scan.NetworkID,
+		ID:        scan.ID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, scandiff.Compare(nil, current), nil
+	} else if err != nil {
+		return nil, nil, fmt.Errorf("could not find previous network scan: %w", err)
+	}
+
+	previous, err := loadScanDocuments(ctx, querier, prev.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &prev, scandiff.Compare(previous, current), nil
+}
+
+// loadScanDocuments returns the host scans recorded for a network scan
+func loadScanDocuments(ctx *gin.Context, querier *database.Queries, scanId int64) ([]*types.HostScanDocument, error) {
+	snapshots, err := querier.ListHostScanSnapshotsByScan(ctx, scanId)
+	if err != nil {
+		return nil, fmt.Errorf("could not list host scan snapshots: %w", err)
+	}
+
+	docs := make([]*types.HostScanDocument, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		doc := &types.HostScanDocument{}
+		if err := json.Unmarshal([]byte(snapshot.Document), doc); err != nil {
+			return nil, fmt.Errorf("could not decode host scan snapshot of %s: %w", snapshot.Address, err)
+		}
+		docs = append(docs, doc)
+	}
+
+	return docs, nil
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"github.com/pirogoeth/apps/maparoon/database"
+)
+
+type vulnerableService struct {
+	Address         string                 `json:"address"`
+	Port            int64                  `json:"port"`
+	Protocol        string                 `json:"protocol"`
+	Cpe             string                 `json:"cpe"`
+	Vulnerabilities []serviceVulnerability `json:"vulnerabilities"`
+}
+
+type serviceVulnerability struct {
+	Id          string  `json:"id"`
+	Severity    string  `json:"severity"`
+	Score       float64 `json:"score"`
+	Description string  `json:"description"`
+}
+
+// listNetworkVulnerabilities lists the network's services that have known
+// vulnerabilities, the most severe first. `min_score` leaves out the
+// vulnerabilities with a lower CVSS score.
+func (e *v1NetworkEndpoints) listNetworkVulnerabilities(ctx *gin.Context) {
+	network, ok := e.getNetworkByPathParam(ctx)
+	if !ok {
+		return
+	}
+
+	minScore, err := strconv.ParseFloat(queryOr(ctx, "min_score", "0"), 64)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, &gin.H{
+			"message": fmt.Sprintf("%s: %s", ErrInvalidParameter, "min_score"),
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	rows, err := e.Querier.ListNetworkVulnerabilities(ctx, database.ListNetworkVulnerabilitiesParams{
+		NetworkID: network.ID,
+		Score:     minScore,
+	})
+	if err != nil {
+		logrus.Errorf("could not list vulnerabilities of network %s: %s", network.Name, err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+			"message": ErrDatabaseLookup,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	// Rows come most severe first, so services keep the order of their most
+	// severe vulnerability
+	services := make([]*vulnerableService, 0)
+	byPort := make(map[string]*vulnerableService)
+	for _, row := range rows {
+		key := fmt.Sprintf("%s %d/%s", row.Address, row.Port, row.Protocol)
+		service, ok := byPort[key]
+		if !ok {
+			service = &vulnerableService{
+				Address:  row.Address,
+				Port:     row.Port,
+				Protocol: row.Protocol,
+				Cpe:      row.Cpe,
+			}
+			byPort[key] = service
+			services = append(services, service)
+		}
+
+		service.Vulnerabilities = append(service.Vulnerabilities, serviceVulnerability{
+			Id:          row.ID,
+			Severity:    row.Severity,
+			Score:       row.Score,
+			Description: row.Description,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, &gin.H{
+		"services": services,
+	})
+}
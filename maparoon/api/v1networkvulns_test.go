@@ -0,0 +1,77 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/pirogoeth/apps/maparoon/database"
+)
+
+func TestListNetworkVulnerabilities(t *testing.T) {
+	router, apiContext := setupTestAPI(t)
+	ctx := context.Background()
+	network := createTestNetwork(t, apiContext, "lan", "10.0.0.0")
+	for _, address := range []string{"10.0.0.5", "10.0.0.6"} {
+		if _, err := apiContext.Querier.CreateHost(ctx, database.CreateHostParams{NetworkID: network.ID, Address: address}); err != nil {
+			t.Fatalf("could not create host %s: %s", address, err)
+		}
+	}
+
+	for id, score := range map[string]float64{"CVE-1": 9.8, "CVE-2": 5.3, "CVE-3": 7.5} {
+		err := apiContext.Querier.SetVulnerability(ctx, database.SetVulnerabilityParams{ID: id, Severity: "high", Score: score})
+		if err != nil {
+			t.Fatalf("could not store %s: %s", id, err)
+		}
+	}
+
+	for _, match := range []database.CreateHostPortVulnerabilityParams{
+		{Address: "10.0.0.5", Port: 22, Protocol: "tcp", VulnerabilityID: "CVE-2", Cpe: "cpe:/a:openbsd:openssh"},
+		{Address: "10.0.0.5", Port: 22, Protocol: "tcp", VulnerabilityID: "CVE-3", Cpe: "cpe:/a:openbsd:openssh"},
+		{Address: "10.0.0.6", Port: 80, Protocol: "tcp", VulnerabilityID: "CVE-1", Cpe: "cpe:/a:apache:http_server"},
+	} {
+		if err := apiContext.Querier.CreateHostPortVulnerability(ctx, match); err != nil {
+			t.Fatalf("could not store vulnerability of %s: %s", match.Address, err)
+		}
+	}
+
+	list := func(query string) []vulnerableService {
+		w := serve(router, http.MethodGet, fmt.Sprintf("/v1/networks/%d/vulnerabilities%s", network.ID, query))
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var resp struct {
+			Services []vulnerableService `json:"services"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("could not decode response: %s", err)
+		}
+		return resp.Services
+	}
+
+	// Grouped by service, the most severe first
+	services := list("")
+	if len(services) != 2 {
+		t.Fatalf("expected 2 services, got %+v", services)
+	}
+	if services[0].Address != "10.0.0.6" || len(services[0].Vulnerabilities) != 1 {
+		t.Errorf("expected 10.0.0.6:80 first, got %+v", services[0])
+	}
+	ssh := services[1]
+	if ssh.Address != "10.0.0.5" || ssh.Port != 22 || len(ssh.Vulnerabilities) != 2 ||
+		ssh.Vulnerabilities[0].Id != "CVE-3" || ssh.Vulnerabilities[1].Id != "CVE-2" {
+		t.Errorf("expected both vulnerabilities of 10.0.0.5:22, most severe first, got %+v", ssh)
+	}
+
+	services = list("?min_score=7")
+	if len(services) != 2 || len(services[1].Vulnerabilities) != 1 || services[1].Vulnerabilities[0].Id != "CVE-3" {
+		t.Errorf("expected vulnerabilities scoring below 7 to be left out, got %+v", services)
+	}
+
+	if w := serve(router, http.MethodGet, fmt.Sprintf("/v1/networks/%d/vulnerabilities?min_score=high", network.ID)); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an invalid min_score, got %d", w.Code)
+	}
+}
@@ -0,0 +1,260 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pirogoeth/apps/maparoon/database"
+	"github.com/pirogoeth/apps/maparoon/types"
+	"github.com/sirupsen/logrus"
+)
+
+// scanProfileAttribute is the network attribute naming the scan profile a
+// network is scanned with
+const scanProfileAttribute = "scan_profile"
+
+type v1ScanProfileEndpoints struct {
+	*types.ApiContext
+}
+
+func (e *v1ScanProfileEndpoints) RegisterRoutesTo(router *gin.RouterGroup) {
+	router.GET("/scanprofiles", e.listScanProfiles)
+	router.POST("/scanprofiles", e.createScanProfile)
+	router.GET("/scanprofiles/:id", e.getScanProfile)
+	router.PUT("/scanprofiles/:id", e.updateScanProfile)
+	router.DELETE("/scanprofiles/:id", e.deleteScanProfile)
+}
+
+func (e *v1ScanProfileEndpoints) listScanProfiles(ctx *gin.Context) {
+	profiles, err := e.Querier.ListScanProfiles(ctx)
+	if err != nil {
+		logrus.Errorf("could not list scan profiles: %s", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+			"message": ErrDatabaseLookup,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	if profiles == nil {
+		profiles = []database.ScanProfile{}
+	}
+
+	ctx.JSON(http.StatusOK, &gin.H{
+		"scan_profiles": profiles,
+	})
+}
+
+// createScanProfile creates a scan profile. Fields left out of the request are
+// taken from the default profile.
+func (e *v1ScanProfileEndpoints) createScanProfile(ctx *gin.Context) {
+	if ok := assertContentTypeJson(ctx); !ok {
+		return
+	}
+
+	profile := types.DefaultScanProfile
+	profile.Name = ""
+	if ok := bindScanProfile(ctx, &profile); !ok {
+		return
+	}
+
+	newProfile, err := e.Querier.CreateScanProfile(ctx, database.CreateScanProfileParams{
+		Name:         profile.Name,
+		ScanType:     profile.ScanType,
+		Ports:        profile.Ports,
+		TopPorts:     profile.TopPorts,
+		NmapFlags:    profile.NmapFlags,
+		NmapScripts:  profile.NmapScripts,
+		SnmpEnabled:  profile.SnmpEnabled,
+		Rate:         profile.Rate,
+		ScanInterval: profile.ScanInterval,
+	})
+	if err != nil {
+		logrus.Errorf("failed to create scan profile in database: %s", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+			"message": ErrDatabaseInsert,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &gin.H{
+		"message":       "Successfully created scan profile",
+		"scan_profiles": []database.ScanProfile{newProfile},
+	})
+}
+
+func (e *v1ScanProfileEndpoints) getScanProfile(ctx *gin.Context) {
+	profile, ok := e.getScanProfileByPathParam(ctx)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &gin.H{
+		"scan_profiles": []*database.ScanProfile{profile},
+	})
+}
+
+func (e *v1ScanProfileEndpoints) updateScanProfile(ctx *gin.Context) {
+	profile, ok := e.getScanProfileByPathParam(ctx)
+	if !ok {
+		return
+	}
+
+	profileUpdate := *profile
+	if ok := bindScanProfile(ctx, &profileUpdate); !ok {
+		return
+	}
+
+	newProfile, err := e.Querier.UpdateScanProfile(ctx, database.UpdateScanProfileParams{
+		Name:         profileUpdate.Name,
+		ScanType:     profileUpdate.ScanType,
+		Ports:        profileUpdate.Ports,
+		TopPorts:     profileUpdate.TopPorts,
+		NmapFlags:    profileUpdate.NmapFlags,
+		NmapScripts:  profileUpdate.NmapScripts,
+		SnmpEnabled:  profileUpdate.SnmpEnabled,
+		Rate:         profileUpdate.Rate,
+		ScanInterval: profileUpdate.ScanInterval,
+		ID:           profile.ID,
+	})
+	if err != nil {
+		logrus.Errorf("failed to update scan profile: %s", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+			"message": ErrDatabaseUpdate,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	if newProfile.Name != profile.Name {
+		// Keep the networks using the profile pointed at it
+		if err := e.Querier.RenameNetworkAttributeValues(ctx, database.RenameNetworkAttributeValuesParams{
+			NewValue: newProfile.Name,
+			Key:      scanProfileAttribute,
+			OldValue: profile.Name,
+		}); err != nil {
+			logrus.Errorf("failed to rename scan profile of networks: %s", err)
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+				"message": ErrDatabaseUpdate,
+				"error":   err.Error(),
+			})
+			return
+		}
+	}
+
+	ctx.JSON(http.StatusOK, &gin.H{
+		"message":       "Scan profile updated",
+		"scan_profiles": []database.ScanProfile{newProfile},
+	})
+}
+
+// deleteScanProfile deletes a scan profile, as long as no network uses it
+func (e *v1ScanProfileEndpoints) deleteScanProfile(ctx *gin.Context) {
+	profile, ok := e.getScanProfileByPathParam(ctx)
+	if !ok {
+		return
+	}
+
+	attachments, err := e.Querier.ListNetworkAttributesByKey(ctx, scanProfileAttribute)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+			"message": ErrDatabaseLookup,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	networkIds := make([]string, 0)
+	for _, attachment := range attachments {
+		if attachment.Value == profile.Name {
+			networkIds = append(networkIds, fmt.Sprint(attachment.NetworkID))
+		}
+	}
+	if len(networkIds) > 0 {
+		ctx.AbortWithStatusJSON(http.StatusConflict, &gin.H{
+			"message": fmt.Sprintf("scan profile %s is used by networks: %s", profile.Name, strings.Join(networkIds, ", ")),
+		})
+		return
+	}
+
+	if err := e.Querier.DeleteScanProfile(ctx, profile.ID); err != nil {
+		logrus.Errorf("failed to delete scan profile: %s", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+			"message": ErrDatabaseDelete,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &gin.H{
+		"message":       "Scan profile deleted",
+		"scan_profiles": []*database.ScanProfile{profile},
+	})
+}
+
+func (e *v1ScanProfileEndpoints) getScanProfileByPathParam(ctx *gin.Context) (*database.ScanProfile, bool) {
+	profileId, ok := parseIdPathParam(ctx, "id")
+	if !ok {
+		return nil, false
+	}
+
+	profile, err := e.Querier.GetScanProfile(ctx, profileId)
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.AbortWithStatusJSON(http.StatusNotFound, &gin.H{
+			"message": fmt.Sprintf("scan profile not found: %d", profileId),
+		})
+		return nil, false
+	} else if err != nil {
+		logrus.Errorf("error fetching scan profile from database: %s", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+			"message": ErrDatabaseLookup,
+			"error":   err.Error(),
+		})
+		return nil, false
+	}
+
+	return &profile, true
+}
+
+// bindScanProfile binds the request over profile and validates the result
+func bindScanProfile(ctx *gin.Context, profile *database.ScanProfile) bool {
+	if err := ctx.BindJSON(profile); err != nil {
+		logrus.Warnf("failed to bind scan profile to database.ScanProfile: %s", err)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, &gin.H{
+			"message": ErrFailedToBind,
+			"error":   err.Error(),
+		})
+		return false
+	}
+
+	profile.ScanType = strings.ToLower(profile.ScanType)
+	if err := types.ValidateScanProfile(*profile); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, &gin.H{
+			"message": ErrInvalidParameter,
+			"error":   err.Error(),
+		})
+		return false
+	}
+
+	return true
+}
+
+// networkScanProfile returns the scan profile attached to the network, or the
+// default profile if it has none
+func networkScanProfile(ctx *gin.Context, apiContext *types.ApiContext, profileName string) (database.ScanProfile, error) {
+	if profileName == "" {
+		return types.DefaultScanProfile, nil
+	}
+
+	profile, err := apiContext.Querier.GetScanProfileByName(ctx, profileName)
+	if err != nil {
+		return database.ScanProfile{}, fmt.Errorf("could not look up scan profile %s: %w", profileName, err)
+	}
+
+	return profile, nil
+}
@@ -0,0 +1,407 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pirogoeth/apps/maparoon/database"
+	"github.com/pirogoeth/apps/maparoon/secrets"
+	"github.com/pirogoeth/apps/maparoon/types"
+	"github.com/sirupsen/logrus"
+)
+
+// redactedSecret replaces stored secrets in responses
+const redactedSecret = "********"
+
+type v1SnmpCredentialEndpoints struct {
+	*types.ApiContext
+}
+
+func (e *v1SnmpCredentialEndpoints) RegisterRoutesTo(router *gin.RouterGroup) {
+	router.GET("/snmpcredentials", e.listSnmpCredentials)
+	router.POST("/snmpcredentials", e.createSnmpCredential)
+	router.GET("/snmpcredentials/:id", e.getSnmpCredential)
+	router.PUT("/snmpcredentials/:id", e.updateSnmpCredential)
+	router.DELETE("/snmpcredentials/:id", e.deleteSnmpCredential)
+}
+
+func (e *v1SnmpCredentialEndpoints) listSnmpCredentials(ctx *gin.Context) {
+	credentials, err := e.Querier.ListSnmpCredentials(ctx)
+	if err != nil {
+		logrus.Errorf("could not list snmp credentials: %s", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+			"message": ErrDatabaseLookup,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	redacted := make([]database.SnmpCredential, 0, len(credentials))
+	for _, credential := range credentials {
+		redacted = append(redacted, redactSnmpCredential(credential))
+	}
+
+	ctx.JSON(http.StatusOK, &gin.H{
+		"snmp_credentials": redacted,
+	})
+}
+
+func (e *v1SnmpCredentialEndpoints) createSnmpCredential(ctx *gin.Context) {
+	if ok := assertContentTypeJson(ctx); !ok {
+		return
+	}
+
+	sealer, ok := e.sealer(ctx)
+	if !ok {
+		return
+	}
+
+	req := types.SnmpCredentialRequest{}
+	if ok := bindSnmpCredentialRequest(ctx, &req, nil); !ok {
+		return
+	}
+
+	sealed, err := sealSnmpCredential(sealer, req)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+			"message": "could not encrypt snmp credential",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	credential, err := e.Querier.CreateSnmpCredential(ctx, database.CreateSnmpCredentialParams{
+		Name:           req.Name,
+		NetworkID:      req.NetworkId,
+		Address:        req.Address,
+		Priority:       req.Priority,
+		Version:        req.Version,
+		Community:      sealed.Community,
+		Username:       req.Username,
+		AuthProtocol:   req.AuthProtocol,
+		AuthPassphrase: sealed.AuthPassphrase,
+		PrivProtocol:   req.PrivProtocol,
+		PrivPassphrase: sealed.PrivPassphrase,
+	})
+	if err != nil {
+		logrus.Errorf("failed to create snmp credential in database: %s", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+			"message": ErrDatabaseInsert,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &gin.H{
+		"message":          "Successfully created snmp credential",
+		"snmp_credentials": []database.SnmpCredential{redactSnmpCredential(credential)},
+	})
+}
+
+func (e *v1SnmpCredentialEndpoints) getSnmpCredential(ctx *gin.Context) {
+	credential, ok := e.getSnmpCredentialByPathParam(ctx)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &gin.H{
+		"snmp_credentials": []database.SnmpCredential{redactSnmpCredential(*credential)},
+	})
+}
+
+// updateSnmpCredential replaces a credential. Secrets left out of the request,
+// or sent back redacted as they were read, are kept.
+func (e *v1SnmpCredentialEndpoints) updateSnmpCredential(ctx *gin.Context) {
+	credential, ok := e.getSnmpCredentialByPathParam(ctx)
+	if !ok {
+		return
+	}
+
+	sealer, ok := e.sealer(ctx)
+	if !ok {
+		return
+	}
+
+	current, err := openSnmpCredential(sealer, *credential)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+			"message": "could not decrypt snmp credential",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	req := types.SnmpCredentialRequest{
+		Name:           credential.Name,
+		NetworkId:      credential.NetworkID,
+		Address:        credential.Address,
+		Priority:       credential.Priority,
+		Version:        credential.Version,
+		Username:       credential.Username,
+		AuthProtocol:   credential.AuthProtocol,
+		PrivProtocol:   credential.PrivProtocol,
+		Community:      current.Community,
+		AuthPassphrase: current.AuthPassphrase,
+		PrivPassphrase: current.PrivPassphrase,
+	}
+	if ok := bindSnmpCredentialRequest(ctx, &req, &current); !ok {
+		return
+	}
+
+	sealed, err := sealSnmpCredential(sealer, req)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+			"message": "could not encrypt snmp credential",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	newCredential, err := e.Querier.UpdateSnmpCredential(ctx, database.UpdateSnmpCredentialParams{
+		Name:           req.Name,
+		NetworkID:      req.NetworkId,
+		Address:        req.Address,
+		Priority:       req.Priority,
+		Version:        req.Version,
+		Community:      sealed.Community,
+		Username:       req.Username,
+		AuthProtocol:   req.AuthProtocol,
+		AuthPassphrase: sealed.AuthPassphrase,
+		PrivProtocol:   req.PrivProtocol,
+		PrivPassphrase: sealed.PrivPassphrase,
+		ID:             credential.ID,
+	})
+	if err != nil {
+		logrus.Errorf("failed to update snmp credential: %s", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+			"message": ErrDatabaseUpdate,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &gin.H{
+		"message":          "Snmp credential updated",
+		"snmp_credentials": []database.SnmpCredential{redactSnmpCredential(newCredential)},
+	})
+}
+
+func (e *v1SnmpCredentialEndpoints) deleteSnmpCredential(ctx *gin.Context) {
+	credential, ok := e.getSnmpCredentialByPathParam(ctx)
+	if !ok {
+		return
+	}
+
+	if err := e.Querier.DeleteSnmpCredential(ctx, credential.ID); err != nil {
+		logrus.Errorf("failed to delete snmp credential: %s", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+			"message": ErrDatabaseDelete,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &gin.H{
+		"message":          "Snmp credential deleted",
+		"snmp_credentials": []database.SnmpCredential{redactSnmpCredential(*credential)},
+	})
+}
+
+func (e *v1SnmpCredentialEndpoints) getSnmpCredentialByPathParam(ctx *gin.Context) (*database.SnmpCredential, bool) {
+	credentialId, ok := parseIdPathParam(ctx, "id")
+	if !ok {
+		return nil, false
+	}
+
+	credential, err := e.Querier.GetSnmpCredential(ctx, credentialId)
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.AbortWithStatusJSON(http.StatusNotFound, &gin.H{
+			"message": fmt.Sprintf("snmp credential not found: %d", credentialId),
+		})
+		return nil, false
+	} else if err != nil {
+		logrus.Errorf("error fetching snmp credential from database: %s", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+			"message": ErrDatabaseLookup,
+			"error":   err.Error(),
+		})
+		return nil, false
+	}
+
+	return &credential, true
+}
+
+// sealer returns the sealer for the stored credentials, which needs the
+// secrets key to be configured
+func (e *v1SnmpCredentialEndpoints) sealer(ctx *gin.Context) (*secrets.Sealer, bool) {
+	sealer, err := secrets.NewSealer(e.Config.Secrets.Key)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+			"message": "could not set up snmp credential encryption",
+			"error":   err.Error(),
+		})
+		return nil, false
+	}
+
+	return sealer, true
+}
+
+// sealSnmpCredential returns the request with its secrets encrypted
+func sealSnmpCredential(sealer *secrets.Sealer, req types.SnmpCredentialRequest) (types.SnmpCredentialRequest, error) {
+	var err error
+	for _, secret := range []*string{&req.Community, &req.AuthPassphrase, &req.PrivPassphrase} {
+		if *secret, err = sealer.Seal(*secret); err != nil {
+			return req, err
+		}
+	}
+
+	return req, nil
+}
+
+// bindSnmpCredentialRequest binds the request over req and validates the result.
+// When updating, secrets sent back redacted are replaced by the current ones.
+func bindSnmpCredentialRequest(ctx *gin.Context, req *types.SnmpCredentialRequest, current *types.SnmpCredential) bool {
+	if err := ctx.BindJSON(req); err != nil {
+		logrus.Warnf("failed to bind request to types.SnmpCredentialRequest: %s", err)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, &gin.H{
+			"message": ErrFailedToBind,
+			"error":   err.Error(),
+		})
+		return false
+	}
+
+	if current != nil {
+		keepRedactedSecret(&req.Community, current.Community)
+		keepRedactedSecret(&req.AuthPassphrase, current.AuthPassphrase)
+		keepRedactedSecret(&req.PrivPassphrase, current.PrivPassphrase)
+	}
+
+	req.Version = strings.TrimPrefix(strings.ToLower(req.Version), "v")
+	req.AuthProtocol = strings.ToLower(req.AuthProtocol)
+	req.PrivProtocol = strings.ToLower(req.PrivProtocol)
+
+	if req.Name == "" {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, &gin.H{
+			"message": fmt.Sprintf("%s: %s", ErrInvalidParameter, "name"),
+		})
+		return false
+	}
+
+	if req.NetworkId != 0 && req.Address != "" {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, &gin.H{
+			"message": ErrInvalidParameter,
+			"error":   "a credential is scoped to either a network or a host, not both",
+		})
+		return false
+	}
+
+	err := types.ValidateSnmpCredential(types.SnmpCredential{
+		Version:        req.Version,
+		Community:      req.Community,
+		Username:       req.Username,
+		AuthProtocol:   req.AuthProtocol,
+		AuthPassphrase: req.AuthPassphrase,
+		PrivProtocol:   req.PrivProtocol,
+		PrivPassphrase: req.PrivPassphrase,
+	})
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, &gin.H{
+			"message": ErrInvalidParameter,
+			"error":   err.Error(),
+		})
+		return false
+	}
+
+	return true
+}
+
+// keepRedactedSecret puts back the current secret when the request carries it
+// redacted, as read from the API
+func keepRedactedSecret(secret *string, current string) {
+	if *secret == redactedSecret {
+		*secret = current
+	}
+}
+
+func redactSnmpCredential(credential database.SnmpCredential) database.SnmpCredential {
+	for _, secret := range []*string{&credential.Community, &credential.AuthPassphrase, &credential.PrivPassphrase} {
+		if *secret != "" {
+			*secret = redactedSecret
+		}
+	}
+
+	return credential
+}
+
+func openSnmpCredential(sealer *secrets.Sealer, credential database.SnmpCredential) (types.SnmpCredential, error) {
+	opened := types.SnmpCredential{
+		ID:           credential.ID,
+		Address:      credential.Address,
+		Version:      credential.Version,
+		Username:     credential.Username,
+		AuthProtocol: credential.AuthProtocol,
+		PrivProtocol: credential.PrivProtocol,
+	}
+
+	var err error
+	if opened.Community, err = sealer.Open(credential.Community); err != nil {
+		return opened, err
+	}
+	if opened.AuthPassphrase, err = sealer.Open(credential.AuthPassphrase); err != nil {
+		return opened, err
+	}
+	if opened.PrivPassphrase, err = sealer.Open(credential.PrivPassphrase); err != nil {
+		return opened, err
+	}
+
+	return opened, nil
+}
+
+// networkSnmpCredentials returns the decrypted credentials to try on the
+// network's hosts, and which credential last worked for each host
+func networkSnmpCredentials(ctx *gin.Context, apiContext *types.ApiContext, networkId int64) ([]types.SnmpCredential, map[string]int64, error) {
+	stored, err := apiContext.Querier.ListSnmpCredentialsForNetwork(ctx, networkId)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not list snmp credentials: %w", err)
+	}
+
+	credentials := make([]types.SnmpCredential, 0, len(stored))
+	if len(stored) > 0 {
+		sealer, err := secrets.NewSealer(apiContext.Config.Secrets.Key)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for _, credential := range stored {
+			opened, err := openSnmpCredential(sealer, credential)
+			if err != nil {
+				return nil, nil, fmt.Errorf("could not decrypt snmp credential %s: %w", credential.Name, err)
+			}
+			credentials = append(credentials, opened)
+		}
+	}
+
+	attributes, err := apiContext.Querier.ListHostAttributesForNetworkByKey(ctx, database.ListHostAttributesForNetworkByKeyParams{
+		NetworkID: networkId,
+		Key:       types.SnmpCredentialAttribute,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not list known snmp credentials: %w", err)
+	}
+
+	known := make(map[string]int64, len(attributes))
+	for _, attribute := range attributes {
+		var credentialId int64
+		if _, err := fmt.Sscan(attribute.Value, &credentialId); err != nil {
+			logrus.Warnf("ignoring invalid %s attribute of host %s: %s", types.SnmpCredentialAttribute, attribute.Address, attribute.Value)
+			continue
+		}
+		known[attribute.Address] = credentialId
+	}
+
+	return credentials, known, nil
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/pirogoeth/apps/maparoon/database"
+	"github.com/pirogoeth/apps/maparoon/secrets"
+)
+
+func TestUpdateSnmpCredentialKeepsRedactedSecrets(t *testing.T) {
+	router, apiContext := setupTestAPI(t)
+	apiContext.Config.Secrets.Key = "test-key"
+
+	w := serveJSON(t, router, http.MethodPost, "/v1/snmpcredentials", map[string]any{
+		"name":            "core",
+		"version":         "3",
+		"username":        "monitor",
+		"auth_protocol":   "sha",
+		"auth_passphrase": "auth-secret",
+		"priv_protocol":   "aes",
+		"priv_passphrase": "priv-secret",
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 creating credential, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var created struct {
+		SnmpCredentials []database.SnmpCredential `json:"snmp_credentials"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+		t.Fatalf("could not decode response: %s", err)
+	}
+	path := fmt.Sprintf("/v1/snmpcredentials/%d", created.SnmpCredentials[0].ID)
+
+	// Resubmit the credential as read, with a changed priority
+	w = serve(router, http.MethodGet, path)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 getting credential, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var read struct {
+		SnmpCredentials []map[string]any `json:"snmp_credentials"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &read); err != nil {
+		t.Fatalf("could not decode response: %s", err)
+	}
+	credential := read.SnmpCredentials[0]
+	if credential["auth_passphrase"] != redactedSecret {
+		t.Fatalf("expected the auth passphrase to be redacted, got %v", credential["auth_passphrase"])
+	}
+	credential["priority"] = 5
+
+	w = serveJSON(t, router, http.MethodPut, path, credential)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 updating credential, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// Leaving the secrets out keeps them too
+	w = serveJSON(t, router, http.MethodPut, path, map[string]any{"name": "core-switches"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 updating credential, got %d: %s", w.Code, w.Body.String())
+	}
+
+	stored, err := apiContext.Querier.GetSnmpCredential(context.Background(), created.SnmpCredentials[0].ID)
+	if err != nil {
+		t.Fatalf("could not get credential: %s", err)
+	}
+	if stored.Name != "core-switches" || stored.Priority != 5 {
+		t.Errorf("expected the credential to be updated, got name %q and priority %d", stored.Name, stored.Priority)
+	}
+
+	sealer, err := secrets.NewSealer(apiContext.Config.Secrets.Key)
+	if err != nil {
+		t.Fatalf("could not create sealer: %s", err)
+	}
+	opened, err := openSnmpCredential(sealer, stored)
+	if err != nil {
+		t.Fatalf("could not decrypt credential: %s", err)
+	}
+	if opened.AuthPassphrase != "auth-secret" || opened.PrivPassphrase != "priv-secret" {
+		t.Errorf("expected the stored secrets to be kept, got %q and %q", opened.AuthPassphrase, opened.PrivPassphrase)
+	}
+}
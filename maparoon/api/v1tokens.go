@@ -0,0 +1,160 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pirogoeth/apps/maparoon/auth"
+	"github.com/pirogoeth/apps/maparoon/database"
+	"github.com/pirogoeth/apps/maparoon/types"
+	"github.com/sirupsen/logrus"
+)
+
+type v1TokenEndpoints struct {
+	*types.ApiContext
+}
+
+func (e *v1TokenEndpoints) RegisterRoutesTo(router *gin.RouterGroup) {
+	router.GET("/tokens", e.listTokens)
+	router.POST("/tokens", e.createToken)
+	router.DELETE("/tokens/:id", e.deleteToken)
+	router.GET("/audit", e.listAuditLog)
+}
+
+func (e *v1TokenEndpoints) listTokens(ctx *gin.Context) {
+	tokens, err := e.Querier.ListApiTokens(ctx)
+	if err != nil {
+		logrus.Errorf("could not list api tokens: %s", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+			"message": ErrDatabaseLookup,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	if tokens == nil {
+		tokens = []database.ListApiTokensRow{}
+	}
+
+	ctx.JSON(http.StatusOK, &gin.H{
+		"tokens": tokens,
+	})
+}
+
+// createToken creates a named api token. The token is only ever returned in
+// this response.
+func (e *v1TokenEndpoints) createToken(ctx *gin.Context) {
+	if ok := assertContentTypeJson(ctx); !ok {
+		return
+	}
+
+	req := types.CreateApiTokenRequest{}
+	if err := ctx.BindJSON(&req); err != nil {
+		logrus.Errorf("failed to bind request to types.CreateApiTokenRequest: %s", err)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, &gin.H{
+			"message": ErrFailedToBind,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	if req.Name == "" {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, &gin.H{
+			"message": fmt.Sprintf("%s: %s", ErrInvalidParameter, "name"),
+		})
+		return
+	}
+
+	role, err := auth.ParseRole(req.Role)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, &gin.H{
+			"message": fmt.Sprintf("%s: %s", ErrInvalidParameter, "role"),
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	token, id, err := auth.CreateToken(ctx, e.Querier, req.Name, role)
+	if errors.Is(err, auth.ErrTokenExists) {
+		ctx.AbortWithStatusJSON(http.StatusConflict, &gin.H{
+			"message": fmt.Sprintf("api token already exists with name: %s", req.Name),
+		})
+		return
+	} else if err != nil {
+		logrus.Errorf("failed to create api token: %s", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+			"message": ErrDatabaseInsert,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &gin.H{
+		"message": "Successfully created api token, it will not be shown again",
+		"id":      id,
+		"name":    req.Name,
+		"role":    role,
+		"token":   token,
+	})
+}
+
+func (e *v1TokenEndpoints) deleteToken(ctx *gin.Context) {
+	tokenId, ok := parseIdPathParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	if err := e.Querier.DeleteApiToken(ctx, tokenId); err != nil {
+		logrus.Errorf("failed to delete api token: %s", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+			"message": ErrDatabaseDelete,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &gin.H{
+		"message": "Api token deleted",
+	})
+}
+
+// listAuditLog lists the most recent writes, optionally only those made with
+// the token named by `token`
+func (e *v1TokenEndpoints) listAuditLog(ctx *gin.Context) {
+	limit, err := strconv.ParseInt(queryOr(ctx, "limit", "100"), 10, 0)
+	if err != nil || limit <= 0 {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, &gin.H{
+			"message": fmt.Sprintf("%s: %s", ErrInvalidParameter, "limit"),
+		})
+		return
+	}
+
+	var entries []database.AuditLog
+	if tokenName := ctx.Query("token"); tokenName != "" {
+		entries, err = e.Querier.ListAuditLogEntriesByToken(ctx, database.ListAuditLogEntriesByTokenParams{
+			TokenName: tokenName,
+			Limit:     limit,
+		})
+	} else {
+		entries, err = e.Querier.ListAuditLogEntries(ctx, limit)
+	}
+	if err != nil {
+		logrus.Errorf("could not list audit log: %s", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+			"message": ErrDatabaseLookup,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	if entries == nil {
+		entries = []database.AuditLog{}
+	}
+
+	ctx.JSON(http.StatusOK, &gin.H{
+		"audit_log": entries,
+	})
+}
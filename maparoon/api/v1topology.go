@@ -0,0 +1,114 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"github.com/pirogoeth/apps/maparoon/topology"
+	"github.com/pirogoeth/apps/maparoon/types"
+)
+
+type v1TopologyEndpoints struct {
+	*types.ApiContext
+}
+
+func (e *v1TopologyEndpoints) RegisterRoutesTo(router *gin.RouterGroup) {
+	router.GET("/topology", e.getTopology)
+}
+
+// getTopology builds the topology graph out of the tables walked from hosts.
+// `format` is one of json (default), dot or graphml, `network_id` limits the
+// graph to one network.
+func (e *v1TopologyEndpoints) getTopology(ctx *gin.Context) {
+	var networkId int64
+	if networkIdStr := ctx.Query("network_id"); networkIdStr != "" {
+		var err error
+		networkId, err = strconv.ParseInt(networkIdStr, 10, 0)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, &gin.H{
+				"message": fmt.Sprintf("%s: %s", ErrInvalidParameter, "network_id"),
+				"error":   err.Error(),
+			})
+			return
+		}
+	}
+
+	format := queryOr(ctx, "format", "json")
+	if format != "json" && format != "dot" && format != "graphml" {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, &gin.H{
+			"message": fmt.Sprintf("%s: %s", ErrInvalidParameter, "format"),
+		})
+		return
+	}
+
+	input, err := e.topologyInput(ctx)
+	if err != nil {
+		logrus.Errorf("error fetching topology from database: %s", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, &gin.H{
+			"message": ErrDatabaseLookup,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	graph := topology.Build(*input, networkId)
+	switch format {
+	case "dot":
+		ctx.Header("Content-Type", "text/vnd.graphviz; charset=utf-8")
+		ctx.Status(http.StatusOK)
+		err = topology.WriteDOT(ctx.Writer, graph)
+	case "graphml":
+		ctx.Header("Content-Type", "application/graphml+xml; charset=utf-8")
+		ctx.Status(http.StatusOK)
+		err = topology.WriteGraphML(ctx.Writer, graph)
+	default:
+		ctx.JSON(http.StatusOK, &gin.H{
+			"topology": graph,
+		})
+	}
+	if err != nil {
+		logrus.Errorf("error writing topology as %s: %s", format, err)
+	}
+}
+
+func (e *v1TopologyEndpoints) topologyInput(ctx *gin.Context) (*topology.Input, error) {
+	var (
+		input topology.Input
+		err   error
+	)
+
+	if input.Networks, err = e.Querier.ListNetworks(ctx); err != nil {
+		return nil, err
+	}
+	if input.Hosts, err = e.Querier.ListHosts(ctx); err != nil {
+		return nil, err
+	}
+	if input.Interfaces, err = e.Querier.ListAllHostInterfaces(ctx); err != nil {
+		return nil, err
+	}
+	if input.ArpEntries, err = e.Querier.ListAllHostArpEntries(ctx); err != nil {
+		return nil, err
+	}
+	if input.FdbEntries, err = e.Querier.ListAllHostFdbEntries(ctx); err != nil {
+		return nil, err
+	}
+	if input.Neighbors, err = e.Querier.ListAllHostNeighbors(ctx); err != nil {
+		return nil, err
+	}
+
+	macAttributes, err := e.Querier.ListHostAttributesByKey(ctx, types.MacAddressAttribute)
+	if err != nil {
+		return nil, err
+	}
+
+	input.MacAddresses = make(map[string]string, len(macAttributes))
+	for _, attribute := range macAttributes {
+		input.MacAddresses[attribute.Address] = attribute.Value
+	}
+
+	return &input, nil
+}
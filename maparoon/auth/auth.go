@@ -0,0 +1,131 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/pirogoeth/apps/maparoon/database"
+)
+
+type Role string
+
+const (
+	// RoleAdmin may make any request
+	RoleAdmin Role = "admin"
+	// RoleWorker may read everything and submit scan results
+	RoleWorker Role = "worker"
+	// RoleReadOnly may only read
+	RoleReadOnly Role = "read-only"
+)
+
+var ErrTokenExists = errors.New("api token already exists")
+
+var Roles = []Role{RoleAdmin, RoleWorker, RoleReadOnly}
+
+// tokenPrefix makes maparoon tokens recognizable, e.g. by secret scanners
+const tokenPrefix = "mrn_"
+
+// workerRoutes are the writes a worker makes while scanning, keyed by method
+// and route
+var workerRoutes = map[string]bool{
+	"POST /v1/hosts":                              true,
+	"PUT /v1/hosts/:address/attributes/:key":      true,
+	"POST /v1/host/:host_address/ports":           true,
+	"POST /v1/hostscans":                          true,
+	"POST /v1/networks/:id/scans":                 true,
+	"POST /v1/networks/:id/scans/:scan_id/alerts": true,
+	"POST /v1/leases":                             true,
+	"POST /v1/leases/:network_id/heartbeat":       true,
+	"DELETE /v1/leases/:network_id":               true,
+	"POST /v1/imports/:format":                    true,
+}
+
+// adminRoutes can't be read by other roles either
+var adminRoutes = map[string]bool{
+	"GET /v1/tokens": true,
+	"GET /v1/audit":  true,
+}
+
+// Principal is the holder of the token a request was made with
+type Principal struct {
+	Name string `json:"name"`
+	Role Role   `json:"role"`
+}
+
+func ParseRole(role string) (Role, error) {
+	for _, r := range Roles {
+		if string(r) == role {
+			return r, nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown role `%s`, expected one of: admin, worker, read-only", role)
+}
+
+// Allows reports whether the role may make a request to route, which is the
+// route's pattern rather than the request path
+func (r Role) Allows(method, route string) bool {
+	if r == RoleAdmin {
+		return true
+	}
+
+	key := method + " " + route
+	if adminRoutes[key] {
+		return false
+	}
+
+	if method == http.MethodGet || method == http.MethodHead {
+		return true
+	}
+
+	return r == RoleWorker && workerRoutes[key]
+}
+
+// GenerateToken returns a new random token. Only its hash is stored.
+func GenerateToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("could not generate token: %w", err)
+	}
+
+	return tokenPrefix + base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateToken stores a new named token and returns it. The token can't be
+// retrieved again afterwards.
+func CreateToken(ctx context.Context, querier *database.Queries, name string, role Role) (string, int64, error) {
+	_, err := querier.GetApiTokenByName(ctx, name)
+	if err == nil {
+		return "", 0, fmt.Errorf("%w: %s", ErrTokenExists, name)
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return "", 0, fmt.Errorf("could not look up api token: %w", err)
+	}
+
+	token, err := GenerateToken()
+	if err != nil {
+		return "", 0, err
+	}
+
+	id, err := querier.CreateApiToken(ctx, database.CreateApiTokenParams{
+		Name:      name,
+		Role:      string(role),
+		TokenHash: HashToken(token),
+	})
+	if err != nil {
+		return "", 0, fmt.Errorf("could not store api token: %w", err)
+	}
+
+	return token, id, nil
+}
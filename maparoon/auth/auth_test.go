@@ -0,0 +1,51 @@
+package auth
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestRoleAllows(t *testing.T) {
+	cases := []struct {
+		role     Role
+		method   string
+		route    string
+		expected bool
+	}{
+		{RoleReadOnly, http.MethodGet, "/v1/networks", true},
+		{RoleReadOnly, http.MethodPost, "/v1/hostscans", false},
+		{RoleReadOnly, http.MethodGet, "/v1/tokens", false},
+		{RoleWorker, http.MethodPost, "/v1/hostscans", true},
+		{RoleWorker, http.MethodPost, "/v1/networks/:id/scans", true},
+		{RoleWorker, http.MethodDelete, "/v1/networks/:id", false},
+		{RoleWorker, http.MethodPost, "/v1/alertrules", false},
+		{RoleWorker, http.MethodPost, "/v1/leases/:network_id/heartbeat", true},
+		{RoleReadOnly, http.MethodPost, "/v1/leases", false},
+		{RoleWorker, http.MethodPut, "/v1/hosts/:address/attributes/:key", true},
+		{RoleWorker, http.MethodPost, "/v1/snmpcredentials", false},
+		{RoleWorker, http.MethodPost, "/v1/imports/:format", true},
+		{RoleAdmin, http.MethodDelete, "/v1/networks/:id", true},
+		{RoleAdmin, http.MethodGet, "/v1/tokens", true},
+	}
+
+	for _, c := range cases {
+		if allowed := c.role.Allows(c.method, c.route); allowed != c.expected {
+			t.Errorf("expected %s %s %s to be allowed=%v", c.role, c.method, c.route, c.expected)
+		}
+	}
+}
+
+func TestGenerateToken(t *testing.T) {
+	a, err := GenerateToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := GenerateToken()
+
+	if a == b {
+		t.Errorf("expected tokens to differ")
+	}
+	if HashToken(a) == HashToken(b) || HashToken(a) != HashToken(a) {
+		t.Errorf("expected hashes to be stable and distinct")
+	}
+}
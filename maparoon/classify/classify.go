@@ -0,0 +1,128 @@
+// Package classify guesses what kind of device a host is from what scans have
+// found out about it
+package classify
+
+import (
+	"regexp"
+	"slices"
+)
+
+const (
+	ClassAccessPoint    = "access_point"
+	ClassCamera         = "camera"
+	ClassEmbedded       = "embedded"
+	ClassFirewall       = "firewall"
+	ClassHypervisor     = "hypervisor"
+	ClassMediaPlayer    = "media_player"
+	ClassMobile         = "mobile"
+	ClassPrinter        = "printer"
+	ClassRouter         = "router"
+	ClassServer         = "server"
+	ClassStorage        = "storage"
+	ClassSwitch         = "switch"
+	ClassVirtualMachine = "virtual_machine"
+	ClassVoipPhone      = "voip_phone"
+	ClassWorkstation    = "workstation"
+)
+
+var Classes = []string{
+	ClassAccessPoint,
+	ClassCamera,
+	ClassEmbedded,
+	ClassFirewall,
+	ClassHypervisor,
+	ClassMediaPlayer,
+	ClassMobile,
+	ClassPrinter,
+	ClassRouter,
+	ClassServer,
+	ClassStorage,
+	ClassSwitch,
+	ClassVirtualMachine,
+	ClassVoipPhone,
+	ClassWorkstation,
+}
+
+// Facts are what's known about a host
+type Facts struct {
+	// Vendor is the MAC address vendor
+	Vendor string
+	// OsMatches are nmap's OS match names, vendors and families
+	OsMatches []string
+	// DeviceTypes are nmap's OS class and service device types
+	DeviceTypes []string
+	// OpenPorts are the open TCP and UDP port numbers
+	OpenPorts []int
+	// SysDescr is the SNMP sysDescr
+	SysDescr string
+}
+
+// Rule adds its score to its class when all of its conditions hold. A rule
+// without conditions never matches.
+type Rule struct {
+	Class string
+	Score int
+
+	Vendor     *regexp.Regexp
+	Os         *regexp.Regexp
+	DeviceType *regexp.Regexp
+	SysDescr   *regexp.Regexp
+	// Port holds when any of the ports is open
+	Port []int
+}
+
+func (r Rule) matches(facts Facts) bool {
+	if r.Vendor == nil && r.Os == nil && r.DeviceType == nil && r.SysDescr == nil && r.Port == nil {
+		return false
+	}
+
+	if r.Vendor != nil && !r.Vendor.MatchString(facts.Vendor) {
+		return false
+	}
+	if r.Os != nil && !slices.ContainsFunc(facts.OsMatches, r.Os.MatchString) {
+		return false
+	}
+	if r.DeviceType != nil && !slices.ContainsFunc(facts.DeviceTypes, r.DeviceType.MatchString) {
+		return false
+	}
+	if r.SysDescr != nil && !r.SysDescr.MatchString(facts.SysDescr) {
+		return false
+	}
+	if r.Port != nil && !slices.ContainsFunc(r.Port, func(port int) bool { return slices.Contains(facts.OpenPorts, port) }) {
+		return false
+	}
+
+	return true
+}
+
+// Result is the class with the highest score, "" if no rule matched
+type Result struct {
+	Class string `json:"class"`
+	Score int    `json:"score"`
+}
+
+// Classify scores the facts against the rules. Ties go to the class whose
+// first matching rule comes first.
+func Classify(rules []Rule, facts Facts) Result {
+	scores := make(map[string]int)
+	order := make([]string, 0)
+	for _, rule := range rules {
+		if !rule.matches(facts) {
+			continue
+		}
+
+		if _, ok := scores[rule.Class]; !ok {
+			order = append(order, rule.Class)
+		}
+		scores[rule.Class] += rule.Score
+	}
+
+	var result Result
+	for _, class := range order {
+		if scores[class] > result.Score {
+			result = Result{Class: class, Score: scores[class]}
+		}
+	}
+
+	return result
+}
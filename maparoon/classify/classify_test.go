@@ -0,0 +1,33 @@
+package classify
+
+import "testing"
+
+func TestClassify(t *testing.T) {
+	cases := []struct {
+		name     string
+		facts    Facts
+		expected string
+	}{
+		{"nothing known", Facts{}, ""},
+		{"jetdirect port", Facts{OpenPorts: []int{80, 443, 9100}}, ClassPrinter},
+		{"vmware guest", Facts{Vendor: "VMware, Inc.", OsMatches: []string{"Linux 5.4"}}, ClassVirtualMachine},
+		{
+			"esxi host on vmware mac",
+			Facts{Vendor: "VMware, Inc.", OsMatches: []string{"VMware ESXi 7.0"}, OpenPorts: []int{443, 902}},
+			ClassHypervisor,
+		},
+		{
+			"switch by sysdescr over vendor",
+			Facts{Vendor: "Cisco Systems, Inc", SysDescr: "Cisco IOS Software, C2960 Software, Catalyst 2960"},
+			ClassSwitch,
+		},
+		{"camera by device type", Facts{DeviceTypes: []string{"webcam"}, OpenPorts: []int{80}}, ClassCamera},
+		{"cisco ios is not a phone", Facts{OsMatches: []string{"Cisco IOS 15.2"}}, ""},
+	}
+
+	for _, c := range cases {
+		if result := Classify(DefaultRules, c.facts); result.Class != c.expected {
+			t.Errorf("%s: got %q (%d), want %q", c.name, result.Class, result.Score, c.expected)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package classify
+
+import "regexp"
+
+// DefaultRules weigh nmap's own device type and SNMP's sysDescr most, as they
+// come from the device itself. Ports and vendors only hint at a class.
+var DefaultRules = []Rule{
+	// nmap device types
+	{Class: ClassPrinter, Score: 10, DeviceType: regexp.MustCompile(`(?i)^printer`)},
+	{Class: ClassSwitch, Score: 10, DeviceType: regexp.MustCompile(`(?i)^switch$`)},
+	{Class: ClassRouter, Score: 10, DeviceType: regexp.MustCompile(`(?i)^(router|broadband router)$`)},
+	{Class: ClassFirewall, Score: 10, DeviceType: regexp.MustCompile(`(?i)^firewall$`)},
+	{Class: ClassAccessPoint, Score: 10, DeviceType: regexp.MustCompile(`(?i)^WAP$`)},
+	{Class: ClassCamera, Score: 10, DeviceType: regexp.MustCompile(`(?i)^webcam$`)},
+	{Class: ClassStorage, Score: 10, DeviceType: regexp.MustCompile(`(?i)^storage-misc$`)},
+	{Class: ClassVoipPhone, Score: 10, DeviceType: regexp.MustCompile(`(?i)^VoIP (phone|adapter)$`)},
+	{Class: ClassMediaPlayer, Score: 10, DeviceType: regexp.MustCompile(`(?i)^media device$`)},
+	{Class: ClassMobile, Score: 10, DeviceType: regexp.MustCompile(`(?i)^phone$`)},
+
+	// SNMP sysDescr
+	{Class: ClassPrinter, Score: 8, SysDescr: regexp.MustCompile(`(?i)printer|laserjet|officejet|jetdirect`)},
+	{Class: ClassHypervisor, Score: 8, SysDescr: regexp.MustCompile(`(?i)esxi|vmware esx|proxmox`)},
+	{Class: ClassFirewall, Score: 8, SysDescr: regexp.MustCompile(`(?i)pfsense|opnsense|fortigate|pan-os|adaptive security appliance`)},
+	{Class: ClassStorage, Score: 8, SysDescr: regexp.MustCompile(`(?i)synology|diskstation|qnap|truenas|freenas`)},
+	{Class: ClassSwitch, Score: 6, SysDescr: regexp.MustCompile(`(?i)switch|catalyst|procurve|nexus|\bEX\d{4}`)},
+	{Class: ClassRouter, Score: 5, SysDescr: regexp.MustCompile(`(?i)router|routeros|edgeos|\bMX\d+`)},
+	{Class: ClassAccessPoint, Score: 6, SysDescr: regexp.MustCompile(`(?i)access point|unifi ap|\bUAP\b`)},
+	{Class: ClassCamera, Score: 6, SysDescr: regexp.MustCompile(`(?i)ip camera|network camera`)},
+	{Class: ClassServer, Score: 1, SysDescr: regexp.MustCompile(`(?i)^linux`)},
+
+	// nmap OS matches
+	{Class: ClassHypervisor, Score: 8, Os: regexp.MustCompile(`(?i)esxi|proxmox|xenserver|hyper-v server`)},
+	{Class: ClassWorkstation, Score: 4, Os: regexp.MustCompile(`(?i)windows (xp|vista|7|8|8\.1|10|11)\b|mac os x|macos`)},
+	{Class: ClassServer, Score: 4, Os: regexp.MustCompile(`(?i)windows server|freebsd|openbsd`)},
+	{Class: ClassServer, Score: 1, Os: regexp.MustCompile(`(?i)linux`)},
+	{Class: ClassMobile, Score: 4, Os: regexp.MustCompile(`\b(iOS|iPadOS|Android)\b`)},
+
+	// Open ports
+	{Class: ClassPrinter, Score: 6, Port: []int{9100}},
+	{Class: ClassPrinter, Score: 3, Port: []int{515, 631}},
+	{Class: ClassCamera, Score: 4, Port: []int{554}},
+	{Class: ClassHypervisor, Score: 6, Port: []int{902, 8006}},
+	{Class: ClassHypervisor, Score: 3, Port: []int{16509}},
+	{Class: ClassStorage, Score: 4, Port: []int{3260}},
+	{Class: ClassStorage, Score: 2, Port: []int{2049, 5000, 5001}},
+	{Class: ClassVoipPhone, Score: 3, Port: []int{5060}},
+	{Class: ClassMediaPlayer, Score: 3, Port: []int{8008, 8009, 1400}},
+	{Class: ClassWorkstation, Score: 2, Port: []int{3389, 5900}},
+
+	// MAC address vendors
+	{Class: ClassVirtualMachine, Score: 5, Vendor: regexp.MustCompile(`(?i)vmware|pcs systemtechnik|xensource|parallels|qemu`)},
+	{Class: ClassCamera, Score: 6, Vendor: regexp.MustCompile(`(?i)hikvision|axis communications|dahua|amcrest|reolink`)},
+	{Class: ClassPrinter, Score: 3, Vendor: regexp.MustCompile(`(?i)brother|seiko epson|lexmark|kyocera|xerox|ricoh`)},
+	{Class: ClassStorage, Score: 5, Vendor: regexp.MustCompile(`(?i)synology|qnap`)},
+	{Class: ClassMediaPlayer, Score: 3, Vendor: regexp.MustCompile(`(?i)sonos|roku`)},
+	{Class: ClassEmbedded, Score: 3, Vendor: regexp.MustCompile(`(?i)espressif|raspberry pi|tuya|shelly`)},
+	{Class: ClassAccessPoint, Score: 2, Vendor: regexp.MustCompile(`(?i)ubiquiti|aruba|ruckus`)},
+	{Class: ClassSwitch, Score: 2, Vendor: regexp.MustCompile(`(?i)cisco|juniper|arista|mikrotik|netgear`)},
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 
 	"github.com/imroc/req/v3"
@@ -17,6 +18,7 @@ var (
 	ErrAlreadyExists = errors.New("resource already exists")
 	ErrNotFound      = errors.New("resource not found")
 	ErrInternal      = errors.New("upstream internal server error")
+	ErrLeaseLost     = errors.New("scan lease lost")
 )
 
 type Options struct {
@@ -49,6 +51,21 @@ type HostPortsResponse struct {
 	HostPorts []database.HostPort `json:"host_ports,omitempty"`
 }
 
+type NetworkScansResponse struct {
+	commonResponse
+	Scans []database.NetworkScan `json:"scans,omitempty"`
+}
+
+type AlertsResponse struct {
+	commonResponse
+	Alerts []database.Alert `json:"alerts,omitempty"`
+}
+
+type ScanLeasesResponse struct {
+	commonResponse
+	Leases []types.ScanLeaseGrant `json:"leases,omitempty"`
+}
+
 func NewClient(opts *Options) *Client {
 	cli := &Client{
 		httpClient: req.NewClient(),
@@ -156,3 +173,156 @@ func (c *Client) CreateHostScans(ctx context.Context, hostScansReq types.CreateH
 
 	return ret, nil
 }
+
+func (c *Client) CreateNetworkScan(ctx context.Context, networkId int64) (*NetworkScansResponse, error) {
+	resp, err := c.httpClient.R().
+		SetContext(ctx).
+		SetPathParam("network_id", fmt.Sprint(networkId)).
+		Post("/v1/networks/{network_id}/scans")
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.IsErrorState() {
+		return nil, fmt.Errorf("error: %s", resp.String())
+	}
+
+	ret := &NetworkScansResponse{}
+	if err := json.Unmarshal(resp.Bytes(), ret); err != nil {
+		logrus.Errorf("could not unmarshal response: %s", err)
+		return nil, err
+	}
+
+	if len(ret.Scans) == 0 {
+		return nil, fmt.Errorf("%w: no network scan returned", ErrInternal)
+	}
+
+	return ret, nil
+}
+
+func (c *Client) EvaluateAlerts(ctx context.Context, networkId, scanId int64) (*AlertsResponse, error) {
+	resp, err := c.httpClient.R().
+		SetContext(ctx).
+		SetPathParam("network_id", fmt.Sprint(networkId)).
+		SetPathParam("scan_id", fmt.Sprint(scanId)).
+		Post("/v1/networks/{network_id}/scans/{scan_id}/alerts")
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.IsErrorState() {
+		return nil, fmt.Errorf("error: %s", resp.String())
+	}
+
+	ret := &AlertsResponse{}
+	if err := json.Unmarshal(resp.Bytes(), ret); err != nil {
+		logrus.Errorf("could not unmarshal response: %s", err)
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (c *Client) ClaimScanLeases(ctx context.Context, claimReq types.ClaimScanLeasesRequest) (*ScanLeasesResponse, error) {
+	resp, err := c.httpClient.R().
+		SetContext(ctx).
+		SetBodyJsonMarshal(claimReq).
+		Post("/v1/leases")
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.IsErrorState() {
+		return nil, fmt.Errorf("error: %s", resp.String())
+	}
+
+	ret := &ScanLeasesResponse{}
+	if err := json.Unmarshal(resp.Bytes(), ret); err != nil {
+		logrus.Errorf("could not unmarshal response: %s", err)
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (c *Client) HeartbeatScanLease(ctx context.Context, networkId, scanId int64, workerId string) error {
+	resp, err := c.httpClient.R().
+		SetContext(ctx).
+		SetBodyJsonMarshal(types.HeartbeatScanLeaseRequest{ScanId: scanId, WorkerId: workerId}).
+		SetPathParam("network_id", fmt.Sprint(networkId)).
+		Post("/v1/leases/{network_id}/heartbeat")
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusConflict:
+		return fmt.Errorf("%w: %s", ErrLeaseLost, resp.String())
+	case resp.IsErrorState():
+		return fmt.Errorf("error: %s", resp.String())
+	}
+
+	return nil
+}
+
+func (c *Client) ReleaseScanLease(ctx context.Context, networkId, scanId int64, workerId string) error {
+	resp, err := c.httpClient.R().
+		SetContext(ctx).
+		SetPathParam("network_id", fmt.Sprint(networkId)).
+		SetQueryParam("scan_id", fmt.Sprint(scanId)).
+		SetQueryParam("worker_id", workerId).
+		Delete("/v1/leases/{network_id}")
+	if err != nil {
+		return err
+	}
+
+	if resp.IsErrorState() {
+		return fmt.Errorf("error: %s", resp.String())
+	}
+
+	return nil
+}
+
+func (c *Client) SetHostAttribute(ctx context.Context, address, key, value string) error {
+	resp, err := c.httpClient.R().
+		SetContext(ctx).
+		SetBodyJsonMarshal(types.SetHostAttributeRequest{Value: value}).
+		SetPathParam("address", address).
+		SetPathParam("key", key).
+		Put("/v1/hosts/{address}/attributes/{key}")
+	if err != nil {
+		return err
+	}
+
+	if resp.IsErrorState() {
+		return fmt.Errorf("error: %s", resp.String())
+	}
+
+	return nil
+}
+
+// ImportDiscoveries uploads a lease file or neighbor table dump in one of the
+// discovery formats
+func (c *Client) ImportDiscoveries(ctx context.Context, format string, body io.Reader) (*HostsResponse, error) {
+	resp, err := c.httpClient.R().
+		SetContext(ctx).
+		SetContentType("text/plain").
+		SetBody(body).
+		SetPathParam("format", format).
+		Post("/v1/imports/{format}")
+	if err != nil {
+		return nil, err
+	}
+
+	ret := &HostsResponse{}
+	if err := json.Unmarshal(resp.Bytes(), ret); err != nil {
+		logrus.Errorf("could not unmarshal response: %s", err)
+		return nil, err
+	}
+
+	if resp.IsErrorState() {
+		return nil, fmt.Errorf("error: %s: %s", ret.Message, ret.Error)
+	}
+
+	return ret, nil
+}
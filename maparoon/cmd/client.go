@@ -4,8 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"strings"
 
 	maparoonClient "github.com/pirogoeth/apps/maparoon/client"
+	"github.com/pirogoeth/apps/maparoon/discovery"
 	"github.com/pirogoeth/apps/pkg/logging"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -21,6 +25,7 @@ var (
 	}
 	clientBaseUrl string
 	clientDevMode bool
+	clientToken   string
 )
 
 func init() {
@@ -35,18 +40,36 @@ func init() {
 		"Enabled dev mode for the client",
 	)
 
+	clientCmd.PersistentFlags().StringVarP(
+		&clientToken,
+		"token", "t", "",
+		"API token for the maparoon API",
+	)
+
 	clientCmd.AddCommand(&cobra.Command{
 		Use:   "list-networks",
 		Short: "List networks",
 		Run:   listNetworks,
 	})
+	clientCmd.AddCommand(&cobra.Command{
+		Use:   "import <format> <file>",
+		Short: "Import hosts from a lease file or neighbor table dump, - reads stdin",
+		Long:  "Import hosts from a lease file or neighbor table dump, - reads stdin. Formats: " + strings.Join(discovery.Formats, ", "),
+		Args:  cobra.ExactArgs(2),
+		Run:   importDiscoveries,
+	})
 }
 
-func listNetworks(cmd *cobra.Command, args []string) {
-	client := maparoonClient.NewClient(&maparoonClient.Options{
-		BaseURL: clientBaseUrl,
-		DevMode: clientDevMode,
+func newClient() *maparoonClient.Client {
+	return maparoonClient.NewClient(&maparoonClient.Options{
+		BaseURL:     clientBaseUrl,
+		DevMode:     clientDevMode,
+		WorkerToken: clientToken,
 	})
+}
+
+func listNetworks(cmd *cobra.Command, args []string) {
+	client := newClient()
 
 	networks, err := client.ListNetworks(context.Background())
 	if err != nil {
@@ -62,3 +85,24 @@ func listNetworks(cmd *cobra.Command, args []string) {
 
 	fmt.Printf("%s\n", out)
 }
+
+func importDiscoveries(cmd *cobra.Command, args []string) {
+	var input io.Reader = os.Stdin
+	if args[1] != "-" {
+		file, err := os.Open(args[1])
+		if err != nil {
+			logrus.Fatalf("could not open %s: %s", args[1], err)
+			return
+		}
+		defer file.Close()
+		input = file
+	}
+
+	resp, err := newClient().ImportDiscoveries(context.Background(), args[0], input)
+	if err != nil {
+		logrus.Fatalf("could not import %s: %s", args[1], err)
+		return
+	}
+
+	fmt.Printf("%s\n", resp.Message)
+}
@@ -20,7 +20,7 @@ var rootCmd = &cobra.Command{
 }
 
 func init() {
-	rootCmd.AddCommand(clientCmd, serveCmd, workerCmd)
+	rootCmd.AddCommand(clientCmd, serveCmd, tokenCmd, vulnsCmd, workerCmd)
 }
 
 func appStart(component string) *types.Config {
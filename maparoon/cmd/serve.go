@@ -2,14 +2,16 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
+	"path"
+	"time"
 
-	"github.com/blevesearch/bleve"
-	bleveMapping "github.com/blevesearch/bleve/mapping"
 	"github.com/gin-gonic/gin"
 	"github.com/pirogoeth/apps/maparoon/api"
 	"github.com/pirogoeth/apps/maparoon/database"
+	"github.com/pirogoeth/apps/maparoon/hostindex"
 	"github.com/pirogoeth/apps/maparoon/types"
 	"github.com/pirogoeth/apps/pkg/search"
 	"github.com/pirogoeth/apps/pkg/system"
@@ -39,10 +41,7 @@ func serveFunc(cmd *cobra.Command, args []string) {
 		panic(fmt.Errorf("could not start (database): %w", err))
 	}
 
-	searcher, err := search.NewSearcher(search.SearcherOpts{
-		IndexDir:     cfg.Search.IndexDir,
-		IndexMapping: createSearchIndexMapping(),
-	})
+	searcher, err := openSearchIndex(ctx, cfg, dbWrapper.Querier())
 	if err != nil {
 		panic(fmt.Errorf("could not start (indexer): %w", err))
 	}
@@ -51,6 +50,7 @@ func serveFunc(cmd *cobra.Command, args []string) {
 	api.MustRegister(router, &types.ApiContext{
 		Config:   app.cfg,
 		Querier:  dbWrapper.Querier(),
+		Database: dbWrapper,
 		Searcher: searcher,
 	})
 
@@ -73,22 +73,102 @@ func serveFunc(cmd *cobra.Command, args []string) {
 	sw.Wait(ctx, cancel)
 }
 
-func createSearchIndexMapping() bleveMapping.IndexMapping {
-	osMapping := bleve.NewDocumentMapping()
-	osMapping.AddSubDocumentMapping("portsused", bleve.NewDocumentDisabledMapping())
+// openSearchIndex opens the search index, rebuilding it from the stored host
+// scans if it was created with an older index mapping. The new index is built
+// next to the old one and only swapped in once it's filled.
+func openSearchIndex(ctx context.Context, cfg *types.Config, querier *database.Queries) (*search.BleveSearcher, error) {
+	searchOpts := search.SearcherOpts{
+		IndexDir:     cfg.Search.IndexDir,
+		IndexMapping: hostindex.NewMapping(),
+	}
+
+	searcher, err := search.NewSearcher(searchOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	var indexed []string
+	handle := searcher.SearcherHandle()
+	current, err := hostindex.IsCurrent(handle.Index())
+	if err == nil && !current {
+		indexed, err = hostindex.Addresses(handle.Index())
+	}
+	handle.Close()
+	if err != nil || current {
+		return searcher, err
+	}
+
+	hostScans, err := querier.ListHostScans(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not list host scans: %w", err)
+	}
+
+	docs := make([]*types.HostScanDocument, 0, len(hostScans))
+	stored := make(map[string]bool, len(hostScans))
+	for _, hostScan := range hostScans {
+		doc := &types.HostScanDocument{}
+		if err := json.Unmarshal([]byte(hostScan.Document), doc); err != nil {
+			return nil, fmt.Errorf("could not decode host scan of %s: %w", hostScan.Address, err)
+		}
+		docs = append(docs, doc)
+		stored[doc.Address] = true
+	}
+
+	// Hosts indexed before host scans were stored can't be rebuilt
+	missing := 0
+	for _, address := range indexed {
+		if !stored[address] {
+			missing++
+		}
+	}
 
-	hostMapping := bleve.NewDocumentMapping()
-	hostMapping.AddSubDocumentMapping("ports", bleve.NewDocumentDisabledMapping())
-	hostMapping.AddSubDocumentMapping("os", osMapping)
+	logrus.Infof("search index mapping is outdated, rebuilding the index from %d host scans", len(docs))
 
-	nmapMapping := bleve.NewDocumentMapping()
-	nmapMapping.AddSubDocumentMapping("host", hostMapping)
+	rebuildDir := path.Join(cfg.Search.IndexDir, "rebuild")
+	if err := os.RemoveAll(rebuildDir); err != nil {
+		return nil, fmt.Errorf("could not remove unfinished index rebuild: %w", err)
+	}
 
-	docMapping := bleve.NewDocumentMapping()
-	docMapping.AddSubDocumentMapping("nmap", nmapMapping)
+	rebuilt, err := search.NewSearcher(search.SearcherOpts{
+		IndexDir:     rebuildDir,
+		IndexMapping: searchOpts.IndexMapping,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	indexer := rebuilt.IndexerHandle()
+	err = hostindex.Fill(indexer.Index(), docs)
+	indexer.Close()
+	if err != nil {
+		rebuilt.Close()
+		return nil, err
+	}
 
-	indexMapping := bleve.NewIndexMapping()
-	indexMapping.AddDocumentMapping("_default", docMapping)
+	if err := rebuilt.Close(); err != nil {
+		return nil, fmt.Errorf("could not close rebuilt index: %w", err)
+	}
+	if err := searcher.Close(); err != nil {
+		return nil, fmt.Errorf("could not close outdated index: %w", err)
+	}
+
+	indexPath := path.Join(cfg.Search.IndexDir, "index.bleve")
+	outdatedPath := fmt.Sprintf("%s.%d", indexPath, time.Now().Unix())
+	if err := os.Rename(indexPath, outdatedPath); err != nil {
+		return nil, fmt.Errorf("could not move outdated index: %w", err)
+	}
+	if err := os.Rename(path.Join(rebuildDir, "index.bleve"), indexPath); err != nil {
+		return nil, fmt.Errorf("could not move rebuilt index: %w", err)
+	}
+	if err := os.Remove(rebuildDir); err != nil {
+		logrus.Warnf("could not remove index rebuild directory: %s", err)
+	}
+
+	if missing > 0 {
+		logrus.Warnf("%d indexed hosts have no stored host scan and weren't rebuilt, the outdated index is kept at %s", missing, outdatedPath)
+	} else if err := os.RemoveAll(outdatedPath); err != nil {
+		logrus.Warnf("could not remove outdated index: %s", err)
+	}
 
-	return indexMapping
+	return search.NewSearcher(searchOpts)
 }
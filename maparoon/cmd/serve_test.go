@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/pirogoeth/apps/maparoon/database"
+	"github.com/pirogoeth/apps/maparoon/hostindex"
+	"github.com/pirogoeth/apps/maparoon/types"
+	"github.com/pirogoeth/apps/pkg/search"
+)
+
+func TestOpenSearchIndexRebuildsOutdatedIndex(t *testing.T) {
+	ctx := context.Background()
+	cfg := &types.Config{}
+	cfg.Search.IndexDir = t.TempDir()
+
+	db, err := database.Open(ctx, fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name()))
+	if err != nil {
+		t.Fatalf("could not open database: %s", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	network, err := db.CreateNetwork(ctx, database.CreateNetworkParams{Name: "lan", Address: "10.0.0.0", Cidr: 24})
+	if err != nil {
+		t.Fatalf("could not create network: %s", err)
+	}
+	if _, err := db.CreateHost(ctx, database.CreateHostParams{NetworkID: network.ID, Address: "10.0.0.5"}); err != nil {
+		t.Fatalf("could not create host: %s", err)
+	}
+	document, _ := json.Marshal(&types.HostScanDocument{Address: "10.0.0.5", Network: network})
+	if err := db.SetHostScan(ctx, database.SetHostScanParams{Address: "10.0.0.5", Document: string(document)}); err != nil {
+		t.Fatalf("could not store host scan: %s", err)
+	}
+
+	// An index from an older mapping, with a host that has no stored scan
+	outdated, err := search.NewSearcher(search.SearcherOpts{IndexDir: cfg.Search.IndexDir, IndexMapping: hostindex.NewMapping()})
+	if err != nil {
+		t.Fatalf("could not create index: %s", err)
+	}
+	handle := outdated.IndexerHandle()
+	handle.Index().SetInternal(hostindex.MappingVersionKey, []byte("1"))
+	handle.Index().Index("10.0.0.9", &types.HostScanDocument{Address: "10.0.0.9"})
+	handle.Close()
+	outdated.Close()
+
+	searcher, err := openSearchIndex(ctx, cfg, db.Querier())
+	if err != nil {
+		t.Fatalf("could not open search index: %s", err)
+	}
+	defer searcher.Close()
+
+	searchHandle := searcher.SearcherHandle()
+	defer searchHandle.Close()
+	if current, err := hostindex.IsCurrent(searchHandle.Index()); err != nil || !current {
+		t.Errorf("expected the rebuilt index to be current, got %v (%v)", current, err)
+	}
+	if addresses, err := hostindex.Addresses(searchHandle.Index()); err != nil || !slices.Equal(addresses, []string{"10.0.0.5"}) {
+		t.Errorf("expected the stored host scan to be indexed, got %v (%v)", addresses, err)
+	}
+
+	// The old index is kept, as it has a host that couldn't be rebuilt
+	kept, err := filepath.Glob(path.Join(cfg.Search.IndexDir, "index.bleve.*"))
+	if err != nil || len(kept) != 1 {
+		t.Errorf("expected the outdated index to be kept, got %v (%v)", kept, err)
+	}
+}
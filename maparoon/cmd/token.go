@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/pirogoeth/apps/maparoon/auth"
+	"github.com/pirogoeth/apps/maparoon/database"
+)
+
+const ComponentToken = "token"
+
+var (
+	tokenCmd = &cobra.Command{
+		Use:   "token",
+		Short: "Manage API tokens directly in the database",
+	}
+	tokenName string
+	tokenRole string
+)
+
+func init() {
+	createCmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a named API token",
+		Run:   createToken,
+	}
+	createCmd.Flags().StringVarP(&tokenName, "name", "n", "", "Name of the token, e.g. the worker it's for")
+	createCmd.Flags().StringVarP(&tokenRole, "role", "r", string(auth.RoleWorker), "Role of the token: admin, worker or read-only")
+	createCmd.MarkFlagRequired("name")
+
+	tokenCmd.AddCommand(createCmd, &cobra.Command{
+		Use:   "list",
+		Short: "List API tokens",
+		Run:   listTokens,
+	})
+}
+
+func openTokenDatabase(ctx context.Context) *database.DbWrapper {
+	cfg := appStart(ComponentToken)
+
+	dbWrapper, err := database.Open(ctx, cfg.Database.Path)
+	if err != nil {
+		logrus.Fatalf("could not open database: %s", err)
+	}
+
+	return dbWrapper
+}
+
+func createToken(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+
+	role, err := auth.ParseRole(tokenRole)
+	if err != nil {
+		logrus.Fatalf("%s", err)
+	}
+
+	dbWrapper := openTokenDatabase(ctx)
+	defer dbWrapper.Close()
+
+	token, _, err := auth.CreateToken(ctx, dbWrapper.Querier(), tokenName, role)
+	if err != nil {
+		logrus.Fatalf("could not create token: %s", err)
+	}
+
+	fmt.Printf("%s\n", token)
+}
+
+func listTokens(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+
+	dbWrapper := openTokenDatabase(ctx)
+	defer dbWrapper.Close()
+
+	tokens, err := dbWrapper.Querier().ListApiTokens(ctx)
+	if err != nil {
+		logrus.Fatalf("could not list tokens: %s", err)
+	}
+
+	for _, token := range tokens {
+		fmt.Printf("%d\t%s\t%s\n", token.ID, token.Name, token.Role)
+	}
+}
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/pirogoeth/apps/maparoon/database"
+	"github.com/pirogoeth/apps/maparoon/types"
+	"github.com/pirogoeth/apps/maparoon/vulns"
+)
+
+const ComponentVulns = "vulns"
+
+var vulnsCmd = &cobra.Command{
+	Use:   "vulns",
+	Short: "Manage the vulnerability database directly",
+}
+
+func init() {
+	vulnsCmd.AddCommand(&cobra.Command{
+		Use:   "import <path>...",
+		Short: "Import NVD CVE feeds and match them against the scanned services",
+		Long: "Import NVD CVE feeds in the JSON 2.0 format, optionally gzipped, and match them " +
+			"against the services of the stored host scans. A directory imports every .json and .json.gz file in it.",
+		Args: cobra.MinimumNArgs(1),
+		Run:  importVulns,
+	})
+}
+
+func importVulns(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	cfg := appStart(ComponentVulns)
+
+	feeds, err := feedFiles(args)
+	if err != nil {
+		logrus.Fatalf("%s", err)
+	}
+
+	dbWrapper, err := database.Open(ctx, cfg.Database.Path)
+	if err != nil {
+		logrus.Fatalf("could not open database: %s", err)
+	}
+	defer dbWrapper.Close()
+
+	imported := 0
+	for _, feed := range feeds {
+		logrus.Infof("importing %s", feed)
+		err := dbWrapper.InTx(ctx, func(querier *database.Queries) error {
+			return vulns.ReadFeedFile(feed, func(vulnerability vulns.Vulnerability) error {
+				imported++
+				return vulns.Store(ctx, querier, vulnerability)
+			})
+		})
+		if err != nil {
+			logrus.Fatalf("could not import %s: %s", feed, err)
+		}
+	}
+
+	// Newly imported vulnerabilities may match services that were already
+	// scanned
+	matched := 0
+	err = dbWrapper.InTx(ctx, func(querier *database.Queries) error {
+		hostScans, err := querier.ListHostScans(ctx)
+		if err != nil {
+			return fmt.Errorf("could not list host scans: %w", err)
+		}
+
+		for _, hostScan := range hostScans {
+			doc := &types.HostScanDocument{}
+			if err := json.Unmarshal([]byte(hostScan.Document), doc); err != nil {
+				return fmt.Errorf("could not decode host scan of %s: %w", hostScan.Address, err)
+			}
+
+			count, err := vulns.Correlate(ctx, querier, doc)
+			if err != nil {
+				return err
+			}
+			matched += count
+		}
+
+		return nil
+	})
+	if err != nil {
+		logrus.Fatalf("could not match vulnerabilities: %s", err)
+	}
+
+	fmt.Printf("imported %d vulnerabilities, %d matched scanned services\n", imported, matched)
+}
+
+// feedFiles expands the directories among the paths to the feed files in them
+func feedFiles(paths []string) ([]string, error) {
+	var feeds []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+
+		if !info.IsDir() {
+			feeds = append(feeds, path)
+			continue
+		}
+
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range entries {
+			name := entry.Name()
+			if !entry.IsDir() && (strings.HasSuffix(name, ".json") || strings.HasSuffix(name, ".json.gz")) {
+				feeds = append(feeds, filepath.Join(path, name))
+			}
+		}
+	}
+
+	return feeds, nil
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/pirogoeth/apps/maparoon/client"
 	"github.com/pirogoeth/apps/maparoon/snmpsmi"
+	"github.com/pirogoeth/apps/maparoon/types"
 	"github.com/pirogoeth/apps/maparoon/worker"
 	"github.com/pirogoeth/apps/pkg/system"
 )
@@ -22,6 +23,17 @@ var workerCmd = &cobra.Command{
 func workerFunc(cmd *cobra.Command, args []string) {
 	cfg := appStart(ComponentWorker)
 
+	if cfg.Worker.Id == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			logrus.Fatalf("could not default the worker id to the hostname: %s", err)
+		}
+		cfg.Worker.Id = hostname
+	}
+	if err := types.ValidateWorkerConfig(cfg); err != nil {
+		logrus.Fatalf("invalid worker configuration: %s", err)
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
@@ -1,23 +1,123 @@
 package database
 
 import (
+	"errors"
 	"fmt"
 	"math"
-	"net"
+	"net/netip"
+	"time"
 )
 
+// MaxSweepSize is the most addresses an IPv6 network can have and still be
+// scanned by sweeping its whole range. Larger IPv6 networks are only scanned
+// at the addresses discovered in them. IPv4 networks are always swept.
+const MaxSweepSize = 1 << 16
+
+var ErrNetworkTooLarge = errors.New("network too large to sweep")
+
 func (n Network) CidrString() string {
 	return fmt.Sprintf("%s/%d", n.Address, n.Cidr)
 }
 
-func (n Network) NetworkSize() (int, error) {
+func (n Network) Prefix() (netip.Prefix, error) {
 	netCidr := n.CidrString()
 
-	_, ipNet, err := net.ParseCIDR(netCidr)
+	prefix, err := netip.ParsePrefix(netCidr)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("could not parse network CIDR %s: %w", netCidr, err)
+	}
+
+	return prefix.Masked(), nil
+}
+
+func (n Network) IsIpv6() bool {
+	prefix, err := n.Prefix()
+	return err == nil && prefix.Addr().Is6()
+}
+
+// NetworkSize is the number of host addresses in the network. It returns
+// ErrNetworkTooLarge for IPv6 networks bigger than MaxSweepSize.
+func (n Network) NetworkSize() (int, error) {
+	prefix, err := n.Prefix()
+	if err != nil {
+		return -1, err
+	}
+
+	hostBits := prefix.Addr().BitLen() - prefix.Bits()
+	if prefix.Addr().Is6() {
+		if 1<<min(hostBits, 62) > MaxSweepSize {
+			return -1, fmt.Errorf("%w: %s", ErrNetworkTooLarge, n.CidrString())
+		}
+		return 1 << hostBits, nil
+	}
+
+	return int(math.Max(math.Pow(2, float64(hostBits))-2, 1.0)), nil
+}
+
+// Sweepable is whether the network is small enough to scan its whole range
+func (n Network) Sweepable() bool {
+	_, err := n.NetworkSize()
+	return err == nil
+}
+
+// Contains is whether the address is in the network
+func (n Network) Contains(address string) bool {
+	prefix, err := n.Prefix()
+	if err != nil {
+		return false
+	}
+
+	addr, err := netip.ParseAddr(address)
+	if err != nil {
+		return false
+	}
+
+	return prefix.Contains(addr.Unmap())
+}
+
+// CanonicalAddress formats an IP address the way hosts are stored, so that
+// the same address is always the same host
+func CanonicalAddress(address string) (string, error) {
+	addr, err := netip.ParseAddr(address)
+	if err != nil {
+		return "", fmt.Errorf("invalid address %q: %w", address, err)
+	}
+
+	return addr.WithZone("").Unmap().String(), nil
+}
+
+// CanonicalNetwork formats a network's address the way networks are stored,
+// with the host bits masked off
+func CanonicalNetwork(address string, cidr int64) (string, error) {
+	addr, err := netip.ParseAddr(address)
 	if err != nil {
-		return -1, fmt.Errorf("could not parse network CIDR %s: %w", netCidr, err)
+		return "", fmt.Errorf("invalid network address %q: %w", address, err)
+	}
+
+	addr = addr.WithZone("").Unmap()
+	if cidr < 0 || cidr > int64(addr.BitLen()) {
+		return "", fmt.Errorf("invalid prefix length %d for %s", cidr, addr)
+	}
+
+	prefix, err := addr.Prefix(int(cidr))
+	if err != nil {
+		return "", err
+	}
+
+	return prefix.Addr().String(), nil
+}
+
+func (h Host) IsGone() bool {
+	return h.GoneAt != 0
+}
+
+// Expired is whether the host has missed at least missedScans network scans in
+// a row, or hasn't been seen in the duration before now. Either limit is
+// disabled when zero, and a host that was never seen doesn't expire by age.
+func (h Host) Expired(missedScans int64, after time.Duration, now time.Time) bool {
+	if missedScans > 0 && h.MissedScans >= missedScans {
+		return true
 	}
 
-	numOnes, totalBits := ipNet.Mask.Size()
-	return int(math.Max(math.Pow(2, float64(totalBits-numOnes))-2, 1.0)), nil
+	return after > 0 && h.LastSeen > 0 && h.LastSeen < now.Add(-after).Unix()
 }
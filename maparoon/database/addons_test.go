@@ -1,6 +1,9 @@
 package database
 
-import "testing"
+import (
+	"testing"
+	"time"
+)
 
 func TestNetworkSizeIpv4(t *testing.T) {
 	n := Network{
@@ -16,4 +19,83 @@ func TestNetworkSizeIpv4(t *testing.T) {
 	if size != 254 {
 		t.Errorf("unexpected network size: %d", size)
 	}
+
+	// IPv4 networks are swept whatever their size
+	n.Cidr = 8
+	size, err = n.NetworkSize()
+	if err != nil || !n.Sweepable() {
+		t.Errorf("expected a /8 to be sweepable, got %s", err)
+	}
+
+	if size != 1<<24-2 {
+		t.Errorf("unexpected network size: %d", size)
+	}
+}
+
+func TestNetworkSizeIpv6(t *testing.T) {
+	cases := []struct {
+		cidr      int64
+		size      int
+		sweepable bool
+	}{
+		{120, 256, true},
+		{112, 65536, true},
+		{64, -1, false},
+		{0, -1, false},
+	}
+
+	for _, c := range cases {
+		n := Network{Address: "2001:db8::", Cidr: c.cidr}
+		size, _ := n.NetworkSize()
+		if size != c.size || n.Sweepable() != c.sweepable {
+			t.Errorf("/%d: got size %d sweepable %t, want %d %t", c.cidr, size, n.Sweepable(), c.size, c.sweepable)
+		}
+	}
+}
+
+func TestCanonicalAddress(t *testing.T) {
+	cases := []struct {
+		address   string
+		canonical string
+	}{
+		{"10.0.0.1", "10.0.0.1"},
+		{"::ffff:10.0.0.1", "10.0.0.1"},
+		{"2001:DB8:0:0:0:0:0:1", "2001:db8::1"},
+		{"fe80::1%eth0", "fe80::1"},
+	}
+
+	for _, c := range cases {
+		canonical, err := CanonicalAddress(c.address)
+		if err != nil || canonical != c.canonical {
+			t.Errorf("%s: got %q (%v), want %q", c.address, canonical, err, c.canonical)
+		}
+	}
+
+	if _, err := CanonicalAddress("2001:db8::/64"); err == nil {
+		t.Errorf("expected an error for a prefix")
+	}
+}
+
+func TestHostExpired(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	cases := []struct {
+		name        string
+		host        Host
+		missedScans int64
+		after       time.Duration
+		expired     bool
+	}{
+		{"disabled", Host{MissedScans: 10, LastSeen: 1}, 0, 0, false},
+		{"missed scans reached", Host{MissedScans: 3, LastSeen: now.Unix()}, 3, 0, true},
+		{"missed scans not reached", Host{MissedScans: 2, LastSeen: now.Unix()}, 3, 0, false},
+		{"not seen recently", Host{LastSeen: now.Add(-25 * time.Hour).Unix()}, 0, 24 * time.Hour, true},
+		{"seen recently", Host{LastSeen: now.Add(-time.Hour).Unix()}, 0, 24 * time.Hour, false},
+		{"never seen", Host{}, 0, 24 * time.Hour, false},
+	}
+
+	for _, c := range cases {
+		if expired := c.host.Expired(c.missedScans, c.after, now); expired != c.expired {
+			t.Errorf("%s: expected expired=%t, got %t", c.name, c.expired, expired)
+		}
+	}
 }
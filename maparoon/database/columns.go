@@ -0,0 +1,72 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+)
+
+// addedColumns are columns added to tables after they were first created. The
+// schema's `create table if not exists` leaves existing tables alone, so they
+// are added to existing databases before the schema is applied.
+var addedColumns = []struct {
+	table, column, definition string
+}{
+	{"hosts", "vendor", "text not null default ''"},
+	{"hosts", "device_class", "text not null default ''"},
+	{"hosts", "first_seen", "integer not null default 0"},
+	{"hosts", "last_seen", "integer not null default 0"},
+	{"hosts", "missed_scans", "integer not null default 0"},
+	{"hosts", "gone_at", "integer not null default 0"},
+	{"hosts", "device_class_score", "integer not null default 0"},
+	{"host_ports", "first_seen", "integer not null default 0"},
+	{"host_ports", "last_seen", "integer not null default 0"},
+	{"host_neighbors", "local_port_id", "text not null default ''"},
+	{"network_scans", "failed_at", "integer not null default -1"},
+	{"network_scans", "failure", "text not null default ''"},
+	{"network_scans", "started_by", "text not null default ''"},
+	{"host_scans", "submitted_by", "text not null default ''"},
+}
+
+func addMissingColumns(ctx context.Context, db *sql.DB) error {
+	for _, added := range addedColumns {
+		columns, err := tableColumns(ctx, db, added.table)
+		if err != nil {
+			return err
+		}
+
+		// A missing table is created with the column by the schema
+		if len(columns) == 0 || columns[added.column] {
+			continue
+		}
+
+		logrus.Infof("Adding column %s.%s", added.table, added.column)
+		stmt := fmt.Sprintf("alter table %s add column %s %s", added.table, added.column, added.definition)
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("could not add column %s.%s: %w", added.table, added.column, err)
+		}
+	}
+
+	return nil
+}
+
+func tableColumns(ctx context.Context, db *sql.DB, table string) (map[string]bool, error) {
+	rows, err := db.QueryContext(ctx, "select name from pragma_table_info(?)", table)
+	if err != nil {
+		return nil, fmt.Errorf("could not list columns of %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	columns := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		columns[name] = true
+	}
+
+	return columns, rows.Err()
+}
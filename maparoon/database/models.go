@@ -6,10 +6,70 @@ package database
 
 import ()
 
+type Alert struct {
+	ID             int64  `json:"id"`
+	RuleID         int64  `json:"rule_id"`
+	NetworkID      int64  `json:"network_id"`
+	ScanID         int64  `json:"scan_id"`
+	Fingerprint    string `json:"fingerprint"`
+	Summary        string `json:"summary"`
+	Count          int64  `json:"count"`
+	FirstSeenAt    int64  `json:"first_seen_at"`
+	LastSeenAt     int64  `json:"last_seen_at"`
+	AcknowledgedAt int64  `json:"acknowledged_at"`
+}
+
+type AlertRule struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	Kind        string `json:"kind"`
+	NetworkID   int64  `json:"network_id"`
+	Port        int64  `json:"port"`
+	Protocol    string `json:"protocol"`
+	Measurement string `json:"measurement"`
+	WebhookUrl  string `json:"webhook_url"`
+	EmailTo     string `json:"email_to"`
+	Enabled     bool   `json:"enabled"`
+	CreatedAt   int64  `json:"created_at"`
+}
+
+type ApiToken struct {
+	ID         int64  `json:"id"`
+	Name       string `json:"name"`
+	Role       string `json:"role"`
+	TokenHash  string `json:"token_hash"`
+	CreatedAt  int64  `json:"created_at"`
+	LastUsedAt int64  `json:"last_used_at"`
+}
+
+type AuditLog struct {
+	ID        int64  `json:"id"`
+	TokenName string `json:"token_name"`
+	Role      string `json:"role"`
+	Method    string `json:"method"`
+	Path      string `json:"path"`
+	Status    int64  `json:"status"`
+	CreatedAt int64  `json:"created_at"`
+}
+
 type Host struct {
-	Address   string `json:"address"`
-	NetworkID int64  `json:"network_id"`
-	Comments  string `json:"comments"`
+	Address          string `json:"address"`
+	NetworkID        int64  `json:"network_id"`
+	Comments         string `json:"comments"`
+	Vendor           string `json:"vendor"`
+	DeviceClass      string `json:"device_class"`
+	FirstSeen        int64  `json:"first_seen"`
+	LastSeen         int64  `json:"last_seen"`
+	MissedScans      int64  `json:"missed_scans"`
+	GoneAt           int64  `json:"gone_at"`
+	DeviceClassScore int64  `json:"device_class_score"`
+}
+
+type HostArpEntry struct {
+	Address    string `json:"address"`
+	IfIndex    int64  `json:"if_index"`
+	IpAddress  string `json:"ip_address"`
+	MacAddress string `json:"mac_address"`
 }
 
 type HostAttribute struct {
@@ -18,11 +78,47 @@ type HostAttribute struct {
 	Value   string `json:"value"`
 }
 
+type HostFdbEntry struct {
+	Address    string `json:"address"`
+	MacAddress string `json:"mac_address"`
+	BridgePort int64  `json:"bridge_port"`
+	IfIndex    int64  `json:"if_index"`
+}
+
+type HostInterface struct {
+	Address     string `json:"address"`
+	IfIndex     int64  `json:"if_index"`
+	Name        string `json:"name"`
+	Descr       string `json:"descr"`
+	Alias       string `json:"alias"`
+	Type        int64  `json:"type"`
+	Mtu         int64  `json:"mtu"`
+	Speed       int64  `json:"speed"`
+	MacAddress  string `json:"mac_address"`
+	AdminStatus string `json:"admin_status"`
+	OperStatus  string `json:"oper_status"`
+}
+
+type HostNeighbor struct {
+	Address           string `json:"address"`
+	LocalPort         int64  `json:"local_port"`
+	LocalPortDescr    string `json:"local_port_descr"`
+	ChassisID         string `json:"chassis_id"`
+	PortID            string `json:"port_id"`
+	PortDescr         string `json:"port_descr"`
+	SysName           string `json:"sys_name"`
+	SysDescr          string `json:"sys_descr"`
+	ManagementAddress string `json:"management_address"`
+	LocalPortID       string `json:"local_port_id"`
+}
+
 type HostPort struct {
-	Address  string `json:"address"`
-	Port     int64  `json:"port"`
-	Protocol string `json:"protocol"`
-	Comments string `json:"comments"`
+	Address   string `json:"address"`
+	Port      int64  `json:"port"`
+	Protocol  string `json:"protocol"`
+	Comments  string `json:"comments"`
+	FirstSeen int64  `json:"first_seen"`
+	LastSeen  int64  `json:"last_seen"`
 }
 
 type HostPortAttribute struct {
@@ -33,6 +129,28 @@ type HostPortAttribute struct {
 	Value    string `json:"value"`
 }
 
+type HostPortVulnerability struct {
+	Address         string `json:"address"`
+	Port            int64  `json:"port"`
+	Protocol        string `json:"protocol"`
+	VulnerabilityID string `json:"vulnerability_id"`
+	Cpe             string `json:"cpe"`
+}
+
+type HostScan struct {
+	Address     string `json:"address"`
+	ScanID      int64  `json:"scan_id"`
+	Document    string `json:"document"`
+	IndexedAt   int64  `json:"indexed_at"`
+	SubmittedBy string `json:"submitted_by"`
+}
+
+type HostScanSnapshot struct {
+	ScanID   int64  `json:"scan_id"`
+	Address  string `json:"address"`
+	Document string `json:"document"`
+}
+
 type Network struct {
 	ID       int64  `json:"id"`
 	Name     string `json:"name"`
@@ -48,10 +166,74 @@ type NetworkAttribute struct {
 }
 
 type NetworkScan struct {
-	ID         int64 `json:"id"`
-	NetworkID  int64 `json:"network_id"`
-	StartedAt  int64 `json:"started_at"`
-	FinishedAt int64 `json:"finished_at"`
-	HostsFound int64 `json:"hosts_found"`
-	PortsFound int64 `json:"ports_found"`
+	ID         int64  `json:"id"`
+	NetworkID  int64  `json:"network_id"`
+	StartedAt  int64  `json:"started_at"`
+	FinishedAt int64  `json:"finished_at"`
+	HostsFound int64  `json:"hosts_found"`
+	PortsFound int64  `json:"ports_found"`
+	FailedAt   int64  `json:"failed_at"`
+	Failure    string `json:"failure"`
+	StartedBy  string `json:"started_by"`
+}
+
+type ScanLease struct {
+	NetworkID   int64  `json:"network_id"`
+	ScanID      int64  `json:"scan_id"`
+	Worker      string `json:"worker"`
+	AcquiredAt  int64  `json:"acquired_at"`
+	HeartbeatAt int64  `json:"heartbeat_at"`
+	ExpiresAt   int64  `json:"expires_at"`
+}
+
+type ScanProfile struct {
+	ID           int64  `json:"id"`
+	Name         string `json:"name"`
+	ScanType     string `json:"scan_type"`
+	Ports        string `json:"ports"`
+	TopPorts     string `json:"top_ports"`
+	NmapFlags    string `json:"nmap_flags"`
+	NmapScripts  string `json:"nmap_scripts"`
+	SnmpEnabled  bool   `json:"snmp_enabled"`
+	Rate         int64  `json:"rate"`
+	ScanInterval int64  `json:"scan_interval"`
+	CreatedAt    int64  `json:"created_at"`
+}
+
+type SnmpCredential struct {
+	ID             int64  `json:"id"`
+	Name           string `json:"name"`
+	NetworkID      int64  `json:"network_id"`
+	Address        string `json:"address"`
+	Priority       int64  `json:"priority"`
+	Version        string `json:"version"`
+	Community      string `json:"community"`
+	Username       string `json:"username"`
+	AuthProtocol   string `json:"auth_protocol"`
+	AuthPassphrase string `json:"auth_passphrase"`
+	PrivProtocol   string `json:"priv_protocol"`
+	PrivPassphrase string `json:"priv_passphrase"`
+	CreatedAt      int64  `json:"created_at"`
+}
+
+type Vulnerability struct {
+	ID          string  `json:"id"`
+	Description string  `json:"description"`
+	Severity    string  `json:"severity"`
+	Score       float64 `json:"score"`
+	Published   string  `json:"published"`
+}
+
+type VulnerabilityCpe struct {
+	VulnerabilityID       string `json:"vulnerability_id"`
+	Part                  string `json:"part"`
+	Vendor                string `json:"vendor"`
+	Product               string `json:"product"`
+	Version               string `json:"version"`
+	VersionUpdate         string `json:"version_update"`
+	VersionStartIncluding string `json:"version_start_including"`
+	VersionStartExcluding string `json:"version_start_excluding"`
+	VersionEndIncluding   string `json:"version_end_including"`
+	VersionEndExcluding   string `json:"version_end_excluding"`
+	Criteria              string `json:"criteria"`
 }
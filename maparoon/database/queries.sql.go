@@ -10,13 +10,222 @@ import (
 	"database/sql"
 )
 
+const acknowledgeAlert = `-- name: AcknowledgeAlert :one
+update alerts
+set acknowledged_at = strftime('%s', 'now')
+where id = ?
+returning id, rule_id, network_id, scan_id, fingerprint, summary, count, first_seen_at, last_seen_at, acknowledged_at
+`
+
+func (q *Queries) AcknowledgeAlert(ctx context.Context, id int64) (Alert, error) {
+	row := q.db.QueryRowContext(ctx, acknowledgeAlert, id)
+	var i Alert
+	err := row.Scan(
+		&i.ID,
+		&i.RuleID,
+		&i.NetworkID,
+		&i.ScanID,
+		&i.Fingerprint,
+		&i.Summary,
+		&i.Count,
+		&i.FirstSeenAt,
+		&i.LastSeenAt,
+		&i.AcknowledgedAt,
+	)
+	return i, err
+}
+
+const claimScanLease = `-- name: ClaimScanLease :one
+insert into scan_leases (
+    network_id, worker, acquired_at, heartbeat_at, expires_at
+) values (
+    ?, ?, ?, ?, ?
+)
+on conflict (network_id) do update set
+    scan_id = 0,
+    worker = excluded.worker,
+    acquired_at = excluded.acquired_at,
+    heartbeat_at = excluded.heartbeat_at,
+    expires_at = excluded.expires_at
+where scan_leases.expires_at <= excluded.acquired_at
+returning network_id, scan_id, worker, acquired_at, heartbeat_at, expires_at
+`
+
+type ClaimScanLeaseParams struct {
+	NetworkID   int64  `json:"network_id"`
+	Worker      string `json:"worker"`
+	AcquiredAt  int64  `json:"acquired_at"`
+	HeartbeatAt int64  `json:"heartbeat_at"`
+	ExpiresAt   int64  `json:"expires_at"`
+}
+
+func (q *Queries) ClaimScanLease(ctx context.Context, arg ClaimScanLeaseParams) (ScanLease, error) {
+	row := q.db.QueryRowContext(ctx, claimScanLease,
+		arg.NetworkID,
+		arg.Worker,
+		arg.AcquiredAt,
+		arg.HeartbeatAt,
+		arg.ExpiresAt,
+	)
+	var i ScanLease
+	err := row.Scan(
+		&i.NetworkID,
+		&i.ScanID,
+		&i.Worker,
+		&i.AcquiredAt,
+		&i.HeartbeatAt,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
+
+const createAlert = `-- name: CreateAlert :one
+insert into alerts (
+    rule_id, network_id, scan_id, fingerprint, summary
+) values (
+    ?, ?, ?, ?, ?
+)
+returning id, rule_id, network_id, scan_id, fingerprint, summary, count, first_seen_at, last_seen_at, acknowledged_at
+`
+
+type CreateAlertParams struct {
+	RuleID      int64  `json:"rule_id"`
+	NetworkID   int64  `json:"network_id"`
+	ScanID      int64  `json:"scan_id"`
+	Fingerprint string `json:"fingerprint"`
+	Summary     string `json:"summary"`
+}
+
+func (q *Queries) CreateAlert(ctx context.Context, arg CreateAlertParams) (Alert, error) {
+	row := q.db.QueryRowContext(ctx, createAlert,
+		arg.RuleID,
+		arg.NetworkID,
+		arg.ScanID,
+		arg.Fingerprint,
+		arg.Summary,
+	)
+	var i Alert
+	err := row.Scan(
+		&i.ID,
+		&i.RuleID,
+		&i.NetworkID,
+		&i.ScanID,
+		&i.Fingerprint,
+		&i.Summary,
+		&i.Count,
+		&i.FirstSeenAt,
+		&i.LastSeenAt,
+		&i.AcknowledgedAt,
+	)
+	return i, err
+}
+
+const createAlertRule = `-- name: CreateAlertRule :one
+insert into alert_rules (
+    name, kind, network_id, port, protocol, measurement, webhook_url, email_to, enabled
+) values (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?
+)
+returning id, name, kind, network_id, port, protocol, measurement, webhook_url, email_to, enabled, created_at
+`
+
+type CreateAlertRuleParams struct {
+	Name        string `json:"name"`
+	Kind        string `json:"kind"`
+	NetworkID   int64  `json:"network_id"`
+	Port        int64  `json:"port"`
+	Protocol    string `json:"protocol"`
+	Measurement string `json:"measurement"`
+	WebhookUrl  string `json:"webhook_url"`
+	EmailTo     string `json:"email_to"`
+	Enabled     bool   `json:"enabled"`
+}
+
+func (q *Queries) CreateAlertRule(ctx context.Context, arg CreateAlertRuleParams) (AlertRule, error) {
+	row := q.db.QueryRowContext(ctx, createAlertRule,
+		arg.Name,
+		arg.Kind,
+		arg.NetworkID,
+		arg.Port,
+		arg.Protocol,
+		arg.Measurement,
+		arg.WebhookUrl,
+		arg.EmailTo,
+		arg.Enabled,
+	)
+	var i AlertRule
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Kind,
+		&i.NetworkID,
+		&i.Port,
+		&i.Protocol,
+		&i.Measurement,
+		&i.WebhookUrl,
+		&i.EmailTo,
+		&i.Enabled,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createApiToken = `-- name: CreateApiToken :one
+insert into api_tokens (
+    name, role, token_hash
+) values (
+    ?, ?, ?
+)
+returning id
+`
+
+type CreateApiTokenParams struct {
+	Name      string `json:"name"`
+	Role      string `json:"role"`
+	TokenHash string `json:"token_hash"`
+}
+
+func (q *Queries) CreateApiToken(ctx context.Context, arg CreateApiTokenParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, createApiToken, arg.Name, arg.Role, arg.TokenHash)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
+const createAuditLogEntry = `-- name: CreateAuditLogEntry :exec
+insert into audit_log (
+    token_name, role, method, path, status
+) values (
+    ?, ?, ?, ?, ?
+)
+`
+
+type CreateAuditLogEntryParams struct {
+	TokenName string `json:"token_name"`
+	Role      string `json:"role"`
+	Method    string `json:"method"`
+	Path      string `json:"path"`
+	Status    int64  `json:"status"`
+}
+
+func (q *Queries) CreateAuditLogEntry(ctx context.Context, arg CreateAuditLogEntryParams) error {
+	_, err := q.db.ExecContext(ctx, createAuditLogEntry,
+		arg.TokenName,
+		arg.Role,
+		arg.Method,
+		arg.Path,
+		arg.Status,
+	)
+	return err
+}
+
 const createHost = `-- name: CreateHost :one
 insert into hosts (
     network_id, address, comments
 ) values (
     ?, ?, ?
 )
-returning address, network_id, comments
+returning address, network_id, comments, vendor, device_class, first_seen, last_seen, missed_scans, gone_at, device_class_score
 `
 
 type CreateHostParams struct {
@@ -28,17 +237,154 @@ type CreateHostParams struct {
 func (q *Queries) CreateHost(ctx context.Context, arg CreateHostParams) (Host, error) {
 	row := q.db.QueryRowContext(ctx, createHost, arg.NetworkID, arg.Address, arg.Comments)
 	var i Host
-	err := row.Scan(&i.Address, &i.NetworkID, &i.Comments)
+	err := row.Scan(
+		&i.Address,
+		&i.NetworkID,
+		&i.Comments,
+		&i.Vendor,
+		&i.DeviceClass,
+		&i.FirstSeen,
+		&i.LastSeen,
+		&i.MissedScans,
+		&i.GoneAt,
+		&i.DeviceClassScore,
+	)
 	return i, err
 }
 
+const createHostArpEntry = `-- name: CreateHostArpEntry :exec
+insert or replace into host_arp_entries (
+    address, if_index, ip_address, mac_address
+) values (
+    ?, ?, ?, ?
+)
+`
+
+type CreateHostArpEntryParams struct {
+	Address    string `json:"address"`
+	IfIndex    int64  `json:"if_index"`
+	IpAddress  string `json:"ip_address"`
+	MacAddress string `json:"mac_address"`
+}
+
+func (q *Queries) CreateHostArpEntry(ctx context.Context, arg CreateHostArpEntryParams) error {
+	_, err := q.db.ExecContext(ctx, createHostArpEntry,
+		arg.Address,
+		arg.IfIndex,
+		arg.IpAddress,
+		arg.MacAddress,
+	)
+	return err
+}
+
+const createHostFdbEntry = `-- name: CreateHostFdbEntry :exec
+insert or replace into host_fdb_entries (
+    address, mac_address, bridge_port, if_index
+) values (
+    ?, ?, ?, ?
+)
+`
+
+type CreateHostFdbEntryParams struct {
+	Address    string `json:"address"`
+	MacAddress string `json:"mac_address"`
+	BridgePort int64  `json:"bridge_port"`
+	IfIndex    int64  `json:"if_index"`
+}
+
+func (q *Queries) CreateHostFdbEntry(ctx context.Context, arg CreateHostFdbEntryParams) error {
+	_, err := q.db.ExecContext(ctx, createHostFdbEntry,
+		arg.Address,
+		arg.MacAddress,
+		arg.BridgePort,
+		arg.IfIndex,
+	)
+	return err
+}
+
+const createHostInterface = `-- name: CreateHostInterface :exec
+insert into host_interfaces (
+    address, if_index, name, descr, alias, type, mtu, speed, mac_address, admin_status, oper_status
+) values (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+)
+`
+
+type CreateHostInterfaceParams struct {
+	Address     string `json:"address"`
+	IfIndex     int64  `json:"if_index"`
+	Name        string `json:"name"`
+	Descr       string `json:"descr"`
+	Alias       string `json:"alias"`
+	Type        int64  `json:"type"`
+	Mtu         int64  `json:"mtu"`
+	Speed       int64  `json:"speed"`
+	MacAddress  string `json:"mac_address"`
+	AdminStatus string `json:"admin_status"`
+	OperStatus  string `json:"oper_status"`
+}
+
+func (q *Queries) CreateHostInterface(ctx context.Context, arg CreateHostInterfaceParams) error {
+	_, err := q.db.ExecContext(ctx, createHostInterface,
+		arg.Address,
+		arg.IfIndex,
+		arg.Name,
+		arg.Descr,
+		arg.Alias,
+		arg.Type,
+		arg.Mtu,
+		arg.Speed,
+		arg.MacAddress,
+		arg.AdminStatus,
+		arg.OperStatus,
+	)
+	return err
+}
+
+const createHostNeighbor = `-- name: CreateHostNeighbor :exec
+insert or replace into host_neighbors (
+    address, local_port, local_port_id, local_port_descr, chassis_id, port_id, port_descr, sys_name, sys_descr, management_address
+) values (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+)
+`
+
+type CreateHostNeighborParams struct {
+	Address           string `json:"address"`
+	LocalPort         int64  `json:"local_port"`
+	LocalPortID       string `json:"local_port_id"`
+	LocalPortDescr    string `json:"local_port_descr"`
+	ChassisID         string `json:"chassis_id"`
+	PortID            string `json:"port_id"`
+	PortDescr         string `json:"port_descr"`
+	SysName           string `json:"sys_name"`
+	SysDescr          string `json:"sys_descr"`
+	ManagementAddress string `json:"management_address"`
+}
+
+func (q *Queries) CreateHostNeighbor(ctx context.Context, arg CreateHostNeighborParams) error {
+	_, err := q.db.ExecContext(ctx, createHostNeighbor,
+		arg.Address,
+		arg.LocalPort,
+		arg.LocalPortID,
+		arg.LocalPortDescr,
+		arg.ChassisID,
+		arg.PortID,
+		arg.PortDescr,
+		arg.SysName,
+		arg.SysDescr,
+		arg.ManagementAddress,
+	)
+	return err
+}
+
 const createHostPort = `-- name: CreateHostPort :one
 insert into host_ports (
     address, port, protocol, comments
 ) values (
     ?, ?, ?, ?
 )
-returning address, port, protocol, comments
+returning address, port, protocol, comments, first_seen, last_seen
 `
 
 type CreateHostPortParams struct {
@@ -61,10 +407,62 @@ func (q *Queries) CreateHostPort(ctx context.Context, arg CreateHostPortParams)
 		&i.Port,
 		&i.Protocol,
 		&i.Comments,
+		&i.FirstSeen,
+		&i.LastSeen,
 	)
 	return i, err
 }
 
+const createHostPortVulnerability = `-- name: CreateHostPortVulnerability :exec
+insert into host_port_vulnerabilities (
+    address, port, protocol, vulnerability_id, cpe
+) values (
+    ?, ?, ?, ?, ?
+)
+on conflict (address, port, protocol, vulnerability_id) do update set
+    cpe = excluded.cpe
+`
+
+type CreateHostPortVulnerabilityParams struct {
+	Address         string `json:"address"`
+	Port            int64  `json:"port"`
+	Protocol        string `json:"protocol"`
+	VulnerabilityID string `json:"vulnerability_id"`
+	Cpe             string `json:"cpe"`
+}
+
+func (q *Queries) CreateHostPortVulnerability(ctx context.Context, arg CreateHostPortVulnerabilityParams) error {
+	_, err := q.db.ExecContext(ctx, createHostPortVulnerability,
+		arg.Address,
+		arg.Port,
+		arg.Protocol,
+		arg.VulnerabilityID,
+		arg.Cpe,
+	)
+	return err
+}
+
+const createHostScanSnapshot = `-- name: CreateHostScanSnapshot :exec
+insert into host_scan_snapshots (
+    scan_id, address, document
+) values (
+    ?, ?, ?
+)
+on conflict (scan_id, address) do update set
+    document = excluded.document
+`
+
+type CreateHostScanSnapshotParams struct {
+	ScanID   int64  `json:"scan_id"`
+	Address  string `json:"address"`
+	Document string `json:"document"`
+}
+
+func (q *Queries) CreateHostScanSnapshot(ctx context.Context, arg CreateHostScanSnapshotParams) error {
+	_, err := q.db.ExecContext(ctx, createHostScanSnapshot, arg.ScanID, arg.Address, arg.Document)
+	return err
+}
+
 const createNetwork = `-- name: CreateNetwork :one
 insert into networks (
     name, address, cidr, comments
@@ -99,6 +497,193 @@ func (q *Queries) CreateNetwork(ctx context.Context, arg CreateNetworkParams) (N
 	return i, err
 }
 
+const createNetworkScan = `-- name: CreateNetworkScan :one
+insert into network_scans (
+    network_id, started_by
+) values (
+    ?, ?
+)
+returning id
+`
+
+type CreateNetworkScanParams struct {
+	NetworkID int64  `json:"network_id"`
+	StartedBy string `json:"started_by"`
+}
+
+func (q *Queries) CreateNetworkScan(ctx context.Context, arg CreateNetworkScanParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, createNetworkScan, arg.NetworkID, arg.StartedBy)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
+const createScanProfile = `-- name: CreateScanProfile :one
+insert into scan_profiles (
+    name, scan_type, ports, top_ports, nmap_flags, nmap_scripts, snmp_enabled, rate, scan_interval
+) values (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?
+)
+returning id, name, scan_type, ports, top_ports, nmap_flags, nmap_scripts, snmp_enabled, rate, scan_interval, created_at
+`
+
+type CreateScanProfileParams struct {
+	Name         string `json:"name"`
+	ScanType     string `json:"scan_type"`
+	Ports        string `json:"ports"`
+	TopPorts     string `json:"top_ports"`
+	NmapFlags    string `json:"nmap_flags"`
+	NmapScripts  string `json:"nmap_scripts"`
+	SnmpEnabled  bool   `json:"snmp_enabled"`
+	Rate         int64  `json:"rate"`
+	ScanInterval int64  `json:"scan_interval"`
+}
+
+func (q *Queries) CreateScanProfile(ctx context.Context, arg CreateScanProfileParams) (ScanProfile, error) {
+	row := q.db.QueryRowContext(ctx, createScanProfile,
+		arg.Name,
+		arg.ScanType,
+		arg.Ports,
+		arg.TopPorts,
+		arg.NmapFlags,
+		arg.NmapScripts,
+		arg.SnmpEnabled,
+		arg.Rate,
+		arg.ScanInterval,
+	)
+	var i ScanProfile
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.ScanType,
+		&i.Ports,
+		&i.TopPorts,
+		&i.NmapFlags,
+		&i.NmapScripts,
+		&i.SnmpEnabled,
+		&i.Rate,
+		&i.ScanInterval,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createSnmpCredential = `-- name: CreateSnmpCredential :one
+insert into snmp_credentials (
+    name, network_id, address, priority, version, community, username,
+    auth_protocol, auth_passphrase, priv_protocol, priv_passphrase
+) values (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+)
+returning id, name, network_id, address, priority, version, community, username, auth_protocol, auth_passphrase, priv_protocol, priv_passphrase, created_at
+`
+
+type CreateSnmpCredentialParams struct {
+	Name           string `json:"name"`
+	NetworkID      int64  `json:"network_id"`
+	Address        string `json:"address"`
+	Priority       int64  `json:"priority"`
+	Version        string `json:"version"`
+	Community      string `json:"community"`
+	Username       string `json:"username"`
+	AuthProtocol   string `json:"auth_protocol"`
+	AuthPassphrase string `json:"auth_passphrase"`
+	PrivProtocol   string `json:"priv_protocol"`
+	PrivPassphrase string `json:"priv_passphrase"`
+}
+
+func (q *Queries) CreateSnmpCredential(ctx context.Context, arg CreateSnmpCredentialParams) (SnmpCredential, error) {
+	row := q.db.QueryRowContext(ctx, createSnmpCredential,
+		arg.Name,
+		arg.NetworkID,
+		arg.Address,
+		arg.Priority,
+		arg.Version,
+		arg.Community,
+		arg.Username,
+		arg.AuthProtocol,
+		arg.AuthPassphrase,
+		arg.PrivProtocol,
+		arg.PrivPassphrase,
+	)
+	var i SnmpCredential
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.NetworkID,
+		&i.Address,
+		&i.Priority,
+		&i.Version,
+		&i.Community,
+		&i.Username,
+		&i.AuthProtocol,
+		&i.AuthPassphrase,
+		&i.PrivProtocol,
+		&i.PrivPassphrase,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createVulnerabilityCpe = `-- name: CreateVulnerabilityCpe :exec
+insert into vulnerability_cpes (
+    vulnerability_id, part, vendor, product, version, version_update,
+    version_start_including, version_start_excluding,
+    version_end_including, version_end_excluding, criteria
+) values (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+)
+`
+
+type CreateVulnerabilityCpeParams struct {
+	VulnerabilityID       string `json:"vulnerability_id"`
+	Part                  string `json:"part"`
+	Vendor                string `json:"vendor"`
+	Product               string `json:"product"`
+	Version               string `json:"version"`
+	VersionUpdate         string `json:"version_update"`
+	VersionStartIncluding string `json:"version_start_including"`
+	VersionStartExcluding string `json:"version_start_excluding"`
+	VersionEndIncluding   string `json:"version_end_including"`
+	VersionEndExcluding   string `json:"version_end_excluding"`
+	Criteria              string `json:"criteria"`
+}
+
+func (q *Queries) CreateVulnerabilityCpe(ctx context.Context, arg CreateVulnerabilityCpeParams) error {
+	_, err := q.db.ExecContext(ctx, createVulnerabilityCpe,
+		arg.VulnerabilityID,
+		arg.Part,
+		arg.Vendor,
+		arg.Product,
+		arg.Version,
+		arg.VersionUpdate,
+		arg.VersionStartIncluding,
+		arg.VersionStartExcluding,
+		arg.VersionEndIncluding,
+		arg.VersionEndExcluding,
+		arg.Criteria,
+	)
+	return err
+}
+
+const deleteAlertRule = `-- name: DeleteAlertRule :exec
+delete from alert_rules where id = ?
+`
+
+func (q *Queries) DeleteAlertRule(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, deleteAlertRule, id)
+	return err
+}
+
+const deleteApiToken = `-- name: DeleteApiToken :exec
+delete from api_tokens where id = ?
+`
+
+func (q *Queries) DeleteApiToken(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, deleteApiToken, id)
+	return err
+}
+
 const deleteHost = `-- name: DeleteHost :exec
 delete from hosts
 where address = ?
@@ -109,6 +694,61 @@ func (q *Queries) DeleteHost(ctx context.Context, address string) error {
 	return err
 }
 
+const deleteHostArpEntries = `-- name: DeleteHostArpEntries :exec
+delete from host_arp_entries
+where address = ?
+`
+
+func (q *Queries) DeleteHostArpEntries(ctx context.Context, address string) error {
+	_, err := q.db.ExecContext(ctx, deleteHostArpEntries, address)
+	return err
+}
+
+const deleteHostAttribute = `-- name: DeleteHostAttribute :exec
+delete from host_attributes
+where address = ? and key = ?
+`
+
+type DeleteHostAttributeParams struct {
+	Address string `json:"address"`
+	Key     string `json:"key"`
+}
+
+func (q *Queries) DeleteHostAttribute(ctx context.Context, arg DeleteHostAttributeParams) error {
+	_, err := q.db.ExecContext(ctx, deleteHostAttribute, arg.Address, arg.Key)
+	return err
+}
+
+const deleteHostFdbEntries = `-- name: DeleteHostFdbEntries :exec
+delete from host_fdb_entries
+where address = ?
+`
+
+func (q *Queries) DeleteHostFdbEntries(ctx context.Context, address string) error {
+	_, err := q.db.ExecContext(ctx, deleteHostFdbEntries, address)
+	return err
+}
+
+const deleteHostInterfaces = `-- name: DeleteHostInterfaces :exec
+delete from host_interfaces
+where address = ?
+`
+
+func (q *Queries) DeleteHostInterfaces(ctx context.Context, address string) error {
+	_, err := q.db.ExecContext(ctx, deleteHostInterfaces, address)
+	return err
+}
+
+const deleteHostNeighbors = `-- name: DeleteHostNeighbors :exec
+delete from host_neighbors
+where address = ?
+`
+
+func (q *Queries) DeleteHostNeighbors(ctx context.Context, address string) error {
+	_, err := q.db.ExecContext(ctx, deleteHostNeighbors, address)
+	return err
+}
+
 const deleteHostPort = `-- name: DeleteHostPort :exec
 delete from host_ports
 where
@@ -128,6 +768,26 @@ func (q *Queries) DeleteHostPort(ctx context.Context, arg DeleteHostPortParams)
 	return err
 }
 
+const deleteHostPortVulnerabilities = `-- name: DeleteHostPortVulnerabilities :exec
+delete from host_port_vulnerabilities
+where address = ?
+`
+
+func (q *Queries) DeleteHostPortVulnerabilities(ctx context.Context, address string) error {
+	_, err := q.db.ExecContext(ctx, deleteHostPortVulnerabilities, address)
+	return err
+}
+
+const deleteHostScan = `-- name: DeleteHostScan :exec
+delete from host_scans
+where address = ?
+`
+
+func (q *Queries) DeleteHostScan(ctx context.Context, address string) error {
+	_, err := q.db.ExecContext(ctx, deleteHostScan, address)
+	return err
+}
+
 const deleteNetwork = `-- name: DeleteNetwork :exec
 delete from networks where id = ?
 `
@@ -137,20 +797,260 @@ func (q *Queries) DeleteNetwork(ctx context.Context, id int64) error {
 	return err
 }
 
-const getHost = `-- name: GetHost :one
-select address, network_id, comments from hosts
-where address = ? limit 1
+const deleteNetworkAttribute = `-- name: DeleteNetworkAttribute :exec
+delete from network_attributes
+where network_id = ? and key = ?
 `
 
-func (q *Queries) GetHost(ctx context.Context, address string) (Host, error) {
-	row := q.db.QueryRowContext(ctx, getHost, address)
-	var i Host
-	err := row.Scan(&i.Address, &i.NetworkID, &i.Comments)
+type DeleteNetworkAttributeParams struct {
+	NetworkID int64  `json:"network_id"`
+	Key       string `json:"key"`
+}
+
+func (q *Queries) DeleteNetworkAttribute(ctx context.Context, arg DeleteNetworkAttributeParams) error {
+	_, err := q.db.ExecContext(ctx, deleteNetworkAttribute, arg.NetworkID, arg.Key)
+	return err
+}
+
+const deleteScanLease = `-- name: DeleteScanLease :exec
+delete from scan_leases
+where network_id = ? and scan_id = ?
+`
+
+type DeleteScanLeaseParams struct {
+	NetworkID int64 `json:"network_id"`
+	ScanID    int64 `json:"scan_id"`
+}
+
+func (q *Queries) DeleteScanLease(ctx context.Context, arg DeleteScanLeaseParams) error {
+	_, err := q.db.ExecContext(ctx, deleteScanLease, arg.NetworkID, arg.ScanID)
+	return err
+}
+
+const deleteScanProfile = `-- name: DeleteScanProfile :exec
+delete from scan_profiles where id = ?
+`
+
+func (q *Queries) DeleteScanProfile(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, deleteScanProfile, id)
+	return err
+}
+
+const deleteSnmpCredential = `-- name: DeleteSnmpCredential :exec
+delete from snmp_credentials where id = ?
+`
+
+func (q *Queries) DeleteSnmpCredential(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, deleteSnmpCredential, id)
+	return err
+}
+
+const deleteVulnerabilityCpes = `-- name: DeleteVulnerabilityCpes :exec
+delete from vulnerability_cpes
+where vulnerability_id = ?
+`
+
+func (q *Queries) DeleteVulnerabilityCpes(ctx context.Context, vulnerabilityID string) error {
+	_, err := q.db.ExecContext(ctx, deleteVulnerabilityCpes, vulnerabilityID)
+	return err
+}
+
+const failNetworkScan = `-- name: FailNetworkScan :execrows
+update network_scans
+set
+    failed_at = ?,
+    failure = ?
+where id = ?
+    and finished_at = -1
+    and failed_at = -1
+`
+
+type FailNetworkScanParams struct {
+	FailedAt int64  `json:"failed_at"`
+	Failure  string `json:"failure"`
+	ID       int64  `json:"id"`
+}
+
+func (q *Queries) FailNetworkScan(ctx context.Context, arg FailNetworkScanParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, failNetworkScan, arg.FailedAt, arg.Failure, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const failStaleNetworkScans = `-- name: FailStaleNetworkScans :execrows
+update network_scans
+set
+    failed_at = ?1,
+    failure = ?2
+where finished_at = -1
+    and failed_at = -1
+    and started_at < ?3
+    and not exists (
+        select 1 from scan_leases
+        where scan_leases.scan_id = network_scans.id
+            and scan_leases.expires_at > ?1
+    )
+`
+
+type FailStaleNetworkScansParams struct {
+	FailedAt      int64  `json:"failed_at"`
+	Failure       string `json:"failure"`
+	StartedBefore int64  `json:"started_before"`
+}
+
+func (q *Queries) FailStaleNetworkScans(ctx context.Context, arg FailStaleNetworkScansParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, failStaleNetworkScans, arg.FailedAt, arg.Failure, arg.StartedBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const finishNetworkScan = `-- name: FinishNetworkScan :one
+update network_scans
+set
+    finished_at = strftime('%s', 'now'),
+    hosts_found = ?,
+    ports_found = ?
+where id = ?
+returning id, network_id, started_at, finished_at, hosts_found, ports_found, failed_at, failure, started_by
+`
+
+type FinishNetworkScanParams struct {
+	HostsFound int64 `json:"hosts_found"`
+	PortsFound int64 `json:"ports_found"`
+	ID         int64 `json:"id"`
+}
+
+func (q *Queries) FinishNetworkScan(ctx context.Context, arg FinishNetworkScanParams) (NetworkScan, error) {
+	row := q.db.QueryRowContext(ctx, finishNetworkScan, arg.HostsFound, arg.PortsFound, arg.ID)
+	var i NetworkScan
+	err := row.Scan(
+		&i.ID,
+		&i.NetworkID,
+		&i.StartedAt,
+		&i.FinishedAt,
+		&i.HostsFound,
+		&i.PortsFound,
+		&i.FailedAt,
+		&i.Failure,
+		&i.StartedBy,
+	)
+	return i, err
+}
+
+const getAlert = `-- name: GetAlert :one
+select id, rule_id, network_id, scan_id, fingerprint, summary, count, first_seen_at, last_seen_at, acknowledged_at from alerts
+where id = ? limit 1
+`
+
+func (q *Queries) GetAlert(ctx context.Context, id int64) (Alert, error) {
+	row := q.db.QueryRowContext(ctx, getAlert, id)
+	var i Alert
+	err := row.Scan(
+		&i.ID,
+		&i.RuleID,
+		&i.NetworkID,
+		&i.ScanID,
+		&i.Fingerprint,
+		&i.Summary,
+		&i.Count,
+		&i.FirstSeenAt,
+		&i.LastSeenAt,
+		&i.AcknowledgedAt,
+	)
+	return i, err
+}
+
+const getAlertRule = `-- name: GetAlertRule :one
+select id, name, kind, network_id, port, protocol, measurement, webhook_url, email_to, enabled, created_at from alert_rules
+where id = ? limit 1
+`
+
+func (q *Queries) GetAlertRule(ctx context.Context, id int64) (AlertRule, error) {
+	row := q.db.QueryRowContext(ctx, getAlertRule, id)
+	var i AlertRule
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Kind,
+		&i.NetworkID,
+		&i.Port,
+		&i.Protocol,
+		&i.Measurement,
+		&i.WebhookUrl,
+		&i.EmailTo,
+		&i.Enabled,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getApiTokenByHash = `-- name: GetApiTokenByHash :one
+select id, name, role, token_hash, created_at, last_used_at from api_tokens
+where token_hash = ? limit 1
+`
+
+func (q *Queries) GetApiTokenByHash(ctx context.Context, tokenHash string) (ApiToken, error) {
+	row := q.db.QueryRowContext(ctx, getApiTokenByHash, tokenHash)
+	var i ApiToken
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Role,
+		&i.TokenHash,
+		&i.CreatedAt,
+		&i.LastUsedAt,
+	)
+	return i, err
+}
+
+const getApiTokenByName = `-- name: GetApiTokenByName :one
+select id, name, role, token_hash, created_at, last_used_at from api_tokens
+where name = ? limit 1
+`
+
+func (q *Queries) GetApiTokenByName(ctx context.Context, name string) (ApiToken, error) {
+	row := q.db.QueryRowContext(ctx, getApiTokenByName, name)
+	var i ApiToken
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Role,
+		&i.TokenHash,
+		&i.CreatedAt,
+		&i.LastUsedAt,
+	)
+	return i, err
+}
+
+const getHost = `-- name: GetHost :one
+select address, network_id, comments, vendor, device_class, first_seen, last_seen, missed_scans, gone_at, device_class_score from hosts
+where address = ? limit 1
+`
+
+func (q *Queries) GetHost(ctx context.Context, address string) (Host, error) {
+	row := q.db.QueryRowContext(ctx, getHost, address)
+	var i Host
+	err := row.Scan(
+		&i.Address,
+		&i.NetworkID,
+		&i.Comments,
+		&i.Vendor,
+		&i.DeviceClass,
+		&i.FirstSeen,
+		&i.LastSeen,
+		&i.MissedScans,
+		&i.GoneAt,
+		&i.DeviceClassScore,
+	)
 	return i, err
 }
 
 const getHostPort = `-- name: GetHostPort :one
-select address, port, protocol, comments from host_ports
+select address, port, protocol, comments, first_seen, last_seen from host_ports
 where address = ?
     and port = ?
     and protocol = ?
@@ -170,12 +1070,32 @@ func (q *Queries) GetHostPort(ctx context.Context, arg GetHostPortParams) (HostP
 		&i.Port,
 		&i.Protocol,
 		&i.Comments,
+		&i.FirstSeen,
+		&i.LastSeen,
+	)
+	return i, err
+}
+
+const getHostScan = `-- name: GetHostScan :one
+select address, scan_id, document, indexed_at, submitted_by from host_scans
+where address = ? limit 1
+`
+
+func (q *Queries) GetHostScan(ctx context.Context, address string) (HostScan, error) {
+	row := q.db.QueryRowContext(ctx, getHostScan, address)
+	var i HostScan
+	err := row.Scan(
+		&i.Address,
+		&i.ScanID,
+		&i.Document,
+		&i.IndexedAt,
+		&i.SubmittedBy,
 	)
 	return i, err
 }
 
 const getHostWithNetwork = `-- name: GetHostWithNetwork :one
-select address, network_id, comments from hosts
+select address, network_id, comments, vendor, device_class, first_seen, last_seen, missed_scans, gone_at, device_class_score from hosts
 where address = ? and network_id = ? limit 1
 `
 
@@ -187,7 +1107,18 @@ type GetHostWithNetworkParams struct {
 func (q *Queries) GetHostWithNetwork(ctx context.Context, arg GetHostWithNetworkParams) (Host, error) {
 	row := q.db.QueryRowContext(ctx, getHostWithNetwork, arg.Address, arg.NetworkID)
 	var i Host
-	err := row.Scan(&i.Address, &i.NetworkID, &i.Comments)
+	err := row.Scan(
+		&i.Address,
+		&i.NetworkID,
+		&i.Comments,
+		&i.Vendor,
+		&i.DeviceClass,
+		&i.FirstSeen,
+		&i.LastSeen,
+		&i.MissedScans,
+		&i.GoneAt,
+		&i.DeviceClassScore,
+	)
 	return i, err
 }
 
@@ -288,24 +1219,1455 @@ func (q *Queries) GetNetworkById(ctx context.Context, id int64) (Network, error)
 	return i, err
 }
 
-const listHostPorts = `-- name: ListHostPorts :many
-select address, port, protocol, comments from host_ports
+const getNetworkScan = `-- name: GetNetworkScan :one
+select id, network_id, started_at, finished_at, hosts_found, ports_found, failed_at, failure, started_by from network_scans
+where id = ? and network_id = ? limit 1
+`
+
+type GetNetworkScanParams struct {
+	ID        int64 `json:"id"`
+	NetworkID int64 `json:"network_id"`
+}
+
+func (q *Queries) GetNetworkScan(ctx context.Context, arg GetNetworkScanParams) (NetworkScan, error) {
+	row := q.db.QueryRowContext(ctx, getNetworkScan, arg.ID, arg.NetworkID)
+	var i NetworkScan
+	err := row.Scan(
+		&i.ID,
+		&i.NetworkID,
+		&i.StartedAt,
+		&i.FinishedAt,
+		&i.HostsFound,
+		&i.PortsFound,
+		&i.FailedAt,
+		&i.Failure,
+		&i.StartedBy,
+	)
+	return i, err
+}
+
+const getOpenAlertByFingerprint = `-- name: GetOpenAlertByFingerprint :one
+select id, rule_id, network_id, scan_id, fingerprint, summary, count, first_seen_at, last_seen_at, acknowledged_at from alerts
+where rule_id = ?
+    and fingerprint = ?
+    and acknowledged_at = -1
+limit 1
+`
+
+type GetOpenAlertByFingerprintParams struct {
+	RuleID      int64  `json:"rule_id"`
+	Fingerprint string `json:"fingerprint"`
+}
+
+func (q *Queries) GetOpenAlertByFingerprint(ctx context.Context, arg GetOpenAlertByFingerprintParams) (Alert, error) {
+	row := q.db.QueryRowContext(ctx, getOpenAlertByFingerprint, arg.RuleID, arg.Fingerprint)
+	var i Alert
+	err := row.Scan(
+		&i.ID,
+		&i.RuleID,
+		&i.NetworkID,
+		&i.ScanID,
+		&i.Fingerprint,
+		&i.Summary,
+		&i.Count,
+		&i.FirstSeenAt,
+		&i.LastSeenAt,
+		&i.AcknowledgedAt,
+	)
+	return i, err
+}
+
+const getPreviousNetworkScan = `-- name: GetPreviousNetworkScan :one
+select id, network_id, started_at, finished_at, hosts_found, ports_found, failed_at, failure, started_by from network_scans
+where network_id = ?
+    and id < ?
+    and finished_at != -1
+order by id desc
+limit 1
+`
+
+type GetPreviousNetworkScanParams struct {
+	NetworkID int64 `json:"network_id"`
+	ID        int64 `json:"id"`
+}
+
+func (q *Queries) GetPreviousNetworkScan(ctx context.Context, arg GetPreviousNetworkScanParams) (NetworkScan, error) {
+	row := q.db.QueryRowContext(ctx, getPreviousNetworkScan, arg.NetworkID, arg.ID)
+	var i NetworkScan
+	err := row.Scan(
+		&i.ID,
+		&i.NetworkID,
+		&i.StartedAt,
+		&i.FinishedAt,
+		&i.HostsFound,
+		&i.PortsFound,
+		&i.FailedAt,
+		&i.Failure,
+		&i.StartedBy,
+	)
+	return i, err
+}
+
+const getScanLease = `-- name: GetScanLease :one
+select network_id, scan_id, worker, acquired_at, heartbeat_at, expires_at from scan_leases
+where network_id = ? limit 1
+`
+
+func (q *Queries) GetScanLease(ctx context.Context, networkID int64) (ScanLease, error) {
+	row := q.db.QueryRowContext(ctx, getScanLease, networkID)
+	var i ScanLease
+	err := row.Scan(
+		&i.NetworkID,
+		&i.ScanID,
+		&i.Worker,
+		&i.AcquiredAt,
+		&i.HeartbeatAt,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
+
+const getScanProfile = `-- name: GetScanProfile :one
+select id, name, scan_type, ports, top_ports, nmap_flags, nmap_scripts, snmp_enabled, rate, scan_interval, created_at from scan_profiles
+where id = ? limit 1
+`
+
+func (q *Queries) GetScanProfile(ctx context.Context, id int64) (ScanProfile, error) {
+	row := q.db.QueryRowContext(ctx, getScanProfile, id)
+	var i ScanProfile
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.ScanType,
+		&i.Ports,
+		&i.TopPorts,
+		&i.NmapFlags,
+		&i.NmapScripts,
+		&i.SnmpEnabled,
+		&i.Rate,
+		&i.ScanInterval,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getScanProfileByName = `-- name: GetScanProfileByName :one
+select id, name, scan_type, ports, top_ports, nmap_flags, nmap_scripts, snmp_enabled, rate, scan_interval, created_at from scan_profiles
+where name = ? limit 1
+`
+
+func (q *Queries) GetScanProfileByName(ctx context.Context, name string) (ScanProfile, error) {
+	row := q.db.QueryRowContext(ctx, getScanProfileByName, name)
+	var i ScanProfile
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.ScanType,
+		&i.Ports,
+		&i.TopPorts,
+		&i.NmapFlags,
+		&i.NmapScripts,
+		&i.SnmpEnabled,
+		&i.Rate,
+		&i.ScanInterval,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getSnmpCredential = `-- name: GetSnmpCredential :one
+select id, name, network_id, address, priority, version, community, username, auth_protocol, auth_passphrase, priv_protocol, priv_passphrase, created_at from snmp_credentials
+where id = ? limit 1
+`
+
+func (q *Queries) GetSnmpCredential(ctx context.Context, id int64) (SnmpCredential, error) {
+	row := q.db.QueryRowContext(ctx, getSnmpCredential, id)
+	var i SnmpCredential
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.NetworkID,
+		&i.Address,
+		&i.Priority,
+		&i.Version,
+		&i.Community,
+		&i.Username,
+		&i.AuthProtocol,
+		&i.AuthPassphrase,
+		&i.PrivProtocol,
+		&i.PrivPassphrase,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const heartbeatScanLease = `-- name: HeartbeatScanLease :one
+update scan_leases
+set
+    heartbeat_at = ?,
+    expires_at = ?
+where network_id = ?
+    and scan_id = ?
+    and worker = ?
+returning network_id, scan_id, worker, acquired_at, heartbeat_at, expires_at
+`
+
+type HeartbeatScanLeaseParams struct {
+	HeartbeatAt int64  `json:"heartbeat_at"`
+	ExpiresAt   int64  `json:"expires_at"`
+	NetworkID   int64  `json:"network_id"`
+	ScanID      int64  `json:"scan_id"`
+	Worker      string `json:"worker"`
+}
+
+func (q *Queries) HeartbeatScanLease(ctx context.Context, arg HeartbeatScanLeaseParams) (ScanLease, error) {
+	row := q.db.QueryRowContext(ctx, heartbeatScanLease,
+		arg.HeartbeatAt,
+		arg.ExpiresAt,
+		arg.NetworkID,
+		arg.ScanID,
+		arg.Worker,
+	)
+	var i ScanLease
+	err := row.Scan(
+		&i.NetworkID,
+		&i.ScanID,
+		&i.Worker,
+		&i.AcquiredAt,
+		&i.HeartbeatAt,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
+
+const listAlertRules = `-- name: ListAlertRules :many
+select id, name, kind, network_id, port, protocol, measurement, webhook_url, email_to, enabled, created_at from alert_rules
+order by id
+`
+
+func (q *Queries) ListAlertRules(ctx context.Context) ([]AlertRule, error) {
+	rows, err := q.db.QueryContext(ctx, listAlertRules)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AlertRule
+	for rows.Next() {
+		var i AlertRule
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Kind,
+			&i.NetworkID,
+			&i.Port,
+			&i.Protocol,
+			&i.Measurement,
+			&i.WebhookUrl,
+			&i.EmailTo,
+			&i.Enabled,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAlerts = `-- name: ListAlerts :many
+select id, rule_id, network_id, scan_id, fingerprint, summary, count, first_seen_at, last_seen_at, acknowledged_at from alerts
+order by id desc
+`
+
+func (q *Queries) ListAlerts(ctx context.Context) ([]Alert, error) {
+	rows, err := q.db.QueryContext(ctx, listAlerts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Alert
+	for rows.Next() {
+		var i Alert
+		if err := rows.Scan(
+			&i.ID,
+			&i.RuleID,
+			&i.NetworkID,
+			&i.ScanID,
+			&i.Fingerprint,
+			&i.Summary,
+			&i.Count,
+			&i.FirstSeenAt,
+			&i.LastSeenAt,
+			&i.AcknowledgedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAllHostArpEntries = `-- name: ListAllHostArpEntries :many
+select address, if_index, ip_address, mac_address from host_arp_entries
+order by address, if_index, ip_address
+`
+
+func (q *Queries) ListAllHostArpEntries(ctx context.Context) ([]HostArpEntry, error) {
+	rows, err := q.db.QueryContext(ctx, listAllHostArpEntries)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []HostArpEntry
+	for rows.Next() {
+		var i HostArpEntry
+		if err := rows.Scan(
+			&i.Address,
+			&i.IfIndex,
+			&i.IpAddress,
+			&i.MacAddress,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAllHostFdbEntries = `-- name: ListAllHostFdbEntries :many
+select address, mac_address, bridge_port, if_index from host_fdb_entries
+order by address, bridge_port, mac_address
+`
+
+func (q *Queries) ListAllHostFdbEntries(ctx context.Context) ([]HostFdbEntry, error) {
+	rows, err := q.db.QueryContext(ctx, listAllHostFdbEntries)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []HostFdbEntry
+	for rows.Next() {
+		var i HostFdbEntry
+		if err := rows.Scan(
+			&i.Address,
+			&i.MacAddress,
+			&i.BridgePort,
+			&i.IfIndex,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAllHostInterfaces = `-- name: ListAllHostInterfaces :many
+select address, if_index, name, descr, alias, type, mtu, speed, mac_address, admin_status, oper_status from host_interfaces
+order by address, if_index
+`
+
+func (q *Queries) ListAllHostInterfaces(ctx context.Context) ([]HostInterface, error) {
+	rows, err := q.db.QueryContext(ctx, listAllHostInterfaces)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []HostInterface
+	for rows.Next() {
+		var i HostInterface
+		if err := rows.Scan(
+			&i.Address,
+			&i.IfIndex,
+			&i.Name,
+			&i.Descr,
+			&i.Alias,
+			&i.Type,
+			&i.Mtu,
+			&i.Speed,
+			&i.MacAddress,
+			&i.AdminStatus,
+			&i.OperStatus,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAllHostNeighbors = `-- name: ListAllHostNeighbors :many
+select address, local_port, local_port_descr, chassis_id, port_id, port_descr, sys_name, sys_descr, management_address, local_port_id from host_neighbors
+order by address, local_port
+`
+
+func (q *Queries) ListAllHostNeighbors(ctx context.Context) ([]HostNeighbor, error) {
+	rows, err := q.db.QueryContext(ctx, listAllHostNeighbors)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []HostNeighbor
+	for rows.Next() {
+		var i HostNeighbor
+		if err := rows.Scan(
+			&i.Address,
+			&i.LocalPort,
+			&i.LocalPortDescr,
+			&i.ChassisID,
+			&i.PortID,
+			&i.PortDescr,
+			&i.SysName,
+			&i.SysDescr,
+			&i.ManagementAddress,
+			&i.LocalPortID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listApiTokens = `-- name: ListApiTokens :many
+select id, name, role, created_at, last_used_at from api_tokens
+order by name
+`
+
+type ListApiTokensRow struct {
+	ID         int64  `json:"id"`
+	Name       string `json:"name"`
+	Role       string `json:"role"`
+	CreatedAt  int64  `json:"created_at"`
+	LastUsedAt int64  `json:"last_used_at"`
+}
+
+func (q *Queries) ListApiTokens(ctx context.Context) ([]ListApiTokensRow, error) {
+	rows, err := q.db.QueryContext(ctx, listApiTokens)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListApiTokensRow
+	for rows.Next() {
+		var i ListApiTokensRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Role,
+			&i.CreatedAt,
+			&i.LastUsedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAuditLogEntries = `-- name: ListAuditLogEntries :many
+select id, token_name, role, method, path, status, created_at from audit_log
+order by id desc
+limit ?
+`
+
+func (q *Queries) ListAuditLogEntries(ctx context.Context, limit int64) ([]AuditLog, error) {
+	rows, err := q.db.QueryContext(ctx, listAuditLogEntries, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AuditLog
+	for rows.Next() {
+		var i AuditLog
+		if err := rows.Scan(
+			&i.ID,
+			&i.TokenName,
+			&i.Role,
+			&i.Method,
+			&i.Path,
+			&i.Status,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAuditLogEntriesByToken = `-- name: ListAuditLogEntriesByToken :many
+select id, token_name, role, method, path, status, created_at from audit_log
+where token_name = ?
+order by id desc
+limit ?
+`
+
+type ListAuditLogEntriesByTokenParams struct {
+	TokenName string `json:"token_name"`
+	Limit     int64  `json:"limit"`
+}
+
+func (q *Queries) ListAuditLogEntriesByToken(ctx context.Context, arg ListAuditLogEntriesByTokenParams) ([]AuditLog, error) {
+	rows, err := q.db.QueryContext(ctx, listAuditLogEntriesByToken, arg.TokenName, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AuditLog
+	for rows.Next() {
+		var i AuditLog
+		if err := rows.Scan(
+			&i.ID,
+			&i.TokenName,
+			&i.Role,
+			&i.Method,
+			&i.Path,
+			&i.Status,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listClaimableNetworks = `-- name: ListClaimableNetworks :many
+select
+    networks.id, networks.name, networks.address, networks.cidr, networks.comments,
+    cast(coalesce((
+        select max(network_scans.finished_at) from network_scans
+        where network_scans.network_id = networks.id
+    ), -1) as integer) as last_finished_at
+from networks
+left join scan_leases on scan_leases.network_id = networks.id
+where scan_leases.network_id is null or scan_leases.expires_at <= ?
+order by networks.id
+`
+
+type ListClaimableNetworksRow struct {
+	Network        Network `json:"network"`
+	LastFinishedAt int64   `json:"last_finished_at"`
+}
+
+func (q *Queries) ListClaimableNetworks(ctx context.Context, expiresAt int64) ([]ListClaimableNetworksRow, error) {
+	rows, err := q.db.QueryContext(ctx, listClaimableNetworks, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListClaimableNetworksRow
+	for rows.Next() {
+		var i ListClaimableNetworksRow
+		if err := rows.Scan(
+			&i.Network.ID,
+			&i.Network.Name,
+			&i.Network.Address,
+			&i.Network.Cidr,
+			&i.Network.Comments,
+			&i.LastFinishedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEnabledAlertRulesForNetwork = `-- name: ListEnabledAlertRulesForNetwork :many
+select id, name, kind, network_id, port, protocol, measurement, webhook_url, email_to, enabled, created_at from alert_rules
+where enabled
+    and (network_id = 0 or network_id = ?)
+order by id
+`
+
+func (q *Queries) ListEnabledAlertRulesForNetwork(ctx context.Context, networkID int64) ([]AlertRule, error) {
+	rows, err := q.db.QueryContext(ctx, listEnabledAlertRulesForNetwork, networkID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AlertRule
+	for rows.Next() {
+		var i AlertRule
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Kind,
+			&i.NetworkID,
+			&i.Port,
+			&i.Protocol,
+			&i.Measurement,
+			&i.WebhookUrl,
+			&i.EmailTo,
+			&i.Enabled,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listHostArpEntries = `-- name: ListHostArpEntries :many
+select address, if_index, ip_address, mac_address from host_arp_entries
+where address = ?
+order by if_index, ip_address
+`
+
+func (q *Queries) ListHostArpEntries(ctx context.Context, address string) ([]HostArpEntry, error) {
+	rows, err := q.db.QueryContext(ctx, listHostArpEntries, address)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []HostArpEntry
+	for rows.Next() {
+		var i HostArpEntry
+		if err := rows.Scan(
+			&i.Address,
+			&i.IfIndex,
+			&i.IpAddress,
+			&i.MacAddress,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listHostAttributes = `-- name: ListHostAttributes :many
+select address, key, value from host_attributes
+where address = ?
+order by key
+`
+
+func (q *Queries) ListHostAttributes(ctx context.Context, address string) ([]HostAttribute, error) {
+	rows, err := q.db.QueryContext(ctx, listHostAttributes, address)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []HostAttribute
+	for rows.Next() {
+		var i HostAttribute
+		if err := rows.Scan(&i.Address, &i.Key, &i.Value); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listHostAttributesByKey = `-- name: ListHostAttributesByKey :many
+select address, key, value from host_attributes
+where key = ?
+`
+
+func (q *Queries) ListHostAttributesByKey(ctx context.Context, key string) ([]HostAttribute, error) {
+	rows, err := q.db.QueryContext(ctx, listHostAttributesByKey, key)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []HostAttribute
+	for rows.Next() {
+		var i HostAttribute
+		if err := rows.Scan(&i.Address, &i.Key, &i.Value); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listHostAttributesForNetworkByKey = `-- name: ListHostAttributesForNetworkByKey :many
+select host_attributes.address, host_attributes.key, host_attributes.value from host_attributes
+join hosts on hosts.address = host_attributes.address
+where hosts.network_id = ? and host_attributes.key = ?
+`
+
+type ListHostAttributesForNetworkByKeyParams struct {
+	NetworkID int64  `json:"network_id"`
+	Key       string `json:"key"`
+}
+
+func (q *Queries) ListHostAttributesForNetworkByKey(ctx context.Context, arg ListHostAttributesForNetworkByKeyParams) ([]HostAttribute, error) {
+	rows, err := q.db.QueryContext(ctx, listHostAttributesForNetworkByKey, arg.NetworkID, arg.Key)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []HostAttribute
+	for rows.Next() {
+		var i HostAttribute
+		if err := rows.Scan(&i.Address, &i.Key, &i.Value); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listHostFdbEntries = `-- name: ListHostFdbEntries :many
+select address, mac_address, bridge_port, if_index from host_fdb_entries
+where address = ?
+order by bridge_port, mac_address
+`
+
+func (q *Queries) ListHostFdbEntries(ctx context.Context, address string) ([]HostFdbEntry, error) {
+	rows, err := q.db.QueryContext(ctx, listHostFdbEntries, address)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []HostFdbEntry
+	for rows.Next() {
+		var i HostFdbEntry
+		if err := rows.Scan(
+			&i.Address,
+			&i.MacAddress,
+			&i.BridgePort,
+			&i.IfIndex,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listHostInterfaces = `-- name: ListHostInterfaces :many
+select address, if_index, name, descr, alias, type, mtu, speed, mac_address, admin_status, oper_status from host_interfaces
+where address = ?
+order by if_index
+`
+
+func (q *Queries) ListHostInterfaces(ctx context.Context, address string) ([]HostInterface, error) {
+	rows, err := q.db.QueryContext(ctx, listHostInterfaces, address)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []HostInterface
+	for rows.Next() {
+		var i HostInterface
+		if err := rows.Scan(
+			&i.Address,
+			&i.IfIndex,
+			&i.Name,
+			&i.Descr,
+			&i.Alias,
+			&i.Type,
+			&i.Mtu,
+			&i.Speed,
+			&i.MacAddress,
+			&i.AdminStatus,
+			&i.OperStatus,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listHostNeighbors = `-- name: ListHostNeighbors :many
+select address, local_port, local_port_descr, chassis_id, port_id, port_descr, sys_name, sys_descr, management_address, local_port_id from host_neighbors
+where address = ?
+order by local_port
+`
+
+func (q *Queries) ListHostNeighbors(ctx context.Context, address string) ([]HostNeighbor, error) {
+	rows, err := q.db.QueryContext(ctx, listHostNeighbors, address)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []HostNeighbor
+	for rows.Next() {
+		var i HostNeighbor
+		if err := rows.Scan(
+			&i.Address,
+			&i.LocalPort,
+			&i.LocalPortDescr,
+			&i.ChassisID,
+			&i.PortID,
+			&i.PortDescr,
+			&i.SysName,
+			&i.SysDescr,
+			&i.ManagementAddress,
+			&i.LocalPortID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listHostPorts = `-- name: ListHostPorts :many
+select address, port, protocol, comments, first_seen, last_seen from host_ports
+`
+
+func (q *Queries) ListHostPorts(ctx context.Context) ([]HostPort, error) {
+	rows, err := q.db.QueryContext(ctx, listHostPorts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []HostPort
+	for rows.Next() {
+		var i HostPort
+		if err := rows.Scan(
+			&i.Address,
+			&i.Port,
+			&i.Protocol,
+			&i.Comments,
+			&i.FirstSeen,
+			&i.LastSeen,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listHostPortsByHostAddress = `-- name: ListHostPortsByHostAddress :many
+select address, port, protocol, comments, first_seen, last_seen from host_ports
+where address = ?
+`
+
+func (q *Queries) ListHostPortsByHostAddress(ctx context.Context, address string) ([]HostPort, error) {
+	rows, err := q.db.QueryContext(ctx, listHostPortsByHostAddress, address)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []HostPort
+	for rows.Next() {
+		var i HostPort
+		if err := rows.Scan(
+			&i.Address,
+			&i.Port,
+			&i.Protocol,
+			&i.Comments,
+			&i.FirstSeen,
+			&i.LastSeen,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listHostScanHistory = `-- name: ListHostScanHistory :many
+select host_scan_snapshots.scan_id, network_scans.started_at, network_scans.finished_at, host_scan_snapshots.document
+from host_scan_snapshots
+join network_scans on network_scans.id = host_scan_snapshots.scan_id
+where host_scan_snapshots.address = ?
+order by host_scan_snapshots.scan_id desc
+limit ?
+`
+
+type ListHostScanHistoryParams struct {
+	Address string `json:"address"`
+	Limit   int64  `json:"limit"`
+}
+
+type ListHostScanHistoryRow struct {
+	ScanID     int64  `json:"scan_id"`
+	StartedAt  int64  `json:"started_at"`
+	FinishedAt int64  `json:"finished_at"`
+	Document   string `json:"document"`
+}
+
+func (q *Queries) ListHostScanHistory(ctx context.Context, arg ListHostScanHistoryParams) ([]ListHostScanHistoryRow, error) {
+	rows, err := q.db.QueryContext(ctx, listHostScanHistory, arg.Address, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListHostScanHistoryRow
+	for rows.Next() {
+		var i ListHostScanHistoryRow
+		if err := rows.Scan(
+			&i.ScanID,
+			&i.StartedAt,
+			&i.FinishedAt,
+			&i.Document,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listHostScanSnapshotsByScan = `-- name: ListHostScanSnapshotsByScan :many
+select scan_id, address, document from host_scan_snapshots
+where scan_id = ?
+order by address
+`
+
+func (q *Queries) ListHostScanSnapshotsByScan(ctx context.Context, scanID int64) ([]HostScanSnapshot, error) {
+	rows, err := q.db.QueryContext(ctx, listHostScanSnapshotsByScan, scanID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []HostScanSnapshot
+	for rows.Next() {
+		var i HostScanSnapshot
+		if err := rows.Scan(&i.ScanID, &i.Address, &i.Document); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listHostScans = `-- name: ListHostScans :many
+select address, scan_id, document, indexed_at, submitted_by from host_scans
+order by address
+`
+
+func (q *Queries) ListHostScans(ctx context.Context) ([]HostScan, error) {
+	rows, err := q.db.QueryContext(ctx, listHostScans)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []HostScan
+	for rows.Next() {
+		var i HostScan
+		if err := rows.Scan(
+			&i.Address,
+			&i.ScanID,
+			&i.Document,
+			&i.IndexedAt,
+			&i.SubmittedBy,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listHosts = `-- name: ListHosts :many
+select address, network_id, comments, vendor, device_class, first_seen, last_seen, missed_scans, gone_at, device_class_score from hosts
+`
+
+func (q *Queries) ListHosts(ctx context.Context) ([]Host, error) {
+	rows, err := q.db.QueryContext(ctx, listHosts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Host
+	for rows.Next() {
+		var i Host
+		if err := rows.Scan(
+			&i.Address,
+			&i.NetworkID,
+			&i.Comments,
+			&i.Vendor,
+			&i.DeviceClass,
+			&i.FirstSeen,
+			&i.LastSeen,
+			&i.MissedScans,
+			&i.GoneAt,
+			&i.DeviceClassScore,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listHostsByDeviceClass = `-- name: ListHostsByDeviceClass :many
+select address, network_id, comments, vendor, device_class, first_seen, last_seen, missed_scans, gone_at, device_class_score from hosts
+where device_class = ?
+`
+
+func (q *Queries) ListHostsByDeviceClass(ctx context.Context, deviceClass string) ([]Host, error) {
+	rows, err := q.db.QueryContext(ctx, listHostsByDeviceClass, deviceClass)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Host
+	for rows.Next() {
+		var i Host
+		if err := rows.Scan(
+			&i.Address,
+			&i.NetworkID,
+			&i.Comments,
+			&i.Vendor,
+			&i.DeviceClass,
+			&i.FirstSeen,
+			&i.LastSeen,
+			&i.MissedScans,
+			&i.GoneAt,
+			&i.DeviceClassScore,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listHostsByNetwork = `-- name: ListHostsByNetwork :many
+select address, network_id, comments, vendor, device_class, first_seen, last_seen, missed_scans, gone_at, device_class_score from hosts
+where network_id = ?
+`
+
+func (q *Queries) ListHostsByNetwork(ctx context.Context, networkID int64) ([]Host, error) {
+	rows, err := q.db.QueryContext(ctx, listHostsByNetwork, networkID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Host
+	for rows.Next() {
+		var i Host
+		if err := rows.Scan(
+			&i.Address,
+			&i.NetworkID,
+			&i.Comments,
+			&i.Vendor,
+			&i.DeviceClass,
+			&i.FirstSeen,
+			&i.LastSeen,
+			&i.MissedScans,
+			&i.GoneAt,
+			&i.DeviceClassScore,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listNetworkAttributes = `-- name: ListNetworkAttributes :many
+select network_id, key, value from network_attributes
+where network_id = ?
+order by key
+`
+
+func (q *Queries) ListNetworkAttributes(ctx context.Context, networkID int64) ([]NetworkAttribute, error) {
+	rows, err := q.db.QueryContext(ctx, listNetworkAttributes, networkID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []NetworkAttribute
+	for rows.Next() {
+		var i NetworkAttribute
+		if err := rows.Scan(&i.NetworkID, &i.Key, &i.Value); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listNetworkAttributesByKey = `-- name: ListNetworkAttributesByKey :many
+select network_id, key, value from network_attributes
+where key = ?
+`
+
+func (q *Queries) ListNetworkAttributesByKey(ctx context.Context, key string) ([]NetworkAttribute, error) {
+	rows, err := q.db.QueryContext(ctx, listNetworkAttributesByKey, key)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []NetworkAttribute
+	for rows.Next() {
+		var i NetworkAttribute
+		if err := rows.Scan(&i.NetworkID, &i.Key, &i.Value); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listNetworkScansByNetwork = `-- name: ListNetworkScansByNetwork :many
+select id, network_id, started_at, finished_at, hosts_found, ports_found, failed_at, failure, started_by from network_scans
+where network_id = ?
+order by id desc
+`
+
+func (q *Queries) ListNetworkScansByNetwork(ctx context.Context, networkID int64) ([]NetworkScan, error) {
+	rows, err := q.db.QueryContext(ctx, listNetworkScansByNetwork, networkID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []NetworkScan
+	for rows.Next() {
+		var i NetworkScan
+		if err := rows.Scan(
+			&i.ID,
+			&i.NetworkID,
+			&i.StartedAt,
+			&i.FinishedAt,
+			&i.HostsFound,
+			&i.PortsFound,
+			&i.FailedAt,
+			&i.Failure,
+			&i.StartedBy,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listNetworkVulnerabilities = `-- name: ListNetworkVulnerabilities :many
+select
+    host_port_vulnerabilities.address,
+    host_port_vulnerabilities.port,
+    host_port_vulnerabilities.protocol,
+    host_port_vulnerabilities.cpe,
+    vulnerabilities.id,
+    vulnerabilities.severity,
+    vulnerabilities.score,
+    vulnerabilities.description
+from host_port_vulnerabilities
+join hosts on hosts.address = host_port_vulnerabilities.address
+join vulnerabilities on vulnerabilities.id = host_port_vulnerabilities.vulnerability_id
+where hosts.network_id = ? and vulnerabilities.score >= ?
+order by vulnerabilities.score desc, host_port_vulnerabilities.address, host_port_vulnerabilities.port, vulnerabilities.id
+`
+
+type ListNetworkVulnerabilitiesParams struct {
+	NetworkID int64   `json:"network_id"`
+	Score     float64 `json:"score"`
+}
+
+type ListNetworkVulnerabilitiesRow struct {
+	Address     string  `json:"address"`
+	Port        int64   `json:"port"`
+	Protocol    string  `json:"protocol"`
+	Cpe         string  `json:"cpe"`
+	ID          string  `json:"id"`
+	Severity    string  `json:"severity"`
+	Score       float64 `json:"score"`
+	Description string  `json:"description"`
+}
+
+func (q *Queries) ListNetworkVulnerabilities(ctx context.Context, arg ListNetworkVulnerabilitiesParams) ([]ListNetworkVulnerabilitiesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listNetworkVulnerabilities, arg.NetworkID, arg.Score)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListNetworkVulnerabilitiesRow
+	for rows.Next() {
+		var i ListNetworkVulnerabilitiesRow
+		if err := rows.Scan(
+			&i.Address,
+			&i.Port,
+			&i.Protocol,
+			&i.Cpe,
+			&i.ID,
+			&i.Severity,
+			&i.Score,
+			&i.Description,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listNetworks = `-- name: ListNetworks :many
+select id, name, address, cidr, comments from networks
+`
+
+func (q *Queries) ListNetworks(ctx context.Context) ([]Network, error) {
+	rows, err := q.db.QueryContext(ctx, listNetworks)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Network
+	for rows.Next() {
+		var i Network
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Address,
+			&i.Cidr,
+			&i.Comments,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOpenAlerts = `-- name: ListOpenAlerts :many
+select id, rule_id, network_id, scan_id, fingerprint, summary, count, first_seen_at, last_seen_at, acknowledged_at from alerts
+where acknowledged_at = -1
+order by id desc
+`
+
+func (q *Queries) ListOpenAlerts(ctx context.Context) ([]Alert, error) {
+	rows, err := q.db.QueryContext(ctx, listOpenAlerts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Alert
+	for rows.Next() {
+		var i Alert
+		if err := rows.Scan(
+			&i.ID,
+			&i.RuleID,
+			&i.NetworkID,
+			&i.ScanID,
+			&i.Fingerprint,
+			&i.Summary,
+			&i.Count,
+			&i.FirstSeenAt,
+			&i.LastSeenAt,
+			&i.AcknowledgedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listScanLeases = `-- name: ListScanLeases :many
+select network_id, scan_id, worker, acquired_at, heartbeat_at, expires_at from scan_leases
+order by network_id
 `
 
-func (q *Queries) ListHostPorts(ctx context.Context) ([]HostPort, error) {
-	rows, err := q.db.QueryContext(ctx, listHostPorts)
+func (q *Queries) ListScanLeases(ctx context.Context) ([]ScanLease, error) {
+	rows, err := q.db.QueryContext(ctx, listScanLeases)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []HostPort
+	var items []ScanLease
 	for rows.Next() {
-		var i HostPort
+		var i ScanLease
 		if err := rows.Scan(
-			&i.Address,
-			&i.Port,
-			&i.Protocol,
-			&i.Comments,
+			&i.NetworkID,
+			&i.ScanID,
+			&i.Worker,
+			&i.AcquiredAt,
+			&i.HeartbeatAt,
+			&i.ExpiresAt,
 		); err != nil {
 			return nil, err
 		}
@@ -320,25 +2682,32 @@ func (q *Queries) ListHostPorts(ctx context.Context) ([]HostPort, error) {
 	return items, nil
 }
 
-const listHostPortsByHostAddress = `-- name: ListHostPortsByHostAddress :many
-select address, port, protocol, comments from host_ports
-where address = ?
+const listScanProfiles = `-- name: ListScanProfiles :many
+select id, name, scan_type, ports, top_ports, nmap_flags, nmap_scripts, snmp_enabled, rate, scan_interval, created_at from scan_profiles
+order by id
 `
 
-func (q *Queries) ListHostPortsByHostAddress(ctx context.Context, address string) ([]HostPort, error) {
-	rows, err := q.db.QueryContext(ctx, listHostPortsByHostAddress, address)
+func (q *Queries) ListScanProfiles(ctx context.Context) ([]ScanProfile, error) {
+	rows, err := q.db.QueryContext(ctx, listScanProfiles)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []HostPort
+	var items []ScanProfile
 	for rows.Next() {
-		var i HostPort
+		var i ScanProfile
 		if err := rows.Scan(
-			&i.Address,
-			&i.Port,
-			&i.Protocol,
-			&i.Comments,
+			&i.ID,
+			&i.Name,
+			&i.ScanType,
+			&i.Ports,
+			&i.TopPorts,
+			&i.NmapFlags,
+			&i.NmapScripts,
+			&i.SnmpEnabled,
+			&i.Rate,
+			&i.ScanInterval,
+			&i.CreatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -353,20 +2722,35 @@ func (q *Queries) ListHostPortsByHostAddress(ctx context.Context, address string
 	return items, nil
 }
 
-const listHosts = `-- name: ListHosts :many
-select address, network_id, comments from hosts
+const listSnmpCredentials = `-- name: ListSnmpCredentials :many
+select id, name, network_id, address, priority, version, community, username, auth_protocol, auth_passphrase, priv_protocol, priv_passphrase, created_at from snmp_credentials
+order by priority, id
 `
 
-func (q *Queries) ListHosts(ctx context.Context) ([]Host, error) {
-	rows, err := q.db.QueryContext(ctx, listHosts)
+func (q *Queries) ListSnmpCredentials(ctx context.Context) ([]SnmpCredential, error) {
+	rows, err := q.db.QueryContext(ctx, listSnmpCredentials)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Host
+	var items []SnmpCredential
 	for rows.Next() {
-		var i Host
-		if err := rows.Scan(&i.Address, &i.NetworkID, &i.Comments); err != nil {
+		var i SnmpCredential
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.NetworkID,
+			&i.Address,
+			&i.Priority,
+			&i.Version,
+			&i.Community,
+			&i.Username,
+			&i.AuthProtocol,
+			&i.AuthPassphrase,
+			&i.PrivProtocol,
+			&i.PrivPassphrase,
+			&i.CreatedAt,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -380,25 +2764,82 @@ func (q *Queries) ListHosts(ctx context.Context) ([]Host, error) {
 	return items, nil
 }
 
-const listNetworks = `-- name: ListNetworks :many
-select id, name, address, cidr, comments from networks
+const listSnmpCredentialsForNetwork = `-- name: ListSnmpCredentialsForNetwork :many
+select id, name, network_id, address, priority, version, community, username, auth_protocol, auth_passphrase, priv_protocol, priv_passphrase, created_at from snmp_credentials
+where (network_id = 0 and address = '')
+    or network_id = ?1
+    or address in (select hosts.address from hosts where hosts.network_id = ?1)
+order by priority, id
 `
 
-func (q *Queries) ListNetworks(ctx context.Context) ([]Network, error) {
-	rows, err := q.db.QueryContext(ctx, listNetworks)
+func (q *Queries) ListSnmpCredentialsForNetwork(ctx context.Context, networkID int64) ([]SnmpCredential, error) {
+	rows, err := q.db.QueryContext(ctx, listSnmpCredentialsForNetwork, networkID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Network
+	var items []SnmpCredential
 	for rows.Next() {
-		var i Network
+		var i SnmpCredential
 		if err := rows.Scan(
 			&i.ID,
 			&i.Name,
+			&i.NetworkID,
 			&i.Address,
-			&i.Cidr,
-			&i.Comments,
+			&i.Priority,
+			&i.Version,
+			&i.Community,
+			&i.Username,
+			&i.AuthProtocol,
+			&i.AuthPassphrase,
+			&i.PrivProtocol,
+			&i.PrivPassphrase,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listVulnerabilityCpesByProduct = `-- name: ListVulnerabilityCpesByProduct :many
+select vulnerability_id, part, vendor, product, version, version_update, version_start_including, version_start_excluding, version_end_including, version_end_excluding, criteria from vulnerability_cpes
+where vendor = ? and product = ?
+`
+
+type ListVulnerabilityCpesByProductParams struct {
+	Vendor  string `json:"vendor"`
+	Product string `json:"product"`
+}
+
+func (q *Queries) ListVulnerabilityCpesByProduct(ctx context.Context, arg ListVulnerabilityCpesByProductParams) ([]VulnerabilityCpe, error) {
+	rows, err := q.db.QueryContext(ctx, listVulnerabilityCpesByProduct, arg.Vendor, arg.Product)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []VulnerabilityCpe
+	for rows.Next() {
+		var i VulnerabilityCpe
+		if err := rows.Scan(
+			&i.VulnerabilityID,
+			&i.Part,
+			&i.Vendor,
+			&i.Product,
+			&i.Version,
+			&i.VersionUpdate,
+			&i.VersionStartIncluding,
+			&i.VersionStartExcluding,
+			&i.VersionEndIncluding,
+			&i.VersionEndExcluding,
+			&i.Criteria,
 		); err != nil {
 			return nil, err
 		}
@@ -413,12 +2854,388 @@ func (q *Queries) ListNetworks(ctx context.Context) ([]Network, error) {
 	return items, nil
 }
 
+const markHostGone = `-- name: MarkHostGone :exec
+update hosts
+set gone_at = ?
+where address = ? and gone_at = 0
+`
+
+type MarkHostGoneParams struct {
+	GoneAt  int64  `json:"gone_at"`
+	Address string `json:"address"`
+}
+
+func (q *Queries) MarkHostGone(ctx context.Context, arg MarkHostGoneParams) error {
+	_, err := q.db.ExecContext(ctx, markHostGone, arg.GoneAt, arg.Address)
+	return err
+}
+
+const markHostPortSeen = `-- name: MarkHostPortSeen :exec
+update host_ports
+set
+    first_seen = case when first_seen = 0 or first_seen > ?1 then ?1 else first_seen end,
+    last_seen = max(last_seen, ?1)
+where
+    address = ?2
+    and port = ?3
+    and protocol = ?4
+`
+
+type MarkHostPortSeenParams struct {
+	SeenAt   int64  `json:"seen_at"`
+	Address  string `json:"address"`
+	Port     int64  `json:"port"`
+	Protocol string `json:"protocol"`
+}
+
+func (q *Queries) MarkHostPortSeen(ctx context.Context, arg MarkHostPortSeenParams) error {
+	_, err := q.db.ExecContext(ctx, markHostPortSeen,
+		arg.SeenAt,
+		arg.Address,
+		arg.Port,
+		arg.Protocol,
+	)
+	return err
+}
+
+const markHostSeen = `-- name: MarkHostSeen :exec
+update hosts
+set
+    first_seen = case when first_seen = 0 or first_seen > ?1 then ?1 else first_seen end,
+    missed_scans = case when ?1 >= last_seen then 0 else missed_scans end,
+    gone_at = case when ?1 >= last_seen then 0 else gone_at end,
+    last_seen = max(last_seen, ?1)
+where address = ?2
+`
+
+type MarkHostSeenParams struct {
+	SeenAt  int64  `json:"seen_at"`
+	Address string `json:"address"`
+}
+
+func (q *Queries) MarkHostSeen(ctx context.Context, arg MarkHostSeenParams) error {
+	_, err := q.db.ExecContext(ctx, markHostSeen, arg.SeenAt, arg.Address)
+	return err
+}
+
+const markNetworkHostsMissed = `-- name: MarkNetworkHostsMissed :exec
+update hosts
+set missed_scans = missed_scans + 1
+where network_id = ? and gone_at = 0 and last_seen < ?
+`
+
+type MarkNetworkHostsMissedParams struct {
+	NetworkID int64 `json:"network_id"`
+	LastSeen  int64 `json:"last_seen"`
+}
+
+func (q *Queries) MarkNetworkHostsMissed(ctx context.Context, arg MarkNetworkHostsMissedParams) error {
+	_, err := q.db.ExecContext(ctx, markNetworkHostsMissed, arg.NetworkID, arg.LastSeen)
+	return err
+}
+
+const renameNetworkAttributeValues = `-- name: RenameNetworkAttributeValues :exec
+update network_attributes
+set value = ?
+where key = ? and value = ?
+`
+
+type RenameNetworkAttributeValuesParams struct {
+	NewValue string `json:"new_value"`
+	Key      string `json:"key"`
+	OldValue string `json:"old_value"`
+}
+
+func (q *Queries) RenameNetworkAttributeValues(ctx context.Context, arg RenameNetworkAttributeValuesParams) error {
+	_, err := q.db.ExecContext(ctx, renameNetworkAttributeValues, arg.NewValue, arg.Key, arg.OldValue)
+	return err
+}
+
+const setHostAttribute = `-- name: SetHostAttribute :one
+insert into host_attributes (
+    address, key, value
+) values (
+    ?, ?, ?
+)
+on conflict (address, key) do update set
+    value = excluded.value
+returning address, key, value
+`
+
+type SetHostAttributeParams struct {
+	Address string `json:"address"`
+	Key     string `json:"key"`
+	Value   string `json:"value"`
+}
+
+func (q *Queries) SetHostAttribute(ctx context.Context, arg SetHostAttributeParams) (HostAttribute, error) {
+	row := q.db.QueryRowContext(ctx, setHostAttribute, arg.Address, arg.Key, arg.Value)
+	var i HostAttribute
+	err := row.Scan(&i.Address, &i.Key, &i.Value)
+	return i, err
+}
+
+const setHostClassification = `-- name: SetHostClassification :one
+update hosts
+set
+    vendor = ?,
+    device_class = ?,
+    device_class_score = ?
+where address = ?
+returning address, network_id, comments, vendor, device_class, first_seen, last_seen, missed_scans, gone_at, device_class_score
+`
+
+type SetHostClassificationParams struct {
+	Vendor           string `json:"vendor"`
+	DeviceClass      string `json:"device_class"`
+	DeviceClassScore int64  `json:"device_class_score"`
+	Address          string `json:"address"`
+}
+
+func (q *Queries) SetHostClassification(ctx context.Context, arg SetHostClassificationParams) (Host, error) {
+	row := q.db.QueryRowContext(ctx, setHostClassification,
+		arg.Vendor,
+		arg.DeviceClass,
+		arg.DeviceClassScore,
+		arg.Address,
+	)
+	var i Host
+	err := row.Scan(
+		&i.Address,
+		&i.NetworkID,
+		&i.Comments,
+		&i.Vendor,
+		&i.DeviceClass,
+		&i.FirstSeen,
+		&i.LastSeen,
+		&i.MissedScans,
+		&i.GoneAt,
+		&i.DeviceClassScore,
+	)
+	return i, err
+}
+
+const setHostScan = `-- name: SetHostScan :exec
+insert into host_scans (
+    address, scan_id, document, submitted_by
+) values (
+    ?, ?, ?, ?
+)
+on conflict (address) do update set
+    scan_id = excluded.scan_id,
+    document = excluded.document,
+    indexed_at = strftime('%s', 'now'),
+    submitted_by = excluded.submitted_by
+`
+
+type SetHostScanParams struct {
+	Address     string `json:"address"`
+	ScanID      int64  `json:"scan_id"`
+	Document    string `json:"document"`
+	SubmittedBy string `json:"submitted_by"`
+}
+
+func (q *Queries) SetHostScan(ctx context.Context, arg SetHostScanParams) error {
+	_, err := q.db.ExecContext(ctx, setHostScan,
+		arg.Address,
+		arg.ScanID,
+		arg.Document,
+		arg.SubmittedBy,
+	)
+	return err
+}
+
+const setNetworkAttribute = `-- name: SetNetworkAttribute :one
+insert into network_attributes (
+    network_id, key, value
+) values (
+    ?, ?, ?
+)
+on conflict (network_id, key) do update set
+    value = excluded.value
+returning network_id, key, value
+`
+
+type SetNetworkAttributeParams struct {
+	NetworkID int64  `json:"network_id"`
+	Key       string `json:"key"`
+	Value     string `json:"value"`
+}
+
+func (q *Queries) SetNetworkAttribute(ctx context.Context, arg SetNetworkAttributeParams) (NetworkAttribute, error) {
+	row := q.db.QueryRowContext(ctx, setNetworkAttribute, arg.NetworkID, arg.Key, arg.Value)
+	var i NetworkAttribute
+	err := row.Scan(&i.NetworkID, &i.Key, &i.Value)
+	return i, err
+}
+
+const setScanLeaseScan = `-- name: SetScanLeaseScan :one
+update scan_leases
+set scan_id = ?
+where network_id = ?
+returning network_id, scan_id, worker, acquired_at, heartbeat_at, expires_at
+`
+
+type SetScanLeaseScanParams struct {
+	ScanID    int64 `json:"scan_id"`
+	NetworkID int64 `json:"network_id"`
+}
+
+func (q *Queries) SetScanLeaseScan(ctx context.Context, arg SetScanLeaseScanParams) (ScanLease, error) {
+	row := q.db.QueryRowContext(ctx, setScanLeaseScan, arg.ScanID, arg.NetworkID)
+	var i ScanLease
+	err := row.Scan(
+		&i.NetworkID,
+		&i.ScanID,
+		&i.Worker,
+		&i.AcquiredAt,
+		&i.HeartbeatAt,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
+
+const setVulnerability = `-- name: SetVulnerability :exec
+insert into vulnerabilities (
+    id, description, severity, score, published
+) values (
+    ?, ?, ?, ?, ?
+)
+on conflict (id) do update set
+    description = excluded.description,
+    severity = excluded.severity,
+    score = excluded.score,
+    published = excluded.published
+`
+
+type SetVulnerabilityParams struct {
+	ID          string  `json:"id"`
+	Description string  `json:"description"`
+	Severity    string  `json:"severity"`
+	Score       float64 `json:"score"`
+	Published   string  `json:"published"`
+}
+
+func (q *Queries) SetVulnerability(ctx context.Context, arg SetVulnerabilityParams) error {
+	_, err := q.db.ExecContext(ctx, setVulnerability,
+		arg.ID,
+		arg.Description,
+		arg.Severity,
+		arg.Score,
+		arg.Published,
+	)
+	return err
+}
+
+const touchAlert = `-- name: TouchAlert :one
+update alerts
+set
+    count = count + 1,
+    last_seen_at = strftime('%s', 'now'),
+    scan_id = ?
+where id = ?
+returning id, rule_id, network_id, scan_id, fingerprint, summary, count, first_seen_at, last_seen_at, acknowledged_at
+`
+
+type TouchAlertParams struct {
+	ScanID int64 `json:"scan_id"`
+	ID     int64 `json:"id"`
+}
+
+func (q *Queries) TouchAlert(ctx context.Context, arg TouchAlertParams) (Alert, error) {
+	row := q.db.QueryRowContext(ctx, touchAlert, arg.ScanID, arg.ID)
+	var i Alert
+	err := row.Scan(
+		&i.ID,
+		&i.RuleID,
+		&i.NetworkID,
+		&i.ScanID,
+		&i.Fingerprint,
+		&i.Summary,
+		&i.Count,
+		&i.FirstSeenAt,
+		&i.LastSeenAt,
+		&i.AcknowledgedAt,
+	)
+	return i, err
+}
+
+const touchApiToken = `-- name: TouchApiToken :exec
+update api_tokens
+set last_used_at = strftime('%s', 'now')
+where id = ?
+`
+
+func (q *Queries) TouchApiToken(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, touchApiToken, id)
+	return err
+}
+
+const updateAlertRule = `-- name: UpdateAlertRule :one
+update alert_rules
+set
+    name = ?,
+    kind = ?,
+    network_id = ?,
+    port = ?,
+    protocol = ?,
+    measurement = ?,
+    webhook_url = ?,
+    email_to = ?,
+    enabled = ?
+where id = ?
+returning id, name, kind, network_id, port, protocol, measurement, webhook_url, email_to, enabled, created_at
+`
+
+type UpdateAlertRuleParams struct {
+	Name        string `json:"name"`
+	Kind        string `json:"kind"`
+	NetworkID   int64  `json:"network_id"`
+	Port        int64  `json:"port"`
+	Protocol    string `json:"protocol"`
+	Measurement string `json:"measurement"`
+	WebhookUrl  string `json:"webhook_url"`
+	EmailTo     string `json:"email_to"`
+	Enabled     bool   `json:"enabled"`
+	ID          int64  `json:"id"`
+}
+
+func (q *Queries) UpdateAlertRule(ctx context.Context, arg UpdateAlertRuleParams) (AlertRule, error) {
+	row := q.db.QueryRowContext(ctx, updateAlertRule,
+		arg.Name,
+		arg.Kind,
+		arg.NetworkID,
+		arg.Port,
+		arg.Protocol,
+		arg.Measurement,
+		arg.WebhookUrl,
+		arg.EmailTo,
+		arg.Enabled,
+		arg.ID,
+	)
+	var i AlertRule
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Kind,
+		&i.NetworkID,
+		&i.Port,
+		&i.Protocol,
+		&i.Measurement,
+		&i.WebhookUrl,
+		&i.EmailTo,
+		&i.Enabled,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
 const updateHost = `-- name: UpdateHost :one
 update hosts
 set
     comments = ?
 where address = ?
-returning address, network_id, comments
+returning address, network_id, comments, vendor, device_class, first_seen, last_seen, missed_scans, gone_at, device_class_score
 `
 
 type UpdateHostParams struct {
@@ -429,7 +3246,18 @@ type UpdateHostParams struct {
 func (q *Queries) UpdateHost(ctx context.Context, arg UpdateHostParams) (Host, error) {
 	row := q.db.QueryRowContext(ctx, updateHost, arg.Comments, arg.Address)
 	var i Host
-	err := row.Scan(&i.Address, &i.NetworkID, &i.Comments)
+	err := row.Scan(
+		&i.Address,
+		&i.NetworkID,
+		&i.Comments,
+		&i.Vendor,
+		&i.DeviceClass,
+		&i.FirstSeen,
+		&i.LastSeen,
+		&i.MissedScans,
+		&i.GoneAt,
+		&i.DeviceClassScore,
+	)
 	return i, err
 }
 
@@ -441,7 +3269,7 @@ where
     address = ?
     and port = ?
     and protocol = ?
-returning address, port, protocol, comments
+returning address, port, protocol, comments, first_seen, last_seen
 `
 
 type UpdateHostPortParams struct {
@@ -464,6 +3292,8 @@ func (q *Queries) UpdateHostPort(ctx context.Context, arg UpdateHostPortParams)
 		&i.Port,
 		&i.Protocol,
 		&i.Comments,
+		&i.FirstSeen,
+		&i.LastSeen,
 	)
 	return i, err
 }
@@ -495,3 +3325,129 @@ func (q *Queries) UpdateNetwork(ctx context.Context, arg UpdateNetworkParams) (N
 	)
 	return i, err
 }
+
+const updateScanProfile = `-- name: UpdateScanProfile :one
+update scan_profiles
+set
+    name = ?,
+    scan_type = ?,
+    ports = ?,
+    top_ports = ?,
+    nmap_flags = ?,
+    nmap_scripts = ?,
+    snmp_enabled = ?,
+    rate = ?,
+    scan_interval = ?
+where id = ?
+returning id, name, scan_type, ports, top_ports, nmap_flags, nmap_scripts, snmp_enabled, rate, scan_interval, created_at
+`
+
+type UpdateScanProfileParams struct {
+	Name         string `json:"name"`
+	ScanType     string `json:"scan_type"`
+	Ports        string `json:"ports"`
+	TopPorts     string `json:"top_ports"`
+	NmapFlags    string `json:"nmap_flags"`
+	NmapScripts  string `json:"nmap_scripts"`
+	SnmpEnabled  bool   `json:"snmp_enabled"`
+	Rate         int64  `json:"rate"`
+	ScanInterval int64  `json:"scan_interval"`
+	ID           int64  `json:"id"`
+}
+
+func (q *Queries) UpdateScanProfile(ctx context.Context, arg UpdateScanProfileParams) (ScanProfile, error) {
+	row := q.db.QueryRowContext(ctx, updateScanProfile,
+		arg.Name,
+		arg.ScanType,
+		arg.Ports,
+		arg.TopPorts,
+		arg.NmapFlags,
+		arg.NmapScripts,
+		arg.SnmpEnabled,
+		arg.Rate,
+		arg.ScanInterval,
+		arg.ID,
+	)
+	var i ScanProfile
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.ScanType,
+		&i.Ports,
+		&i.TopPorts,
+		&i.NmapFlags,
+		&i.NmapScripts,
+		&i.SnmpEnabled,
+		&i.Rate,
+		&i.ScanInterval,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const updateSnmpCredential = `-- name: UpdateSnmpCredential :one
+update snmp_credentials
+set
+    name = ?,
+    network_id = ?,
+    address = ?,
+    priority = ?,
+    version = ?,
+    community = ?,
+    username = ?,
+    auth_protocol = ?,
+    auth_passphrase = ?,
+    priv_protocol = ?,
+    priv_passphrase = ?
+where id = ?
+returning id, name, network_id, address, priority, version, community, username, auth_protocol, auth_passphrase, priv_protocol, priv_passphrase, created_at
+`
+
+type UpdateSnmpCredentialParams struct {
+	Name           string `json:"name"`
+	NetworkID      int64  `json:"network_id"`
+	Address        string `json:"address"`
+	Priority       int64  `json:"priority"`
+	Version        string `json:"version"`
+	Community      string `json:"community"`
+	Username       string `json:"username"`
+	AuthProtocol   string `json:"auth_protocol"`
+	AuthPassphrase string `json:"auth_passphrase"`
+	PrivProtocol   string `json:"priv_protocol"`
+	PrivPassphrase string `json:"priv_passphrase"`
+	ID             int64  `json:"id"`
+}
+
+func (q *Queries) UpdateSnmpCredential(ctx context.Context, arg UpdateSnmpCredentialParams) (SnmpCredential, error) {
+	row := q.db.QueryRowContext(ctx, updateSnmpCredential,
+		arg.Name,
+		arg.NetworkID,
+		arg.Address,
+		arg.Priority,
+		arg.Version,
+		arg.Community,
+		arg.Username,
+		arg.AuthProtocol,
+		arg.AuthPassphrase,
+		arg.PrivProtocol,
+		arg.PrivPassphrase,
+		arg.ID,
+	)
+	var i SnmpCredential
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.NetworkID,
+		&i.Address,
+		&i.Priority,
+		&i.Version,
+		&i.Community,
+		&i.Username,
+		&i.AuthProtocol,
+		&i.AuthPassphrase,
+		&i.PrivProtocol,
+		&i.PrivPassphrase,
+		&i.CreatedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,82 @@
+-- name: CreateAlertRule :one
+insert into alert_rules (
+    name, kind, network_id, port, protocol, measurement, webhook_url, email_to, enabled
+) values (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?
+)
+returning *;
+
+-- name: GetAlertRule :one
+select * from alert_rules
+where id = ? limit 1;
+
+-- name: ListAlertRules :many
+select * from alert_rules
+order by id;
+
+-- name: ListEnabledAlertRulesForNetwork :many
+select * from alert_rules
+where enabled
+    and (network_id = 0 or network_id = ?)
+order by id;
+
+-- name: UpdateAlertRule :one
+update alert_rules
+set
+    name = ?,
+    kind = ?,
+    network_id = ?,
+    port = ?,
+    protocol = ?,
+    measurement = ?,
+    webhook_url = ?,
+    email_to = ?,
+    enabled = ?
+where id = ?
+returning *;
+
+-- name: DeleteAlertRule :exec
+delete from alert_rules where id = ?;
+
+-- name: CreateAlert :one
+insert into alerts (
+    rule_id, network_id, scan_id, fingerprint, summary
+) values (
+    ?, ?, ?, ?, ?
+)
+returning *;
+
+-- name: GetAlert :one
+select * from alerts
+where id = ? limit 1;
+
+-- name: GetOpenAlertByFingerprint :one
+select * from alerts
+where rule_id = ?
+    and fingerprint = ?
+    and acknowledged_at = -1
+limit 1;
+
+-- name: TouchAlert :one
+update alerts
+set
+    count = count + 1,
+    last_seen_at = strftime('%s', 'now'),
+    scan_id = ?
+where id = ?
+returning *;
+
+-- name: ListAlerts :many
+select * from alerts
+order by id desc;
+
+-- name: ListOpenAlerts :many
+select * from alerts
+where acknowledged_at = -1
+order by id desc;
+
+-- name: AcknowledgeAlert :one
+update alerts
+set acknowledged_at = strftime('%s', 'now')
+where id = ?
+returning *;
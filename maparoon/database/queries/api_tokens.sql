@@ -0,0 +1,45 @@
+-- name: CreateApiToken :one
+insert into api_tokens (
+    name, role, token_hash
+) values (
+    ?, ?, ?
+)
+returning id;
+
+-- name: GetApiTokenByHash :one
+select * from api_tokens
+where token_hash = ? limit 1;
+
+-- name: GetApiTokenByName :one
+select * from api_tokens
+where name = ? limit 1;
+
+-- name: ListApiTokens :many
+select id, name, role, created_at, last_used_at from api_tokens
+order by name;
+
+-- name: TouchApiToken :exec
+update api_tokens
+set last_used_at = strftime('%s', 'now')
+where id = ?;
+
+-- name: DeleteApiToken :exec
+delete from api_tokens where id = ?;
+
+-- name: CreateAuditLogEntry :exec
+insert into audit_log (
+    token_name, role, method, path, status
+) values (
+    ?, ?, ?, ?, ?
+);
+
+-- name: ListAuditLogEntries :many
+select * from audit_log
+order by id desc
+limit ?;
+
+-- name: ListAuditLogEntriesByToken :many
+select * from audit_log
+where token_name = ?
+order by id desc
+limit ?;
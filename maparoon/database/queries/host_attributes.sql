@@ -0,0 +1,27 @@
+-- name: ListHostAttributes :many
+select * from host_attributes
+where address = ?
+order by key;
+
+-- name: ListHostAttributesForNetworkByKey :many
+select host_attributes.* from host_attributes
+join hosts on hosts.address = host_attributes.address
+where hosts.network_id = ? and host_attributes.key = ?;
+
+-- name: SetHostAttribute :one
+insert into host_attributes (
+    address, key, value
+) values (
+    ?, ?, ?
+)
+on conflict (address, key) do update set
+    value = excluded.value
+returning *;
+
+-- name: DeleteHostAttribute :exec
+delete from host_attributes
+where address = ? and key = ?;
+
+-- name: ListHostAttributesByKey :many
+select * from host_attributes
+where key = ?;
@@ -35,3 +35,13 @@ where
     address = ?
     and port = ?
     and protocol = ?;
+
+-- name: MarkHostPortSeen :exec
+update host_ports
+set
+    first_seen = case when first_seen = 0 or first_seen > sqlc.arg(seen_at) then sqlc.arg(seen_at) else first_seen end,
+    last_seen = max(last_seen, sqlc.arg(seen_at))
+where
+    address = sqlc.arg(address)
+    and port = sqlc.arg(port)
+    and protocol = sqlc.arg(protocol);
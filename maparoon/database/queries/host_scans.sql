@@ -0,0 +1,31 @@
+-- name: SetHostScan :exec
+insert into host_scans (
+    address, scan_id, document, submitted_by
+) values (
+    ?, ?, ?, ?
+)
+on conflict (address) do update set
+    scan_id = excluded.scan_id,
+    document = excluded.document,
+    indexed_at = strftime('%s', 'now'),
+    submitted_by = excluded.submitted_by;
+
+-- name: GetHostScan :one
+select * from host_scans
+where address = ? limit 1;
+
+-- name: DeleteHostScan :exec
+delete from host_scans
+where address = ?;
+
+-- name: ListHostScanHistory :many
+select host_scan_snapshots.scan_id, network_scans.started_at, network_scans.finished_at, host_scan_snapshots.document
+from host_scan_snapshots
+join network_scans on network_scans.id = host_scan_snapshots.scan_id
+where host_scan_snapshots.address = ?
+order by host_scan_snapshots.scan_id desc
+limit ?;
+
+-- name: ListHostScans :many
+select * from host_scans
+order by address;
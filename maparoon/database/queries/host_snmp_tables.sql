@@ -0,0 +1,79 @@
+-- name: ListHostInterfaces :many
+select * from host_interfaces
+where address = ?
+order by if_index;
+
+-- name: CreateHostInterface :exec
+insert into host_interfaces (
+    address, if_index, name, descr, alias, type, mtu, speed, mac_address, admin_status, oper_status
+) values (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+);
+
+-- name: DeleteHostInterfaces :exec
+delete from host_interfaces
+where address = ?;
+
+-- name: ListHostArpEntries :many
+select * from host_arp_entries
+where address = ?
+order by if_index, ip_address;
+
+-- name: CreateHostArpEntry :exec
+insert or replace into host_arp_entries (
+    address, if_index, ip_address, mac_address
+) values (
+    ?, ?, ?, ?
+);
+
+-- name: DeleteHostArpEntries :exec
+delete from host_arp_entries
+where address = ?;
+
+-- name: ListHostFdbEntries :many
+select * from host_fdb_entries
+where address = ?
+order by bridge_port, mac_address;
+
+-- name: CreateHostFdbEntry :exec
+insert or replace into host_fdb_entries (
+    address, mac_address, bridge_port, if_index
+) values (
+    ?, ?, ?, ?
+);
+
+-- name: DeleteHostFdbEntries :exec
+delete from host_fdb_entries
+where address = ?;
+
+-- name: ListHostNeighbors :many
+select * from host_neighbors
+where address = ?
+order by local_port;
+
+-- name: CreateHostNeighbor :exec
+insert or replace into host_neighbors (
+    address, local_port, local_port_id, local_port_descr, chassis_id, port_id, port_descr, sys_name, sys_descr, management_address
+) values (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+);
+
+-- name: DeleteHostNeighbors :exec
+delete from host_neighbors
+where address = ?;
+
+-- name: ListAllHostInterfaces :many
+select * from host_interfaces
+order by address, if_index;
+
+-- name: ListAllHostArpEntries :many
+select * from host_arp_entries
+order by address, if_index, ip_address;
+
+-- name: ListAllHostFdbEntries :many
+select * from host_fdb_entries
+order by address, bridge_port, mac_address;
+
+-- name: ListAllHostNeighbors :many
+select * from host_neighbors
+order by address, local_port;
@@ -27,3 +27,39 @@ returning *;
 -- name: DeleteHost :exec
 delete from hosts
 where address = ?;
+
+-- name: ListHostsByNetwork :many
+select * from hosts
+where network_id = ?;
+
+-- name: ListHostsByDeviceClass :many
+select * from hosts
+where device_class = ?;
+
+-- name: SetHostClassification :one
+update hosts
+set
+    vendor = ?,
+    device_class = ?,
+    device_class_score = ?
+where address = ?
+returning *;
+
+-- name: MarkHostSeen :exec
+update hosts
+set
+    first_seen = case when first_seen = 0 or first_seen > sqlc.arg(seen_at) then sqlc.arg(seen_at) else first_seen end,
+    missed_scans = case when sqlc.arg(seen_at) >= last_seen then 0 else missed_scans end,
+    gone_at = case when sqlc.arg(seen_at) >= last_seen then 0 else gone_at end,
+    last_seen = max(last_seen, sqlc.arg(seen_at))
+where address = sqlc.arg(address);
+
+-- name: MarkNetworkHostsMissed :exec
+update hosts
+set missed_scans = missed_scans + 1
+where network_id = ? and gone_at = 0 and last_seen < ?;
+
+-- name: MarkHostGone :exec
+update hosts
+set gone_at = ?
+where address = ? and gone_at = 0;
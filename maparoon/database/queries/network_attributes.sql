@@ -0,0 +1,27 @@
+-- name: ListNetworkAttributes :many
+select * from network_attributes
+where network_id = ?
+order by key;
+
+-- name: ListNetworkAttributesByKey :many
+select * from network_attributes
+where key = ?;
+
+-- name: SetNetworkAttribute :one
+insert into network_attributes (
+    network_id, key, value
+) values (
+    ?, ?, ?
+)
+on conflict (network_id, key) do update set
+    value = excluded.value
+returning *;
+
+-- name: DeleteNetworkAttribute :exec
+delete from network_attributes
+where network_id = ? and key = ?;
+
+-- name: RenameNetworkAttributeValues :exec
+update network_attributes
+set value = sqlc.arg(new_value)
+where key = sqlc.arg(key) and value = sqlc.arg(old_value);
@@ -0,0 +1,70 @@
+-- name: CreateNetworkScan :one
+insert into network_scans (
+    network_id, started_by
+) values (
+    ?, ?
+)
+returning id;
+
+-- name: GetNetworkScan :one
+select * from network_scans
+where id = ? and network_id = ? limit 1;
+
+-- name: ListNetworkScansByNetwork :many
+select * from network_scans
+where network_id = ?
+order by id desc;
+
+-- name: GetPreviousNetworkScan :one
+select * from network_scans
+where network_id = ?
+    and id < ?
+    and finished_at != -1
+order by id desc
+limit 1;
+
+-- name: FinishNetworkScan :one
+update network_scans
+set
+    finished_at = strftime('%s', 'now'),
+    hosts_found = ?,
+    ports_found = ?
+where id = ?
+returning *;
+
+-- name: FailNetworkScan :execrows
+update network_scans
+set
+    failed_at = ?,
+    failure = ?
+where id = ?
+    and finished_at = -1
+    and failed_at = -1;
+
+-- name: FailStaleNetworkScans :execrows
+update network_scans
+set
+    failed_at = sqlc.arg(failed_at),
+    failure = sqlc.arg(failure)
+where finished_at = -1
+    and failed_at = -1
+    and started_at < sqlc.arg(started_before)
+    and not exists (
+        select 1 from scan_leases
+        where scan_leases.scan_id = network_scans.id
+            and scan_leases.expires_at > sqlc.arg(failed_at)
+    );
+
+-- name: CreateHostScanSnapshot :exec
+insert into host_scan_snapshots (
+    scan_id, address, document
+) values (
+    ?, ?, ?
+)
+on conflict (scan_id, address) do update set
+    document = excluded.document;
+
+-- name: ListHostScanSnapshotsByScan :many
+select * from host_scan_snapshots
+where scan_id = ?
+order by address;
@@ -0,0 +1,54 @@
+-- name: ListClaimableNetworks :many
+select
+    sqlc.embed(networks),
+    cast(coalesce((
+        select max(network_scans.finished_at) from network_scans
+        where network_scans.network_id = networks.id
+    ), -1) as integer) as last_finished_at
+from networks
+left join scan_leases on scan_leases.network_id = networks.id
+where scan_leases.network_id is null or scan_leases.expires_at <= ?
+order by networks.id;
+
+-- name: ClaimScanLease :one
+insert into scan_leases (
+    network_id, worker, acquired_at, heartbeat_at, expires_at
+) values (
+    ?, ?, ?, ?, ?
+)
+on conflict (network_id) do update set
+    scan_id = 0,
+    worker = excluded.worker,
+    acquired_at = excluded.acquired_at,
+    heartbeat_at = excluded.heartbeat_at,
+    expires_at = excluded.expires_at
+where scan_leases.expires_at <= excluded.acquired_at
+returning *;
+
+-- name: SetScanLeaseScan :one
+update scan_leases
+set scan_id = ?
+where network_id = ?
+returning *;
+
+-- name: HeartbeatScanLease :one
+update scan_leases
+set
+    heartbeat_at = ?,
+    expires_at = ?
+where network_id = ?
+    and scan_id = ?
+    and worker = ?
+returning *;
+
+-- name: GetScanLease :one
+select * from scan_leases
+where network_id = ? limit 1;
+
+-- name: ListScanLeases :many
+select * from scan_leases
+order by network_id;
+
+-- name: DeleteScanLease :exec
+delete from scan_leases
+where network_id = ? and scan_id = ?;
@@ -0,0 +1,37 @@
+-- name: CreateScanProfile :one
+insert into scan_profiles (
+    name, scan_type, ports, top_ports, nmap_flags, nmap_scripts, snmp_enabled, rate, scan_interval
+) values (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?
+)
+returning *;
+
+-- name: GetScanProfile :one
+select * from scan_profiles
+where id = ? limit 1;
+
+-- name: GetScanProfileByName :one
+select * from scan_profiles
+where name = ? limit 1;
+
+-- name: ListScanProfiles :many
+select * from scan_profiles
+order by id;
+
+-- name: UpdateScanProfile :one
+update scan_profiles
+set
+    name = ?,
+    scan_type = ?,
+    ports = ?,
+    top_ports = ?,
+    nmap_flags = ?,
+    nmap_scripts = ?,
+    snmp_enabled = ?,
+    rate = ?,
+    scan_interval = ?
+where id = ?
+returning *;
+
+-- name: DeleteScanProfile :exec
+delete from scan_profiles where id = ?;
@@ -0,0 +1,43 @@
+-- name: CreateSnmpCredential :one
+insert into snmp_credentials (
+    name, network_id, address, priority, version, community, username,
+    auth_protocol, auth_passphrase, priv_protocol, priv_passphrase
+) values (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+)
+returning *;
+
+-- name: GetSnmpCredential :one
+select * from snmp_credentials
+where id = ? limit 1;
+
+-- name: ListSnmpCredentials :many
+select * from snmp_credentials
+order by priority, id;
+
+-- name: ListSnmpCredentialsForNetwork :many
+select * from snmp_credentials
+where (network_id = 0 and address = '')
+    or network_id = sqlc.arg(network_id)
+    or address in (select hosts.address from hosts where hosts.network_id = sqlc.arg(network_id))
+order by priority, id;
+
+-- name: UpdateSnmpCredential :one
+update snmp_credentials
+set
+    name = ?,
+    network_id = ?,
+    address = ?,
+    priority = ?,
+    version = ?,
+    community = ?,
+    username = ?,
+    auth_protocol = ?,
+    auth_passphrase = ?,
+    priv_protocol = ?,
+    priv_passphrase = ?
+where id = ?
+returning *;
+
+-- name: DeleteSnmpCredential :exec
+delete from snmp_credentials where id = ?;
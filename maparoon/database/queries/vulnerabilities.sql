@@ -0,0 +1,57 @@
+-- name: SetVulnerability :exec
+insert into vulnerabilities (
+    id, description, severity, score, published
+) values (
+    ?, ?, ?, ?, ?
+)
+on conflict (id) do update set
+    description = excluded.description,
+    severity = excluded.severity,
+    score = excluded.score,
+    published = excluded.published;
+
+-- name: DeleteVulnerabilityCpes :exec
+delete from vulnerability_cpes
+where vulnerability_id = ?;
+
+-- name: CreateVulnerabilityCpe :exec
+insert into vulnerability_cpes (
+    vulnerability_id, part, vendor, product, version, version_update,
+    version_start_including, version_start_excluding,
+    version_end_including, version_end_excluding, criteria
+) values (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+);
+
+-- name: ListVulnerabilityCpesByProduct :many
+select * from vulnerability_cpes
+where vendor = ? and product = ?;
+
+-- name: DeleteHostPortVulnerabilities :exec
+delete from host_port_vulnerabilities
+where address = ?;
+
+-- name: CreateHostPortVulnerability :exec
+insert into host_port_vulnerabilities (
+    address, port, protocol, vulnerability_id, cpe
+) values (
+    ?, ?, ?, ?, ?
+)
+on conflict (address, port, protocol, vulnerability_id) do update set
+    cpe = excluded.cpe;
+
+-- name: ListNetworkVulnerabilities :many
+select
+    host_port_vulnerabilities.address,
+    host_port_vulnerabilities.port,
+    host_port_vulnerabilities.protocol,
+    host_port_vulnerabilities.cpe,
+    vulnerabilities.id,
+    vulnerabilities.severity,
+    vulnerabilities.score,
+    vulnerabilities.description
+from host_port_vulnerabilities
+join hosts on hosts.address = host_port_vulnerabilities.address
+join vulnerabilities on vulnerabilities.id = host_port_vulnerabilities.vulnerability_id
+where hosts.network_id = ? and vulnerabilities.score >= ?
+order by vulnerabilities.score desc, host_port_vulnerabilities.address, host_port_vulnerabilities.port, vulnerabilities.id;
@@ -25,10 +25,23 @@ create table if not exists hosts (
     address text primary key,
     network_id integer not null,
     comments text not null,
+    vendor text not null default '',
+    device_class text not null default '',
+    -- Unix times, 0 if the host was never seen by a scan or import
+    first_seen integer not null default 0,
+    last_seen integer not null default 0,
+    -- missed_scans counts the network scans in a row that didn't find the
+    -- host, gone_at is set when it expires
+    missed_scans integer not null default 0,
+    gone_at integer not null default 0,
+    -- device_class_score is the classifier's score for device_class, a
+    -- class is only replaced by one that scores higher
+    device_class_score integer not null default 0,
 
     foreign key (network_id) references networks(id) on delete cascade on update cascade
 );
 create index if not exists idx_hosts_address on hosts(address);
+create index if not exists idx_hosts_device_class on hosts(device_class);
 
 create table if not exists host_attributes (
     address text not null,
@@ -44,6 +57,8 @@ create table if not exists host_ports (
     port integer not null,
     protocol text not null,
     comments text not null,
+    first_seen integer not null default 0,
+    last_seen integer not null default 0,
 
     primary key (address, port, protocol),
     foreign key (address) references hosts(address) on delete cascade on update cascade
@@ -70,6 +85,12 @@ create table if not exists network_scans (
     finished_at integer not null default -1,
     hosts_found integer not null default 0,
     ports_found integer not null default 0,
+    -- failed_at is set instead of finished_at when the scan's results
+    -- couldn't be stored or it was abandoned, failure says why
+    failed_at integer not null default -1,
+    failure text not null default '',
+    -- started_by is the principal that started the scan
+    started_by text not null default '',
 
     foreign key (network_id) references networks(id) on delete cascade on update cascade
 );
@@ -83,3 +104,227 @@ begin
     set started_at = strftime('%s', 'now')
     where id = new.id;
 end;
+
+create table if not exists host_scan_snapshots (
+    scan_id integer not null,
+    address text not null,
+    document text not null,
+
+    primary key (scan_id, address),
+    foreign key (scan_id) references network_scans(id) on delete cascade on update cascade
+);
+create index if not exists idx_host_scan_snapshots_address on host_scan_snapshots(address);
+
+-- host_scans keeps the latest host scan document of each host, as indexed
+create table if not exists host_scans (
+    address text primary key,
+    scan_id integer not null default 0,
+    document text not null,
+    indexed_at integer not null default (strftime('%s', 'now')),
+    -- submitted_by is the principal that submitted the host scan
+    submitted_by text not null default '',
+
+    foreign key (address) references hosts(address) on delete cascade on update cascade
+);
+
+create table if not exists alert_rules (
+    id integer primary key,
+    name text not null,
+    kind text not null,
+    -- network_id of 0 matches every network
+    network_id integer not null default 0,
+    port integer not null default 0,
+    protocol text not null default '',
+    measurement text not null default '',
+    webhook_url text not null default '',
+    email_to text not null default '',
+    enabled boolean not null default true,
+    created_at integer not null default (strftime('%s', 'now'))
+);
+
+create table if not exists alerts (
+    id integer primary key,
+    rule_id integer not null,
+    network_id integer not null,
+    scan_id integer not null,
+    fingerprint text not null,
+    summary text not null,
+    count integer not null default 1,
+    first_seen_at integer not null default (strftime('%s', 'now')),
+    last_seen_at integer not null default (strftime('%s', 'now')),
+    acknowledged_at integer not null default -1,
+
+    foreign key (rule_id) references alert_rules(id) on delete cascade on update cascade,
+    foreign key (network_id) references networks(id) on delete cascade on update cascade,
+    foreign key (scan_id) references network_scans(id) on delete cascade on update cascade
+);
+create index if not exists idx_alerts_rule_id_fingerprint on alerts(rule_id, fingerprint);
+
+create table if not exists api_tokens (
+    id integer primary key,
+    name text not null unique,
+    role text not null,
+    token_hash text not null unique,
+    created_at integer not null default (strftime('%s', 'now')),
+    last_used_at integer not null default -1
+);
+
+create table if not exists audit_log (
+    id integer primary key,
+    token_name text not null,
+    role text not null,
+    method text not null,
+    path text not null,
+    status integer not null,
+    created_at integer not null default (strftime('%s', 'now'))
+);
+create index if not exists idx_audit_log_token_name on audit_log(token_name);
+
+create table if not exists scan_leases (
+    network_id integer primary key,
+    scan_id integer not null default 0,
+    worker text not null,
+    acquired_at integer not null,
+    heartbeat_at integer not null,
+    expires_at integer not null,
+
+    foreign key (network_id) references networks(id) on delete cascade on update cascade
+);
+
+create table if not exists scan_profiles (
+    id integer primary key,
+    name text not null unique,
+    -- scan_type is one of connect, syn or ping, ping only discovers hosts
+    scan_type text not null default 'connect',
+    -- ports is a naabu port list, e.g. `22,80,8000-8100`, top_ports is used if empty
+    ports text not null default '',
+    top_ports text not null default '100',
+    -- an empty nmap_flags skips the nmap scan of discovered ports
+    nmap_flags text not null default '-A -O -sV',
+    nmap_scripts text not null default '',
+    snmp_enabled boolean not null default true,
+    -- rate is the number of packets per second, 0 uses naabu's default
+    rate integer not null default 0,
+    -- scan_interval is the seconds between scans, 0 uses the worker's interval
+    scan_interval integer not null default 0,
+    created_at integer not null default (strftime('%s', 'now'))
+);
+
+create table if not exists snmp_credentials (
+    id integer primary key,
+    name text not null unique,
+    -- a credential with neither network_id nor address is tried on every host
+    network_id integer not null default 0,
+    address text not null default '',
+    -- credentials are tried in ascending priority
+    priority integer not null default 0,
+    version text not null,
+    -- community, auth_passphrase and priv_passphrase are encrypted
+    community text not null default '',
+    username text not null default '',
+    auth_protocol text not null default '',
+    auth_passphrase text not null default '',
+    priv_protocol text not null default '',
+    priv_passphrase text not null default '',
+    created_at integer not null default (strftime('%s', 'now'))
+);
+
+create table if not exists host_interfaces (
+    address text not null,
+    if_index integer not null,
+    name text not null,
+    descr text not null,
+    alias text not null,
+    type integer not null,
+    mtu integer not null,
+    -- speed is in Mbit/s
+    speed integer not null,
+    mac_address text not null,
+    admin_status text not null,
+    oper_status text not null,
+
+    primary key (address, if_index),
+    foreign key (address) references hosts(address) on delete cascade on update cascade
+);
+
+create table if not exists host_arp_entries (
+    address text not null,
+    if_index integer not null,
+    ip_address text not null,
+    mac_address text not null,
+
+    primary key (address, if_index, ip_address),
+    foreign key (address) references hosts(address) on delete cascade on update cascade
+);
+create index if not exists idx_host_arp_entries_mac_address on host_arp_entries(mac_address);
+
+create table if not exists host_fdb_entries (
+    address text not null,
+    mac_address text not null,
+    bridge_port integer not null,
+    if_index integer not null,
+
+    primary key (address, mac_address),
+    foreign key (address) references hosts(address) on delete cascade on update cascade
+);
+create index if not exists idx_host_fdb_entries_mac_address on host_fdb_entries(mac_address);
+
+create table if not exists host_neighbors (
+    address text not null,
+    local_port integer not null,
+    local_port_descr text not null,
+    chassis_id text not null,
+    port_id text not null,
+    port_descr text not null,
+    sys_name text not null,
+    sys_descr text not null,
+    management_address text not null,
+    -- local_port is the lldpLocPortNum, local_port_id usually the ifName
+    local_port_id text not null default '',
+
+    primary key (address, local_port, chassis_id, port_id),
+    foreign key (address) references hosts(address) on delete cascade on update cascade
+);
+
+-- vulnerabilities are imported from NVD CVE feeds, score is the CVSS base score
+create table if not exists vulnerabilities (
+    id text primary key,
+    description text not null default '',
+    severity text not null default '',
+    score real not null default 0,
+    published text not null default ''
+);
+
+-- vulnerability_cpes are the vulnerable CPEs of each vulnerability. A version
+-- of '*' matches every version within the version_* bounds that are set.
+create table if not exists vulnerability_cpes (
+    vulnerability_id text not null,
+    part text not null,
+    vendor text not null,
+    product text not null,
+    version text not null,
+    version_update text not null,
+    version_start_including text not null default '',
+    version_start_excluding text not null default '',
+    version_end_including text not null default '',
+    version_end_excluding text not null default '',
+    criteria text not null,
+
+    foreign key (vulnerability_id) references vulnerabilities(id) on delete cascade on update cascade
+);
+create index if not exists idx_vulnerability_cpes_product on vulnerability_cpes(vendor, product);
+create index if not exists idx_vulnerability_cpes_vulnerability_id on vulnerability_cpes(vulnerability_id);
+
+-- host_port_vulnerabilities are the vulnerabilities matched to the CPEs nmap
+-- reported for a host's port
+create table if not exists host_port_vulnerabilities (
+    address text not null,
+    port integer not null,
+    protocol text not null,
+    vulnerability_id text not null,
+    cpe text not null,
+
+    primary key (address, port, protocol, vulnerability_id),
+    foreign key (address) references hosts(address) on delete cascade on update cascade
+);
+create index if not exists idx_host_port_vulnerabilities_vulnerability_id on host_port_vulnerabilities(vulnerability_id);
@@ -48,6 +48,10 @@ func Open(ctx context.Context, path string) (*DbWrapper, error) {
 		return nil, fmt.Errorf("could not open database: %w", err)
 	}
 
+	if err := addMissingColumns(ctx, db); err != nil {
+		return nil, fmt.Errorf("could not update database schema: %w", err)
+	}
+
 	logrus.Infof("Applying database schema")
 	logrus.Debugf("Database schema being applied: %s", dbSchema)
 	if _, err := db.ExecContext(ctx, dbSchema); err != nil {
@@ -56,3 +60,19 @@ func Open(ctx context.Context, path string) (*DbWrapper, error) {
 
 	return Wrap(db), nil
 }
+
+// InTx runs fn with queries in a transaction, which is committed if fn
+// succeeds and rolled back otherwise
+func (w *DbWrapper) InTx(ctx context.Context, fn func(*Queries) error) error {
+	tx, err := w.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("could not begin transaction: %w", err)
+	}
+
+	if err := fn(w.Queries.WithTx(tx)); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,136 @@
+// Package discovery parses the lease files and neighbor tables that hosts are
+// passively discovered from
+package discovery
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pirogoeth/apps/maparoon/database"
+)
+
+const (
+	FormatIscDhcp = "isc-dhcp"
+	FormatKea     = "kea"
+	FormatDnsmasq = "dnsmasq"
+	FormatIpNeigh = "ip-neigh"
+	FormatArp     = "arp"
+)
+
+var Formats = []string{FormatIscDhcp, FormatKea, FormatDnsmasq, FormatIpNeigh, FormatArp}
+
+// Observation is a host seen in a lease file or neighbor table
+type Observation struct {
+	Address    string    `json:"address"`
+	MacAddress string    `json:"mac_address,omitempty"`
+	Hostname   string    `json:"hostname,omitempty"`
+	LastSeen   time.Time `json:"last_seen"`
+}
+
+type parser func(r io.Reader, now time.Time) ([]Observation, error)
+
+var parsers = map[string]parser{
+	FormatIscDhcp: parseIscLeases,
+	FormatKea:     parseKeaLeases,
+	FormatDnsmasq: parseDnsmasqLeases,
+	FormatIpNeigh: parseIpNeigh,
+	FormatArp:     parseArp,
+}
+
+// Parse reads the observations in r, one per address. Sources that don't
+// record when a host was seen, like neighbor tables, count it as seen at now.
+func Parse(format string, r io.Reader, now time.Time) ([]Observation, error) {
+	parse, ok := parsers[format]
+	if !ok {
+		return nil, fmt.Errorf("unknown discovery format %q, expected one of %s", format, strings.Join(Formats, ", "))
+	}
+
+	observations, err := parse(r, now)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse %s: %w", format, err)
+	}
+
+	for i := range observations {
+		if observations[i].LastSeen.IsZero() {
+			observations[i].LastSeen = now
+		}
+	}
+
+	return merge(observations), nil
+}
+
+// merge keeps the latest observation of each address, with its addresses in
+// canonical form
+func merge(observations []Observation) []Observation {
+	latest := make(map[string]Observation)
+	for _, observation := range observations {
+		address, err := database.CanonicalAddress(observation.Address)
+		if err != nil {
+			continue
+		}
+		observation.Address = address
+
+		if previous, ok := latest[address]; !ok || !observation.LastSeen.Before(previous.LastSeen) {
+			latest[address] = observation
+		}
+	}
+
+	merged := make([]Observation, 0, len(latest))
+	for _, observation := range latest {
+		merged = append(merged, observation)
+	}
+	sort.Slice(merged, func(i, j int) bool {
+		return merged[i].Address < merged[j].Address
+	})
+
+	return merged
+}
+
+func scanLines(r io.Reader, fn func(line string) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if err := fn(line); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
+
+// normalizeMac parses a MAC address in any of the usual notations, including
+// BSD's unpadded octets, into lowercase colon notation. It returns "" if the
+// value isn't an ethernet address or is the zero or broadcast address.
+func normalizeMac(value string) string {
+	value = strings.ReplaceAll(value, "-", ":")
+	if parts := strings.Split(value, ":"); len(parts) == 6 {
+		for i, part := range parts {
+			if len(part) == 1 {
+				parts[i] = "0" + part
+			}
+		}
+		value = strings.Join(parts, ":")
+	}
+
+	mac, err := net.ParseMAC(value)
+	if err != nil || len(mac) != 6 {
+		return ""
+	}
+
+	switch mac.String() {
+	case "00:00:00:00:00:00", "ff:ff:ff:ff:ff:ff":
+		return ""
+	}
+
+	return mac.String()
+}
@@ -0,0 +1,131 @@
+package discovery
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var now = time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		name     string
+		format   string
+		input    string
+		expected []Observation
+	}{
+		{
+			name:   "isc dhcp",
+			format: FormatIscDhcp,
+			input: `# The format of this file is documented in the dhcpd.leases(5) manual page.
+lease 10.0.0.5 {
+  starts 3 2024/01/10 08:00:00;
+  ends 3 2024/01/10 20:00:00;
+  cltt 3 2024/01/10 08:00:00;
+  binding state active;
+  next binding state free;
+  hardware ethernet 00:1B:21:00:00:05;
+  uid "\001\000\033!\000\000\005";
+  client-hostname "laptop";
+}
+lease 10.0.0.5 {
+  starts 3 2024/01/10 10:00:00;
+  cltt 3 2024/01/10 10:00:00;
+  binding state active;
+  hardware ethernet 00:1b:21:00:00:05;
+  client-hostname "laptop";
+}
+lease 10.0.0.6 {
+  starts epoch 1704880800; # Wed Jan 10 10:00:00 2024
+  binding state abandoned;
+}
+ia-na "\001\000\000\000\000\003" {
+  cltt 3 2024/01/10 09:00:00;
+  iaaddr 2001:db8::10 {
+    binding state active;
+    preferred-life 375;
+  }
+}`,
+			expected: []Observation{
+				{Address: "10.0.0.5", MacAddress: "00:1b:21:00:00:05", Hostname: "laptop", LastSeen: now.Add(-2 * time.Hour)},
+				{Address: "2001:db8::10", LastSeen: now.Add(-3 * time.Hour)},
+			},
+		},
+		{
+			name:   "kea",
+			format: FormatKea,
+			input: `address,hwaddr,client_id,valid_lifetime,expire,subnet_id,fqdn_fwd,fqdn_rev,hostname,state,user_context
+10.0.0.7,00:1b:21:00:00:07,,3600,1704888000,1,0,0,printer.lan.,0,
+10.0.0.8,00:1b:21:00:00:08,,3600,1704888000,1,0,0,,1,`,
+			expected: []Observation{
+				{Address: "10.0.0.7", MacAddress: "00:1b:21:00:00:07", Hostname: "printer.lan", LastSeen: now.Add(-time.Hour)},
+			},
+		},
+		{
+			name:   "dnsmasq",
+			format: FormatDnsmasq,
+			input: `1704891600 00:1b:21:00:00:09 10.0.0.9 phone 01:00:1b:21:00:00:09
+1704870000 00:1b:21:00:00:0a 10.0.0.10 * *
+duid 00:01:00:01:2c:3b:4a:5d:00:1b:21:00:00:01
+1704891600 1234567 2001:db8::20 tv 00:01:00:01:aa:bb`,
+			expected: []Observation{
+				{Address: "10.0.0.10", MacAddress: "00:1b:21:00:00:0a", LastSeen: now.Add(-5 * time.Hour)},
+				{Address: "10.0.0.9", MacAddress: "00:1b:21:00:00:09", Hostname: "phone", LastSeen: now},
+				{Address: "2001:db8::20", Hostname: "tv", LastSeen: now},
+			},
+		},
+		{
+			name:   "ip neigh",
+			format: FormatIpNeigh,
+			input: `10.0.0.1 dev eth0 lladdr 00:1b:21:00:00:01 REACHABLE
+10.0.0.2 dev eth0  FAILED
+2001:db8::1 dev eth0 lladdr 00:1b:21:00:00:01 router STALE
+fe80::1 dev eth0 lladdr 00:1b:21:00:00:01 router DELAY`,
+			expected: []Observation{
+				{Address: "10.0.0.1", MacAddress: "00:1b:21:00:00:01", LastSeen: now},
+				{Address: "2001:db8::1", MacAddress: "00:1b:21:00:00:01", LastSeen: now},
+				{Address: "fe80::1", MacAddress: "00:1b:21:00:00:01", LastSeen: now},
+			},
+		},
+		{
+			name:   "arp",
+			format: FormatArp,
+			input: `? (10.0.0.1) at 00:1b:21:00:00:01 [ether] on eth0
+? (10.0.0.2) at <incomplete> on eth0
+? (10.0.0.3) at 0:1b:21:0:0:3 on en0 ifscope [ethernet]
+Interface: 10.0.0.50 --- 0xb
+  10.0.0.4              00-1b-21-00-00-04     dynamic
+  10.0.0.255            ff-ff-ff-ff-ff-ff     static
+IP address       HW type     Flags       HW address            Mask     Device
+10.0.0.5         0x1         0x2         00:1b:21:00:00:05     *        eth0
+10.0.0.6         0x1         0x0         00:00:00:00:00:00     *        eth0`,
+			expected: []Observation{
+				{Address: "10.0.0.1", MacAddress: "00:1b:21:00:00:01", LastSeen: now},
+				{Address: "10.0.0.3", MacAddress: "00:1b:21:00:00:03", LastSeen: now},
+				{Address: "10.0.0.4", MacAddress: "00:1b:21:00:00:04", LastSeen: now},
+				{Address: "10.0.0.5", MacAddress: "00:1b:21:00:00:05", LastSeen: now},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			observations, err := Parse(c.format, strings.NewReader(c.input), now)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !reflect.DeepEqual(observations, c.expected) {
+				t.Errorf("expected %+v, got %+v", c.expected, observations)
+			}
+		})
+	}
+}
+
+func TestParseUnknownFormat(t *testing.T) {
+	if _, err := Parse("nmap", strings.NewReader(""), now); err == nil {
+		t.Errorf("expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,268 @@
+package discovery
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseIscLeases parses an ISC dhcpd.leases (or dhcpd6.leases) file. Leases
+// are appended as they change, so an address may appear more than once.
+func parseIscLeases(r io.Reader, now time.Time) ([]Observation, error) {
+	observations := make([]Observation, 0)
+
+	var (
+		current   *Observation
+		state     string
+		depth     int
+		leaseAt   int
+		blockCltt time.Time
+	)
+	err := scanLines(r, func(line string) error {
+		line = strings.TrimSuffix(line, ";")
+		fields := strings.Fields(line)
+
+		switch {
+		case strings.HasSuffix(line, "{"):
+			depth++
+			if len(fields) == 3 && (fields[0] == "lease" || fields[0] == "iaaddr") {
+				current = &Observation{Address: fields[1], LastSeen: blockCltt}
+				state = ""
+				leaseAt = depth
+			}
+			return nil
+		case line == "}":
+			if current != nil && depth == leaseAt {
+				if state != "abandoned" && state != "backup" {
+					observations = append(observations, *current)
+				}
+				current = nil
+			}
+			depth--
+			if depth == 0 {
+				blockCltt = time.Time{}
+			}
+			return nil
+		case len(fields) == 0:
+			return nil
+		}
+
+		if current == nil {
+			// dhcpd6.leases keep cltt on the ia-na block around the iaaddr
+			if fields[0] == "cltt" {
+				blockCltt = parseIscTime(fields[1:])
+			}
+			return nil
+		}
+
+		switch {
+		case fields[0] == "cltt":
+			current.LastSeen = parseIscTime(fields[1:])
+		case fields[0] == "starts" && current.LastSeen.IsZero():
+			current.LastSeen = parseIscTime(fields[1:])
+		case fields[0] == "binding" && len(fields) == 3:
+			state = fields[2]
+		case fields[0] == "hardware" && len(fields) == 3:
+			current.MacAddress = normalizeMac(fields[2])
+		case fields[0] == "client-hostname" && len(fields) == 2:
+			current.Hostname = strings.Trim(fields[1], `"`)
+		}
+
+		return nil
+	})
+
+	return observations, err
+}
+
+// parseIscTime parses dhcpd's `<weekday> yyyy/mm/dd hh:mm:ss` UTC times as well
+// as `epoch <seconds>`
+func parseIscTime(fields []string) time.Time {
+	if len(fields) >= 2 && fields[0] == "epoch" {
+		seconds, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return time.Time{}
+		}
+		return time.Unix(seconds, 0).UTC()
+	}
+
+	if len(fields) < 3 {
+		return time.Time{}
+	}
+
+	parsed, err := time.Parse("2006/01/02 15:04:05", fields[1]+" "+fields[2])
+	if err != nil {
+		return time.Time{}
+	}
+
+	return parsed
+}
+
+// parseKeaLeases parses Kea's memfile lease CSV, for either DHCPv4 or DHCPv6.
+// Columns are found by the header, which differs between the two.
+func parseKeaLeases(r io.Reader, now time.Time) ([]Observation, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.Comment = '#'
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+	if _, ok := columns["address"]; !ok {
+		return nil, errors.New("no address column in lease file header")
+	}
+
+	column := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	observations := make([]Observation, 0)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		// State 1 is declined, the address is in use by something that isn't
+		// the lease holder
+		if column(record, "state") == "1" {
+			continue
+		}
+
+		observation := Observation{
+			Address:    column(record, "address"),
+			MacAddress: normalizeMac(column(record, "hwaddr")),
+			Hostname:   strings.TrimSuffix(column(record, "hostname"), "."),
+		}
+
+		// The lease was last renewed a lifetime before it expires
+		expire, _ := strconv.ParseInt(column(record, "expire"), 10, 64)
+		lifetime, _ := strconv.ParseInt(column(record, "valid_lifetime"), 10, 64)
+		if expire > 0 {
+			observation.LastSeen = time.Unix(expire-lifetime, 0).UTC()
+		}
+
+		observations = append(observations, observation)
+	}
+
+	return observations, nil
+}
+
+// parseDnsmasqLeases parses a dnsmasq.leases file. dnsmasq only records when
+// leases expire, so hosts with active leases count as seen now and the others
+// as seen when their leases expired.
+func parseDnsmasqLeases(r io.Reader, now time.Time) ([]Observation, error) {
+	observations := make([]Observation, 0)
+	err := scanLines(r, func(line string) error {
+		// <expiry> <mac or iaid> <address> <hostname> <client id>
+		fields := strings.Fields(line)
+		if len(fields) < 4 || fields[0] == "duid" {
+			return nil
+		}
+
+		expiry, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			return nil
+		}
+
+		lastSeen := now
+		if expiry != 0 && time.Unix(expiry, 0).Before(now) {
+			lastSeen = time.Unix(expiry, 0).UTC()
+		}
+
+		observation := Observation{
+			Address: fields[2],
+			// DHCPv6 leases have the IAID here instead
+			MacAddress: normalizeMac(fields[1]),
+			LastSeen:   lastSeen,
+		}
+		if fields[3] != "*" {
+			observation.Hostname = fields[3]
+		}
+
+		observations = append(observations, observation)
+		return nil
+	})
+
+	return observations, err
+}
+
+// parseIpNeigh parses the output of `ip neigh show`, for both ARP and NDP
+// entries. Entries that never resolved are skipped.
+func parseIpNeigh(r io.Reader, now time.Time) ([]Observation, error) {
+	observations := make([]Observation, 0)
+	err := scanLines(r, func(line string) error {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || net.ParseIP(fields[0]) == nil {
+			return nil
+		}
+
+		macAddress := ""
+		for i := 1; i < len(fields); i++ {
+			switch fields[i] {
+			case "lladdr":
+				if i+1 < len(fields) {
+					macAddress = normalizeMac(fields[i+1])
+				}
+			case "FAILED", "INCOMPLETE":
+				return nil
+			}
+		}
+		if macAddress == "" {
+			return nil
+		}
+
+		observations = append(observations, Observation{
+			Address:    fields[0],
+			MacAddress: macAddress,
+			LastSeen:   now,
+		})
+		return nil
+	})
+
+	return observations, err
+}
+
+// parseArp parses ARP table dumps: `arp -an` on Linux and BSD, Windows'
+// `arp -a` and /proc/net/arp. Each line has an address and a MAC address
+// somewhere in it, incomplete entries have no MAC address.
+func parseArp(r io.Reader, now time.Time) ([]Observation, error) {
+	observations := make([]Observation, 0)
+	err := scanLines(r, func(line string) error {
+		address, macAddress := "", ""
+		for _, field := range strings.Fields(line) {
+			field = strings.Trim(field, "()[]")
+			if address == "" && net.ParseIP(field) != nil {
+				address = field
+			} else if macAddress == "" {
+				macAddress = normalizeMac(field)
+			}
+		}
+		if address == "" || macAddress == "" {
+			return nil
+		}
+
+		observations = append(observations, Observation{
+			Address:    address,
+			MacAddress: macAddress,
+			LastSeen:   now,
+		})
+		return nil
+	})
+
+	return observations, err
+}
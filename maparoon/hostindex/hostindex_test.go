@@ -0,0 +1,131 @@
+package hostindex
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/adifire/go-nmap"
+	"github.com/blevesearch/bleve"
+
+	"github.com/pirogoeth/apps/maparoon/database"
+	"github.com/pirogoeth/apps/maparoon/types"
+)
+
+func scanDocument(address, os string, ports ...nmap.Port) *types.HostScanDocument {
+	return &types.HostScanDocument{
+		Address: address,
+		Network: database.Network{ID: 1, Name: "lan", Address: "10.0.0.0", Cidr: 24},
+		Nmap: &types.NmapHostScanDocument{
+			HostDetails: nmap.Host{
+				Ports: ports,
+				Os: nmap.Os{
+					OsMatches: []nmap.OsMatch{
+						{Name: "Some Other OS", Accuracy: "80"},
+						{Name: os, Accuracy: "96"},
+					},
+				},
+			},
+		},
+	}
+}
+
+func openPort(id int, name, product string) nmap.Port {
+	return nmap.Port{
+		Protocol: "tcp",
+		PortId:   id,
+		State:    nmap.State{State: "open"},
+		Service:  nmap.Service{Name: name, Product: product},
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	doc := scanDocument("10.0.0.5", "Linux 5.X",
+		openPort(22, "ssh", "OpenSSH"),
+		openPort(80, "http", "nginx"),
+		nmap.Port{Protocol: "tcp", PortId: 443, State: nmap.State{State: "closed"}, Service: nmap.Service{Name: "https"}},
+	)
+	doc.OpenPorts = []types.DiscoveredPort{{Port: 22, Protocol: "tcp"}, {Port: 8080, Protocol: "tcp"}}
+
+	summary := Summarize(doc)
+	if expected := []string{"22/tcp", "80/tcp", "8080/tcp"}; !slices.Equal(summary.OpenPorts, expected) {
+		t.Errorf("expected open ports %v, got %v", expected, summary.OpenPorts)
+	}
+	if expected := []string{"http", "ssh"}; !slices.Equal(summary.Services, expected) {
+		t.Errorf("expected services %v, got %v", expected, summary.Services)
+	}
+	if expected := []string{"OpenSSH", "nginx"}; !slices.Equal(summary.Products, expected) {
+		t.Errorf("expected products %v, got %v", expected, summary.Products)
+	}
+	if summary.Os != "Linux 5.X" {
+		t.Errorf("expected os Linux 5.X, got %s", summary.Os)
+	}
+}
+
+func TestMappingFacetsAndNumericPorts(t *testing.T) {
+	index, err := bleve.NewMemOnly(NewMapping())
+	if err != nil {
+		t.Fatalf("could not create index: %s", err)
+	}
+	defer index.Close()
+
+	docs := []*types.HostScanDocument{
+		scanDocument("10.0.0.5", "Linux 5.X", openPort(22, "ssh", "OpenSSH"), openPort(80, "http", "nginx")),
+		scanDocument("10.0.0.6", "Linux 5.X", openPort(22, "ssh", "OpenSSH")),
+		scanDocument("10.0.0.7", "Microsoft Windows 10", openPort(3389, "ms-wbt-server", "")),
+	}
+	if err := Fill(index, docs); err != nil {
+		t.Fatalf("could not fill index: %s", err)
+	}
+
+	current, err := IsCurrent(index)
+	if err != nil || !current {
+		t.Errorf("expected index to be current, got %t (%v)", current, err)
+	}
+
+	req := bleve.NewSearchRequest(bleve.NewMatchAllQuery())
+	req.Facets = bleve.FacetsRequest{
+		"os":   bleve.NewFacetRequest(Facets["os"], 10),
+		"port": bleve.NewFacetRequest(Facets["port"], 10),
+	}
+	results, err := index.Search(req)
+	if err != nil {
+		t.Fatalf("could not search: %s", err)
+	}
+
+	osCounts := make(map[string]int)
+	for _, term := range results.Facets["os"].Terms {
+		osCounts[term.Term] = term.Count
+	}
+	if osCounts["Linux 5.X"] != 2 || osCounts["Microsoft Windows 10"] != 1 {
+		t.Errorf("unexpected os facet: %v", osCounts)
+	}
+
+	portCounts := make(map[string]int)
+	for _, term := range results.Facets["port"].Terms {
+		portCounts[term.Term] = term.Count
+	}
+	if portCounts["22/tcp"] != 2 || portCounts["80/tcp"] != 1 {
+		t.Errorf("unexpected port facet: %v", portCounts)
+	}
+
+	// Ports are numeric, so they can be searched by range
+	low, high := 1000.0, 65535.0
+	rangeQuery := bleve.NewNumericRangeQuery(&low, &high)
+	rangeQuery.SetField("nmap.host.ports.id")
+	results, err = index.Search(bleve.NewSearchRequest(rangeQuery))
+	if err != nil {
+		t.Fatalf("could not search: %s", err)
+	}
+	if results.Total != 1 || results.Hits[0].ID != "10.0.0.7" {
+		t.Errorf("expected only 10.0.0.7 to have a port above 1000, got %d hits", results.Total)
+	}
+
+	// Products are keywords, matched as a whole and case sensitively
+	results, err = index.Search(bleve.NewSearchRequest(bleve.NewQueryStringQuery(`nmap.host.ports.service.product:OpenSSH`)))
+	if err != nil {
+		t.Fatalf("could not search: %s", err)
+	}
+	if results.Total != 2 {
+		t.Errorf("expected 2 hosts running OpenSSH, got %d", results.Total)
+	}
+}
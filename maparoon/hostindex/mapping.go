@@ -0,0 +1,144 @@
+// Package hostindex defines how host scan documents are indexed for search
+package hostindex
+
+import (
+	"github.com/blevesearch/bleve"
+	"github.com/blevesearch/bleve/analysis/analyzer/keyword"
+	bleveMapping "github.com/blevesearch/bleve/mapping"
+)
+
+// MappingVersion is stored in the index when it's created. An index created
+// with another version of the mapping is rebuilt, since bleve can't change the
+// mapping of an existing index.
+const MappingVersion = "2"
+
+// MappingVersionKey is the internal index key MappingVersion is stored under
+var MappingVersionKey = []byte("maparoon:mapping_version")
+
+// NewMapping is the index mapping of types.HostScanDocument. Names, products
+// and addresses are keywords, so they match, sort and facet as whole values;
+// bleve has no IP field type, so addresses match exactly. Ports and ids are
+// numeric.
+func NewMapping() bleveMapping.IndexMapping {
+	networkMapping := bleve.NewDocumentMapping()
+	networkMapping.AddFieldMappingsAt("id", numericField())
+	networkMapping.AddFieldMappingsAt("name", keywordField())
+	networkMapping.AddFieldMappingsAt("address", keywordField())
+	networkMapping.AddFieldMappingsAt("cidr", numericField())
+	networkMapping.AddFieldMappingsAt("comments", bleve.NewTextFieldMapping())
+
+	serviceMapping := bleve.NewDocumentStaticMapping()
+	serviceMapping.AddFieldMappingsAt("name", keywordField())
+	serviceMapping.AddFieldMappingsAt("product", keywordField())
+	serviceMapping.AddFieldMappingsAt("version", keywordField())
+	serviceMapping.AddFieldMappingsAt("extrainfo", bleve.NewTextFieldMapping())
+	serviceMapping.AddFieldMappingsAt("ostype", keywordField())
+	serviceMapping.AddFieldMappingsAt("devicetype", keywordField())
+
+	portStateMapping := bleve.NewDocumentStaticMapping()
+	portStateMapping.AddFieldMappingsAt("state", keywordField())
+
+	portMapping := bleve.NewDocumentStaticMapping()
+	portMapping.AddFieldMappingsAt("id", numericField())
+	portMapping.AddFieldMappingsAt("protocol", keywordField())
+	portMapping.AddSubDocumentMapping("state", portStateMapping)
+	portMapping.AddSubDocumentMapping("service", serviceMapping)
+
+	osClassMapping := bleve.NewDocumentStaticMapping()
+	osClassMapping.AddFieldMappingsAt("vendor", keywordField())
+	osClassMapping.AddFieldMappingsAt("type", keywordField())
+	osClassMapping.AddFieldMappingsAt("osfamily", keywordField())
+
+	osMatchMapping := bleve.NewDocumentStaticMapping()
+	osMatchMapping.AddFieldMappingsAt("name", keywordField())
+	osMatchMapping.AddSubDocumentMapping("osclasses", osClassMapping)
+
+	osMapping := bleve.NewDocumentStaticMapping()
+	osMapping.AddSubDocumentMapping("osmatches", osMatchMapping)
+
+	addressMapping := bleve.NewDocumentStaticMapping()
+	addressMapping.AddFieldMappingsAt("addr", keywordField())
+	addressMapping.AddFieldMappingsAt("addrtype", keywordField())
+	addressMapping.AddFieldMappingsAt("vendor", keywordField())
+
+	hostnameMapping := bleve.NewDocumentStaticMapping()
+	hostnameMapping.AddFieldMappingsAt("name", keywordField())
+
+	hostMapping := bleve.NewDocumentStaticMapping()
+	hostMapping.AddSubDocumentMapping("addresses", addressMapping)
+	hostMapping.AddSubDocumentMapping("hostnames", hostnameMapping)
+	hostMapping.AddSubDocumentMapping("ports", portMapping)
+	hostMapping.AddSubDocumentMapping("os", osMapping)
+
+	nmapMapping := bleve.NewDocumentMapping()
+	nmapMapping.AddSubDocumentMapping("host", hostMapping)
+	nmapMapping.AddSubDocumentMapping("fingerprint_ports", bleve.NewDocumentDisabledMapping())
+
+	measurementMapping := bleve.NewDocumentMapping()
+	measurementMapping.AddFieldMappingsAt("oid", keywordField())
+	measurementMapping.AddFieldMappingsAt("type", keywordField())
+
+	interfaceMapping := bleve.NewDocumentStaticMapping()
+	interfaceMapping.AddFieldMappingsAt("index", numericField())
+	interfaceMapping.AddFieldMappingsAt("name", keywordField())
+	interfaceMapping.AddFieldMappingsAt("descr", bleve.NewTextFieldMapping())
+	interfaceMapping.AddFieldMappingsAt("alias", bleve.NewTextFieldMapping())
+	interfaceMapping.AddFieldMappingsAt("speed", numericField())
+	interfaceMapping.AddFieldMappingsAt("mac_address", keywordField())
+	interfaceMapping.AddFieldMappingsAt("oper_status", keywordField())
+
+	arpEntryMapping := bleve.NewDocumentStaticMapping()
+	arpEntryMapping.AddFieldMappingsAt("ip_address", keywordField())
+	arpEntryMapping.AddFieldMappingsAt("mac_address", keywordField())
+
+	neighborMapping := bleve.NewDocumentStaticMapping()
+	neighborMapping.AddFieldMappingsAt("chassis_id", keywordField())
+	neighborMapping.AddFieldMappingsAt("port_id", keywordField())
+	neighborMapping.AddFieldMappingsAt("sys_name", keywordField())
+	neighborMapping.AddFieldMappingsAt("management_address", keywordField())
+
+	snmpMapping := bleve.NewDocumentMapping()
+	snmpMapping.AddFieldMappingsAt("available", bleve.NewBooleanFieldMapping())
+	snmpMapping.AddSubDocumentMapping("interfaces", interfaceMapping)
+	snmpMapping.AddSubDocumentMapping("arp_entries", arpEntryMapping)
+	snmpMapping.AddSubDocumentMapping("fdb_entries", bleve.NewDocumentDisabledMapping())
+	snmpMapping.AddSubDocumentMapping("neighbors", neighborMapping)
+	// Measurements are keyed by OID, their values stay dynamic
+	snmpMapping.AddSubDocumentMapping("measurements", measurementMapping)
+
+	discoveredPortMapping := bleve.NewDocumentStaticMapping()
+	discoveredPortMapping.AddFieldMappingsAt("port", numericField())
+	discoveredPortMapping.AddFieldMappingsAt("protocol", keywordField())
+
+	summaryMapping := bleve.NewDocumentStaticMapping()
+	summaryMapping.AddFieldMappingsAt("open_ports", keywordField())
+	summaryMapping.AddFieldMappingsAt("services", keywordField())
+	summaryMapping.AddFieldMappingsAt("products", keywordField())
+	summaryMapping.AddFieldMappingsAt("os", keywordField())
+
+	docMapping := bleve.NewDocumentMapping()
+	docMapping.AddFieldMappingsAt("address", keywordField())
+	docMapping.AddFieldMappingsAt("scan_id", numericField())
+	docMapping.AddFieldMappingsAt("vendor", keywordField())
+	docMapping.AddFieldMappingsAt("device_class", keywordField())
+	docMapping.AddSubDocumentMapping("network", networkMapping)
+	docMapping.AddSubDocumentMapping("nmap", nmapMapping)
+	docMapping.AddSubDocumentMapping("snmp", snmpMapping)
+	docMapping.AddSubDocumentMapping("open_ports", discoveredPortMapping)
+	docMapping.AddSubDocumentMapping("summary", summaryMapping)
+
+	indexMapping := bleve.NewIndexMapping()
+	indexMapping.DefaultMapping = docMapping
+
+	return indexMapping
+}
+
+func keywordField() *bleveMapping.FieldMapping {
+	field := bleve.NewTextFieldMapping()
+	field.Analyzer = keyword.Name
+	return field
+}
+
+func numericField() *bleveMapping.FieldMapping {
+	return bleve.NewNumericFieldMapping()
+}
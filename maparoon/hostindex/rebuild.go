@@ -0,0 +1,61 @@
+package hostindex
+
+import (
+	"fmt"
+
+	"github.com/blevesearch/bleve"
+
+	"github.com/pirogoeth/apps/maparoon/types"
+)
+
+// IsCurrent reports whether the index was created with the current mapping
+func IsCurrent(index bleve.Index) (bool, error) {
+	version, err := index.GetInternal(MappingVersionKey)
+	if err != nil {
+		return false, fmt.Errorf("could not read index mapping version: %w", err)
+	}
+
+	return string(version) == MappingVersion, nil
+}
+
+// Fill indexes the host scans into an index created with the current mapping
+// and marks it as current
+func Fill(index bleve.Index, docs []*types.HostScanDocument) error {
+	batch := index.NewBatch()
+	for _, doc := range docs {
+		doc.Summary = Summarize(doc)
+		if err := batch.Index(doc.Address, doc); err != nil {
+			return fmt.Errorf("could not index host scan of %s: %w", doc.Address, err)
+		}
+	}
+
+	if err := index.Batch(batch); err != nil {
+		return fmt.Errorf("could not index host scans: %w", err)
+	}
+
+	if err := index.SetInternal(MappingVersionKey, []byte(MappingVersion)); err != nil {
+		return fmt.Errorf("could not store index mapping version: %w", err)
+	}
+
+	return nil
+}
+
+// Addresses lists the addresses of every document in the index
+func Addresses(index bleve.Index) ([]string, error) {
+	count, err := index.DocCount()
+	if err != nil {
+		return nil, fmt.Errorf("could not count indexed documents: %w", err)
+	}
+
+	results, err := index.Search(bleve.NewSearchRequestOptions(bleve.NewMatchAllQuery(), int(count), 0, false))
+	if err != nil {
+		return nil, fmt.Errorf("could not list indexed documents: %w", err)
+	}
+
+	addresses := make([]string, 0, len(results.Hits))
+	for _, hit := range results.Hits {
+		addresses = append(addresses, hit.ID)
+	}
+
+	return addresses, nil
+}
@@ -0,0 +1,72 @@
+package hostindex
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/pirogoeth/apps/maparoon/scandiff"
+	"github.com/pirogoeth/apps/maparoon/types"
+)
+
+// Facets are the facets searches can ask for, by the name they're requested
+// with
+var Facets = map[string]string{
+	"os":           "summary.os",
+	"service":      "summary.services",
+	"product":      "summary.products",
+	"port":         "summary.open_ports",
+	"vendor":       "vendor",
+	"device_class": "device_class",
+}
+
+// Summarize derives the summary of a host scan: its open ports, the services
+// and products nmap found on them, and the OS it matched best
+func Summarize(doc *types.HostScanDocument) *types.HostScanSummary {
+	summary := &types.HostScanSummary{
+		OpenPorts: []string{},
+		Services:  []string{},
+		Products:  []string{},
+	}
+
+	for _, port := range doc.OpenPorts {
+		summary.OpenPorts = appendUnique(summary.OpenPorts, fmt.Sprintf("%d/%s", port.Port, port.Protocol))
+	}
+
+	for key := range scandiff.OpenPorts(doc) {
+		summary.OpenPorts = appendUnique(summary.OpenPorts, key)
+	}
+
+	if doc.Nmap != nil {
+		for _, port := range doc.Nmap.HostDetails.Ports {
+			if port.State.State != "open" {
+				continue
+			}
+
+			summary.Services = appendUnique(summary.Services, port.Service.Name)
+			summary.Products = appendUnique(summary.Products, port.Service.Product)
+		}
+
+		bestAccuracy := -1
+		for _, match := range doc.Nmap.HostDetails.Os.OsMatches {
+			accuracy, _ := strconv.Atoi(match.Accuracy)
+			if accuracy > bestAccuracy {
+				summary.Os, bestAccuracy = match.Name, accuracy
+			}
+		}
+	}
+
+	slices.Sort(summary.OpenPorts)
+	slices.Sort(summary.Services)
+	slices.Sort(summary.Products)
+
+	return summary
+}
+
+func appendUnique(values []string, value string) []string {
+	if value == "" || slices.Contains(values, value) {
+		return values
+	}
+
+	return append(values, value)
+}
@@ -0,0 +1,6 @@
+package ieeeRegistries
+
+import "embed"
+
+//go:embed *.csv
+var FS embed.FS
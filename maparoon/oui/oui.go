@@ -0,0 +1,109 @@
+// Package oui resolves MAC addresses to the organization they were assigned
+// to, from the IEEE registries embedded in the binary
+package oui
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/pirogoeth/apps/maparoon/oui/ieeeRegistries"
+)
+
+// Assignment lengths in hex digits, longest first. MA-S blocks are carved out
+// of MA-L blocks, so the longest match wins.
+var prefixLengths = []int{9, 7, 6}
+
+var registry = sync.OnceValue(func() map[string]string {
+	assignments, err := loadRegistries(ieeeRegistries.FS)
+	if err != nil {
+		logrus.Errorf("could not load oui registries: %s", err)
+	}
+
+	logrus.Debugf("Loaded %d oui assignments", len(assignments))
+	return assignments
+})
+
+// Lookup returns the vendor of the MAC address, or "" if it's unknown or the
+// address is locally administered
+func Lookup(macAddress string) string {
+	mac, err := net.ParseMAC(macAddress)
+	if err != nil || len(mac) != 6 || mac[0]&0x02 != 0 {
+		return ""
+	}
+
+	digits := strings.ToUpper(strings.ReplaceAll(mac.String(), ":", ""))
+	assignments := registry()
+	for _, length := range prefixLengths {
+		if vendor, ok := assignments[digits[:length]]; ok {
+			return vendor
+		}
+	}
+
+	return ""
+}
+
+// loadRegistries reads every registry in the FS, in the IEEE's CSV format.
+// Files are read in name order, later assignments replace earlier ones.
+func loadRegistries(registries fs.ReadDirFS) (map[string]string, error) {
+	files, err := registries.ReadDir(".")
+	if err != nil {
+		return nil, fmt.Errorf("could not read registries: %w", err)
+	}
+
+	assignments := make(map[string]string)
+	for _, file := range files {
+		if !strings.HasSuffix(file.Name(), ".csv") {
+			continue
+		}
+
+		f, err := registries.Open(file.Name())
+		if err != nil {
+			return assignments, err
+		}
+
+		err = parseRegistry(f, assignments)
+		f.Close()
+		if err != nil {
+			return assignments, fmt.Errorf("could not parse %s: %w", file.Name(), err)
+		}
+	}
+
+	return assignments, nil
+}
+
+// parseRegistry reads `Registry,Assignment,Organization Name,...` rows
+func parseRegistry(r io.Reader, into map[string]string) error {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	if _, err := reader.Read(); err != nil {
+		return err
+	}
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if len(record) < 3 {
+			continue
+		}
+
+		assignment := strings.ToUpper(strings.TrimSpace(record[1]))
+		switch len(assignment) {
+		case 6, 7, 9:
+			into[assignment] = strings.TrimSpace(record[2])
+		}
+	}
+}
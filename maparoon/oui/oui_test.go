@@ -0,0 +1,39 @@
+package oui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	cases := []struct {
+		mac    string
+		vendor string
+	}{
+		{"00:50:56:aa:bb:cc", "VMware, Inc."},
+		{"B8-27-EB-00-00-01", "Raspberry Pi Foundation"},
+		{"52:54:00:12:34:56", ""},
+		{"not a mac", ""},
+	}
+
+	for _, c := range cases {
+		if vendor := Lookup(c.mac); vendor != c.vendor {
+			t.Errorf("%s: got %q, want %q", c.mac, vendor, c.vendor)
+		}
+	}
+}
+
+func TestParseRegistryPrefixLengths(t *testing.T) {
+	assignments := make(map[string]string)
+	err := parseRegistry(strings.NewReader(`Registry,Assignment,Organization Name,Organization Address
+MA-L,70B3D5,IEEE Registration Authority,
+MA-S,70B3D5123,Example Sensors Ltd,"1 Example Road"
+`), assignments)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if assignments["70B3D5"] != "IEEE Registration Authority" || assignments["70B3D5123"] != "Example Sensors Ltd" {
+		t.Errorf("unexpected assignments: %v", assignments)
+	}
+}
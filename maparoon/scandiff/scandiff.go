@@ -0,0 +1,219 @@
+package scandiff
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pirogoeth/apps/maparoon/types"
+)
+
+// Port is an open port found on a host
+type Port struct {
+	Port     int    `json:"port"`
+	Protocol string `json:"protocol"`
+	// Service describes the service nmap detected on the port, including its version
+	Service string `json:"service"`
+}
+
+type PortChange struct {
+	Address string `json:"address"`
+	Port
+}
+
+type ServiceChange struct {
+	Address  string `json:"address"`
+	Port     int    `json:"port"`
+	Protocol string `json:"protocol"`
+	Previous string `json:"previous"`
+	Current  string `json:"current"`
+}
+
+type MeasurementChange struct {
+	Address  string `json:"address"`
+	Oid      string `json:"oid"`
+	Name     string `json:"name"`
+	Previous string `json:"previous"`
+	Current  string `json:"current"`
+}
+
+// Changes are the differences between two scans of a network. Port and service
+// changes are only reported for hosts found by both scans, and measurement
+// changes only for hosts that answered SNMP in both scans.
+type Changes struct {
+	NewHosts            []string            `json:"new_hosts"`
+	DisappearedHosts    []string            `json:"disappeared_hosts"`
+	OpenedPorts         []PortChange        `json:"opened_ports"`
+	ClosedPorts         []PortChange        `json:"closed_ports"`
+	ChangedServices     []ServiceChange     `json:"changed_services"`
+	ChangedMeasurements []MeasurementChange `json:"changed_measurements"`
+}
+
+// Empty reports whether the scans found no differences
+func (c *Changes) Empty() bool {
+	return len(c.NewHosts) == 0 &&
+		len(c.DisappearedHosts) == 0 &&
+		len(c.OpenedPorts) == 0 &&
+		len(c.ClosedPorts) == 0 &&
+		len(c.ChangedServices) == 0 &&
+		len(c.ChangedMeasurements) == 0
+}
+
+// Compare reports what changed between the host scans of a previous scan and
+// the host scans of the current one
+func Compare(previous, current []*types.HostScanDocument) *Changes {
+	changes := &Changes{
+		NewHosts:            []string{},
+		DisappearedHosts:    []string{},
+		OpenedPorts:         []PortChange{},
+		ClosedPorts:         []PortChange{},
+		ChangedServices:     []ServiceChange{},
+		ChangedMeasurements: []MeasurementChange{},
+	}
+
+	previousHosts := byAddress(previous)
+	currentHosts := byAddress(current)
+
+	for address := range previousHosts {
+		if _, ok := currentHosts[address]; !ok {
+			changes.DisappearedHosts = append(changes.DisappearedHosts, address)
+		}
+	}
+
+	for address, currentDoc := range currentHosts {
+		previousDoc, ok := previousHosts[address]
+		if !ok {
+			changes.NewHosts = append(changes.NewHosts, address)
+			continue
+		}
+
+		previousPorts := OpenPorts(previousDoc)
+		currentPorts := OpenPorts(currentDoc)
+
+		for key, port := range currentPorts {
+			previousPort, ok := previousPorts[key]
+			if !ok {
+				changes.OpenedPorts = append(changes.OpenedPorts, PortChange{Address: address, Port: port})
+				continue
+			}
+
+			if previousPort.Service != port.Service {
+				changes.ChangedServices = append(changes.ChangedServices, ServiceChange{
+					Address:  address,
+					Port:     port.Port,
+					Protocol: port.Protocol,
+					Previous: previousPort.Service,
+					Current:  port.Service,
+				})
+			}
+		}
+
+		for key, port := range previousPorts {
+			if _, ok := currentPorts[key]; !ok {
+				changes.ClosedPorts = append(changes.ClosedPorts, PortChange{Address: address, Port: port})
+			}
+		}
+
+		if snmpAvailable(previousDoc) && snmpAvailable(currentDoc) {
+			for key, measurement := range currentDoc.Snmp.Measurements {
+				previousMeasurement, ok := previousDoc.Snmp.Measurements[key]
+				if !ok || previousMeasurement.Value == measurement.Value {
+					continue
+				}
+
+				changes.ChangedMeasurements = append(changes.ChangedMeasurements, MeasurementChange{
+					Address:  address,
+					Oid:      measurement.Oid,
+					Name:     measurement.Name,
+					Previous: previousMeasurement.Value,
+					Current:  measurement.Value,
+				})
+			}
+		}
+	}
+
+	sort.Strings(changes.NewHosts)
+	sort.Strings(changes.DisappearedHosts)
+	sortPortChanges(changes.OpenedPorts)
+	sortPortChanges(changes.ClosedPorts)
+	sort.Slice(changes.ChangedServices, func(i, j int) bool {
+		a, b := changes.ChangedServices[i], changes.ChangedServices[j]
+		if a.Address != b.Address {
+			return a.Address < b.Address
+		}
+		return a.Port < b.Port
+	})
+	sort.Slice(changes.ChangedMeasurements, func(i, j int) bool {
+		a, b := changes.ChangedMeasurements[i], changes.ChangedMeasurements[j]
+		if a.Address != b.Address {
+			return a.Address < b.Address
+		}
+		return a.Oid < b.Oid
+	})
+
+	return changes
+}
+
+// OpenPorts returns the ports nmap found open on a host, keyed by port and protocol
+func OpenPorts(doc *types.HostScanDocument) map[string]Port {
+	ports := make(map[string]Port)
+	if doc == nil || doc.Nmap == nil {
+		return ports
+	}
+
+	for _, port := range doc.Nmap.HostDetails.Ports {
+		if port.State.State != "open" {
+			continue
+		}
+
+		ports[fmt.Sprintf("%d/%s", port.PortId, port.Protocol)] = Port{
+			Port:     port.PortId,
+			Protocol: port.Protocol,
+			Service:  serviceDescription(port.Service.Name, port.Service.Product, port.Service.Version, port.Service.ExtraInfo),
+		}
+	}
+
+	return ports
+}
+
+// serviceDescription combines the detected product and version, falling back
+// to the service name when nmap couldn't identify the product
+func serviceDescription(name, product, version, extraInfo string) string {
+	parts := make([]string, 0, 3)
+	for _, part := range []string{product, version, extraInfo} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	if len(parts) == 0 {
+		return name
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func snmpAvailable(doc *types.HostScanDocument) bool {
+	return doc.Snmp != nil && doc.Snmp.Available
+}
+
+func byAddress(docs []*types.HostScanDocument) map[string]*types.HostScanDocument {
+	hosts := make(map[string]*types.HostScanDocument, len(docs))
+	for _, doc := range docs {
+		hosts[doc.Address] = doc
+	}
+
+	return hosts
+}
+
+func sortPortChanges(changes []PortChange) {
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Address != changes[j].Address {
+			return changes[i].Address < changes[j].Address
+		}
+		if changes[i].Port.Port != changes[j].Port.Port {
+			return changes[i].Port.Port < changes[j].Port.Port
+		}
+		return changes[i].Protocol < changes[j].Protocol
+	})
+}
@@ -0,0 +1,79 @@
+package scandiff
+
+import (
+	"testing"
+
+	"github.com/adifire/go-nmap"
+
+	"github.com/pirogoeth/apps/maparoon/types"
+)
+
+func hostScan(address string, ports ...nmap.Port) *types.HostScanDocument {
+	return &types.HostScanDocument{
+		Address: address,
+		Nmap: &types.NmapHostScanDocument{
+			HostDetails: nmap.Host{Ports: ports},
+		},
+	}
+}
+
+func openPort(id int, product, version string) nmap.Port {
+	return nmap.Port{
+		Protocol: "tcp",
+		PortId:   id,
+		State:    nmap.State{State: "open"},
+		Service:  nmap.Service{Name: "svc", Product: product, Version: version},
+	}
+}
+
+func snmpScan(sysName string) *types.SnmpHostScanDocument {
+	return &types.SnmpHostScanDocument{
+		Available: true,
+		Measurements: map[string]types.SnmpMeasurement{
+			"1-3-6-1-2-1-1-5-0": {Oid: ".1.3.6.1.2.1.1.5.0", Name: "sysName.0", Value: sysName},
+		},
+	}
+}
+
+func TestCompare(t *testing.T) {
+	previous := []*types.HostScanDocument{
+		hostScan("10.0.0.1", openPort(22, "OpenSSH", "8.9"), openPort(80, "nginx", "1.24")),
+		hostScan("10.0.0.2", openPort(443, "", "")),
+	}
+	current := []*types.HostScanDocument{
+		hostScan("10.0.0.1", openPort(22, "OpenSSH", "9.6"), openPort(8080, "", "")),
+		hostScan("10.0.0.3"),
+	}
+
+	changes := Compare(previous, current)
+
+	if len(changes.NewHosts) != 1 || changes.NewHosts[0] != "10.0.0.3" {
+		t.Errorf("unexpected new hosts: %v", changes.NewHosts)
+	}
+	if len(changes.DisappearedHosts) != 1 || changes.DisappearedHosts[0] != "10.0.0.2" {
+		t.Errorf("unexpected disappeared hosts: %v", changes.DisappearedHosts)
+	}
+	if len(changes.OpenedPorts) != 1 || changes.OpenedPorts[0].Port.Port != 8080 {
+		t.Errorf("unexpected opened ports: %v", changes.OpenedPorts)
+	}
+	if len(changes.ClosedPorts) != 1 || changes.ClosedPorts[0].Port.Port != 80 {
+		t.Errorf("unexpected closed ports: %v", changes.ClosedPorts)
+	}
+	if len(changes.ChangedServices) != 1 {
+		t.Fatalf("unexpected changed services: %v", changes.ChangedServices)
+	}
+	if change := changes.ChangedServices[0]; change.Previous != "OpenSSH 8.9" || change.Current != "OpenSSH 9.6" {
+		t.Errorf("unexpected service change: %+v", change)
+	}
+
+	previous[0].Snmp = snmpScan("router-a")
+	current[0].Snmp = snmpScan("router-b")
+	changes = Compare(previous, current)
+	if len(changes.ChangedMeasurements) != 1 || changes.ChangedMeasurements[0].Current != "router-b" {
+		t.Errorf("unexpected changed measurements: %v", changes.ChangedMeasurements)
+	}
+
+	if !Compare(current, current).Empty() {
+		t.Errorf("expected identical scans to have no changes")
+	}
+}
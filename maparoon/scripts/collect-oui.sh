@@ -0,0 +1,33 @@
+#!/usr/bin/env bash
+
+echo
+echo " ******************************************************* "
+echo " ***                                                 *** "
+echo " *** Hey! This script collects the IEEE MAC address  *** "
+echo " *** registries from the internet. Check out the     *** "
+echo " *** source here to see what's going on:             *** "
+echo " *** scripts/collect-oui.sh                          *** "
+echo " ***                                                 *** "
+echo " ******************************************************* "
+echo
+sleep 3
+
+
+set -e
+
+IEEE_REGISTRY_URL="https://standards-oui.ieee.org"
+
+this_dir="$(dirname $(readlink -nf "${BASH_SOURCE[0]}"))"
+echo "this_dir: ${this_dir}"
+
+registries_dir="$(readlink -nf "${this_dir}/../oui/ieeeRegistries")"
+echo "registries_dir: ${registries_dir}"
+mkdir -p "${registries_dir}"
+
+# MA-L (24 bit), MA-M (28 bit) and MA-S (36 bit) assignments
+echo "Downloading MA-L registry"
+curl -L -o "${registries_dir}/ma-l.csv" "${IEEE_REGISTRY_URL}/oui/oui.csv"
+echo "Downloading MA-M registry"
+curl -L -o "${registries_dir}/ma-m.csv" "${IEEE_REGISTRY_URL}/oui28/mam.csv"
+echo "Downloading MA-S registry"
+curl -L -o "${registries_dir}/ma-s.csv" "${IEEE_REGISTRY_URL}/oui36/oui36.csv"
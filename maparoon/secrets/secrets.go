@@ -0,0 +1,79 @@
+// Package secrets encrypts the credentials that are stored in the database
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+var ErrNoKey = errors.New("no secrets key configured")
+
+// Sealer encrypts secrets with AES-GCM, using a key derived from the
+// configured secrets key
+type Sealer struct {
+	aead cipher.AEAD
+}
+
+func NewSealer(key string) (*Sealer, error) {
+	if key == "" {
+		return nil, ErrNoKey
+	}
+
+	derivedKey := sha256.Sum256([]byte(key))
+	block, err := aes.NewCipher(derivedKey[:])
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Sealer{aead: aead}, nil
+}
+
+// Seal encrypts plaintext. An empty plaintext stays empty, so that optional
+// secrets can be told apart from unset ones.
+func (s *Sealer) Seal(plaintext string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("could not generate nonce: %w", err)
+	}
+
+	sealed := s.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts a secret returned by Seal
+func (s *Sealer) Open(sealed string) (string, error) {
+	if sealed == "" {
+		return "", nil
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil {
+		return "", fmt.Errorf("could not decode secret: %w", err)
+	}
+
+	nonceSize := s.aead.NonceSize()
+	if len(raw) < nonceSize {
+		return "", fmt.Errorf("secret is too short")
+	}
+
+	plaintext, err := s.aead.Open(nil, raw[:nonceSize], raw[nonceSize:], nil)
+	if err != nil {
+		return "", fmt.Errorf("could not decrypt secret, was the secrets key changed?: %w", err)
+	}
+
+	return string(plaintext), nil
+}
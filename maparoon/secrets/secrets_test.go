@@ -0,0 +1,37 @@
+package secrets
+
+import (
+	"testing"
+)
+
+func TestSealAndOpen(t *testing.T) {
+	sealer, err := NewSealer("correct horse battery staple")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sealed, err := sealer.Seal("public")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sealed == "public" {
+		t.Fatalf("expected secret to be encrypted")
+	}
+
+	if opened, err := sealer.Open(sealed); err != nil || opened != "public" {
+		t.Errorf("expected to open sealed secret, got %q: %v", opened, err)
+	}
+
+	other, _ := NewSealer("another key")
+	if _, err := other.Open(sealed); err == nil {
+		t.Errorf("expected opening with another key to fail")
+	}
+
+	if sealed, _ := sealer.Seal(""); sealed != "" {
+		t.Errorf("expected empty secret to stay empty, got %q", sealed)
+	}
+
+	if _, err := NewSealer(""); err != ErrNoKey {
+		t.Errorf("expected ErrNoKey, got %v", err)
+	}
+}
@@ -2,6 +2,7 @@ package snmpsmi
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/sirupsen/logrus"
 	"github.com/sleepinggenius2/gosmi"
@@ -12,8 +13,14 @@ import (
 	"github.com/pirogoeth/apps/maparoon/snmpsmi/netSnmpMibs"
 )
 
-var globalOidCache = newOidCache()
+var (
+	globalOidCache  = newOidCache()
+	globalNameCache = newOidCache()
+)
 
+// Init loads the embedded MIBs. They aren't checked in, scripts/collect-mibs.sh
+// downloads net-snmp's and Juniper's before building. Without them, OIDs
+// aren't resolved to names and the worker walks tables by their numeric OIDs.
 func Init() {
 	gosmi.Init()
 
@@ -24,6 +31,7 @@ func Init() {
 	}
 	gosmi.SetFS(fses...)
 
+	embedded := 0
 	for _, fs := range fses {
 		// Walk the FS and load modules
 		mibsDir, err := fs.FS.ReadDir(".")
@@ -38,9 +46,14 @@ func Init() {
 				logrus.Warnf("failed to load module %s: %s, skipping", file.Name(), err)
 				continue
 			}
+			embedded++
 		}
 	}
 
+	if embedded == 0 {
+		logrus.Warnf("No MIB modules are embedded, run scripts/collect-mibs.sh before building to resolve OIDs by name")
+	}
+
 	modules := gosmi.GetLoadedModules()
 	logrus.Infof("Loaded %d MIB modules", len(modules))
 }
@@ -68,3 +81,49 @@ func ResolveOIDWithoutCache(oid string) (*gosmi.SmiNode, error) {
 
 	return &node, nil
 }
+
+// ResolveName looks up a node by name, optionally qualified by the module that
+// defines it, like IF-MIB::ifEntry
+func ResolveName(name string) (*gosmi.SmiNode, error) {
+	if node := globalNameCache.Get(name); node != nil {
+		return node, nil
+	}
+
+	node, err := ResolveNameWithoutCache(name)
+	if err != nil {
+		return nil, err
+	}
+
+	globalNameCache.Add(name, node)
+
+	return node, err
+}
+
+func ResolveNameWithoutCache(name string) (*gosmi.SmiNode, error) {
+	moduleName, nodeName, qualified := strings.Cut(name, "::")
+	if !qualified {
+		node, err := gosmi.GetNode(name)
+		if err != nil {
+			return nil, err
+		}
+
+		return &node, nil
+	}
+
+	// Only modules that are already loaded are searched, the lookup shouldn't
+	// go off and load a MIB
+	for _, module := range gosmi.GetLoadedModules() {
+		if module.Name != moduleName {
+			continue
+		}
+
+		node, err := gosmi.GetNode(nodeName, module)
+		if err != nil {
+			return nil, err
+		}
+
+		return &node, nil
+	}
+
+	return nil, fmt.Errorf("module %s is not loaded", moduleName)
+}
@@ -0,0 +1,153 @@
+package topology
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// WriteDOT writes the graph in Graphviz DOT format
+func WriteDOT(w io.Writer, graph *Graph) error {
+	var sb strings.Builder
+	sb.WriteString("graph topology {\n")
+	for _, node := range graph.Nodes {
+		fmt.Fprintf(&sb, "  %s [label=%s, shape=%s];\n",
+			strconv.Quote(node.ID), strconv.Quote(node.Label), dotShape(node.Kind))
+	}
+	for _, edge := range graph.Edges {
+		attrs := []string{"kind=" + strconv.Quote(edge.Kind)}
+		if edge.SourcePort != "" {
+			attrs = append(attrs, "taillabel="+strconv.Quote(edge.SourcePort))
+		}
+		if edge.TargetPort != "" {
+			attrs = append(attrs, "headlabel="+strconv.Quote(edge.TargetPort))
+		}
+		if edge.Kind == EdgeMember {
+			attrs = append(attrs, "style=dotted")
+		}
+		fmt.Fprintf(&sb, "  %s -- %s [%s];\n",
+			strconv.Quote(edge.Source), strconv.Quote(edge.Target), strings.Join(attrs, ", "))
+	}
+	sb.WriteString("}\n")
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func dotShape(kind string) string {
+	switch kind {
+	case NodeNetwork:
+		return "folder"
+	case NodeDevice:
+		return "box3d"
+	case NodeSegment:
+		return "point"
+	default:
+		return "box"
+	}
+}
+
+type graphmlDocument struct {
+	XMLName xml.Name     `xml:"graphml"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	Keys    []graphmlKey `xml:"key"`
+	Graph   graphmlGraph `xml:"graph"`
+}
+
+type graphmlKey struct {
+	ID       string `xml:"id,attr"`
+	For      string `xml:"for,attr"`
+	AttrName string `xml:"attr.name,attr"`
+	AttrType string `xml:"attr.type,attr"`
+}
+
+type graphmlGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphmlNode `xml:"node"`
+	Edges       []graphmlEdge `xml:"edge"`
+}
+
+type graphmlNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphmlData `xml:"data"`
+}
+
+type graphmlEdge struct {
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphmlData `xml:"data"`
+}
+
+type graphmlData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// WriteGraphML writes the graph in GraphML format
+func WriteGraphML(w io.Writer, graph *Graph) error {
+	doc := graphmlDocument{
+		Xmlns: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphmlKey{
+			{ID: "kind", For: "all", AttrName: "kind", AttrType: "string"},
+			{ID: "label", For: "node", AttrName: "label", AttrType: "string"},
+			{ID: "address", For: "node", AttrName: "address", AttrType: "string"},
+			{ID: "network_id", For: "node", AttrName: "network_id", AttrType: "long"},
+			{ID: "mac_address", For: "node", AttrName: "mac_address", AttrType: "string"},
+			{ID: "source_port", For: "edge", AttrName: "source_port", AttrType: "string"},
+			{ID: "target_port", For: "edge", AttrName: "target_port", AttrType: "string"},
+		},
+		Graph: graphmlGraph{ID: "topology", EdgeDefault: "undirected"},
+	}
+
+	for _, node := range graph.Nodes {
+		data := graphmlDataOf(
+			"kind", node.Kind,
+			"label", node.Label,
+			"address", node.Address,
+			"mac_address", node.MacAddress,
+		)
+		if node.NetworkID != 0 {
+			data = append(data, graphmlData{Key: "network_id", Value: strconv.FormatInt(node.NetworkID, 10)})
+		}
+		doc.Graph.Nodes = append(doc.Graph.Nodes, graphmlNode{ID: node.ID, Data: data})
+	}
+	for _, edge := range graph.Edges {
+		doc.Graph.Edges = append(doc.Graph.Edges, graphmlEdge{
+			Source: edge.Source,
+			Target: edge.Target,
+			Data: graphmlDataOf(
+				"kind", edge.Kind,
+				"source_port", edge.SourcePort,
+				"target_port", edge.TargetPort,
+			),
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// graphmlDataOf turns key, value pairs into data elements, skipping empty values
+func graphmlDataOf(pairs ...string) []graphmlData {
+	data := make([]graphmlData, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if pairs[i+1] != "" {
+			data = append(data, graphmlData{Key: pairs[i], Value: pairs[i+1]})
+		}
+	}
+
+	return data
+}
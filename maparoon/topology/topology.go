@@ -0,0 +1,348 @@
+// Package topology builds a graph of networks, hosts and the links between
+// them out of the tables walked from hosts over SNMP
+package topology
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pirogoeth/apps/maparoon/database"
+)
+
+const (
+	NodeNetwork = "network"
+	NodeHost    = "host"
+	// NodeDevice is an LLDP neighbor that isn't a known host
+	NodeDevice = "device"
+	// NodeSegment is a layer 2 segment, the hosts a device sees on one of
+	// its interfaces
+	NodeSegment = "segment"
+)
+
+const (
+	// EdgeMember links a host to its network
+	EdgeMember = "member"
+	// EdgeLldp links two devices' ports, as seen by LLDP
+	EdgeLldp = "lldp"
+	// EdgeFdb links a switch port to a host whose MAC address it learned
+	EdgeFdb = "fdb"
+	// EdgeSegment links a host to a layer 2 segment it shares with others
+	EdgeSegment = "segment"
+)
+
+type Node struct {
+	ID         string `json:"id"`
+	Kind       string `json:"kind"`
+	Label      string `json:"label"`
+	Address    string `json:"address,omitempty"`
+	NetworkID  int64  `json:"network_id,omitempty"`
+	MacAddress string `json:"mac_address,omitempty"`
+}
+
+type Edge struct {
+	Source     string `json:"source"`
+	Target     string `json:"target"`
+	Kind       string `json:"kind"`
+	SourcePort string `json:"source_port,omitempty"`
+	TargetPort string `json:"target_port,omitempty"`
+}
+
+type Graph struct {
+	Nodes []Node `json:"nodes"`
+	Edges []Edge `json:"edges"`
+}
+
+// Input is everything the graph is built from
+type Input struct {
+	Networks   []database.Network
+	Hosts      []database.Host
+	Interfaces []database.HostInterface
+	ArpEntries []database.HostArpEntry
+	FdbEntries []database.HostFdbEntry
+	Neighbors  []database.HostNeighbor
+	// MacAddresses maps host addresses to their MAC address
+	MacAddresses map[string]string
+}
+
+type builder struct {
+	graph     *Graph
+	nodes     map[string]bool
+	edges     map[string]bool
+	hosts     map[string]database.Host
+	macHosts  map[string]string
+	portNames map[string]string
+	// ifNames maps a host's interface names to their ifIndex, bridgePorts
+	// its bridge ports
+	ifNames     map[string]int64
+	bridgePorts map[string]int64
+}
+
+// Build builds the topology graph. If networkId isn't 0, only that network's
+// hosts are included, along with the devices they link to.
+func Build(input Input, networkId int64) *Graph {
+	b := &builder{
+		graph:     &Graph{Nodes: []Node{}, Edges: []Edge{}},
+		nodes:     make(map[string]bool),
+		edges:     make(map[string]bool),
+		hosts:     make(map[string]database.Host),
+		macHosts:  make(map[string]string),
+		portNames: make(map[string]string),
+
+		ifNames:     make(map[string]int64),
+		bridgePorts: make(map[string]int64),
+	}
+
+	for _, host := range input.Hosts {
+		if networkId == 0 || host.NetworkID == networkId {
+			b.hosts[host.Address] = host
+		}
+	}
+
+	for _, network := range input.Networks {
+		if networkId == 0 || network.ID == networkId {
+			b.addNode(Node{
+				ID:        networkNodeId(network.ID),
+				Kind:      NodeNetwork,
+				Label:     fmt.Sprintf("%s (%s)", network.Name, network.CidrString()),
+				NetworkID: network.ID,
+			})
+		}
+	}
+
+	for _, address := range sortedKeys(b.hosts) {
+		host := b.hosts[address]
+		b.addNode(Node{
+			ID:         hostNodeId(address),
+			Kind:       NodeHost,
+			Label:      address,
+			Address:    address,
+			NetworkID:  host.NetworkID,
+			MacAddress: normalizeMac(input.MacAddresses[address]),
+		})
+		b.addEdge(Edge{Source: hostNodeId(address), Target: networkNodeId(host.NetworkID), Kind: EdgeMember})
+	}
+
+	// A host is known by its own interfaces' MAC addresses as well as the one
+	// others see in their ARP tables
+	for _, address := range sortedKeys(input.MacAddresses) {
+		b.addMac(input.MacAddresses[address], address)
+	}
+	for _, iface := range input.Interfaces {
+		b.addMac(iface.MacAddress, iface.Address)
+		b.portNames[portKey(iface.Address, iface.IfIndex)] = firstNonEmpty(iface.Name, iface.Descr)
+		for _, name := range []string{iface.Name, iface.Descr} {
+			if _, ok := b.ifNames[ifNameKey(iface.Address, name)]; name != "" && !ok {
+				b.ifNames[ifNameKey(iface.Address, name)] = iface.IfIndex
+			}
+		}
+	}
+	for _, entry := range input.FdbEntries {
+		if entry.IfIndex != 0 {
+			b.bridgePorts[portKey(entry.Address, entry.BridgePort)] = entry.IfIndex
+		}
+	}
+	for _, entry := range input.ArpEntries {
+		b.addMac(entry.MacAddress, entry.IpAddress)
+	}
+
+	uplinks := b.addLldpEdges(input.Neighbors)
+	b.addFdbEdges(input.FdbEntries, uplinks)
+	b.addSegments(input.ArpEntries)
+
+	return b.graph
+}
+
+// addLldpEdges links neighbors and returns the ports they were seen on, which
+// are treated as uplinks
+func (b *builder) addLldpEdges(neighbors []database.HostNeighbor) map[string]bool {
+	uplinks := make(map[string]bool)
+	for _, neighbor := range neighbors {
+		if _, ok := b.hosts[neighbor.Address]; !ok {
+			continue
+		}
+		localPort := portKey(neighbor.Address, b.neighborIfIndex(neighbor))
+		uplinks[localPort] = true
+
+		remoteId := ""
+		if _, ok := b.hosts[neighbor.ManagementAddress]; ok {
+			remoteId = hostNodeId(neighbor.ManagementAddress)
+		} else if address, ok := b.macHosts[normalizeMac(neighbor.ChassisID)]; ok {
+			remoteId = hostNodeId(address)
+		} else {
+			remoteId = "device:" + neighbor.ChassisID
+			b.addNode(Node{
+				ID:         remoteId,
+				Kind:       NodeDevice,
+				Label:      firstNonEmpty(neighbor.SysName, neighbor.ChassisID),
+				Address:    neighbor.ManagementAddress,
+				MacAddress: normalizeMac(neighbor.ChassisID),
+			})
+		}
+
+		b.addEdge(Edge{
+			Source:     hostNodeId(neighbor.Address),
+			Target:     remoteId,
+			Kind:       EdgeLldp,
+			SourcePort: firstNonEmpty(neighbor.LocalPortDescr, b.portNames[localPort], fmt.Sprint(neighbor.LocalPort)),
+			TargetPort: firstNonEmpty(neighbor.PortDescr, neighbor.PortID),
+		})
+	}
+
+	return uplinks
+}
+
+// neighborIfIndex maps the LLDP port number a neighbor was seen on to the
+// ifIndex the other tables use. The port's id is usually the interface name,
+// otherwise the port number is often the bridge port. Failing both, agents
+// that number LLDP ports by ifIndex are assumed.
+func (b *builder) neighborIfIndex(neighbor database.HostNeighbor) int64 {
+	for _, name := range []string{neighbor.LocalPortID, neighbor.LocalPortDescr} {
+		if ifIndex, ok := b.ifNames[ifNameKey(neighbor.Address, name)]; name != "" && ok {
+			return ifIndex
+		}
+	}
+
+	if ifIndex, ok := b.bridgePorts[portKey(neighbor.Address, neighbor.LocalPort)]; ok {
+		return ifIndex
+	}
+
+	return neighbor.LocalPort
+}
+
+// addFdbEdges links switch ports to the hosts learned on them. MAC addresses
+// learned on uplinks are skipped, they're behind another switch.
+func (b *builder) addFdbEdges(entries []database.HostFdbEntry, uplinks map[string]bool) {
+	for _, entry := range entries {
+		if _, ok := b.hosts[entry.Address]; !ok {
+			continue
+		}
+		if uplinks[portKey(entry.Address, entry.IfIndex)] {
+			continue
+		}
+
+		address, ok := b.macHosts[normalizeMac(entry.MacAddress)]
+		if !ok || address == entry.Address {
+			continue
+		}
+
+		b.addEdge(Edge{
+			Source:     hostNodeId(entry.Address),
+			Target:     hostNodeId(address),
+			Kind:       EdgeFdb,
+			SourcePort: firstNonEmpty(b.portNames[portKey(entry.Address, entry.IfIndex)], fmt.Sprint(entry.BridgePort)),
+		})
+	}
+}
+
+// addSegments groups the known hosts each device sees on an interface into a
+// layer 2 segment, if there's more than one of them
+func (b *builder) addSegments(entries []database.HostArpEntry) {
+	segments := make(map[string][]string)
+	for _, entry := range entries {
+		if _, ok := b.hosts[entry.Address]; !ok {
+			continue
+		}
+		if _, ok := b.hosts[entry.IpAddress]; !ok {
+			continue
+		}
+
+		key := portKey(entry.Address, entry.IfIndex)
+		segments[key] = append(segments[key], entry.IpAddress)
+	}
+
+	for _, key := range sortedKeys(segments) {
+		members := segments[key]
+		if len(members) < 2 {
+			continue
+		}
+
+		address, ifIndex, _ := strings.Cut(key, "#")
+		segmentId := "segment:" + key
+		b.addNode(Node{
+			ID:    segmentId,
+			Kind:  NodeSegment,
+			Label: fmt.Sprintf("%s %s", address, firstNonEmpty(b.portNames[key], ifIndex)),
+		})
+
+		// The device itself is on the segment as well
+		for _, member := range append([]string{address}, members...) {
+			b.addEdge(Edge{Source: hostNodeId(member), Target: segmentId, Kind: EdgeSegment})
+		}
+	}
+}
+
+func (b *builder) addNode(node Node) {
+	if b.nodes[node.ID] {
+		return
+	}
+
+	b.nodes[node.ID] = true
+	b.graph.Nodes = append(b.graph.Nodes, node)
+}
+
+// addEdge adds an edge unless it was already added, from either end
+func (b *builder) addEdge(edge Edge) {
+	forward := strings.Join([]string{edge.Kind, edge.Source, edge.SourcePort, edge.Target, edge.TargetPort}, "|")
+	reverse := strings.Join([]string{edge.Kind, edge.Target, edge.TargetPort, edge.Source, edge.SourcePort}, "|")
+	if b.edges[forward] || b.edges[reverse] {
+		return
+	}
+
+	b.edges[forward] = true
+	b.graph.Edges = append(b.graph.Edges, edge)
+}
+
+func (b *builder) addMac(mac, address string) {
+	mac = normalizeMac(mac)
+	if mac == "" {
+		return
+	}
+	if _, ok := b.hosts[address]; !ok {
+		return
+	}
+
+	if _, ok := b.macHosts[mac]; !ok {
+		b.macHosts[mac] = address
+	}
+}
+
+func networkNodeId(networkId int64) string {
+	return fmt.Sprintf("network:%d", networkId)
+}
+
+func hostNodeId(address string) string {
+	return "host:" + address
+}
+
+func portKey(address string, ifIndex int64) string {
+	return fmt.Sprintf("%s#%d", address, ifIndex)
+}
+
+func ifNameKey(address, name string) string {
+	return address + "#" + name
+}
+
+func normalizeMac(mac string) string {
+	return strings.ToLower(strings.ReplaceAll(mac, "-", ":"))
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
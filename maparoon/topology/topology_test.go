@@ -0,0 +1,153 @@
+package topology
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/pirogoeth/apps/maparoon/database"
+)
+
+func testInput() Input {
+	return Input{
+		Networks: []database.Network{{ID: 1, Name: "lan", Address: "10.0.0.0", Cidr: 24}},
+		Hosts: []database.Host{
+			{Address: "10.0.0.1", NetworkID: 1},
+			{Address: "10.0.0.2", NetworkID: 1},
+			{Address: "10.0.0.10", NetworkID: 1},
+			{Address: "10.0.0.11", NetworkID: 1},
+		},
+		Interfaces: []database.HostInterface{
+			{Address: "10.0.0.1", IfIndex: 1, Name: "ge-0/0/1"},
+			{Address: "10.0.0.1", IfIndex: 2, Name: "ge-0/0/2"},
+			{Address: "10.0.0.1", IfIndex: 24, Name: "ge-0/0/24"},
+			{Address: "10.0.0.2", IfIndex: 48, Name: "port48", MacAddress: "00:11:22:33:44:02"},
+		},
+		Neighbors: []database.HostNeighbor{
+			{Address: "10.0.0.1", LocalPort: 24, ChassisID: "00:11:22:33:44:02", PortID: "port48"},
+			{Address: "10.0.0.2", LocalPort: 48, ManagementAddress: "10.0.0.1", PortID: "ge-0/0/24"},
+			{Address: "10.0.0.2", LocalPort: 47, ChassisID: "aa:bb:cc:00:00:01", SysName: "ap-1", PortID: "eth0"},
+		},
+		FdbEntries: []database.HostFdbEntry{
+			{Address: "10.0.0.1", MacAddress: "00:11:22:33:44:10", IfIndex: 1},
+			{Address: "10.0.0.1", MacAddress: "00:11:22:33:44:11", IfIndex: 24},
+		},
+		ArpEntries: []database.HostArpEntry{
+			{Address: "10.0.0.1", IfIndex: 2, IpAddress: "10.0.0.10", MacAddress: "00:11:22:33:44:10"},
+			{Address: "10.0.0.1", IfIndex: 2, IpAddress: "10.0.0.11", MacAddress: "00:11:22:33:44:11"},
+		},
+	}
+}
+
+func TestBuild(t *testing.T) {
+	graph := Build(testInput(), 0)
+
+	edges := make(map[string]int)
+	for _, edge := range graph.Edges {
+		edges[edge.Kind]++
+	}
+
+	cases := []struct {
+		kind  string
+		count int
+	}{
+		// One per host
+		{EdgeMember, 4},
+		// The switch pair is seen from both ends but only linked once
+		{EdgeLldp, 2},
+		// 10.0.0.11 is learned on the uplink so it isn't linked
+		{EdgeFdb, 1},
+		// The switch and both hosts on ifIndex 2
+		{EdgeSegment, 3},
+	}
+
+	for _, c := range cases {
+		if edges[c.kind] != c.count {
+			t.Errorf("%s edges: got %d, want %d", c.kind, edges[c.kind], c.count)
+		}
+	}
+
+	var devices []string
+	for _, node := range graph.Nodes {
+		if node.Kind == NodeDevice {
+			devices = append(devices, node.Label)
+		}
+	}
+	if len(devices) != 1 || devices[0] != "ap-1" {
+		t.Errorf("devices: got %v, want [ap-1]", devices)
+	}
+}
+
+func TestBuildMapsLldpPortsToIfIndex(t *testing.T) {
+	cases := []struct {
+		name     string
+		neighbor database.HostNeighbor
+	}{
+		{"by port id", database.HostNeighbor{Address: "10.0.0.1", LocalPort: 24, LocalPortID: "ge-0/0/24", ChassisID: "aa:bb:cc:00:00:01"}},
+		{"by bridge port", database.HostNeighbor{Address: "10.0.0.1", LocalPort: 24, ChassisID: "aa:bb:cc:00:00:01"}},
+	}
+
+	for _, c := range cases {
+		input := testInput()
+		// The uplink is LLDP port and bridge port 24 but ifIndex 1024
+		input.Interfaces = []database.HostInterface{{Address: "10.0.0.1", IfIndex: 1024, Name: "ge-0/0/24"}}
+		input.Neighbors = []database.HostNeighbor{c.neighbor}
+		input.FdbEntries = []database.HostFdbEntry{
+			{Address: "10.0.0.1", MacAddress: "00:11:22:33:44:11", BridgePort: 24, IfIndex: 1024},
+		}
+		input.MacAddresses = map[string]string{"10.0.0.11": "00:11:22:33:44:11"}
+
+		for _, edge := range Build(input, 0).Edges {
+			if edge.Kind == EdgeFdb {
+				t.Errorf("%s: expected the host learned on the uplink not to be linked, got %+v", c.name, edge)
+			}
+			if edge.Kind == EdgeLldp && edge.SourcePort != "ge-0/0/24" {
+				t.Errorf("%s: expected the lldp edge on ge-0/0/24, got %q", c.name, edge.SourcePort)
+			}
+		}
+	}
+}
+
+func TestBuildSharedMacIsStable(t *testing.T) {
+	input := testInput()
+	input.FdbEntries = []database.HostFdbEntry{{Address: "10.0.0.1", MacAddress: "00:11:22:33:44:10", IfIndex: 1}}
+	input.MacAddresses = map[string]string{
+		"10.0.0.10": "00:11:22:33:44:10",
+		"10.0.0.11": "00:11:22:33:44:10",
+	}
+
+	for range 20 {
+		for _, edge := range Build(input, 0).Edges {
+			if edge.Kind == EdgeFdb && edge.Target != "host:10.0.0.10" {
+				t.Fatalf("expected the shared MAC address to go to the first host, got %+v", edge)
+			}
+		}
+	}
+}
+
+func TestBuildNetworkFilter(t *testing.T) {
+	graph := Build(testInput(), 2)
+	if len(graph.Nodes) != 0 || len(graph.Edges) != 0 {
+		t.Errorf("expected an empty graph, got %d nodes and %d edges", len(graph.Nodes), len(graph.Edges))
+	}
+}
+
+func TestEncoders(t *testing.T) {
+	graph := Build(testInput(), 0)
+
+	var dot bytes.Buffer
+	if err := WriteDOT(&dot, graph); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(dot.String(), `"host:10.0.0.1" -- "host:10.0.0.2"`) {
+		t.Errorf("missing lldp edge in dot output:\n%s", dot.String())
+	}
+
+	var graphml bytes.Buffer
+	if err := WriteGraphML(&graphml, graph); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(graphml.String(), `<node id="device:aa:bb:cc:00:00:01">`) {
+		t.Errorf("missing device node in graphml output:\n%s", graphml.String())
+	}
+}
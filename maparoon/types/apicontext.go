@@ -13,6 +13,9 @@ type ApiContext struct {
 	// Querier is the database interface
 	Querier *database.Queries
 
+	// Database runs queries that must succeed or fail together in a transaction
+	Database *database.DbWrapper
+
 	// Searcher is the search index interface
 	Searcher *search.BleveSearcher
 }
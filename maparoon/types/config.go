@@ -17,11 +17,71 @@ type Config struct {
 		IndexDir string `json:"index_dir" envconfig:"INDEX_DIR" default:"index"`
 	}
 
+	Auth struct {
+		// Disabled turns off authentication, every request is treated as an admin's
+		Disabled bool `json:"disabled" envconfig:"AUTH_DISABLED" default:"false"`
+		// AdminToken grants full access, including creating named API tokens
+		AdminToken string `json:"admin_token" envconfig:"AUTH_ADMIN_TOKEN"`
+		// ReadOnlyToken may only make GET requests
+		ReadOnlyToken string `json:"read_only_token" envconfig:"AUTH_READ_ONLY_TOKEN"`
+	} `json:"auth"`
+
+	Secrets struct {
+		// Key encrypts the credentials stored in the database, e.g. SNMP
+		// communities. Changing it makes the stored credentials unreadable.
+		Key string `json:"key" envconfig:"SECRETS_KEY"`
+	} `json:"secrets"`
+
+	Leases struct {
+		// Duration is how long a worker holds a network after claiming it or
+		// heartbeating, before another worker may claim it
+		Duration time.Duration `json:"duration" envconfig:"LEASE_DURATION" default:"5m"`
+	} `json:"leases"`
+
+	Scans struct {
+		// StaleAfter fails a network scan that is still running this long after
+		// it started and isn't held by a live lease, so it stops looking busy
+		StaleAfter time.Duration `json:"stale_after" envconfig:"SCAN_STALE_AFTER" default:"6h"`
+	} `json:"scans"`
+
+	Hosts struct {
+		// ExpireAfterMissedScans marks a host gone once this many network scans
+		// in a row haven't found it, zero disables it
+		ExpireAfterMissedScans int64 `json:"expire_after_missed_scans" envconfig:"HOST_EXPIRE_AFTER_MISSED_SCANS" default:"0"`
+		// ExpireAfter marks a host gone once it hasn't been seen for this long,
+		// zero disables it
+		ExpireAfter time.Duration `json:"expire_after" envconfig:"HOST_EXPIRE_AFTER" default:"0"`
+		// DeleteExpired deletes expired hosts instead of marking them gone
+		DeleteExpired bool `json:"delete_expired" envconfig:"HOST_DELETE_EXPIRED" default:"false"`
+	} `json:"hosts"`
+
+	Alerting struct {
+		WebhookTimeout time.Duration `json:"webhook_timeout" envconfig:"ALERT_WEBHOOK_TIMEOUT" default:"10s"`
+
+		Smtp struct {
+			Host     string `json:"host" envconfig:"ALERT_SMTP_HOST"`
+			Port     int    `json:"port" envconfig:"ALERT_SMTP_PORT" default:"25"`
+			Username string `json:"username" envconfig:"ALERT_SMTP_USERNAME"`
+			Password string `json:"password" envconfig:"ALERT_SMTP_PASSWORD"`
+			From     string `json:"from" envconfig:"ALERT_SMTP_FROM" default:"maparoon@localhost"`
+			// Timeout bounds each email delivery, from connecting to the server to QUIT
+			Timeout time.Duration `json:"timeout" envconfig:"ALERT_SMTP_TIMEOUT" default:"30s"`
+		} `json:"smtp"`
+	} `json:"alerting"`
+
 	Worker struct {
 		BaseURL             string        `json:"base_url" envconfig:"BASE_URL" default:"http://localhost:8000"`
 		ReverseDNSResolvers []string      `json:"reverse_dns_resolvers" envconfig:"REVERSE_DNS_RESOLVERS" default:""`
 		ScanInterval        time.Duration `json:"scan_interval" envconfig:"SCAN_INTERVAL" default:"30m"`
 		Token               string        `json:"token" envconfig:"WORKER_TOKEN"`
+		// Id tells apart the workers sharing a token, leases are held by the
+		// token's principal and the worker's id. Defaults to the hostname.
+		Id string `json:"id" envconfig:"WORKER_ID"`
+		// Labels pin networks to this worker, a network with a `worker_label`
+		// attribute is only scanned by workers with that label
+		Labels            []string      `json:"labels" envconfig:"WORKER_LABELS" default:""`
+		PollInterval      time.Duration `json:"poll_interval" envconfig:"POLL_INTERVAL" default:"1m"`
+		HeartbeatInterval time.Duration `json:"heartbeat_interval" envconfig:"HEARTBEAT_INTERVAL" default:"1m"`
 
 		Concurrent struct {
 			IndexLimit       int `json:"index_limit" envconfig:"CONCURRENT_INDEX_LIMIT" default:"4"`
@@ -29,7 +89,11 @@ type Config struct {
 		} `json:"concurrent"`
 
 		Snmp struct {
+			// Community is tried on hosts that no stored SNMP credential works for
 			Community string `json:"community" envconfig:"SNMP_COMMUNITY" default:"public"`
+			// Walks are the tables walked on hosts that answer SNMP, out of
+			// interfaces, arp, fdb and lldp
+			Walks []string `json:"walks" envconfig:"SNMP_WALKS" default:"interfaces,arp,lldp"`
 		}
 	} `json:"worker"`
 }
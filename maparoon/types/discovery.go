@@ -0,0 +1,12 @@
+package types
+
+// Host attributes set when a host is passively discovered
+const (
+	HostnameAttribute = "hostname"
+	// LastSeenAttribute is the unix time the host was last seen by a discovery
+	// source
+	LastSeenAttribute = "last_seen"
+	// DiscoverySourceAttribute is the comma separated discovery sources that
+	// have seen the host
+	DiscoverySourceAttribute = "discovery_source"
+)
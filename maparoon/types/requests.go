@@ -27,12 +27,20 @@ type SnmpMeasurement struct {
 type SnmpHostScanDocument struct {
 	Available    bool                       `json:"available"`
 	Measurements map[string]SnmpMeasurement `json:"measurements"`
+	// The walked tables, nil if the table wasn't walked
+	Interfaces []SnmpInterface `json:"interfaces"`
+	ArpEntries []SnmpArpEntry  `json:"arp_entries"`
+	FdbEntries []SnmpFdbEntry  `json:"fdb_entries"`
+	Neighbors  []SnmpNeighbor  `json:"neighbors"`
 }
 
 type SnmpHostScan struct {
 	SnmpHostScanDocument
 
 	Address string `json:"address"`
+	// CredentialId is the stored credential that worked, 0 if none did or the
+	// worker's community was used
+	CredentialId int64 `json:"credential_id,omitempty"`
 }
 
 type HostScanDocument struct {
@@ -40,9 +48,93 @@ type HostScanDocument struct {
 	Network database.Network      `json:"network"`
 	Nmap    *NmapHostScanDocument `json:"nmap"`
 	Snmp    *SnmpHostScanDocument `json:"snmp"`
+	ScanId  int64                 `json:"scan_id,omitempty"`
+	// Vendor and DeviceClass are filled in from the host's classification when
+	// the scan is indexed
+	Vendor      string `json:"vendor,omitempty"`
+	DeviceClass string `json:"device_class,omitempty"`
+	// OpenPorts are the ports host discovery found open, whether or not nmap
+	// scanned them
+	OpenPorts []DiscoveredPort `json:"open_ports,omitempty"`
+	// Summary is derived from the scan when it's indexed, for faceted search
+	Summary *HostScanSummary `json:"summary,omitempty"`
+}
+
+type HostScanSummary struct {
+	// OpenPorts are the open ports as `port/protocol`, from host discovery
+	// and nmap
+	OpenPorts []string `json:"open_ports"`
+	Services  []string `json:"services"`
+	Products  []string `json:"products"`
+	// Os is nmap's most accurate OS match
+	Os string `json:"os"`
+}
+
+type DiscoveredPort struct {
+	Port     int64  `json:"port"`
+	Protocol string `json:"protocol"`
 }
 
 type CreateHostScansRequest struct {
 	HostScans []*HostScanDocument `json:"host_scans"`
 	NetworkId int64               `json:"network_id"`
+	// ScanId is the network scan the host scans belong to. When set, the scan is
+	// finished and its results are kept for change detection.
+	ScanId int64 `json:"scan_id,omitempty"`
+	// WorkerId is the worker holding the scan's lease, if it's leased
+	WorkerId string `json:"worker_id,omitempty"`
+}
+
+type CreateApiTokenRequest struct {
+	Name string `json:"name"`
+	Role string `json:"role"`
+}
+
+type SetNetworkAttributeRequest struct {
+	Value string `json:"value"`
+}
+
+type SetHostAttributeRequest struct {
+	Value string `json:"value"`
+}
+
+// ImportHostsRequest adds hosts to a network that can't be swept, like an IPv6
+// network. Hostnames are resolved and only their addresses inside the network
+// are added.
+type ImportHostsRequest struct {
+	Addresses []string `json:"addresses"`
+	Hostnames []string `json:"hostnames"`
+}
+
+type ClaimScanLeasesRequest struct {
+	// WorkerId identifies the worker the leases are held by
+	WorkerId string `json:"worker_id"`
+	// Labels are the worker's labels, which networks pinned to a label need
+	Labels []string `json:"labels"`
+	// ScanInterval is how long ago, in seconds, a network must last have been
+	// scanned to be claimed
+	ScanInterval int64 `json:"scan_interval"`
+	// Limit is the most networks to claim at once
+	Limit int64 `json:"limit"`
+}
+
+// ScanLeaseGrant is a claimed network, along with the scan to submit its
+// results to and the profile to scan it with
+type ScanLeaseGrant struct {
+	Lease   database.ScanLease   `json:"lease"`
+	Network database.Network     `json:"network"`
+	Scan    database.NetworkScan `json:"scan"`
+	Profile database.ScanProfile `json:"profile"`
+	// SnmpCredentials are the credentials to try on the network's hosts, in
+	// order. KnownSnmpCredentials maps hosts to the credential that last worked.
+	SnmpCredentials      []SnmpCredential `json:"snmp_credentials"`
+	KnownSnmpCredentials map[string]int64 `json:"known_snmp_credentials"`
+	// Targets are the addresses to scan when the network is too large to
+	// sweep, the known hosts and the addresses seen in neighbor tables
+	Targets []string `json:"targets"`
+}
+
+type HeartbeatScanLeaseRequest struct {
+	ScanId   int64  `json:"scan_id"`
+	WorkerId string `json:"worker_id"`
 }
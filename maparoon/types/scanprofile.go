@@ -0,0 +1,69 @@
+package types
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/pirogoeth/apps/maparoon/database"
+)
+
+const (
+	ScanTypeConnect = "connect"
+	ScanTypeSyn     = "syn"
+	// ScanTypePing only discovers hosts, without scanning their ports
+	ScanTypePing = "ping"
+)
+
+// DefaultScanProfile is used for networks that don't have a `scan_profile`
+// attribute
+var DefaultScanProfile = database.ScanProfile{
+	Name:        "default",
+	ScanType:    ScanTypeConnect,
+	TopPorts:    "100",
+	NmapFlags:   "-A -O -sV",
+	SnmpEnabled: true,
+}
+
+// nmapScriptsPattern is what a profile's `--script` argument may look like, a
+// comma separated list of script names and categories
+var nmapScriptsPattern = regexp.MustCompile(`^[A-Za-z0-9_,.-]+$`)
+
+// ValidateScanProfile checks the parts of a profile that the worker can't
+// recover from at scan time
+func ValidateScanProfile(profile database.ScanProfile) error {
+	if profile.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+
+	switch profile.ScanType {
+	case ScanTypeConnect, ScanTypeSyn, ScanTypePing:
+	default:
+		return fmt.Errorf("unknown scan type: %s", profile.ScanType)
+	}
+
+	if profile.Ports == "" && profile.TopPorts == "" && profile.ScanType != ScanTypePing {
+		return fmt.Errorf("one of ports or top_ports is required for a %s scan", profile.ScanType)
+	}
+
+	for _, flag := range strings.Fields(profile.NmapFlags) {
+		// The worker reads nmap's results from the output it chooses
+		if strings.HasPrefix(flag, "-o") {
+			return fmt.Errorf("nmap output flags aren't allowed: %s", flag)
+		}
+	}
+
+	// The scripts are added to the nmap command line
+	if profile.NmapScripts != "" && !nmapScriptsPattern.MatchString(profile.NmapScripts) {
+		return fmt.Errorf("invalid nmap scripts: %s", profile.NmapScripts)
+	}
+
+	if profile.Rate < 0 {
+		return fmt.Errorf("rate can't be negative")
+	}
+	if profile.ScanInterval < 0 {
+		return fmt.Errorf("scan interval can't be negative")
+	}
+
+	return nil
+}
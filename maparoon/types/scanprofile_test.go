@@ -0,0 +1,41 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/pirogoeth/apps/maparoon/database"
+)
+
+func TestValidateScanProfile(t *testing.T) {
+	gentle := database.ScanProfile{Name: "ot", ScanType: ScanTypePing, SnmpEnabled: false}
+	withOutput := DefaultScanProfile
+	withOutput.NmapFlags = "-sV -oN /tmp/out"
+	noPorts := DefaultScanProfile
+	noPorts.TopPorts = ""
+	unknownType := DefaultScanProfile
+	unknownType.ScanType = "udp"
+	withScripts := DefaultScanProfile
+	withScripts.NmapScripts = "default,snmp-info,ssl-enum-ciphers"
+	injectedScripts := DefaultScanProfile
+	injectedScripts.NmapScripts = "default -oN /tmp/out"
+
+	cases := []struct {
+		name    string
+		profile database.ScanProfile
+		valid   bool
+	}{
+		{"default", DefaultScanProfile, true},
+		{"ping only without ports", gentle, true},
+		{"nmap output flags", withOutput, false},
+		{"port scan without ports", noPorts, false},
+		{"unknown scan type", unknownType, false},
+		{"nmap scripts", withScripts, true},
+		{"nmap scripts with flags", injectedScripts, false},
+	}
+
+	for _, c := range cases {
+		if err := ValidateScanProfile(c.profile); (err == nil) != c.valid {
+			t.Errorf("%s: expected valid=%v, got %v", c.name, c.valid, err)
+		}
+	}
+}
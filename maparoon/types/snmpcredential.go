@@ -0,0 +1,92 @@
+package types
+
+import (
+	"fmt"
+	"slices"
+)
+
+// SnmpCredentialAttribute is the host attribute remembering the id of the
+// credential that last worked for the host
+const SnmpCredentialAttribute = "snmp_credential"
+
+const (
+	SnmpVersion1  = "1"
+	SnmpVersion2c = "2c"
+	SnmpVersion3  = "3"
+)
+
+var (
+	SnmpAuthProtocols = []string{"md5", "sha", "sha224", "sha256", "sha384", "sha512"}
+	SnmpPrivProtocols = []string{"des", "aes", "aes192", "aes256", "aes192c", "aes256c"}
+)
+
+// SnmpCredentialRequest creates or updates an SNMP credential. On update, the
+// secrets that are left out or sent back redacted are kept.
+type SnmpCredentialRequest struct {
+	Name string `json:"name"`
+	// NetworkId and Address scope the credential to a network or a single host,
+	// it's tried on every host if neither is set
+	NetworkId      int64  `json:"network_id"`
+	Address        string `json:"address"`
+	Priority       int64  `json:"priority"`
+	Version        string `json:"version"`
+	Community      string `json:"community"`
+	Username       string `json:"username"`
+	AuthProtocol   string `json:"auth_protocol"`
+	AuthPassphrase string `json:"auth_passphrase"`
+	PrivProtocol   string `json:"priv_protocol"`
+	PrivPassphrase string `json:"priv_passphrase"`
+}
+
+// SnmpCredential is a decrypted credential, as handed to the worker scanning
+// a network
+type SnmpCredential struct {
+	ID             int64  `json:"id"`
+	Address        string `json:"address"`
+	Version        string `json:"version"`
+	Community      string `json:"community,omitempty"`
+	Username       string `json:"username,omitempty"`
+	AuthProtocol   string `json:"auth_protocol,omitempty"`
+	AuthPassphrase string `json:"auth_passphrase,omitempty"`
+	PrivProtocol   string `json:"priv_protocol,omitempty"`
+	PrivPassphrase string `json:"priv_passphrase,omitempty"`
+}
+
+// ValidateSnmpCredential checks that the credential is complete for its version
+func ValidateSnmpCredential(credential SnmpCredential) error {
+	switch credential.Version {
+	case SnmpVersion1, SnmpVersion2c:
+		if credential.Community == "" {
+			return fmt.Errorf("community is required for snmp v%s", credential.Version)
+		}
+	case SnmpVersion3:
+		if credential.Username == "" {
+			return fmt.Errorf("username is required for snmp v3")
+		}
+
+		if credential.AuthProtocol != "" {
+			if !slices.Contains(SnmpAuthProtocols, credential.AuthProtocol) {
+				return fmt.Errorf("unknown auth protocol: %s", credential.AuthProtocol)
+			}
+			if credential.AuthPassphrase == "" {
+				return fmt.Errorf("auth passphrase is required with auth protocol %s", credential.AuthProtocol)
+			}
+		}
+
+		if credential.PrivProtocol != "" {
+			if credential.AuthProtocol == "" {
+				return fmt.Errorf("priv protocol requires an auth protocol")
+			}
+			if !slices.Contains(SnmpPrivProtocols, credential.PrivProtocol) {
+				return fmt.Errorf("unknown priv protocol: %s", credential.PrivProtocol)
+			}
+			if credential.PrivPassphrase == "" {
+				return fmt.Errorf("priv passphrase is required with priv protocol %s", credential.PrivProtocol)
+			}
+		}
+	default:
+		return fmt.Errorf("unknown snmp version: %s", credential.Version)
+	}
+
+	return nil
+}
@@ -0,0 +1,28 @@
+package types
+
+import (
+	"testing"
+)
+
+func TestValidateSnmpCredential(t *testing.T) {
+	cases := []struct {
+		name       string
+		credential SnmpCredential
+		valid      bool
+	}{
+		{"v2c community", SnmpCredential{Version: SnmpVersion2c, Community: "public"}, true},
+		{"v1 without community", SnmpCredential{Version: SnmpVersion1}, false},
+		{"v3 noAuthNoPriv", SnmpCredential{Version: SnmpVersion3, Username: "monitor"}, true},
+		{"v3 authPriv", SnmpCredential{Version: SnmpVersion3, Username: "monitor", AuthProtocol: "sha256", AuthPassphrase: "a", PrivProtocol: "aes", PrivPassphrase: "b"}, true},
+		{"v3 priv without auth", SnmpCredential{Version: SnmpVersion3, Username: "monitor", PrivProtocol: "aes", PrivPassphrase: "b"}, false},
+		{"v3 auth without passphrase", SnmpCredential{Version: SnmpVersion3, Username: "monitor", AuthProtocol: "sha"}, false},
+		{"v3 unknown auth protocol", SnmpCredential{Version: SnmpVersion3, Username: "monitor", AuthProtocol: "crc32", AuthPassphrase: "a"}, false},
+		{"unknown version", SnmpCredential{Version: "4", Community: "public"}, false},
+	}
+
+	for _, c := range cases {
+		if err := ValidateSnmpCredential(c.credential); (err == nil) != c.valid {
+			t.Errorf("%s: expected valid=%v, got %v", c.name, c.valid, err)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package types
+
+const (
+	SnmpWalkInterfaces = "interfaces"
+	SnmpWalkArp        = "arp"
+	SnmpWalkFdb        = "fdb"
+	SnmpWalkLldp       = "lldp"
+)
+
+// MacAddressAttribute is the host attribute holding the host's MAC address, as
+// learned from the ARP tables of other hosts
+const MacAddressAttribute = "mac_address"
+
+// SnmpInterface is a row of IF-MIB's ifTable, joined with ifXTable
+type SnmpInterface struct {
+	Index int64  `json:"index"`
+	Name  string `json:"name"`
+	Descr string `json:"descr"`
+	Alias string `json:"alias"`
+	Type  int64  `json:"type"`
+	Mtu   int64  `json:"mtu"`
+	// Speed is in Mbit/s
+	Speed       int64  `json:"speed"`
+	MacAddress  string `json:"mac_address"`
+	AdminStatus string `json:"admin_status"`
+	OperStatus  string `json:"oper_status"`
+}
+
+// SnmpArpEntry is a row of IP-MIB's ipNetToMediaTable
+type SnmpArpEntry struct {
+	IfIndex    int64  `json:"if_index"`
+	IpAddress  string `json:"ip_address"`
+	MacAddress string `json:"mac_address"`
+}
+
+// SnmpFdbEntry is a MAC address learned on a bridge port, from BRIDGE-MIB's
+// dot1dTpFdbTable
+type SnmpFdbEntry struct {
+	MacAddress string `json:"mac_address"`
+	BridgePort int64  `json:"bridge_port"`
+	// IfIndex is 0 if the bridge port isn't mapped to an interface
+	IfIndex int64 `json:"if_index"`
+}
+
+// SnmpNeighbor is a remote system seen on a local port, from LLDP-MIB's
+// lldpRemTable and lldpRemManAddrTable
+type SnmpNeighbor struct {
+	// LocalPort is the lldpLocPortNum, which isn't necessarily the ifIndex.
+	// LocalPortId is usually the interface's ifName.
+	LocalPort         int64  `json:"local_port"`
+	LocalPortId       string `json:"local_port_id"`
+	LocalPortDescr    string `json:"local_port_descr"`
+	ChassisId         string `json:"chassis_id"`
+	PortId            string `json:"port_id"`
+	PortDescr         string `json:"port_descr"`
+	SysName           string `json:"sys_name"`
+	SysDescr          string `json:"sys_descr"`
+	ManagementAddress string `json:"management_address"`
+}
@@ -0,0 +1,31 @@
+package types
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// workerIdPattern is what a worker id may look like, e.g. a hostname
+var workerIdPattern = regexp.MustCompile(`^[A-Za-z0-9_.:-]{1,64}$`)
+
+// ValidateWorkerId checks the id a worker holds its scan leases under
+func ValidateWorkerId(id string) error {
+	if !workerIdPattern.MatchString(id) {
+		return fmt.Errorf("invalid worker id %q, expected up to 64 letters, digits and _.:-", id)
+	}
+
+	return nil
+}
+
+// ValidateWorkerConfig checks the worker's configuration before it starts
+func ValidateWorkerConfig(cfg *Config) error {
+	if err := ValidateWorkerId(cfg.Worker.Id); err != nil {
+		return err
+	}
+
+	if cfg.Worker.HeartbeatInterval <= 0 {
+		return fmt.Errorf("heartbeat interval must be positive, got %s", cfg.Worker.HeartbeatInterval)
+	}
+
+	return nil
+}
@@ -0,0 +1,31 @@
+package types
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidateWorkerConfig(t *testing.T) {
+	cases := []struct {
+		name      string
+		id        string
+		heartbeat time.Duration
+		valid     bool
+	}{
+		{"hostname", "scanner-01.lan", time.Minute, true},
+		{"missing id", "", time.Minute, false},
+		{"id with spaces", "scanner 01", time.Minute, false},
+		{"zero heartbeat interval", "scanner-01", 0, false},
+		{"negative heartbeat interval", "scanner-01", -time.Second, false},
+	}
+
+	for _, c := range cases {
+		cfg := &Config{}
+		cfg.Worker.Id = c.id
+		cfg.Worker.HeartbeatInterval = c.heartbeat
+
+		if err := ValidateWorkerConfig(cfg); (err == nil) != c.valid {
+			t.Errorf("%s: expected valid %t, got error %v", c.name, c.valid, err)
+		}
+	}
+}
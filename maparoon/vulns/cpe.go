@@ -0,0 +1,86 @@
+// Package vulns matches the services nmap identifies against known
+// vulnerabilities imported from NVD CVE feeds
+package vulns
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Cpe is the part of a CPE name vulnerabilities are matched on
+type Cpe struct {
+	Part    string
+	Vendor  string
+	Product string
+	// Version and Update are "*" when any value matches and "-" when there is
+	// none
+	Version string
+	Update  string
+}
+
+// ParseCpe parses a CPE 2.3 formatted string, as NVD uses, or a CPE 2.2 URI,
+// as nmap reports
+func ParseCpe(name string) (Cpe, error) {
+	var fields []string
+	switch {
+	case strings.HasPrefix(name, "cpe:2.3:"):
+		fields = splitFormatted(strings.TrimPrefix(name, "cpe:2.3:"))
+	case strings.HasPrefix(name, "cpe:/"):
+		for _, field := range strings.Split(strings.TrimPrefix(name, "cpe:/"), ":") {
+			unescaped, err := url.PathUnescape(field)
+			if err != nil {
+				return Cpe{}, fmt.Errorf("invalid CPE %s: %w", name, err)
+			}
+			fields = append(fields, unescaped)
+		}
+	default:
+		return Cpe{}, fmt.Errorf("invalid CPE %s: unknown format", name)
+	}
+
+	if len(fields) < 3 || fields[1] == "" || fields[2] == "" {
+		return Cpe{}, fmt.Errorf("invalid CPE %s: missing vendor or product", name)
+	}
+
+	cpe := Cpe{
+		Part:    strings.ToLower(fields[0]),
+		Vendor:  strings.ToLower(fields[1]),
+		Product: strings.ToLower(fields[2]),
+		Version: "*",
+		Update:  "*",
+	}
+	if len(fields) > 3 && fields[3] != "" {
+		cpe.Version = strings.ToLower(fields[3])
+	}
+	if len(fields) > 4 && fields[4] != "" {
+		cpe.Update = strings.ToLower(fields[4])
+	}
+
+	return cpe, nil
+}
+
+// splitFormatted splits a CPE 2.3 formatted string on the colons that aren't
+// escaped, removing the escapes
+func splitFormatted(name string) []string {
+	var (
+		fields  []string
+		field   strings.Builder
+		escaped bool
+	)
+	for _, r := range name {
+		switch {
+		case escaped:
+			field.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == ':':
+			fields = append(fields, field.String())
+			field.Reset()
+		default:
+			field.WriteRune(r)
+		}
+	}
+
+	return append(fields, field.String())
+}
@@ -0,0 +1,70 @@
+package vulns
+
+import (
+	"strings"
+
+	"github.com/pirogoeth/apps/maparoon/database"
+)
+
+// Matches reports whether a service's CPE is one of a vulnerability's
+// vulnerable CPEs. A service without a version never matches, as which
+// versions are vulnerable is nearly always what matters.
+func Matches(vulnerable database.VulnerabilityCpe, cpe Cpe) bool {
+	if vulnerable.Part != cpe.Part || vulnerable.Vendor != cpe.Vendor || vulnerable.Product != cpe.Product {
+		return false
+	}
+
+	if cpe.Version == "*" || cpe.Version == "-" {
+		return false
+	}
+
+	version := cpe.Version
+	if cpe.Update != "*" && cpe.Update != "-" {
+		version += cpe.Update
+	}
+
+	switch vulnerable.Version {
+	case "-":
+		return false
+	case "*", "":
+		return inRange(vulnerable, version)
+	}
+
+	want := vulnerable.Version
+	if vulnerable.VersionUpdate == "*" || vulnerable.VersionUpdate == "-" || vulnerable.VersionUpdate == "" {
+		// Without an update, any update of the version matches, e.g. 7.4
+		// matches 7.4p1
+		return CompareVersions(version, want) == 0 || hasVersionPrefix(version, want)
+	}
+
+	return CompareVersions(version, want+vulnerable.VersionUpdate) == 0
+}
+
+func inRange(vulnerable database.VulnerabilityCpe, version string) bool {
+	if bound := vulnerable.VersionStartIncluding; bound != "" && CompareVersions(version, bound) < 0 {
+		return false
+	}
+	if bound := vulnerable.VersionStartExcluding; bound != "" && CompareVersions(version, bound) <= 0 {
+		return false
+	}
+	if bound := vulnerable.VersionEndIncluding; bound != "" && CompareVersions(version, bound) > 0 {
+		return false
+	}
+	if bound := vulnerable.VersionEndExcluding; bound != "" && CompareVersions(version, bound) >= 0 {
+		return false
+	}
+
+	return true
+}
+
+// hasVersionPrefix is whether version is prefix followed by a suffix that
+// isn't a further version number, e.g. 7.4p1 has the prefix 7.4 but 7.41 and
+// 7.4.1 don't
+func hasVersionPrefix(version, prefix string) bool {
+	if !strings.HasPrefix(version, prefix) || len(version) == len(prefix) {
+		return false
+	}
+
+	next := version[len(prefix)]
+	return next != '.' && (next < '0' || next > '9')
+}
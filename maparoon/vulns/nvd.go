@@ -0,0 +1,200 @@
+package vulns
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Vulnerability is a CVE read from an NVD feed
+type Vulnerability struct {
+	Id          string
+	Description string
+	// Severity is the lowercased CVSS severity, e.g. critical
+	Severity  string
+	Score     float64
+	Published string
+	// Cpes are the CPEs the CVE's configurations list as vulnerable. A CPE
+	// that's only vulnerable in combination with another, e.g. when running
+	// on a particular OS, is still listed on its own.
+	Cpes []CpeMatch
+}
+
+type CpeMatch struct {
+	Criteria              string `json:"criteria"`
+	Vulnerable            bool   `json:"vulnerable"`
+	VersionStartIncluding string `json:"versionStartIncluding"`
+	VersionStartExcluding string `json:"versionStartExcluding"`
+	VersionEndIncluding   string `json:"versionEndIncluding"`
+	VersionEndExcluding   string `json:"versionEndExcluding"`
+}
+
+type nvdCvssMetric struct {
+	Type     string `json:"type"`
+	CvssData struct {
+		BaseScore    float64 `json:"baseScore"`
+		BaseSeverity string  `json:"baseSeverity"`
+	} `json:"cvssData"`
+	// BaseSeverity is outside cvssData for CVSS v2
+	BaseSeverity string `json:"baseSeverity"`
+}
+
+type nvdCve struct {
+	Id           string `json:"id"`
+	Published    string `json:"published"`
+	Descriptions []struct {
+		Lang  string `json:"lang"`
+		Value string `json:"value"`
+	} `json:"descriptions"`
+	Metrics struct {
+		CvssMetricV31 []nvdCvssMetric `json:"cvssMetricV31"`
+		CvssMetricV30 []nvdCvssMetric `json:"cvssMetricV30"`
+		CvssMetricV2  []nvdCvssMetric `json:"cvssMetricV2"`
+	} `json:"metrics"`
+	Configurations []struct {
+		Nodes []struct {
+			CpeMatch []CpeMatch `json:"cpeMatch"`
+		} `json:"nodes"`
+	} `json:"configurations"`
+}
+
+// ReadFeedFile reads an NVD CVE feed file, gzipped if its name ends in .gz
+func ReadFeedFile(path string, fn func(Vulnerability) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return fmt.Errorf("could not decompress %s: %w", path, err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	if err := ReadFeed(r, fn); err != nil {
+		return fmt.Errorf("could not read %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// ReadFeed reads a feed in the NVD CVE API 2.0 format, as the NVD JSON 2.0
+// feeds and API responses are, calling fn with each vulnerability in turn
+func ReadFeed(r io.Reader, fn func(Vulnerability) error) error {
+	decoder := json.NewDecoder(r)
+	if err := expectDelim(decoder, '{'); err != nil {
+		return err
+	}
+
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+
+		if token != "vulnerabilities" {
+			var skipped json.RawMessage
+			if err := decoder.Decode(&skipped); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := expectDelim(decoder, '['); err != nil {
+			return err
+		}
+
+		for decoder.More() {
+			var item struct {
+				Cve nvdCve `json:"cve"`
+			}
+			if err := decoder.Decode(&item); err != nil {
+				return err
+			}
+
+			if err := fn(item.Cve.vulnerability()); err != nil {
+				return err
+			}
+		}
+
+		if err := expectDelim(decoder, ']'); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func expectDelim(decoder *json.Decoder, delim json.Delim) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+
+	if token != delim {
+		return fmt.Errorf("expected %s in feed, got %v", delim, token)
+	}
+
+	return nil
+}
+
+func (c nvdCve) vulnerability() Vulnerability {
+	vulnerability := Vulnerability{
+		Id:        c.Id,
+		Published: c.Published,
+	}
+
+	for _, description := range c.Descriptions {
+		if description.Lang == "en" {
+			vulnerability.Description = description.Value
+			break
+		}
+	}
+
+	for _, metrics := range [][]nvdCvssMetric{c.Metrics.CvssMetricV31, c.Metrics.CvssMetricV30, c.Metrics.CvssMetricV2} {
+		if metric, ok := primaryMetric(metrics); ok {
+			vulnerability.Score = metric.CvssData.BaseScore
+			vulnerability.Severity = metric.CvssData.BaseSeverity
+			if vulnerability.Severity == "" {
+				vulnerability.Severity = metric.BaseSeverity
+			}
+			vulnerability.Severity = strings.ToLower(vulnerability.Severity)
+			break
+		}
+	}
+
+	for _, configuration := range c.Configurations {
+		for _, node := range configuration.Nodes {
+			for _, match := range node.CpeMatch {
+				if match.Vulnerable {
+					vulnerability.Cpes = append(vulnerability.Cpes, match)
+				}
+			}
+		}
+	}
+
+	return vulnerability
+}
+
+// primaryMetric prefers NVD's own scoring over other sources'
+func primaryMetric(metrics []nvdCvssMetric) (nvdCvssMetric, bool) {
+	for _, metric := range metrics {
+		if metric.Type == "Primary" {
+			return metric, true
+		}
+	}
+
+	if len(metrics) > 0 {
+		return metrics[0], true
+	}
+
+	return nvdCvssMetric{}, false
+}
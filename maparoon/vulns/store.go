@@ -0,0 +1,140 @@
+package vulns
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/pirogoeth/apps/maparoon/database"
+	"github.com/pirogoeth/apps/maparoon/types"
+)
+
+// Store saves the vulnerability, replacing its vulnerable CPEs if it was
+// imported before
+func Store(ctx context.Context, querier *database.Queries, vulnerability Vulnerability) error {
+	err := querier.SetVulnerability(ctx, database.SetVulnerabilityParams{
+		ID:          vulnerability.Id,
+		Description: vulnerability.Description,
+		Severity:    vulnerability.Severity,
+		Score:       vulnerability.Score,
+		Published:   vulnerability.Published,
+	})
+	if err != nil {
+		return fmt.Errorf("could not store %s: %w", vulnerability.Id, err)
+	}
+
+	if err := querier.DeleteVulnerabilityCpes(ctx, vulnerability.Id); err != nil {
+		return fmt.Errorf("could not delete CPEs of %s: %w", vulnerability.Id, err)
+	}
+
+	for _, match := range vulnerability.Cpes {
+		cpe, err := ParseCpe(match.Criteria)
+		if err != nil {
+			logrus.Debugf("skipping CPE of %s: %s", vulnerability.Id, err)
+			continue
+		}
+
+		err = querier.CreateVulnerabilityCpe(ctx, database.CreateVulnerabilityCpeParams{
+			VulnerabilityID:       vulnerability.Id,
+			Part:                  cpe.Part,
+			Vendor:                cpe.Vendor,
+			Product:               cpe.Product,
+			Version:               cpe.Version,
+			VersionUpdate:         cpe.Update,
+			VersionStartIncluding: match.VersionStartIncluding,
+			VersionStartExcluding: match.VersionStartExcluding,
+			VersionEndIncluding:   match.VersionEndIncluding,
+			VersionEndExcluding:   match.VersionEndExcluding,
+			Criteria:              match.Criteria,
+		})
+		if err != nil {
+			return fmt.Errorf("could not store CPE of %s: %w", vulnerability.Id, err)
+		}
+	}
+
+	return nil
+}
+
+// Correlate matches the CPEs nmap reported for the host scan's open ports
+// against the stored vulnerabilities, replacing the host's earlier matches. A
+// host scan without nmap results leaves them alone. It returns the number of
+// vulnerabilities matched. querier must be a transaction's, so that a failed
+// insert doesn't drop the host's earlier matches.
+func Correlate(ctx context.Context, querier *database.Queries, doc *types.HostScanDocument) (int, error) {
+	if doc.Nmap == nil {
+		return 0, nil
+	}
+
+	if err := querier.DeleteHostPortVulnerabilities(ctx, doc.Address); err != nil {
+		return 0, fmt.Errorf("could not delete vulnerabilities of %s: %w", doc.Address, err)
+	}
+
+	matched := make(map[string]bool)
+	vulnerableCpes := make(map[[2]string][]database.VulnerabilityCpe)
+	for _, port := range doc.Nmap.HostDetails.Ports {
+		if port.State.State != "open" {
+			continue
+		}
+
+		for _, name := range port.Service.CPEs {
+			cpe, err := ParseCpe(string(name))
+			if err != nil {
+				logrus.Debugf("skipping CPE of %s port %d: %s", doc.Address, port.PortId, err)
+				continue
+			}
+			if cpe.Version == "*" {
+				// nmap often leaves the version out of the CPE while
+				// reporting it for the service
+				cpe.Version = serviceVersion(port.Service.Version)
+			}
+
+			product := [2]string{cpe.Vendor, cpe.Product}
+			candidates, ok := vulnerableCpes[product]
+			if !ok {
+				candidates, err = querier.ListVulnerabilityCpesByProduct(ctx, database.ListVulnerabilityCpesByProductParams{
+					Vendor:  cpe.Vendor,
+					Product: cpe.Product,
+				})
+				if err != nil {
+					return len(matched), fmt.Errorf("could not list vulnerable CPEs of %s:%s: %w", cpe.Vendor, cpe.Product, err)
+				}
+				vulnerableCpes[product] = candidates
+			}
+
+			for _, candidate := range candidates {
+				key := fmt.Sprintf("%d/%s %s", port.PortId, port.Protocol, candidate.VulnerabilityID)
+				if matched[key] || !Matches(candidate, cpe) {
+					continue
+				}
+
+				err := querier.CreateHostPortVulnerability(ctx, database.CreateHostPortVulnerabilityParams{
+					Address:         doc.Address,
+					Port:            int64(port.PortId),
+					Protocol:        port.Protocol,
+					VulnerabilityID: candidate.VulnerabilityID,
+					Cpe:             string(name),
+				})
+				if err != nil {
+					return len(matched), fmt.Errorf("could not store vulnerability of %s: %w", doc.Address, err)
+				}
+				matched[key] = true
+			}
+		}
+	}
+
+	return len(matched), nil
+}
+
+// serviceVersion returns the version of a service nmap reported, which may be
+// followed by the distribution's package version, e.g. "7.4p1 Debian 10", or
+// "*" if there is none
+func serviceVersion(version string) string {
+	fields := strings.Fields(version)
+	if len(fields) == 0 {
+		return "*"
+	}
+
+	return strings.ToLower(fields[0])
+}
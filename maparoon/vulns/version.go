@@ -0,0 +1,106 @@
+package vulns
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// CompareVersions compares two version strings, returning -1, 0 or 1. They're
+// compared a run of digits or letters at a time, numbers by value, so that
+// 7.10 comes after 7.9 and 7.4p1 after 7.4. A number sorts after letters in
+// the same position, so 1.0.1 comes after 1.0rc1. Pre-release letters like rc
+// or beta sort before the version they precede, so 2.0rc1 comes before 2.0,
+// while other letters are taken as patch levels.
+func CompareVersions(a, b string) int {
+	as, bs := versionSegments(a), versionSegments(b)
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		if c := compareSegments(as[i], bs[i]); c != 0 {
+			return c
+		}
+	}
+
+	switch {
+	case len(as) < len(bs):
+		return -suffixOrder(bs[len(as)])
+	case len(as) > len(bs):
+		return suffixOrder(as[len(bs)])
+	}
+	return 0
+}
+
+// preReleases are the letter segments that mark a version as coming before
+// the version without them
+var preReleases = map[string]bool{
+	"alpha":    true,
+	"beta":     true,
+	"rc":       true,
+	"pre":      true,
+	"preview":  true,
+	"dev":      true,
+	"snapshot": true,
+}
+
+// suffixOrder is how a version compares to its prefix when the rest of it
+// starts with segment
+func suffixOrder(segment string) int {
+	if preReleases[segment] {
+		return -1
+	}
+	return 1
+}
+
+func versionSegments(version string) []string {
+	var (
+		segments []string
+		segment  strings.Builder
+		digits   bool
+	)
+	for _, r := range strings.ToLower(version) {
+		isDigit := unicode.IsDigit(r)
+		if !isDigit && !unicode.IsLetter(r) {
+			if segment.Len() > 0 {
+				segments = append(segments, segment.String())
+				segment.Reset()
+			}
+			continue
+		}
+
+		if segment.Len() > 0 && isDigit != digits {
+			segments = append(segments, segment.String())
+			segment.Reset()
+		}
+		segment.WriteRune(r)
+		digits = isDigit
+	}
+	if segment.Len() > 0 {
+		segments = append(segments, segment.String())
+	}
+
+	return segments
+}
+
+func compareSegments(a, b string) int {
+	an, aErr := strconv.ParseUint(a, 10, 64)
+	bn, bErr := strconv.ParseUint(b, 10, 64)
+	switch {
+	case aErr == nil && bErr == nil:
+		if an < bn {
+			return -1
+		} else if an > bn {
+			return 1
+		}
+		return 0
+	case aErr == nil:
+		return 1
+	case bErr == nil:
+		return -1
+	case preReleases[a] != preReleases[b]:
+		if preReleases[a] {
+			return -1
+		}
+		return 1
+	}
+
+	return strings.Compare(a, b)
+}
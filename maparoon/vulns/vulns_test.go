@@ -0,0 +1,259 @@
+package vulns
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/adifire/go-nmap"
+
+	"github.com/pirogoeth/apps/maparoon/database"
+	"github.com/pirogoeth/apps/maparoon/types"
+)
+
+func TestParseCpe(t *testing.T) {
+	cases := []struct {
+		name     string
+		expected Cpe
+	}{
+		{"cpe:/a:openbsd:openssh:7.4p1", Cpe{"a", "openbsd", "openssh", "7.4p1", "*"}},
+		{"cpe:/o:linux:linux_kernel", Cpe{"o", "linux", "linux_kernel", "*", "*"}},
+		{"cpe:/a:apache:http_server:2.4.%32%39", Cpe{"a", "apache", "http_server", "2.4.29", "*"}},
+		{"cpe:2.3:a:openbsd:openssh:7.4:p1:*:*:*:*:*:*", Cpe{"a", "openbsd", "openssh", "7.4", "p1"}},
+		{`cpe:2.3:a:vendor:prod\:uct:1.0:-:*:*:*:*:*:*`, Cpe{"a", "vendor", "prod:uct", "1.0", "-"}},
+	}
+
+	for _, c := range cases {
+		cpe, err := ParseCpe(c.name)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", c.name, err)
+			continue
+		}
+
+		if cpe != c.expected {
+			t.Errorf("%s: expected %+v, got %+v", c.name, c.expected, cpe)
+		}
+	}
+
+	for _, name := range []string{"openssh", "cpe:/a:openbsd", "cpe:2.3:a::openssh"} {
+		if _, err := ParseCpe(name); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	cases := []struct {
+		a, b     string
+		expected int
+	}{
+		{"7.4", "7.4", 0},
+		{"7.4", "7.5", -1},
+		{"7.10", "7.9", 1},
+		{"7.4p1", "7.4", 1},
+		{"7.4p1", "7.4p2", -1},
+		{"2.0rc1", "2.0", -1},
+		{"2.0", "2.0beta", 1},
+		{"2.0-alpha2", "2.0", -1},
+		{"2.0alpha", "2.0beta", -1},
+		{"2.0rc1", "2.0p1", -1},
+		{"1.0.2a", "1.0.2", 1},
+		{"1.0.1", "1.0rc1", 1},
+		{"2.4.29", "2.4.3", 1},
+		{"1.0-beta", "1.0.beta", 0},
+	}
+
+	for _, c := range cases {
+		if actual := CompareVersions(c.a, c.b); actual != c.expected {
+			t.Errorf("CompareVersions(%q, %q): expected %d, got %d", c.a, c.b, c.expected, actual)
+		}
+	}
+}
+
+func TestMatches(t *testing.T) {
+	ranged := database.VulnerabilityCpe{
+		Part: "a", Vendor: "openbsd", Product: "openssh", Version: "*", VersionUpdate: "*",
+		VersionStartIncluding: "6.2", VersionEndExcluding: "7.5",
+	}
+	exact := database.VulnerabilityCpe{
+		Part: "a", Vendor: "openbsd", Product: "openssh", Version: "7.4", VersionUpdate: "*",
+	}
+	exactUpdate := database.VulnerabilityCpe{
+		Part: "a", Vendor: "openbsd", Product: "openssh", Version: "7.4", VersionUpdate: "p2",
+	}
+
+	cases := []struct {
+		vulnerable database.VulnerabilityCpe
+		cpe        string
+		expected   bool
+	}{
+		{ranged, "cpe:/a:openbsd:openssh:7.4p1", true},
+		{ranged, "cpe:/a:openbsd:openssh:6.2", true},
+		{ranged, "cpe:/a:openbsd:openssh:7.5", false},
+		{ranged, "cpe:/a:openbsd:openssh:7.5rc1", true},
+		{ranged, "cpe:/a:openbsd:openssh:6.1", false},
+		{ranged, "cpe:/a:openbsd:openssh", false},
+		{ranged, "cpe:/a:openbsd:openssl:7.4", false},
+		{exact, "cpe:/a:openbsd:openssh:7.4", true},
+		{exact, "cpe:/a:openbsd:openssh:7.4p1", true},
+		{exact, "cpe:/a:openbsd:openssh:7.41", false},
+		{exact, "cpe:/a:openbsd:openssh:7.4.1", false},
+		{exactUpdate, "cpe:/a:openbsd:openssh:7.4p2", true},
+		{exactUpdate, "cpe:/a:openbsd:openssh:7.4p1", false},
+	}
+
+	for _, c := range cases {
+		cpe, err := ParseCpe(c.cpe)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", c.cpe, err)
+		}
+
+		if actual := Matches(c.vulnerable, cpe); actual != c.expected {
+			t.Errorf("%s against %+v: expected %t, got %t", c.cpe, c.vulnerable, c.expected, actual)
+		}
+	}
+}
+
+const testFeed = `{
+  "resultsPerPage": 2,
+  "format": "NVD_CVE",
+  "version": "2.0",
+  "vulnerabilities": [
+    {
+      "cve": {
+        "id": "CVE-2018-15473",
+        "published": "2018-08-17T19:29:00.000",
+        "descriptions": [
+          {"lang": "es", "value": "OpenSSH hasta 7.7"},
+          {"lang": "en", "value": "OpenSSH through 7.7 is prone to a user enumeration vulnerability."}
+        ],
+        "metrics": {
+          "cvssMetricV31": [
+            {"source": "other", "type": "Secondary", "cvssData": {"baseScore": 7.5, "baseSeverity": "HIGH"}},
+            {"source": "nvd@nist.gov", "type": "Primary", "cvssData": {"baseScore": 5.3, "baseSeverity": "MEDIUM"}}
+          ],
+          "cvssMetricV2": [
+            {"source": "nvd@nist.gov", "type": "Primary", "cvssData": {"baseScore": 5.0}, "baseSeverity": "MEDIUM"}
+          ]
+        },
+        "configurations": [
+          {
+            "nodes": [
+              {
+                "operator": "OR",
+                "cpeMatch": [
+                  {"vulnerable": true, "criteria": "cpe:2.3:a:openbsd:openssh:*:*:*:*:*:*:*:*", "versionEndIncluding": "7.7"},
+                  {"vulnerable": false, "criteria": "cpe:2.3:o:debian:debian_linux:8.0:*:*:*:*:*:*:*"}
+                ]
+              }
+            ]
+          }
+        ]
+      }
+    },
+    {
+      "cve": {
+        "id": "CVE-1999-0001",
+        "descriptions": [{"lang": "en", "value": "An old one"}],
+        "metrics": {
+          "cvssMetricV2": [
+            {"type": "Primary", "cvssData": {"baseScore": 5.0}, "baseSeverity": "MEDIUM"}
+          ]
+        }
+      }
+    }
+  ],
+  "timestamp": "2024-01-01T00:00:00.000"
+}`
+
+func TestReadFeed(t *testing.T) {
+	var vulnerabilities []Vulnerability
+	err := ReadFeed(strings.NewReader(testFeed), func(vulnerability Vulnerability) error {
+		vulnerabilities = append(vulnerabilities, vulnerability)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(vulnerabilities) != 2 {
+		t.Fatalf("expected 2 vulnerabilities, got %d", len(vulnerabilities))
+	}
+
+	openssh := vulnerabilities[0]
+	if openssh.Id != "CVE-2018-15473" || !strings.HasPrefix(openssh.Description, "OpenSSH through 7.7") {
+		t.Errorf("unexpected vulnerability: %+v", openssh)
+	}
+	if openssh.Score != 5.3 || openssh.Severity != "medium" {
+		t.Errorf("expected the primary CVSS 3.1 score, got %.1f %s", openssh.Score, openssh.Severity)
+	}
+	if len(openssh.Cpes) != 1 || openssh.Cpes[0].VersionEndIncluding != "7.7" {
+		t.Errorf("expected only the vulnerable CPE, got %+v", openssh.Cpes)
+	}
+
+	old := vulnerabilities[1]
+	if old.Score != 5.0 || old.Severity != "medium" || len(old.Cpes) != 0 {
+		t.Errorf("unexpected vulnerability: %+v", old)
+	}
+}
+
+func TestCorrelate(t *testing.T) {
+	ctx := context.Background()
+	db, err := database.Open(ctx, "file:TestCorrelate?mode=memory&cache=shared")
+	if err != nil {
+		t.Fatalf("could not open database: %s", err)
+	}
+	defer db.Close()
+	querier := db.Querier()
+
+	err = ReadFeed(strings.NewReader(testFeed), func(vulnerability Vulnerability) error {
+		return Store(ctx, querier, vulnerability)
+	})
+	if err != nil {
+		t.Fatalf("could not store feed: %s", err)
+	}
+
+	network, err := querier.CreateNetwork(ctx, database.CreateNetworkParams{Name: "lan", Address: "10.0.0.0", Cidr: 24})
+	if err != nil {
+		t.Fatalf("could not create network: %s", err)
+	}
+	if _, err := querier.CreateHost(ctx, database.CreateHostParams{NetworkID: network.ID, Address: "10.0.0.5"}); err != nil {
+		t.Fatalf("could not create host: %s", err)
+	}
+
+	port := func(id int, version string, cpe nmap.CPE) nmap.Port {
+		return nmap.Port{
+			Protocol: "tcp",
+			PortId:   id,
+			State:    nmap.State{State: "open"},
+			Service:  nmap.Service{Name: "ssh", Version: version, CPEs: []nmap.CPE{cpe}},
+		}
+	}
+	doc := &types.HostScanDocument{
+		Address: "10.0.0.5",
+		Nmap: &types.NmapHostScanDocument{
+			HostDetails: nmap.Host{Ports: []nmap.Port{
+				// The version is only reported for the service
+				port(22, "7.4p1 Debian 10+deb9u7", "cpe:/a:openbsd:openssh"),
+				port(2222, "", "cpe:/a:openbsd:openssh:8.0"),
+				port(2223, "", "cpe:/a:openbsd:openssh"),
+			}},
+		},
+	}
+
+	matched, err := Correlate(ctx, querier, doc)
+	if err != nil {
+		t.Fatalf("could not correlate: %s", err)
+	}
+	if matched != 1 {
+		t.Errorf("expected 1 match, got %d", matched)
+	}
+
+	rows, err := querier.ListNetworkVulnerabilities(ctx, database.ListNetworkVulnerabilitiesParams{NetworkID: network.ID})
+	if err != nil {
+		t.Fatalf("could not list vulnerabilities: %s", err)
+	}
+	if len(rows) != 1 || rows[0].Port != 22 || rows[0].ID != "CVE-2018-15473" {
+		t.Errorf("expected CVE-2018-15473 on port 22, got %+v", rows)
+	}
+}
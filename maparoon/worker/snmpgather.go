@@ -29,10 +29,35 @@ var (
 		snmpSysLocation,
 		snmpSysServices,
 	}
+
+	snmpAuthProtocols = map[string]gosnmp.SnmpV3AuthProtocol{
+		"md5":    gosnmp.MD5,
+		"sha":    gosnmp.SHA,
+		"sha224": gosnmp.SHA224,
+		"sha256": gosnmp.SHA256,
+		"sha384": gosnmp.SHA384,
+		"sha512": gosnmp.SHA512,
+	}
+
+	snmpPrivProtocols = map[string]gosnmp.SnmpV3PrivProtocol{
+		"des":     gosnmp.DES,
+		"aes":     gosnmp.AES,
+		"aes192":  gosnmp.AES192,
+		"aes256":  gosnmp.AES256,
+		"aes192c": gosnmp.AES192C,
+		"aes256c": gosnmp.AES256C,
+	}
 )
 
 type SnmpGathererOpts struct {
+	// Community is tried as v2c after the stored credentials
 	Community string
+	// Credentials are tried in order, after the one that last worked for the
+	// host in KnownCredentials
+	Credentials      []types.SnmpCredential
+	KnownCredentials map[string]int64
+	// Walks are the tables to walk on hosts that answer, see types.SnmpWalk*
+	Walks []string
 }
 
 type SnmpGatherer struct {
@@ -87,28 +112,36 @@ func (g *SnmpGatherer) gatherSnmpTargetData(ctx context.Context, target string)
 	}
 	defer func() { g.targetDataChannel <- hostScanResult }()
 
-	snmpClient := &gosnmp.GoSNMP{
-		Community: g.opts.Community,
-		Context:   ctx,
-		Port:      gosnmp.Default.Port,
-		Target:    target,
-		Timeout:   gosnmp.Default.Timeout,
-		Version:   gosnmp.Version2c,
-	}
-	if err := snmpClient.Connect(); err != nil {
-		if strings.Contains(err.Error(), "timeout") {
-			// No SNMP response in the timeout period, treat as no SNMP service on target
-			hostScanResult.Available = false
-		} else {
-			return fmt.Errorf("failed to connect to snmp target %s: %w", target, err)
+	var snmpClient *gosnmp.GoSNMP
+	var snmpData *gosnmp.SnmpPacket
+	for _, credential := range g.credentialsFor(target) {
+		candidate, err := newSnmpClient(ctx, target, credential)
+		if err != nil {
+			return fmt.Errorf("failed to set up snmp client for %s: %w", target, err)
+		}
+
+		snmpData, err = getSnmpData(candidate)
+		if err == nil {
+			logrus.Debugf("snmp v%s credential %d works for %s", credential.Version, credential.ID, target)
+			hostScanResult.CredentialId = credential.ID
+			snmpClient = candidate
+			break
+		}
+
+		logrus.Debugf("snmp v%s credential %d failed for %s: %s", credential.Version, credential.ID, target, err)
+		if candidate.Conn != nil {
+			candidate.Conn.Close()
 		}
 	}
-	logrus.Debugf("snmp connected to %s", target)
 
-	snmpData, err := snmpClient.Get(defaultRequestOids)
-	if err != nil {
-		return fmt.Errorf("failed getting default measurements from snmp target %s: %w", target, err)
+	if snmpClient == nil {
+		// No credential got a response, treat as no SNMP service on target
+		hostScanResult.Available = false
+		return nil
 	}
+	defer snmpClient.Conn.Close()
+
+	walkTables(snmpClient, g.opts.Walks, &hostScanResult.SnmpHostScanDocument)
 
 	for _, packet := range snmpData.Variables {
 		resolvedName, err := snmpsmi.ResolveOID(packet.Name)
@@ -148,6 +181,103 @@ func (g *SnmpGatherer) gatherSnmpTargetData(ctx context.Context, target string)
 	return nil
 }
 
+// credentialsFor orders the credentials to try on target: the one that last
+// worked, the credentials scoped to the target, then the rest and finally the
+// worker's community
+func (g *SnmpGatherer) credentialsFor(target string) []types.SnmpCredential {
+	candidates := make([]types.SnmpCredential, 0, len(g.opts.Credentials)+1)
+	knownId := g.opts.KnownCredentials[target]
+	for _, credential := range g.opts.Credentials {
+		if credential.ID == knownId {
+			candidates = append(candidates, credential)
+		}
+	}
+	for _, credential := range g.opts.Credentials {
+		if credential.ID != knownId && credential.Address == target {
+			candidates = append(candidates, credential)
+		}
+	}
+	for _, credential := range g.opts.Credentials {
+		if credential.ID != knownId && credential.Address == "" {
+			candidates = append(candidates, credential)
+		}
+	}
+
+	if g.opts.Community != "" {
+		candidates = append(candidates, types.SnmpCredential{
+			Version:   types.SnmpVersion2c,
+			Community: g.opts.Community,
+		})
+	}
+
+	return candidates
+}
+
+func newSnmpClient(ctx context.Context, target string, credential types.SnmpCredential) (*gosnmp.GoSNMP, error) {
+	snmpClient := &gosnmp.GoSNMP{
+		Context: ctx,
+		Port:    gosnmp.Default.Port,
+		Target:  target,
+		Timeout: gosnmp.Default.Timeout,
+		Retries: gosnmp.Default.Retries,
+		MaxOids: gosnmp.Default.MaxOids,
+	}
+
+	switch credential.Version {
+	case types.SnmpVersion1:
+		snmpClient.Version = gosnmp.Version1
+		snmpClient.Community = credential.Community
+	case types.SnmpVersion2c:
+		snmpClient.Version = gosnmp.Version2c
+		snmpClient.Community = credential.Community
+	case types.SnmpVersion3:
+		securityParams := &gosnmp.UsmSecurityParameters{
+			UserName:                 credential.Username,
+			AuthenticationProtocol:   gosnmp.NoAuth,
+			AuthenticationPassphrase: credential.AuthPassphrase,
+			PrivacyProtocol:          gosnmp.NoPriv,
+			PrivacyPassphrase:        credential.PrivPassphrase,
+		}
+		msgFlags := gosnmp.NoAuthNoPriv
+		if credential.AuthProtocol != "" {
+			securityParams.AuthenticationProtocol = snmpAuthProtocols[credential.AuthProtocol]
+			msgFlags = gosnmp.AuthNoPriv
+		}
+		if credential.PrivProtocol != "" {
+			securityParams.PrivacyProtocol = snmpPrivProtocols[credential.PrivProtocol]
+			msgFlags = gosnmp.AuthPriv
+		}
+
+		snmpClient.Version = gosnmp.Version3
+		snmpClient.SecurityModel = gosnmp.UserSecurityModel
+		snmpClient.MsgFlags = msgFlags
+		snmpClient.SecurityParameters = securityParams
+	default:
+		return nil, fmt.Errorf("unknown snmp version: %s", credential.Version)
+	}
+
+	return snmpClient, nil
+}
+
+func getSnmpData(snmpClient *gosnmp.GoSNMP) (*gosnmp.SnmpPacket, error) {
+	if err := snmpClient.Connect(); err != nil {
+		return nil, err
+	}
+
+	snmpData, err := snmpClient.Get(defaultRequestOids)
+	if err != nil {
+		return nil, err
+	}
+
+	// v1 agents answer unknown communities with an error status instead of
+	// staying silent
+	if snmpData.Error != gosnmp.NoError {
+		return nil, fmt.Errorf("snmp error status: %s", snmpData.Error)
+	}
+
+	return snmpData, nil
+}
+
 func escapeOid(oid string) string {
 	oid = strings.ReplaceAll(oid, ".", "-")
 	oid = strings.TrimPrefix(oid, "-")
@@ -0,0 +1,525 @@
+package worker
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/sirupsen/logrus"
+
+	"github.com/pirogoeth/apps/maparoon/snmpsmi"
+	"github.com/pirogoeth/apps/maparoon/types"
+)
+
+// mibNode is a table entry or column, looked up by name through snmpsmi. Its
+// numeric OID is the fallback, so that walks work even when the MIB defining it
+// isn't loaded, as with LLDP-MIB which isn't among the MIBs collected by
+// scripts/collect-mibs.sh.
+type mibNode struct {
+	name string
+	oid  string
+}
+
+// resolvedOids holds the OID each node was resolved to, or its fallback
+var resolvedOids sync.Map
+
+func (n mibNode) String() string {
+	return n.name
+}
+
+// OID returns the node's numeric OID
+func (n mibNode) OID() string {
+	if oid, ok := resolvedOids.Load(n.name); ok {
+		return oid.(string)
+	}
+
+	oid := n.oid
+	if node, err := snmpsmi.ResolveName(n.name); err == nil {
+		oid = node.Oid.String()
+	} else {
+		logrus.Debugf("could not resolve %s, using %s: %s", n.name, n.oid, err)
+	}
+	resolvedOids.Store(n.name, oid)
+
+	return oid
+}
+
+// column returns the sub-identifier of a column within its table entry
+func (n mibNode) column() int {
+	oid := n.OID()
+	column, err := strconv.Atoi(oid[strings.LastIndex(oid, ".")+1:])
+	if err != nil {
+		return -1
+	}
+
+	return column
+}
+
+var (
+	// IF-MIB
+	ifEntry       = mibNode{"IF-MIB::ifEntry", "1.3.6.1.2.1.2.2.1"}
+	ifDescr       = mibNode{"IF-MIB::ifDescr", "1.3.6.1.2.1.2.2.1.2"}
+	ifType        = mibNode{"IF-MIB::ifType", "1.3.6.1.2.1.2.2.1.3"}
+	ifMtu         = mibNode{"IF-MIB::ifMtu", "1.3.6.1.2.1.2.2.1.4"}
+	ifSpeed       = mibNode{"IF-MIB::ifSpeed", "1.3.6.1.2.1.2.2.1.5"}
+	ifPhysAddress = mibNode{"IF-MIB::ifPhysAddress", "1.3.6.1.2.1.2.2.1.6"}
+	ifAdminStatus = mibNode{"IF-MIB::ifAdminStatus", "1.3.6.1.2.1.2.2.1.7"}
+	ifOperStatus  = mibNode{"IF-MIB::ifOperStatus", "1.3.6.1.2.1.2.2.1.8"}
+	ifXEntry      = mibNode{"IF-MIB::ifXEntry", "1.3.6.1.2.1.31.1.1.1"}
+	ifName        = mibNode{"IF-MIB::ifName", "1.3.6.1.2.1.31.1.1.1.1"}
+	ifHighSpeed   = mibNode{"IF-MIB::ifHighSpeed", "1.3.6.1.2.1.31.1.1.1.15"}
+	ifAlias       = mibNode{"IF-MIB::ifAlias", "1.3.6.1.2.1.31.1.1.1.18"}
+	// IP-MIB
+	ipNetToMediaEntry          = mibNode{"IP-MIB::ipNetToMediaEntry", "1.3.6.1.2.1.4.22.1"}
+	ipNetToMediaPhysAddress    = mibNode{"IP-MIB::ipNetToMediaPhysAddress", "1.3.6.1.2.1.4.22.1.2"}
+	ipNetToPhysicalEntry       = mibNode{"IP-MIB::ipNetToPhysicalEntry", "1.3.6.1.2.1.4.35.1"}
+	ipNetToPhysicalPhysAddress = mibNode{"IP-MIB::ipNetToPhysicalPhysAddress", "1.3.6.1.2.1.4.35.1.2"}
+	// BRIDGE-MIB
+	dot1dBasePortIfIndex = mibNode{"BRIDGE-MIB::dot1dBasePortIfIndex", "1.3.6.1.2.1.17.1.4.1.2"}
+	dot1dTpFdbEntry      = mibNode{"BRIDGE-MIB::dot1dTpFdbEntry", "1.3.6.1.2.1.17.4.3.1"}
+	dot1dTpFdbPort       = mibNode{"BRIDGE-MIB::dot1dTpFdbPort", "1.3.6.1.2.1.17.4.3.1.2"}
+	dot1dTpFdbStatus     = mibNode{"BRIDGE-MIB::dot1dTpFdbStatus", "1.3.6.1.2.1.17.4.3.1.3"}
+	// LLDP-MIB
+	lldpLocPortEntry        = mibNode{"LLDP-MIB::lldpLocPortEntry", "1.0.8802.1.1.2.1.3.7.1"}
+	lldpLocPortIdSubtype    = mibNode{"LLDP-MIB::lldpLocPortIdSubtype", "1.0.8802.1.1.2.1.3.7.1.2"}
+	lldpLocPortId           = mibNode{"LLDP-MIB::lldpLocPortId", "1.0.8802.1.1.2.1.3.7.1.3"}
+	lldpLocPortDesc         = mibNode{"LLDP-MIB::lldpLocPortDesc", "1.0.8802.1.1.2.1.3.7.1.4"}
+	lldpRemEntry            = mibNode{"LLDP-MIB::lldpRemEntry", "1.0.8802.1.1.2.1.4.1.1"}
+	lldpRemChassisIdSubtype = mibNode{"LLDP-MIB::lldpRemChassisIdSubtype", "1.0.8802.1.1.2.1.4.1.1.4"}
+	lldpRemChassisId        = mibNode{"LLDP-MIB::lldpRemChassisId", "1.0.8802.1.1.2.1.4.1.1.5"}
+	lldpRemPortIdSubtype    = mibNode{"LLDP-MIB::lldpRemPortIdSubtype", "1.0.8802.1.1.2.1.4.1.1.6"}
+	lldpRemPortId           = mibNode{"LLDP-MIB::lldpRemPortId", "1.0.8802.1.1.2.1.4.1.1.7"}
+	lldpRemPortDesc         = mibNode{"LLDP-MIB::lldpRemPortDesc", "1.0.8802.1.1.2.1.4.1.1.8"}
+	lldpRemSysName          = mibNode{"LLDP-MIB::lldpRemSysName", "1.0.8802.1.1.2.1.4.1.1.9"}
+	lldpRemSysDesc          = mibNode{"LLDP-MIB::lldpRemSysDesc", "1.0.8802.1.1.2.1.4.1.1.10"}
+	lldpRemManAddrEntry     = mibNode{"LLDP-MIB::lldpRemManAddrEntry", "1.0.8802.1.1.2.1.4.2.1"}
+)
+
+var (
+	ifAdminStatuses = map[int64]string{1: "up", 2: "down", 3: "testing"}
+	ifOperStatuses  = map[int64]string{
+		1: "up",
+		2: "down",
+		3: "testing",
+		4: "unknown",
+		5: "dormant",
+		6: "notPresent",
+		7: "lowerLayerDown",
+	}
+)
+
+// walkTables walks the enabled tables into the host scan. A table that can't be
+// walked is skipped, most agents only implement some of them.
+func walkTables(snmpClient *gosnmp.GoSNMP, walks []string, hostScan *types.SnmpHostScanDocument) {
+	walk := func(entry mibNode) []gosnmp.SnmpPDU {
+		pdus, err := walkTable(snmpClient, entry)
+		if err != nil {
+			logrus.Debugf("could not walk %s on %s: %s", entry, snmpClient.Target, err)
+		}
+		return pdus
+	}
+
+	for _, table := range walks {
+		switch table {
+		case types.SnmpWalkInterfaces:
+			hostScan.Interfaces = parseInterfaces(walk(ifEntry), walk(ifXEntry))
+		case types.SnmpWalkArp:
+			hostScan.ArpEntries = parseArpEntries(walk(ipNetToMediaEntry), walk(ipNetToPhysicalEntry))
+		case types.SnmpWalkFdb:
+			hostScan.FdbEntries = parseFdbEntries(walk(dot1dTpFdbEntry), walk(dot1dBasePortIfIndex))
+		case types.SnmpWalkLldp:
+			hostScan.Neighbors = parseNeighbors(walk(lldpRemEntry), walk(lldpRemManAddrEntry), walk(lldpLocPortEntry))
+		default:
+			logrus.Warnf("unknown snmp walk: %s", table)
+		}
+	}
+}
+
+func walkTable(snmpClient *gosnmp.GoSNMP, entry mibNode) ([]gosnmp.SnmpPDU, error) {
+	entryOid := entry.OID()
+	logrus.Debugf("walking %s (%s) on %s", entry, entryOid, snmpClient.Target)
+
+	if snmpClient.Version == gosnmp.Version1 {
+		return snmpClient.WalkAll(entryOid)
+	}
+
+	return snmpClient.BulkWalkAll(entryOid)
+}
+
+// tableCell splits a walked OID into its column and index below the entry
+func tableCell(oid string, entry mibNode) (int, []int, bool) {
+	rest, ok := strings.CutPrefix(strings.TrimPrefix(oid, "."), entry.OID()+".")
+	if !ok {
+		return 0, nil, false
+	}
+
+	parts := strings.Split(rest, ".")
+	subIds := make([]int, 0, len(parts))
+	for _, part := range parts {
+		subId, err := strconv.Atoi(part)
+		if err != nil {
+			return 0, nil, false
+		}
+		subIds = append(subIds, subId)
+	}
+
+	if len(subIds) < 2 {
+		return 0, nil, false
+	}
+
+	return subIds[0], subIds[1:], true
+}
+
+func indexKey(index []int) string {
+	parts := make([]string, 0, len(index))
+	for _, subId := range index {
+		parts = append(parts, strconv.Itoa(subId))
+	}
+
+	return strings.Join(parts, ".")
+}
+
+func parseInterfaces(ifPdus, ifXPdus []gosnmp.SnmpPDU) []types.SnmpInterface {
+	interfaces := make(map[int64]*types.SnmpInterface)
+	order := make([]int64, 0)
+	row := func(ifIndex int64) *types.SnmpInterface {
+		if _, ok := interfaces[ifIndex]; !ok {
+			interfaces[ifIndex] = &types.SnmpInterface{Index: ifIndex}
+			order = append(order, ifIndex)
+		}
+		return interfaces[ifIndex]
+	}
+
+	for _, pdu := range ifPdus {
+		column, index, ok := tableCell(pdu.Name, ifEntry)
+		if !ok || len(index) != 1 {
+			continue
+		}
+
+		iface := row(int64(index[0]))
+		switch column {
+		case ifDescr.column():
+			iface.Descr = pduString(pdu)
+		case ifType.column():
+			iface.Type = pduInt(pdu)
+		case ifMtu.column():
+			iface.Mtu = pduInt(pdu)
+		case ifSpeed.column():
+			// ifSpeed is in bit/s and saturates at 4.29Gbit/s, ifHighSpeed wins
+			if iface.Speed == 0 {
+				iface.Speed = pduInt(pdu) / 1_000_000
+			}
+		case ifPhysAddress.column():
+			iface.MacAddress = pduMac(pdu)
+		case ifAdminStatus.column():
+			iface.AdminStatus = ifAdminStatuses[pduInt(pdu)]
+		case ifOperStatus.column():
+			iface.OperStatus = ifOperStatuses[pduInt(pdu)]
+		}
+	}
+
+	for _, pdu := range ifXPdus {
+		column, index, ok := tableCell(pdu.Name, ifXEntry)
+		if !ok || len(index) != 1 {
+			continue
+		}
+
+		iface := row(int64(index[0]))
+		switch column {
+		case ifName.column():
+			iface.Name = pduString(pdu)
+		case ifHighSpeed.column():
+			if highSpeed := pduInt(pdu); highSpeed > 0 {
+				iface.Speed = highSpeed
+			}
+		case ifAlias.column():
+			iface.Alias = pduString(pdu)
+		}
+	}
+
+	rows := make([]types.SnmpInterface, 0, len(order))
+	for _, ifIndex := range order {
+		rows = append(rows, *interfaces[ifIndex])
+	}
+
+	return rows
+}
+
+// parseArpEntries merges the IPv4-only ipNetToMediaTable with
+// ipNetToPhysicalTable, which also holds the IPv6 neighbor cache
+func parseArpEntries(mediaPdus, physicalPdus []gosnmp.SnmpPDU) []types.SnmpArpEntry {
+	entries := make([]types.SnmpArpEntry, 0)
+	seen := make(map[string]bool)
+	add := func(ifIndex int64, ipAddress net.IP, pdu gosnmp.SnmpPDU) {
+		macAddress := pduMac(pdu)
+		if macAddress == "" {
+			return
+		}
+
+		key := fmt.Sprintf("%d/%s", ifIndex, ipAddress)
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+
+		entries = append(entries, types.SnmpArpEntry{
+			IfIndex:    ifIndex,
+			IpAddress:  ipAddress.String(),
+			MacAddress: macAddress,
+		})
+	}
+
+	for _, pdu := range mediaPdus {
+		// Indexed by ipNetToMediaIfIndex and ipNetToMediaNetAddress
+		column, index, ok := tableCell(pdu.Name, ipNetToMediaEntry)
+		if !ok || column != ipNetToMediaPhysAddress.column() || len(index) != 5 {
+			continue
+		}
+
+		add(int64(index[0]), indexIp(index[1:]), pdu)
+	}
+
+	for _, pdu := range physicalPdus {
+		// Indexed by ipNetToPhysicalIfIndex, ipNetToPhysicalNetAddressType and
+		// the length prefixed ipNetToPhysicalNetAddress
+		column, index, ok := tableCell(pdu.Name, ipNetToPhysicalEntry)
+		if !ok || column != ipNetToPhysicalPhysAddress.column() || len(index) < 3 || len(index) != 3+index[2] {
+			continue
+		}
+
+		ipAddress := indexIp(index[3:])
+		if ipAddress == nil || ipAddress.IsLinkLocalUnicast() {
+			continue
+		}
+
+		add(int64(index[0]), ipAddress, pdu)
+	}
+
+	return entries
+}
+
+// indexIp turns the octets of an address in a table index into an IP
+func indexIp(octets []int) net.IP {
+	if len(octets) != net.IPv4len && len(octets) != net.IPv6len {
+		return nil
+	}
+
+	ip := make(net.IP, 0, len(octets))
+	for _, octet := range octets {
+		ip = append(ip, byte(octet))
+	}
+
+	return ip
+}
+
+func parseFdbEntries(fdbPdus, basePortPdus []gosnmp.SnmpPDU) []types.SnmpFdbEntry {
+	portIfIndexes := make(map[int64]int64)
+	for _, pdu := range basePortPdus {
+		if index, ok := strings.CutPrefix(strings.TrimPrefix(pdu.Name, "."), dot1dBasePortIfIndex.OID()+"."); ok {
+			if port, err := strconv.ParseInt(index, 10, 64); err == nil {
+				portIfIndexes[port] = pduInt(pdu)
+			}
+		}
+	}
+
+	ports := make(map[string]int64)
+	statuses := make(map[string]int64)
+	order := make([]string, 0)
+	for _, pdu := range fdbPdus {
+		// Indexed by dot1dTpFdbAddress, the six octets of the MAC address
+		column, index, ok := tableCell(pdu.Name, dot1dTpFdbEntry)
+		if !ok || len(index) != 6 {
+			continue
+		}
+
+		macAddress := formatMac(index)
+		switch column {
+		case dot1dTpFdbPort.column():
+			ports[macAddress] = pduInt(pdu)
+			order = append(order, macAddress)
+		case dot1dTpFdbStatus.column():
+			statuses[macAddress] = pduInt(pdu)
+		}
+	}
+
+	entries := make([]types.SnmpFdbEntry, 0, len(order))
+	for _, macAddress := range order {
+		// Skip invalid(2) entries and the bridge's own addresses, self(4)
+		if status := statuses[macAddress]; status == 2 || status == 4 {
+			continue
+		}
+
+		port := ports[macAddress]
+		entries = append(entries, types.SnmpFdbEntry{
+			MacAddress: macAddress,
+			BridgePort: port,
+			IfIndex:    portIfIndexes[port],
+		})
+	}
+
+	return entries
+}
+
+func parseNeighbors(remPdus, manAddrPdus, locPortPdus []gosnmp.SnmpPDU) []types.SnmpNeighbor {
+	localPortIdSubtypes := make(map[int64]int64)
+	localPortIds := make(map[int64]string)
+	localPortDescrs := make(map[int64]string)
+	for _, pdu := range locPortPdus {
+		// Indexed by lldpLocPortNum
+		column, index, ok := tableCell(pdu.Name, lldpLocPortEntry)
+		if !ok || len(index) != 1 {
+			continue
+		}
+
+		port := int64(index[0])
+		switch column {
+		case lldpLocPortIdSubtype.column():
+			localPortIdSubtypes[port] = pduInt(pdu)
+		case lldpLocPortId.column():
+			localPortIds[port] = formatLldpId(pduBytes(pdu), localPortIdSubtypes[port], 3, 4)
+		case lldpLocPortDesc.column():
+			localPortDescrs[port] = pduString(pdu)
+		}
+	}
+
+	neighbors := make(map[string]*types.SnmpNeighbor)
+	chassisIdSubtypes := make(map[string]int64)
+	portIdSubtypes := make(map[string]int64)
+	order := make([]string, 0)
+	for _, pdu := range remPdus {
+		// Indexed by lldpRemTimeMark, lldpRemLocalPortNum and lldpRemIndex
+		column, index, ok := tableCell(pdu.Name, lldpRemEntry)
+		if !ok || len(index) != 3 {
+			continue
+		}
+
+		key := indexKey(index[1:])
+		neighbor, ok := neighbors[key]
+		if !ok {
+			neighbor = &types.SnmpNeighbor{
+				LocalPort:      int64(index[1]),
+				LocalPortId:    localPortIds[int64(index[1])],
+				LocalPortDescr: localPortDescrs[int64(index[1])],
+			}
+			neighbors[key] = neighbor
+			order = append(order, key)
+		}
+
+		switch column {
+		case lldpRemChassisIdSubtype.column():
+			chassisIdSubtypes[key] = pduInt(pdu)
+		case lldpRemChassisId.column():
+			neighbor.ChassisId = formatLldpId(pduBytes(pdu), chassisIdSubtypes[key], 4, 5)
+		case lldpRemPortIdSubtype.column():
+			portIdSubtypes[key] = pduInt(pdu)
+		case lldpRemPortId.column():
+			neighbor.PortId = formatLldpId(pduBytes(pdu), portIdSubtypes[key], 3, 4)
+		case lldpRemPortDesc.column():
+			neighbor.PortDescr = pduString(pdu)
+		case lldpRemSysName.column():
+			neighbor.SysName = pduString(pdu)
+		case lldpRemSysDesc.column():
+			neighbor.SysDescr = pduString(pdu)
+		}
+	}
+
+	for _, pdu := range manAddrPdus {
+		// Indexed by the remote entry's index, then lldpRemManAddrSubtype and
+		// the length prefixed lldpRemManAddr
+		_, index, ok := tableCell(pdu.Name, lldpRemManAddrEntry)
+		if !ok || len(index) < 5 {
+			continue
+		}
+
+		neighbor, ok := neighbors[indexKey(index[1:3])]
+		if !ok || neighbor.ManagementAddress != "" {
+			continue
+		}
+
+		addrLen := index[4]
+		if len(index) != 5+addrLen {
+			continue
+		}
+
+		addr := make(net.IP, 0, addrLen)
+		for _, octet := range index[5:] {
+			addr = append(addr, byte(octet))
+		}
+
+		// IANA address family 1 is IPv4, 2 is IPv6
+		if family := index[3]; (family == 1 && addrLen == net.IPv4len) || (family == 2 && addrLen == net.IPv6len) {
+			neighbor.ManagementAddress = addr.String()
+		}
+	}
+
+	rows := make([]types.SnmpNeighbor, 0, len(order))
+	for _, key := range order {
+		rows = append(rows, *neighbors[key])
+	}
+
+	return rows
+}
+
+// formatLldpId formats a chassis or port id according to its subtype, which
+// says whether it's a MAC or a network address
+func formatLldpId(id []byte, subtype, macSubtype, networkAddressSubtype int64) string {
+	switch {
+	case subtype == macSubtype && len(id) == 6:
+		return net.HardwareAddr(id).String()
+	case subtype == networkAddressSubtype && len(id) == 1+net.IPv4len && id[0] == 1:
+		return net.IP(id[1:]).String()
+	case subtype == networkAddressSubtype && len(id) == 1+net.IPv6len && id[0] == 2:
+		return net.IP(id[1:]).String()
+	}
+
+	return printableOrHex(id)
+}
+
+func pduBytes(pdu gosnmp.SnmpPDU) []byte {
+	if value, ok := pdu.Value.([]byte); ok {
+		return value
+	}
+
+	return []byte(fmt.Sprint(pdu.Value))
+}
+
+func pduString(pdu gosnmp.SnmpPDU) string {
+	return printableOrHex(pduBytes(pdu))
+}
+
+func pduInt(pdu gosnmp.SnmpPDU) int64 {
+	return gosnmp.ToBigInt(pdu.Value).Int64()
+}
+
+func pduMac(pdu gosnmp.SnmpPDU) string {
+	value, ok := pdu.Value.([]byte)
+	if !ok || len(value) != 6 {
+		return ""
+	}
+
+	return net.HardwareAddr(value).String()
+}
+
+func formatMac(octets []int) string {
+	mac := make(net.HardwareAddr, 0, len(octets))
+	for _, octet := range octets {
+		mac = append(mac, byte(octet))
+	}
+
+	return mac.String()
+}
+
+func printableOrHex(value []byte) string {
+	trimmed := strings.TrimRight(string(value), "\x00")
+	for _, r := range trimmed {
+		if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return fmt.Sprintf("%x", value)
+		}
+	}
+
+	return trimmed
+}
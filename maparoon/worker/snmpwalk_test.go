@@ -0,0 +1,121 @@
+package worker
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gosnmp/gosnmp"
+
+	"github.com/pirogoeth/apps/maparoon/types"
+)
+
+func octets(name string, value []byte) gosnmp.SnmpPDU {
+	return gosnmp.SnmpPDU{Name: name, Type: gosnmp.OctetString, Value: value}
+}
+
+func integer(name string, value int) gosnmp.SnmpPDU {
+	return gosnmp.SnmpPDU{Name: name, Type: gosnmp.Integer, Value: value}
+}
+
+func TestMibNodeFallback(t *testing.T) {
+	// No MIBs are loaded here, so nodes fall back to their numeric OIDs
+	node := mibNode{"IF-MIB::ifHighSpeed", "1.3.6.1.2.1.31.1.1.1.15"}
+	if oid := node.OID(); oid != node.oid {
+		t.Errorf("expected %s, got %s", node.oid, oid)
+	}
+	if column := node.column(); column != 15 {
+		t.Errorf("expected column 15, got %d", column)
+	}
+}
+
+func TestParseInterfaces(t *testing.T) {
+	ifPdus := []gosnmp.SnmpPDU{
+		octets(".1.3.6.1.2.1.2.2.1.2.1", []byte("ge-0/0/0")),
+		integer(".1.3.6.1.2.1.2.2.1.3.1", 6),
+		integer(".1.3.6.1.2.1.2.2.1.5.1", 1_000_000_000),
+		octets(".1.3.6.1.2.1.2.2.1.6.1", []byte{0x00, 0x1b, 0x21, 0xaa, 0xbb, 0xcc}),
+		integer(".1.3.6.1.2.1.2.2.1.7.1", 1),
+		integer(".1.3.6.1.2.1.2.2.1.8.1", 2),
+	}
+	ifXPdus := []gosnmp.SnmpPDU{
+		octets(".1.3.6.1.2.1.31.1.1.1.1.1", []byte("ge-0/0/0")),
+		integer(".1.3.6.1.2.1.31.1.1.1.15.1", 10000),
+		octets(".1.3.6.1.2.1.31.1.1.1.18.1", []byte("uplink")),
+	}
+
+	expected := []types.SnmpInterface{{
+		Index:       1,
+		Name:        "ge-0/0/0",
+		Descr:       "ge-0/0/0",
+		Alias:       "uplink",
+		Type:        6,
+		Speed:       10000,
+		MacAddress:  "00:1b:21:aa:bb:cc",
+		AdminStatus: "up",
+		OperStatus:  "down",
+	}}
+	if interfaces := parseInterfaces(ifPdus, ifXPdus); !reflect.DeepEqual(interfaces, expected) {
+		t.Errorf("expected %+v, got %+v", expected, interfaces)
+	}
+}
+
+func TestParseArpAndFdbEntries(t *testing.T) {
+	arp := parseArpEntries([]gosnmp.SnmpPDU{
+		octets(".1.3.6.1.2.1.4.22.1.2.3.10.0.0.7", []byte{0x00, 0x1b, 0x21, 0x00, 0x00, 0x07}),
+	}, []gosnmp.SnmpPDU{
+		octets(".1.3.6.1.2.1.4.35.1.2.3.1.4.10.0.0.7", []byte{0x00, 0x1b, 0x21, 0x00, 0x00, 0x07}),
+		octets(".1.3.6.1.2.1.4.35.1.2.3.2.16.32.1.13.184.0.0.0.0.0.0.0.0.0.0.0.7", []byte{0x00, 0x1b, 0x21, 0x00, 0x00, 0x07}),
+		octets(".1.3.6.1.2.1.4.35.1.2.3.2.16.254.128.0.0.0.0.0.0.2.27.33.255.254.0.0.7", []byte{0x00, 0x1b, 0x21, 0x00, 0x00, 0x07}),
+	})
+	expectedArp := []types.SnmpArpEntry{
+		{IfIndex: 3, IpAddress: "10.0.0.7", MacAddress: "00:1b:21:00:00:07"},
+		{IfIndex: 3, IpAddress: "2001:db8::7", MacAddress: "00:1b:21:00:00:07"},
+	}
+	if !reflect.DeepEqual(arp, expectedArp) {
+		t.Errorf("expected %+v, got %+v", expectedArp, arp)
+	}
+
+	fdb := parseFdbEntries([]gosnmp.SnmpPDU{
+		integer(".1.3.6.1.2.1.17.4.3.1.2.0.27.33.0.0.7", 12),
+		integer(".1.3.6.1.2.1.17.4.3.1.2.0.27.33.0.0.1", 0),
+		integer(".1.3.6.1.2.1.17.4.3.1.3.0.27.33.0.0.7", 3),
+		integer(".1.3.6.1.2.1.17.4.3.1.3.0.27.33.0.0.1", 4),
+	}, []gosnmp.SnmpPDU{
+		integer(".1.3.6.1.2.1.17.1.4.1.2.12", 512),
+	})
+	expectedFdb := []types.SnmpFdbEntry{{MacAddress: "00:1b:21:00:00:07", BridgePort: 12, IfIndex: 512}}
+	if !reflect.DeepEqual(fdb, expectedFdb) {
+		t.Errorf("expected %+v, got %+v", expectedFdb, fdb)
+	}
+}
+
+func TestParseNeighbors(t *testing.T) {
+	remPdus := []gosnmp.SnmpPDU{
+		integer(".1.0.8802.1.1.2.1.4.1.1.4.0.5.1", 4),
+		octets(".1.0.8802.1.1.2.1.4.1.1.5.0.5.1", []byte{0x00, 0x1b, 0x21, 0xaa, 0xbb, 0xcc}),
+		integer(".1.0.8802.1.1.2.1.4.1.1.6.0.5.1", 5),
+		octets(".1.0.8802.1.1.2.1.4.1.1.7.0.5.1", []byte("xe-0/0/1")),
+		octets(".1.0.8802.1.1.2.1.4.1.1.9.0.5.1", []byte("core-sw1")),
+	}
+	manAddrPdus := []gosnmp.SnmpPDU{
+		integer(".1.0.8802.1.1.2.1.4.2.1.3.0.5.1.1.4.10.0.0.1", 2),
+	}
+	locPortPdus := []gosnmp.SnmpPDU{
+		integer(".1.0.8802.1.1.2.1.3.7.1.2.5", 5),
+		octets(".1.0.8802.1.1.2.1.3.7.1.3.5", []byte("ge-0/0/5")),
+		octets(".1.0.8802.1.1.2.1.3.7.1.4.5", []byte("eth5")),
+	}
+
+	expected := []types.SnmpNeighbor{{
+		LocalPort:         5,
+		LocalPortId:       "ge-0/0/5",
+		LocalPortDescr:    "eth5",
+		ChassisId:         "00:1b:21:aa:bb:cc",
+		PortId:            "xe-0/0/1",
+		SysName:           "core-sw1",
+		ManagementAddress: "10.0.0.1",
+	}}
+	if neighbors := parseNeighbors(remPdus, manAddrPdus, locPortPdus); !reflect.DeepEqual(neighbors, expected) {
+		t.Errorf("expected %+v, got %+v", expected, neighbors)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"sync"
 	"time"
 
 	"github.com/projectdiscovery/goflags"
@@ -34,41 +35,48 @@ func New(apiClient *client.Client, cfg *types.Config) *worker {
 }
 
 func (w *worker) Run(ctx context.Context) {
-	scanInterval := 5 * time.Second
+	pollInterval := 5 * time.Second
 
 	for {
 		select {
-		case <-time.After(scanInterval):
-			err := w.findAndScanNetworks(ctx)
+		case <-time.After(pollInterval):
+			err := w.claimAndScanNetworks(ctx)
 			if err != nil {
 				logrus.Errorf("encountered error during scan: %s", err.Error())
 			}
 
-			scanInterval = w.cfg.Worker.ScanInterval
-			logrus.Debugf("Setting next scan interval to %s", scanInterval)
+			pollInterval = w.cfg.Worker.PollInterval
+			logrus.Debugf("Setting next poll interval to %s", pollInterval)
 		case <-ctx.Done():
 			return
 		}
 	}
 }
 
-func (w *worker) findAndScanNetworks(ctx context.Context) error {
-	networks, err := w.apiClient.ListNetworks(ctx)
+// claimAndScanNetworks leases the networks that are due to be scanned from the
+// server, so that networks are only scanned by one worker at a time
+func (w *worker) claimAndScanNetworks(ctx context.Context) error {
+	resp, err := w.apiClient.ClaimScanLeases(ctx, types.ClaimScanLeasesRequest{
+		WorkerId:     w.cfg.Worker.Id,
+		Labels:       w.cfg.Worker.Labels,
+		ScanInterval: int64(w.cfg.Worker.ScanInterval.Seconds()),
+		Limit:        int64(w.cfg.Worker.Concurrent.NetworkScanLimit),
+	})
 	if err != nil {
 		return err
 	}
 
-	logrus.Debugf("Found %d networks, only scanning %d concurrently",
-		len(networks.Networks),
+	logrus.Debugf("Claimed %d networks, only scanning %d concurrently",
+		len(resp.Leases),
 		w.cfg.Worker.Concurrent.NetworkScanLimit,
 	)
 
 	eg, _ := errgroup.WithContext(ctx)
 	eg.SetLimit(w.cfg.Worker.Concurrent.NetworkScanLimit)
-	for _, network := range networks.Networks {
-		network := network
+	for _, grant := range resp.Leases {
+		grant := grant
 		eg.Go(func() error {
-			return w.startNetworkScanSingle(ctx, network)
+			return w.scanLeasedNetwork(ctx, grant)
 		})
 	}
 
@@ -79,34 +87,81 @@ func (w *worker) findAndScanNetworks(ctx context.Context) error {
 	return nil
 }
 
-func (w *worker) startNetworkScanSingle(pCtx context.Context, network database.Network) error {
-	logrus.Debugf("Scanning network %s", network.Name)
-	defer logrus.Debugf("Finished scanning network %s", network.Name)
-
+// scanLeasedNetwork scans a claimed network while heartbeating its lease. The
+// scan is abandoned if the lease is lost.
+func (w *worker) scanLeasedNetwork(pCtx context.Context, grant types.ScanLeaseGrant) error {
 	ctx, cancel := context.WithCancel(pCtx)
 	defer cancel()
 
-	snmpGatherer := NewSnmpGatherer(&SnmpGathererOpts{
-		Community: w.cfg.Worker.Snmp.Community,
-	})
-	go snmpGatherer.Run(ctx)
+	go w.heartbeatScanLease(ctx, cancel, grant)
 
-	scansDir := path.Join(os.TempDir(), "maparoon")
-	if err := os.MkdirAll(scansDir, 0750); err != nil {
-		return fmt.Errorf("could not create maparoon temp directory: %w", err)
+	profile := grant.Profile
+	if profile.ScanType == "" {
+		profile = types.DefaultScanProfile
 	}
 
-	scanPipe := path.Join(scansDir, fmt.Sprintf("network-%d.sock", network.ID))
-	if err := os.Remove(scanPipe); err != nil {
-		logrus.Infof("could not remove existing fifo: %s", err)
+	if err := w.startNetworkScanSingle(ctx, grant, profile); err != nil {
+		if releaseErr := w.apiClient.ReleaseScanLease(pCtx, grant.Network.ID, grant.Scan.ID, w.cfg.Worker.Id); releaseErr != nil {
+			logrus.Warnf("could not release scan lease for network %s: %s", grant.Network.Name, releaseErr)
+		}
+		return err
 	}
 
-	if err := unix.Mkfifo(scanPipe, 0666); err != nil {
-		return fmt.Errorf("could not create fifo for nmap scan: %w", err)
+	return nil
+}
+
+func (w *worker) heartbeatScanLease(ctx context.Context, cancel context.CancelFunc, grant types.ScanLeaseGrant) {
+	ticker := time.NewTicker(w.cfg.Worker.HeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			err := w.apiClient.HeartbeatScanLease(ctx, grant.Network.ID, grant.Scan.ID, w.cfg.Worker.Id)
+			if errors.Is(err, client.ErrLeaseLost) {
+				logrus.Errorf("lost scan lease for network %s, abandoning scan", grant.Network.Name)
+				cancel()
+				return
+			} else if err != nil {
+				logrus.Warnf("could not heartbeat scan lease for network %s: %s", grant.Network.Name, err)
+			}
+		case <-ctx.Done():
+			return
+		}
 	}
+}
+
+func (w *worker) startNetworkScanSingle(pCtx context.Context, grant types.ScanLeaseGrant, profile database.ScanProfile) error {
+	network := grant.Network
+	logrus.Debugf("Scanning network %s with profile %s", network.Name, profile.Name)
+	defer logrus.Debugf("Finished scanning network %s", network.Name)
 
+	ctx, cancel := context.WithCancel(pCtx)
+	defer cancel()
+
+	runNmap := profile.ScanType != types.ScanTypePing && profile.NmapFlags != ""
+
+	var snmpGatherer *SnmpGatherer
+	if profile.SnmpEnabled {
+		snmpGatherer = NewSnmpGatherer(&SnmpGathererOpts{
+			Community:        w.cfg.Worker.Snmp.Community,
+			Credentials:      grant.SnmpCredentials,
+			KnownCredentials: grant.KnownSnmpCredentials,
+			Walks:            w.cfg.Worker.Snmp.Walks,
+		})
+		go snmpGatherer.Run(ctx)
+	}
+
+	targets := []string{network.CidrString()}
 	networkSize, err := network.NetworkSize()
-	if err != nil {
+	if errors.Is(err, database.ErrNetworkTooLarge) {
+		// Only the addresses discovered in the network are scanned
+		targets, networkSize = grant.Targets, len(grant.Targets)
+		if len(targets) == 0 {
+			logrus.Infof("no addresses discovered in network %s yet, nothing to scan", network.Name)
+			return w.submitHostScans(ctx, grant, nil)
+		}
+	} else if err != nil {
 		return fmt.Errorf("could not calculate network size: %w", err)
 	}
 
@@ -115,35 +170,63 @@ func (w *worker) startNetworkScanSingle(pCtx context.Context, network database.N
 
 	scanDoneCh := make(chan bool)
 	procEg, _ := errgroup.WithContext(ctx)
-	procEg.Go(func() error {
-		nsp := &nmapScanProcessor{
-			network,
-			scanPipe,
-			scanDoneCh,
-			nmapHostResultCh,
+
+	// Every host discovery finds is reported, so the server knows which hosts
+	// were seen even when nmap and SNMP have nothing on them
+	var discoveredMu sync.Mutex
+	discovered := make(map[string][]types.DiscoveredPort)
+
+	options := naabuOptions(network, profile, targets)
+	options.OnResult = func(res *naabuResult.HostResult) {
+		logrus.Debugf("Found host %s", res.IP)
+		discoveredMu.Lock()
+		for _, port := range res.Ports {
+			discovered[res.IP] = append(discovered[res.IP], types.DiscoveredPort{
+				Port:     int64(port.Port),
+				Protocol: port.Protocol.String(),
+			})
 		}
-		return nsp.ProcessResults(ctx)
-	})
+		if _, ok := discovered[res.IP]; !ok {
+			discovered[res.IP] = nil
+		}
+		discoveredMu.Unlock()
 
-	options := naabuRunner.Options{
-		Host:       goflags.StringSlice{network.CidrString()},
-		ScanType:   "c",
-		Silent:     true,
-		Ping:       true,
-		ReversePTR: true,
-		JSON:       true,
-		Stream:     false,
-		Nmap:       true,
-		NmapCLI:    fmt.Sprintf("nmap -oX %s -A -O -sV -v0 --noninteractive", scanPipe),
-		Output:     "/dev/null",
-		OnResult: func(res *naabuResult.HostResult) {
-			logrus.Debugf("Found host %s", res.IP)
-			if err := w.saveDiscoveredHost(pCtx, network, res); err != nil {
-				logrus.Errorf("failed to receive host result: %s", err)
-			}
+		if err := w.saveDiscoveredHost(pCtx, network, res); err != nil {
+			logrus.Errorf("failed to receive host result: %s", err)
+		}
 
+		if snmpGatherer != nil {
 			snmpGatherer.AddTarget(res.IP)
-		},
+		}
+	}
+
+	if runNmap {
+		scansDir := path.Join(os.TempDir(), "maparoon")
+		if err := os.MkdirAll(scansDir, 0750); err != nil {
+			return fmt.Errorf("could not create maparoon temp directory: %w", err)
+		}
+
+		scanPipe := path.Join(scansDir, fmt.Sprintf("network-%d.sock", network.ID))
+		if err := os.Remove(scanPipe); err != nil {
+			logrus.Infof("could not remove existing fifo: %s", err)
+		}
+
+		if err := unix.Mkfifo(scanPipe, 0666); err != nil {
+			return fmt.Errorf("could not create fifo for nmap scan: %w", err)
+		}
+
+		procEg.Go(func() error {
+			nsp := &nmapScanProcessor{
+				network,
+				scanPipe,
+				scanDoneCh,
+				nmapHostResultCh,
+			}
+			return nsp.ProcessResults(ctx)
+		})
+
+		options.Nmap = true
+		options.NmapCLI = nmapCommand(scanPipe, network, profile)
 	}
 
 	runner, err := naabuRunner.NewRunner(&options)
@@ -158,7 +241,9 @@ func (w *worker) startNetworkScanSingle(pCtx context.Context, network database.N
 		return err
 	}
 
-	scanDoneCh <- true
+	if runNmap {
+		scanDoneCh <- true
+	}
 
 	if err = procEg.Wait(); err != nil {
 		logrus.Errorf("error while processing scan results: %s", err)
@@ -167,8 +252,16 @@ func (w *worker) startNetworkScanSingle(pCtx context.Context, network database.N
 
 	// Nmap scan reader is done here, collate host results with SNMP gather?
 	hostScanResults := make(map[string]*types.HostScanDocument)
-	pendingNmapResults := networkSize
-	pendingSnmpResults := networkSize
+	pendingNmapResults := 0
+	if runNmap {
+		pendingNmapResults = networkSize
+	}
+	pendingSnmpResults := 0
+	var snmpResultCh <-chan types.SnmpHostScan
+	if snmpGatherer != nil {
+		pendingSnmpResults = networkSize
+		snmpResultCh = snmpGatherer.ReceiveChannel()
+	}
 	for pendingNmapResults > 0 || pendingSnmpResults > 0 {
 		select {
 		case nmapResult := <-nmapHostResultCh:
@@ -179,7 +272,7 @@ func (w *worker) startNetworkScanSingle(pCtx context.Context, network database.N
 			}
 			hostScanResults[nmapResult.Address].Nmap = &nmapResult.NmapHostScanDocument
 			pendingNmapResults -= 1
-		case snmpResult := <-snmpGatherer.ReceiveChannel():
+		case snmpResult := <-snmpResultCh:
 			if _, ok := hostScanResults[snmpResult.Address]; !ok {
 				hostScanResults[snmpResult.Address] = new(types.HostScanDocument)
 				hostScanResults[snmpResult.Address].Address = snmpResult.Address
@@ -187,15 +280,36 @@ func (w *worker) startNetworkScanSingle(pCtx context.Context, network database.N
 			}
 			hostScanResults[snmpResult.Address].Snmp = &snmpResult.SnmpHostScanDocument
 			pendingSnmpResults -= 1
+
+			if snmpResult.CredentialId != 0 && snmpResult.CredentialId != grant.KnownSnmpCredentials[snmpResult.Address] {
+				w.rememberSnmpCredential(ctx, snmpResult.Address, snmpResult.CredentialId)
+			}
 		case <-time.After(1 * time.Second):
 			logrus.Debugf("pending worker results [nmap/snmp]: %d/%d", pendingNmapResults, pendingSnmpResults)
 		}
 	}
 
-	scanDocs := maps.Values(hostScanResults)
+	for address, ports := range discovered {
+		if _, ok := hostScanResults[address]; !ok {
+			hostScanResults[address] = new(types.HostScanDocument)
+			hostScanResults[address].Address = address
+			hostScanResults[address].Network = network
+		}
+		hostScanResults[address].OpenPorts = ports
+	}
+
+	return w.submitHostScans(ctx, grant, maps.Values(hostScanResults))
+}
+
+// submitHostScans indexes the scan's results, which finishes it, and evaluates
+// the network's alert rules against them
+func (w *worker) submitHostScans(ctx context.Context, grant types.ScanLeaseGrant, scanDocs []*types.HostScanDocument) error {
+	network, scan := grant.Network, grant.Scan
 	resp, err := w.apiClient.CreateHostScans(ctx, types.CreateHostScansRequest{
 		HostScans: scanDocs,
 		NetworkId: network.ID,
+		ScanId:    scan.ID,
+		WorkerId:  w.cfg.Worker.Id,
 	})
 	if err != nil {
 		logrus.Errorf("could not index host scans for network %s: %s", network.Name, err.Error())
@@ -204,9 +318,77 @@ func (w *worker) startNetworkScanSingle(pCtx context.Context, network database.N
 
 	logrus.Debugf("host scans response: %s", resp.Message)
 
+	alertsResp, err := w.apiClient.EvaluateAlerts(ctx, network.ID, scan.ID)
+	if err != nil {
+		logrus.Errorf("could not evaluate alert rules for network %s: %s", network.Name, err.Error())
+		return err
+	}
+
+	if len(alertsResp.Alerts) > 0 {
+		logrus.Infof("scan of network %s fired %d alerts", network.Name, len(alertsResp.Alerts))
+	}
+
 	return nil
 }
 
+// rememberSnmpCredential records the credential that worked for the host, so
+// that it's tried first next time
+func (w *worker) rememberSnmpCredential(ctx context.Context, address string, credentialId int64) {
+	err := w.apiClient.SetHostAttribute(ctx, address, types.SnmpCredentialAttribute, fmt.Sprint(credentialId))
+	if err != nil {
+		logrus.Warnf("could not remember snmp credential for host %s: %s", address, err)
+	}
+}
+
+// naabuOptions configures naabu's host discovery and port scan of the targets
+// from the profile
+func naabuOptions(network database.Network, profile database.ScanProfile, targets []string) naabuRunner.Options {
+	options := naabuRunner.Options{
+		Host:       goflags.StringSlice(targets),
+		ScanType:   "c",
+		Silent:     true,
+		Ping:       true,
+		ReversePTR: true,
+		JSON:       true,
+		Stream:     false,
+		Output:     "/dev/null",
+		Rate:       int(profile.Rate),
+	}
+
+	if network.IsIpv6() {
+		options.IPVersion = goflags.StringSlice{"6"}
+	}
+
+	switch profile.ScanType {
+	case types.ScanTypeSyn:
+		options.ScanType = "s"
+	case types.ScanTypePing:
+		options.OnlyHostDiscovery = true
+	}
+
+	if profile.Ports != "" {
+		options.Ports = profile.Ports
+	} else {
+		options.TopPorts = profile.TopPorts
+	}
+
+	return options
+}
+
+// nmapCommand is the nmap command naabu runs against the discovered ports,
+// writing its XML results to scanPipe
+func nmapCommand(scanPipe string, network database.Network, profile database.ScanProfile) string {
+	command := fmt.Sprintf("nmap -oX %s %s -v0 --noninteractive", scanPipe, profile.NmapFlags)
+	if network.IsIpv6() {
+		command += " -6"
+	}
+	if profile.NmapScripts != "" {
+		command += " --script " + profile.NmapScripts
+	}
+
+	return command
+}
+
 func (w *worker) saveDiscoveredHost(ctx context.Context, network database.Network, scanResult *naabuResult.HostResult) error {
 	var hostAddress string
 